@@ -15,6 +15,10 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+
+	"github.com/jmoiron/sqlx"
 )
 
 const (
@@ -50,11 +54,13 @@ func run() error {
 			return fmt.Errorf("usage: %s migrate <up|down>", os.Args[0])
 		}
 		return runMigrations(cfg, os.Args[2])
+	case "rotate-storage-keys":
+		return rotateStorageKeys(cfg)
 	case "version":
 		fmt.Printf("Terraform Registry v%s\n", version)
 		return nil
 	default:
-		return fmt.Errorf("unknown command: %s\nAvailable commands: serve, migrate, version", command)
+		return fmt.Errorf("unknown command: %s\nAvailable commands: serve, migrate, rotate-storage-keys, version", command)
 	}
 }
 
@@ -181,3 +187,34 @@ func runMigrations(cfg *config.Config, direction string) error {
 	log.Printf("Migration completed successfully. Current version: %d (dirty: %v)", version, dirty)
 	return nil
 }
+
+// rotateStorageKeys re-wraps the data keys of encrypted files with the current master key, after
+// which retired master keys can be removed from the configuration
+func rotateStorageKeys(cfg *config.Config) error {
+	if !cfg.Storage.Encryption.Enabled {
+		return fmt.Errorf("storage encryption is not enabled")
+	}
+
+	wrapper, err := storage.NewKeyWrapper(cfg.Storage.Encryption)
+	if err != nil {
+		return err
+	}
+
+	// Connect to database
+	database, err := db.Connect(cfg.Database.GetDSN(), cfg.Database.MaxConnections)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	log.Printf("Re-wrapping storage data keys with master key %s", wrapper.KeyID())
+
+	dataKeyRepo := repositories.NewStorageDataKeyRepository(sqlx.NewDb(database, "postgres"))
+	rewrapped, err := storage.RewrapDataKeys(context.Background(), dataKeyRepo, wrapper)
+	if err != nil {
+		return fmt.Errorf("key rotation stopped after re-wrapping %d data keys: %w", rewrapped, err)
+	}
+
+	log.Printf("Key rotation completed successfully. Re-wrapped %d data keys", rewrapped)
+	return nil
+}
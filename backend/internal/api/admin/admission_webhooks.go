@@ -0,0 +1,229 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// AdmissionWebhookHandlers handles admission webhook management
+type AdmissionWebhookHandlers struct {
+	webhookRepo *repositories.AdmissionWebhookRepository
+}
+
+// NewAdmissionWebhookHandlers creates a new admission webhook handlers instance
+func NewAdmissionWebhookHandlers(webhookRepo *repositories.AdmissionWebhookRepository) *AdmissionWebhookHandlers {
+	return &AdmissionWebhookHandlers{webhookRepo: webhookRepo}
+}
+
+// AdmissionWebhookRequest represents the request to create or update an admission webhook
+type AdmissionWebhookRequest struct {
+	OrganizationID *string `json:"organization_id"`
+	Name           string  `json:"name" binding:"required"`
+	Description    string  `json:"description"`
+	URL            string  `json:"url" binding:"required"`
+	ArtifactType   string  `json:"artifact_type" binding:"required"` // "module" or "provider"
+	TimeoutSeconds int     `json:"timeout_seconds"`
+	FailurePolicy  string  `json:"failure_policy"` // "fail_open" or "fail_closed"
+	IsActive       bool    `json:"is_active"`
+}
+
+// validate checks the webhook URL, artifact type, timeout and failure policy, applying defaults
+func (req *AdmissionWebhookRequest) validate() string {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "URL must be an absolute http or https URL"
+	}
+
+	artifactType := models.ArtifactType(req.ArtifactType)
+	if artifactType != models.ArtifactTypeModule && artifactType != models.ArtifactTypeProvider {
+		return "Artifact type must be 'module' or 'provider'"
+	}
+
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = 10
+	}
+	if req.TimeoutSeconds < 1 || req.TimeoutSeconds > 60 {
+		return "Timeout must be between 1 and 60 seconds"
+	}
+
+	if req.FailurePolicy == "" {
+		req.FailurePolicy = string(models.AdmissionFailClosed)
+	}
+	policy := models.AdmissionFailurePolicy(req.FailurePolicy)
+	if policy != models.AdmissionFailOpen && policy != models.AdmissionFailClosed {
+		return "Failure policy must be 'fail_open' or 'fail_closed'"
+	}
+
+	return ""
+}
+
+// ListAdmissionWebhooks lists all admission webhooks
+// GET /api/v1/admin/admission-webhooks
+func (h *AdmissionWebhookHandlers) ListAdmissionWebhooks(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	hooks, err := h.webhookRepo.List(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list admission webhooks"})
+		return
+	}
+	if hooks == nil {
+		hooks = []*models.AdmissionWebhook{}
+	}
+
+	c.JSON(http.StatusOK, hooks)
+}
+
+// GetAdmissionWebhook returns a single admission webhook
+// GET /api/v1/admin/admission-webhooks/:id
+func (h *AdmissionWebhookHandlers) GetAdmissionWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	hook, err := h.webhookRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get admission webhook"})
+		return
+	}
+	if hook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// CreateAdmissionWebhook creates a new admission webhook
+// POST /api/v1/admin/admission-webhooks
+func (h *AdmissionWebhookHandlers) CreateAdmissionWebhook(c *gin.Context) {
+	var req AdmissionWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var orgID *uuid.UUID
+	if req.OrganizationID != nil {
+		id, err := uuid.Parse(*req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	// Get creator ID from context
+	var createdBy *uuid.UUID
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				createdBy = &id
+			}
+		}
+	}
+
+	hook := &models.AdmissionWebhook{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    &req.Description,
+		URL:            req.URL,
+		ArtifactType:   models.ArtifactType(req.ArtifactType),
+		TimeoutSeconds: req.TimeoutSeconds,
+		FailurePolicy:  models.AdmissionFailurePolicy(req.FailurePolicy),
+		IsActive:       req.IsActive,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		CreatedBy:      createdBy,
+	}
+
+	if err := h.webhookRepo.Create(c.Request.Context(), hook); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admission webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hook)
+}
+
+// UpdateAdmissionWebhook updates an existing admission webhook
+// PUT /api/v1/admin/admission-webhooks/:id
+func (h *AdmissionWebhookHandlers) UpdateAdmissionWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	existing, err := h.webhookRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get admission webhook"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admission webhook not found"})
+		return
+	}
+
+	var req AdmissionWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	existing.Name = req.Name
+	existing.Description = &req.Description
+	existing.URL = req.URL
+	existing.ArtifactType = models.ArtifactType(req.ArtifactType)
+	existing.TimeoutSeconds = req.TimeoutSeconds
+	existing.FailurePolicy = models.AdmissionFailurePolicy(req.FailurePolicy)
+	existing.IsActive = req.IsActive
+
+	if err := h.webhookRepo.Update(c.Request.Context(), existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admission webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, existing)
+}
+
+// DeleteAdmissionWebhook deletes an admission webhook
+// DELETE /api/v1/admin/admission-webhooks/:id
+func (h *AdmissionWebhookHandlers) DeleteAdmissionWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := h.webhookRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete admission webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admission webhook deleted"})
+}
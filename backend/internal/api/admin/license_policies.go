@@ -0,0 +1,227 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// LicensePolicyHandlers handles license policy management
+type LicensePolicyHandlers struct {
+	policyRepo *repositories.LicensePolicyRepository
+}
+
+// NewLicensePolicyHandlers creates a new license policy handlers instance
+func NewLicensePolicyHandlers(policyRepo *repositories.LicensePolicyRepository) *LicensePolicyHandlers {
+	return &LicensePolicyHandlers{policyRepo: policyRepo}
+}
+
+// LicensePolicyRequest represents the request to create or update a license policy
+type LicensePolicyRequest struct {
+	OrganizationID *string  `json:"organization_id"`
+	Name           string   `json:"name" binding:"required"`
+	Description    string   `json:"description"`
+	ArtifactType   string   `json:"artifact_type" binding:"required"` // "module" or "provider"
+	PolicyType     string   `json:"policy_type" binding:"required"`   // "allowlist" or "denylist"
+	Licenses       []string `json:"licenses"`                         // SPDX identifiers, e.g. ["MIT", "Apache-2.0"]
+	AllowUnknown   bool     `json:"allow_unknown"`
+	IsActive       bool     `json:"is_active"`
+}
+
+// validate checks the artifact type, policy type and license list, trimming identifiers
+func (req *LicensePolicyRequest) validate() string {
+	artifactType := models.ArtifactType(req.ArtifactType)
+	if artifactType != models.ArtifactTypeModule && artifactType != models.ArtifactTypeProvider {
+		return "Artifact type must be 'module' or 'provider'"
+	}
+
+	policyType := models.LicensePolicyType(req.PolicyType)
+	if policyType != models.LicensePolicyAllowlist && policyType != models.LicensePolicyDenylist {
+		return "Policy type must be 'allowlist' or 'denylist'"
+	}
+
+	licenses := make([]string, 0, len(req.Licenses))
+	for _, license := range req.Licenses {
+		license = strings.TrimSpace(license)
+		if license == "" {
+			continue
+		}
+		if strings.ContainsAny(license, " ,") {
+			return "Licenses must be individual SPDX identifiers"
+		}
+		licenses = append(licenses, license)
+	}
+	req.Licenses = licenses
+
+	return ""
+}
+
+// ListLicensePolicies lists all license policies
+// GET /api/v1/admin/license-policies
+func (h *LicensePolicyHandlers) ListLicensePolicies(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	policies, err := h.policyRepo.List(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list license policies"})
+		return
+	}
+	if policies == nil {
+		policies = []*models.LicensePolicy{}
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// GetLicensePolicy returns a single license policy
+// GET /api/v1/admin/license-policies/:id
+func (h *LicensePolicyHandlers) GetLicensePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	policy, err := h.policyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get license policy"})
+		return
+	}
+	if policy == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "License policy not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// CreateLicensePolicy creates a new license policy
+// POST /api/v1/admin/license-policies
+func (h *LicensePolicyHandlers) CreateLicensePolicy(c *gin.Context) {
+	var req LicensePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var orgID *uuid.UUID
+	if req.OrganizationID != nil {
+		id, err := uuid.Parse(*req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	// Get creator ID from context
+	var createdBy *uuid.UUID
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				createdBy = &id
+			}
+		}
+	}
+
+	policy := &models.LicensePolicy{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		Name:           req.Name,
+		Description:    &req.Description,
+		ArtifactType:   models.ArtifactType(req.ArtifactType),
+		PolicyType:     models.LicensePolicyType(req.PolicyType),
+		AllowUnknown:   req.AllowUnknown,
+		IsActive:       req.IsActive,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+		CreatedBy:      createdBy,
+	}
+	policy.SetLicenseList(req.Licenses)
+
+	if err := h.policyRepo.Create(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create license policy"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, policy)
+}
+
+// UpdateLicensePolicy updates an existing license policy
+// PUT /api/v1/admin/license-policies/:id
+func (h *LicensePolicyHandlers) UpdateLicensePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	existing, err := h.policyRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get license policy"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "License policy not found"})
+		return
+	}
+
+	var req LicensePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	existing.Name = req.Name
+	existing.Description = &req.Description
+	existing.ArtifactType = models.ArtifactType(req.ArtifactType)
+	existing.PolicyType = models.LicensePolicyType(req.PolicyType)
+	existing.SetLicenseList(req.Licenses)
+	existing.AllowUnknown = req.AllowUnknown
+	existing.IsActive = req.IsActive
+
+	if err := h.policyRepo.Update(c.Request.Context(), existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update license policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, existing)
+}
+
+// DeleteLicensePolicy deletes a license policy
+// DELETE /api/v1/admin/license-policies/:id
+func (h *LicensePolicyHandlers) DeleteLicensePolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+
+	if err := h.policyRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete license policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "License policy deleted"})
+}
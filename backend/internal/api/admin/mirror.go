@@ -3,21 +3,30 @@ package admin
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
 	"github.com/terraform-registry/terraform-registry/internal/mirror"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// MirrorSyncJobInterface defines the interface for triggering manual syncs
+// syncStreamHeartbeat is how often an idle sync progress stream sends a comment to keep proxies from
+// closing the connection
+const syncStreamHeartbeat = 15 * time.Second
+
+// MirrorSyncJobInterface defines the interface for triggering, cancelling and following syncs
 type MirrorSyncJobInterface interface {
 	TriggerManualSync(ctx context.Context, mirrorID uuid.UUID) error
+	CancelSync(ctx context.Context, mirrorID uuid.UUID) error
+	SubscribeSync(mirrorID uuid.UUID) (<-chan jobs.SyncEvent, func(), error)
 }
 
 // MirrorHandler handles mirror configuration endpoints
@@ -53,6 +62,13 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		return
 	}
 
+	if req.VersionFilter != nil {
+		if _, err := mirror.ParseVersionFilter(*req.VersionFilter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version filter: " + err.Error()})
+			return
+		}
+	}
+
 	// Check if name already exists
 	existing, err := h.mirrorRepo.GetByName(c.Request.Context(), req.Name)
 	if err != nil {
@@ -84,6 +100,11 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		syncInterval = *req.SyncIntervalHours
 	}
 
+	syncConcurrency := 4
+	if req.SyncConcurrency != nil {
+		syncConcurrency = *req.SyncConcurrency
+	}
+
 	// Parse organization ID if provided
 	var orgID *uuid.UUID
 	if req.OrganizationID != nil && *req.OrganizationID != "" {
@@ -125,6 +146,7 @@ func (h *MirrorHandler) CreateMirrorConfig(c *gin.Context) {
 		PlatformFilter:      platformFilter,
 		Enabled:             enabled,
 		SyncIntervalHours:   syncInterval,
+		SyncConcurrency:     syncConcurrency,
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 		CreatedBy:           createdBy,
@@ -254,6 +276,10 @@ func (h *MirrorHandler) UpdateMirrorConfig(c *gin.Context) {
 
 	if req.VersionFilter != nil {
 		if *req.VersionFilter != "" {
+			if _, err := mirror.ParseVersionFilter(*req.VersionFilter); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version filter: " + err.Error()})
+				return
+			}
 			config.VersionFilter = req.VersionFilter
 		} else {
 			config.VersionFilter = nil
@@ -291,6 +317,10 @@ func (h *MirrorHandler) UpdateMirrorConfig(c *gin.Context) {
 		config.SyncIntervalHours = *req.SyncIntervalHours
 	}
 
+	if req.SyncConcurrency != nil {
+		config.SyncConcurrency = *req.SyncConcurrency
+	}
+
 	if err := h.mirrorRepo.Update(c.Request.Context(), config); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update mirror configuration: " + err.Error()})
 		return
@@ -366,6 +396,86 @@ func (h *MirrorHandler) TriggerSync(c *gin.Context) {
 	})
 }
 
+// CancelSync stops the sync running for a mirror configuration
+// DELETE /api/v1/admin/mirrors/:id/sync
+func (h *MirrorHandler) CancelSync(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+
+	if h.syncJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sync job not configured"})
+		return
+	}
+
+	if err := h.syncJob.CancelSync(c.Request.Context(), id); err != nil {
+		if errors.Is(err, jobs.ErrNoActiveSync) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No sync in progress for this mirror"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel sync: " + err.Error()})
+		return
+	}
+
+	log.Printf("API: Cancelled sync for mirror %s", id)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Sync cancelled",
+	})
+}
+
+// StreamSyncProgress streams the progress events of the sync running for a mirror configuration
+// as Server-Sent Events. The stream ends when the sync does.
+// GET /api/v1/admin/mirrors/:id/sync/events
+func (h *MirrorHandler) StreamSyncProgress(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mirror ID"})
+		return
+	}
+
+	if h.syncJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sync job not configured"})
+		return
+	}
+
+	events, unsubscribe, err := h.syncJob.SubscribeSync(id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrNoActiveSync) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No sync in progress for this mirror"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow sync: " + err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(syncStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		}
+	})
+}
+
 // GetMirrorStatus retrieves the status and sync history for a mirror configuration
 // GET /api/v1/admin/mirrors/:id/status
 func (h *MirrorHandler) GetMirrorStatus(c *gin.Context) {
@@ -428,6 +538,8 @@ func (h *MirrorHandler) RegisterRoutes(router *gin.RouterGroup) {
 		mirrors.PUT("/:id", h.UpdateMirrorConfig)
 		mirrors.DELETE("/:id", h.DeleteMirrorConfig)
 		mirrors.POST("/:id/sync", h.TriggerSync)
+		mirrors.DELETE("/:id/sync", h.CancelSync)
+		mirrors.GET("/:id/sync/events", h.StreamSyncProgress)
 		mirrors.GET("/:id/status", h.GetMirrorStatus)
 	}
 }
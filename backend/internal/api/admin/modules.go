@@ -5,27 +5,35 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // ModuleAdminHandlers handles administrative module operations
 type ModuleAdminHandlers struct {
-	moduleRepo     *repositories.ModuleRepository
-	orgRepo        *repositories.OrganizationRepository
-	storageBackend storage.Storage
-	cfg            *config.Config
+	moduleRepo      *repositories.ModuleRepository
+	orgRepo         *repositories.OrganizationRepository
+	publishRuleRepo *repositories.PublishRuleRepository
+	signerRepo      *repositories.TrustedSignerRepository
+	backends        *storage.Registry
+	cfg             *config.Config
 }
 
 // NewModuleAdminHandlers creates a new module admin handlers instance
-func NewModuleAdminHandlers(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) *ModuleAdminHandlers {
+func NewModuleAdminHandlers(db *sql.DB, backends *storage.Registry, cfg *config.Config) *ModuleAdminHandlers {
+	sqlxDB := sqlx.NewDb(db, "postgres")
 	return &ModuleAdminHandlers{
-		moduleRepo:     repositories.NewModuleRepository(db),
-		orgRepo:        repositories.NewOrganizationRepository(db),
-		storageBackend: storageBackend,
-		cfg:            cfg,
+		moduleRepo:      repositories.NewModuleRepository(db),
+		orgRepo:         repositories.NewOrganizationRepository(db),
+		publishRuleRepo: repositories.NewPublishRuleRepository(sqlxDB),
+		signerRepo:      repositories.NewTrustedSignerRepository(sqlxDB),
+		backends:        backends,
+		cfg:             cfg,
 	}
 }
 
@@ -135,6 +143,9 @@ func (h *ModuleAdminHandlers) GetModule(c *gin.Context) {
 			"download_count": v.DownloadCount,
 			"deprecated":     v.Deprecated,
 			"created_at":     v.CreatedAt,
+			// Cosign signature and SLSA provenance verification status
+			"signature_status":  v.SignatureStatus,
+			"provenance_status": v.ProvenanceStatus,
 		}
 		if v.DeprecatedAt != nil {
 			versionData["deprecated_at"] = v.DeprecatedAt
@@ -142,6 +153,9 @@ func (h *ModuleAdminHandlers) GetModule(c *gin.Context) {
 		if v.DeprecationMessage != nil {
 			versionData["deprecation_message"] = v.DeprecationMessage
 		}
+		if v.LicenseSPDX != nil {
+			versionData["license"] = *v.LicenseSPDX
+		}
 		versionsList = append(versionsList, versionData)
 	}
 
@@ -201,7 +215,10 @@ func (h *ModuleAdminHandlers) DeleteModule(c *gin.Context) {
 	for _, v := range versions {
 		if v.StoragePath != "" {
 			// Try to delete from storage (ignore errors - file might not exist)
-			_ = h.storageBackend.Delete(c.Request.Context(), v.StoragePath)
+			_ = h.backends.Delete(c.Request.Context(), v.StorageBackend, v.StoragePath)
+			for _, format := range sbom.Formats {
+				_ = h.backends.Default().Delete(c.Request.Context(), sbom.ModulePath(module.Namespace, module.Name, module.System, v.Version, format))
+			}
 		}
 	}
 
@@ -265,7 +282,10 @@ func (h *ModuleAdminHandlers) DeleteVersion(c *gin.Context) {
 
 	// Delete file from storage
 	if versionRecord.StoragePath != "" {
-		_ = h.storageBackend.Delete(c.Request.Context(), versionRecord.StoragePath)
+		_ = h.backends.Delete(c.Request.Context(), versionRecord.StorageBackend, versionRecord.StoragePath)
+		for _, format := range sbom.Formats {
+			_ = h.backends.Default().Delete(c.Request.Context(), sbom.ModulePath(module.Namespace, module.Name, module.System, versionRecord.Version, format))
+		}
 	}
 
 	// Delete version from database
@@ -416,3 +436,134 @@ func (h *ModuleAdminHandlers) UndeprecateVersion(c *gin.Context) {
 		"version":   version,
 	})
 }
+
+// ListSecretFindings returns the secret scan findings recorded for a version
+// GET /api/v1/modules/:namespace/:name/:system/versions/:version/secret-findings
+func (h *ModuleAdminHandlers) ListSecretFindings(c *gin.Context) {
+	versionRecord, ok := h.resolveVersion(c)
+	if !ok {
+		return
+	}
+
+	findings, err := h.moduleRepo.ListSecretFindings(c.Request.Context(), versionRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list secret findings"})
+		return
+	}
+
+	findingsList := make([]gin.H, 0, len(findings))
+	for _, f := range findings {
+		findingsList = append(findingsList, gin.H{
+			"id":             f.ID,
+			"rule":           f.Rule,
+			"file":           f.FilePath,
+			"line":           f.LineNumber,
+			"description":    f.Description,
+			"redacted_match": f.RedactedMatch,
+			"created_at":     f.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version":  versionRecord.Version,
+		"findings": findingsList,
+	})
+}
+
+// ListPolicyResults returns the publish rule results recorded for a version
+// GET /api/v1/modules/:namespace/:name/:system/versions/:version/policy-results
+func (h *ModuleAdminHandlers) ListPolicyResults(c *gin.Context) {
+	versionRecord, ok := h.resolveVersion(c)
+	if !ok {
+		return
+	}
+
+	versionID, err := uuid.Parse(versionRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid version ID"})
+		return
+	}
+
+	results, err := h.publishRuleRepo.ListVersionResults(c.Request.Context(), versionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list policy results"})
+		return
+	}
+	if results == nil {
+		results = []*models.ModuleVersionPolicyResult{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version": versionRecord.Version,
+		"results": results,
+	})
+}
+
+// ListAttestations returns the signatures and provenance attestations recorded for a version
+// GET /api/v1/modules/:namespace/:name/:system/versions/:version/attestations
+func (h *ModuleAdminHandlers) ListAttestations(c *gin.Context) {
+	versionRecord, ok := h.resolveVersion(c)
+	if !ok {
+		return
+	}
+
+	versionID, err := uuid.Parse(versionRecord.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid version ID"})
+		return
+	}
+
+	attestations, err := h.signerRepo.ListAttestations(c.Request.Context(), versionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list attestations"})
+		return
+	}
+	if attestations == nil {
+		attestations = []*models.ModuleVersionAttestation{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version":           versionRecord.Version,
+		"signature_status":  versionRecord.SignatureStatus,
+		"provenance_status": versionRecord.ProvenanceStatus,
+		"attestations":      attestations,
+	})
+}
+
+// resolveVersion looks up the module version addressed by the namespace, name, system
+// and version path parameters. It writes an error response and returns false when the
+// version cannot be resolved.
+func (h *ModuleAdminHandlers) resolveVersion(c *gin.Context) (*models.ModuleVersion, bool) {
+	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return nil, false
+	}
+
+	var orgID string
+	if org != nil {
+		orgID = org.ID
+	}
+
+	module, err := h.moduleRepo.GetModule(c.Request.Context(), orgID, c.Param("namespace"), c.Param("name"), c.Param("system"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get module"})
+		return nil, false
+	}
+	if module == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return nil, false
+	}
+
+	versionRecord, err := h.moduleRepo.GetVersion(c.Request.Context(), module.ID, c.Param("version"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get version"})
+		return nil, false
+	}
+	if versionRecord == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return nil, false
+	}
+
+	return versionRecord, true
+}
@@ -7,24 +7,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // ProviderAdminHandlers handles administrative provider operations
 type ProviderAdminHandlers struct {
-	providerRepo   *repositories.ProviderRepository
-	orgRepo        *repositories.OrganizationRepository
-	storageBackend storage.Storage
-	cfg            *config.Config
+	providerRepo *repositories.ProviderRepository
+	orgRepo      *repositories.OrganizationRepository
+	backends     *storage.Registry
+	cfg          *config.Config
 }
 
 // NewProviderAdminHandlers creates a new provider admin handlers instance
-func NewProviderAdminHandlers(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) *ProviderAdminHandlers {
+func NewProviderAdminHandlers(db *sql.DB, backends *storage.Registry, cfg *config.Config) *ProviderAdminHandlers {
 	return &ProviderAdminHandlers{
-		providerRepo:   repositories.NewProviderRepository(db),
-		orgRepo:        repositories.NewOrganizationRepository(db),
-		storageBackend: storageBackend,
-		cfg:            cfg,
+		providerRepo: repositories.NewProviderRepository(db),
+		orgRepo:      repositories.NewOrganizationRepository(db),
+		backends:     backends,
+		cfg:          cfg,
 	}
 }
 
@@ -94,6 +95,9 @@ func (h *ProviderAdminHandlers) GetProvider(c *gin.Context) {
 		if v.DeprecationMessage != nil {
 			versionData["deprecation_message"] = v.DeprecationMessage
 		}
+		if v.LicenseSPDX != nil {
+			versionData["license"] = *v.LicenseSPDX
+		}
 		versionsList = append(versionsList, versionData)
 	}
 
@@ -152,9 +156,12 @@ func (h *ProviderAdminHandlers) DeleteProvider(c *gin.Context) {
 		for _, p := range platforms {
 			if p.StoragePath != "" {
 				// Try to delete from storage (ignore errors - file might not exist)
-				_ = h.storageBackend.Delete(c.Request.Context(), p.StoragePath)
+				_ = h.backends.Delete(c.Request.Context(), p.StorageBackend, p.StoragePath)
 			}
 		}
+		for _, format := range sbom.Formats {
+			_ = h.backends.Default().Delete(c.Request.Context(), sbom.ProviderPath(provider.Namespace, provider.Type, v.Version, format))
+		}
 	}
 
 	// Delete provider from database (cascades to versions and platforms)
@@ -217,9 +224,12 @@ func (h *ProviderAdminHandlers) DeleteVersion(c *gin.Context) {
 	platforms, _ := h.providerRepo.ListPlatforms(c.Request.Context(), versionRecord.ID)
 	for _, p := range platforms {
 		if p.StoragePath != "" {
-			_ = h.storageBackend.Delete(c.Request.Context(), p.StoragePath)
+			_ = h.backends.Delete(c.Request.Context(), p.StorageBackend, p.StoragePath)
 		}
 	}
+	for _, format := range sbom.Formats {
+		_ = h.backends.Default().Delete(c.Request.Context(), sbom.ProviderPath(provider.Namespace, provider.Type, versionRecord.Version, format))
+	}
 
 	// Delete version from database (cascades to platforms)
 	if err := h.providerRepo.DeleteVersion(c.Request.Context(), versionRecord.ID); err != nil {
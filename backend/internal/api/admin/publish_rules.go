@@ -0,0 +1,225 @@
+package admin
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// PublishRuleHandlers handles module publish rule management
+type PublishRuleHandlers struct {
+	ruleRepo *repositories.PublishRuleRepository
+}
+
+// NewPublishRuleHandlers creates a new publish rule handlers instance
+func NewPublishRuleHandlers(ruleRepo *repositories.PublishRuleRepository) *PublishRuleHandlers {
+	return &PublishRuleHandlers{ruleRepo: ruleRepo}
+}
+
+// PublishRuleRequest represents the request to create or update a publish rule
+type PublishRuleRequest struct {
+	OrganizationID   *string `json:"organization_id"`
+	Name             string  `json:"name" binding:"required"`
+	Description      string  `json:"description"`
+	RuleType         string  `json:"rule_type" binding:"required"`
+	Enforcement      string  `json:"enforcement" binding:"required"` // "block" or "warn"
+	Parameter        *string `json:"parameter"`
+	NamespacePattern *string `json:"namespace_pattern"`
+	IsActive         bool    `json:"is_active"`
+}
+
+// validate checks the rule type, enforcement and rule-specific parameter
+func (req *PublishRuleRequest) validate() string {
+	if !models.PublishRuleType(req.RuleType).IsValid() {
+		return "Invalid rule type"
+	}
+	enforcement := models.RuleEnforcement(req.Enforcement)
+	if enforcement != models.RuleEnforcementBlock && enforcement != models.RuleEnforcementWarn {
+		return "Enforcement must be 'block' or 'warn'"
+	}
+	if models.PublishRuleType(req.RuleType) == models.PublishRuleVersionPattern && req.Parameter != nil && *req.Parameter != "" {
+		if _, err := regexp.Compile(*req.Parameter); err != nil {
+			return "Parameter must be a valid regular expression for version_pattern rules"
+		}
+	}
+	if models.PublishRuleType(req.RuleType) == models.PublishRuleSignatureRequired && req.Parameter != nil && *req.Parameter != "" {
+		if !strings.EqualFold(strings.TrimSpace(*req.Parameter), "provenance") {
+			return "Parameter must be empty or 'provenance' for signature_required rules"
+		}
+	}
+	return ""
+}
+
+// ListPublishRules lists all publish rules
+// GET /api/v1/admin/publish-rules
+func (h *PublishRuleHandlers) ListPublishRules(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	rules, err := h.ruleRepo.ListRules(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list publish rules"})
+		return
+	}
+	if rules == nil {
+		rules = []*models.ModulePublishRule{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules":      rules,
+		"rule_types": models.ValidPublishRuleTypes,
+	})
+}
+
+// GetPublishRule returns a single publish rule
+// GET /api/v1/admin/publish-rules/:id
+func (h *PublishRuleHandlers) GetPublishRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	rule, err := h.ruleRepo.GetRule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get publish rule"})
+		return
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Publish rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreatePublishRule creates a new publish rule
+// POST /api/v1/admin/publish-rules
+func (h *PublishRuleHandlers) CreatePublishRule(c *gin.Context) {
+	var req PublishRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var orgID *uuid.UUID
+	if req.OrganizationID != nil {
+		id, err := uuid.Parse(*req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	// Get creator ID from context
+	var createdBy *uuid.UUID
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				createdBy = &id
+			}
+		}
+	}
+
+	rule := &models.ModulePublishRule{
+		ID:               uuid.New(),
+		OrganizationID:   orgID,
+		Name:             req.Name,
+		Description:      &req.Description,
+		RuleType:         models.PublishRuleType(req.RuleType),
+		Enforcement:      models.RuleEnforcement(req.Enforcement),
+		Parameter:        req.Parameter,
+		NamespacePattern: req.NamespacePattern,
+		IsActive:         req.IsActive,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		CreatedBy:        createdBy,
+	}
+
+	if err := h.ruleRepo.CreateRule(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create publish rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdatePublishRule updates an existing publish rule
+// PUT /api/v1/admin/publish-rules/:id
+func (h *PublishRuleHandlers) UpdatePublishRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	existing, err := h.ruleRepo.GetRule(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get publish rule"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Publish rule not found"})
+		return
+	}
+
+	var req PublishRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	existing.Name = req.Name
+	existing.Description = &req.Description
+	existing.RuleType = models.PublishRuleType(req.RuleType)
+	existing.Enforcement = models.RuleEnforcement(req.Enforcement)
+	existing.Parameter = req.Parameter
+	existing.NamespacePattern = req.NamespacePattern
+	existing.IsActive = req.IsActive
+
+	if err := h.ruleRepo.UpdateRule(c.Request.Context(), existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update publish rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, existing)
+}
+
+// DeletePublishRule deletes a publish rule
+// DELETE /api/v1/admin/publish-rules/:id
+func (h *PublishRuleHandlers) DeletePublishRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return
+	}
+
+	if err := h.ruleRepo.DeleteRule(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete publish rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Publish rule deleted"})
+}
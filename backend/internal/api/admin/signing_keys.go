@@ -0,0 +1,60 @@
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// SigningKeyHandlers handles management of the registry's module signing keys
+type SigningKeyHandlers struct {
+	orgRepo *repositories.OrganizationRepository
+	signer  *services.ModuleSigner
+}
+
+// NewSigningKeyHandlers creates a new signing key handlers instance
+func NewSigningKeyHandlers(db *sql.DB, signer *services.ModuleSigner) *SigningKeyHandlers {
+	return &SigningKeyHandlers{
+		orgRepo: repositories.NewOrganizationRepository(db),
+		signer:  signer,
+	}
+}
+
+// ListSigningKeys lists the organization's signing keys, including retired ones
+// GET /api/v1/admin/signing-keys
+func (h *SigningKeyHandlers) ListSigningKeys(c *gin.Context) {
+	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil || org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+
+	keys, err := h.signer.PublicKeys(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list signing keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RotateSigningKey generates a new active signing key and retires the current one
+// POST /api/v1/admin/signing-keys/rotate
+func (h *SigningKeyHandlers) RotateSigningKey(c *gin.Context) {
+	org, err := h.orgRepo.GetDefaultOrganization(c.Request.Context())
+	if err != nil || org == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get organization context"})
+		return
+	}
+
+	key, err := h.signer.RotateKey(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate signing key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
@@ -1,6 +1,7 @@
 package admin
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"time"
@@ -11,20 +12,35 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/crypto"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // StorageHandlers handles storage configuration CRUD operations
 type StorageHandlers struct {
 	cfg                   *config.Config
 	storageConfigRepo     *repositories.StorageConfigRepository
+	migrationRepo         *repositories.StorageMigrationRepository
+	migrationJob          StorageMigrationJobInterface
+	replicationRepo       *repositories.StorageReplicationRepository
+	replicationJob        StorageReplicationJobInterface
+	contentAddressingJob  ContentAddressingJobInterface
+	scrubJob              StorageScrubJobInterface
+	quotaRepo             *repositories.StorageQuotaRepository
+	quotas                *services.StorageQuotaService
+	orgRepo               *repositories.OrganizationRepository
+	backends              *storage.Registry
 	tokenCipher           *crypto.TokenCipher
 }
 
 // NewStorageHandlers creates a new storage handlers instance
-func NewStorageHandlers(cfg *config.Config, storageConfigRepo *repositories.StorageConfigRepository, tokenCipher *crypto.TokenCipher) *StorageHandlers {
+func NewStorageHandlers(cfg *config.Config, storageConfigRepo *repositories.StorageConfigRepository, migrationRepo *repositories.StorageMigrationRepository, replicationRepo *repositories.StorageReplicationRepository, backends *storage.Registry, tokenCipher *crypto.TokenCipher) *StorageHandlers {
 	return &StorageHandlers{
 		cfg:               cfg,
 		storageConfigRepo: storageConfigRepo,
+		migrationRepo:     migrationRepo,
+		replicationRepo:   replicationRepo,
+		backends:          backends,
 		tokenCipher:       tokenCipher,
 	}
 }
@@ -134,8 +150,8 @@ func (h *StorageHandlers) CreateStorageConfig(c *gin.Context) {
 	}
 
 	// Validate backend type
-	if input.BackendType != "local" && input.BackendType != "azure" && input.BackendType != "s3" && input.BackendType != "gcs" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid backend_type: must be local, azure, s3, or gcs"})
+	if input.BackendType != "local" && input.BackendType != "azure" && input.BackendType != "s3" && input.BackendType != "gcs" && input.BackendType != "replicated" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid backend_type: must be local, azure, s3, gcs, or replicated"})
 		return
 	}
 
@@ -144,6 +160,10 @@ func (h *StorageHandlers) CreateStorageConfig(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := h.validateReplicas(ctx, &input, uuid.Nil); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("user_id")
@@ -242,6 +262,10 @@ func (h *StorageHandlers) UpdateStorageConfig(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := h.validateReplicas(ctx, &input, id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Get user ID from context
 	userID, exists := c.Get("user_id")
@@ -262,6 +286,7 @@ func (h *StorageHandlers) UpdateStorageConfig(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update storage configuration"})
 		return
 	}
+	h.backends.Forget(id.String())
 
 	c.JSON(http.StatusOK, existing.ToResponse())
 }
@@ -295,15 +320,39 @@ func (h *StorageHandlers) DeleteStorageConfig(c *gin.Context) {
 		return
 	}
 
+	// Artifacts stored in this configuration would become unreadable
+	stored, err := h.storageConfigRepo.CountStoredArtifacts(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check stored artifacts"})
+		return
+	}
+	if stored > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete a storage configuration that still holds artifacts"})
+		return
+	}
+
+	// Replicated configurations would lose a replica
+	references, err := h.storageConfigRepo.CountReplicaReferences(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check replicated configurations"})
+		return
+	}
+	if references > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete a storage configuration used as a replica"})
+		return
+	}
+
 	if err := h.storageConfigRepo.DeleteStorageConfig(ctx, id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete storage configuration"})
 		return
 	}
+	h.backends.Forget(id.String())
 
 	c.JSON(http.StatusOK, gin.H{"message": "storage configuration deleted"})
 }
 
-// ActivateStorageConfig activates a storage configuration
+// ActivateStorageConfig activates a storage configuration. With ?migrate=true, existing
+// artifacts are copied to the newly activated backend by a background storage migration.
 // POST /api/v1/storage/configs/:id/activate
 func (h *StorageHandlers) ActivateStorageConfig(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -335,11 +384,22 @@ func (h *StorageHandlers) ActivateStorageConfig(c *gin.Context) {
 		}
 	}
 
+	// Remember the previously active config as the migration source
+	var previousID *uuid.UUID
+	if previous, err := h.storageConfigRepo.GetActiveStorageConfig(ctx); err == nil && previous != nil && previous.ID != id {
+		previousID = &previous.ID
+	}
+
 	if err := h.storageConfigRepo.ActivateStorageConfig(ctx, id, userUUID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to activate storage configuration"})
 		return
 	}
 
+	if c.Query("migrate") == "true" {
+		h.startMigration(c, previousID, id, true)
+		return
+	}
+
 	// Refresh the config
 	existing, _ = h.storageConfigRepo.GetStorageConfig(ctx, id)
 
@@ -418,10 +478,75 @@ func (h *StorageHandlers) validateStorageConfig(input *models.StorageConfigInput
 				return &ValidationError{Field: "gcs_credentials", Message: "credentials_file or credentials_json required for service_account auth"}
 			}
 		}
+	case "replicated":
+		if input.ReplicationPrimaryID == nil {
+			return &ValidationError{Field: "replication_primary_id", Message: "required for replicated storage"}
+		}
+		if len(input.ReplicationSecondaryIDs) == 0 {
+			return &ValidationError{Field: "replication_secondary_ids", Message: "at least one secondary is required for replicated storage"}
+		}
+		if input.ReplicationMode != "" && input.ReplicationMode != models.ReplicationModeSync && input.ReplicationMode != models.ReplicationModeAsync {
+			return &ValidationError{Field: "replication_mode", Message: "must be sync or async"}
+		}
+		seen := map[uuid.UUID]bool{*input.ReplicationPrimaryID: true}
+		for _, id := range input.ReplicationSecondaryIDs {
+			if seen[id] {
+				return &ValidationError{Field: "replication_secondary_ids", Message: "replicas must be distinct"}
+			}
+			seen[id] = true
+		}
+	}
+	return nil
+}
+
+// validateReplicas checks that the replicas of a replicated configuration are existing,
+// non-replicated configurations other than the configuration itself
+func (h *StorageHandlers) validateReplicas(ctx context.Context, input *models.StorageConfigInput, self uuid.UUID) error {
+	if input.BackendType != "replicated" {
+		return nil
+	}
+
+	replicaIDs := append([]uuid.UUID{*input.ReplicationPrimaryID}, input.ReplicationSecondaryIDs...)
+	for _, id := range replicaIDs {
+		if id == self {
+			return &ValidationError{Field: "replication_secondary_ids", Message: "a configuration cannot replicate to itself"}
+		}
+		replica, err := h.storageConfigRepo.GetStorageConfig(ctx, id)
+		if err != nil {
+			return err
+		}
+		if replica == nil {
+			return &ValidationError{Field: "replication_secondary_ids", Message: "storage configuration " + id.String() + " not found"}
+		}
+		if replica.BackendType == "replicated" {
+			return &ValidationError{Field: "replication_secondary_ids", Message: "replicated configurations cannot be used as replicas"}
+		}
 	}
 	return nil
 }
 
+// applyReplicationSettings copies the replicated storage settings of the input onto config,
+// clearing them for other backend types
+func applyReplicationSettings(config *models.StorageConfig, input *models.StorageConfigInput) {
+	if input.BackendType != "replicated" {
+		config.ReplicationPrimaryID = uuid.NullUUID{}
+		config.ReplicationSecondaryIDs = nil
+		config.ReplicationMode = sql.NullString{}
+		return
+	}
+
+	config.ReplicationPrimaryID = uuid.NullUUID{UUID: *input.ReplicationPrimaryID, Valid: true}
+	config.ReplicationSecondaryIDs = make([]string, 0, len(input.ReplicationSecondaryIDs))
+	for _, id := range input.ReplicationSecondaryIDs {
+		config.ReplicationSecondaryIDs = append(config.ReplicationSecondaryIDs, id.String())
+	}
+	mode := input.ReplicationMode
+	if mode == "" {
+		mode = models.ReplicationModeSync
+	}
+	config.ReplicationMode = sql.NullString{String: mode, Valid: true}
+}
+
 func (h *StorageHandlers) buildStorageConfig(input *models.StorageConfigInput, userID uuid.NullUUID) (*models.StorageConfig, error) {
 	now := time.Now()
 	config := &models.StorageConfig{
@@ -494,6 +619,7 @@ func (h *StorageHandlers) buildStorageConfig(input *models.StorageConfigInput, u
 			config.GCSCredentialsJSONEncrypted = sql.NullString{String: encrypted, Valid: true}
 		}
 	}
+	applyReplicationSettings(config, input)
 
 	return config, nil
 }
@@ -560,6 +686,7 @@ func (h *StorageHandlers) updateStorageConfigFromInput(config *models.StorageCon
 			config.GCSCredentialsJSONEncrypted = sql.NullString{String: encrypted, Valid: true}
 		}
 	}
+	applyReplicationSettings(config, input)
 
 	return nil
 }
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
+)
+
+// ContentAddressingJobInterface defines the interface for converting artifacts to content-addressed storage
+type ContentAddressingJobInterface interface {
+	Trigger(ctx context.Context) error
+	Stats(ctx context.Context) (*models.ContentAddressedStats, error)
+}
+
+// SetContentAddressingJob sets the job that converts path-based artifacts to content-addressed storage
+func (h *StorageHandlers) SetContentAddressingJob(contentAddressingJob ContentAddressingJobInterface) {
+	h.contentAddressingJob = contentAddressingJob
+}
+
+// GetContentAddressedStorage returns deduplication statistics for the default backend
+// GET /api/v1/storage/cas
+func (h *StorageHandlers) GetContentAddressedStorage(c *gin.Context) {
+	if h.contentAddressingJob == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	stats, err := h.contentAddressingJob.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get content-addressed storage statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": true,
+		"stats":   stats,
+	})
+}
+
+// MigrateToContentAddressedStorage converts artifacts still stored by path on the default
+// backend to the content-addressed layout in the background
+// POST /api/v1/storage/cas/migrate
+func (h *StorageHandlers) MigrateToContentAddressedStorage(c *gin.Context) {
+	if h.contentAddressingJob == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "content-addressed storage is not enabled"})
+		return
+	}
+
+	if err := h.contentAddressingJob.Trigger(c.Request.Context()); err != nil {
+		if errors.Is(err, jobs.ErrContentAddressingInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "content-addressed storage conversion started"})
+}
@@ -0,0 +1,232 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
+)
+
+// StorageMigrationJobInterface defines the interface for running storage migrations
+type StorageMigrationJobInterface interface {
+	StartMigration(ctx context.Context, sourceConfigID *uuid.UUID, targetConfigID uuid.UUID, deleteTargetOnRollback bool, createdBy *uuid.UUID) (*models.StorageMigration, error)
+	ResumeMigration(ctx context.Context, id uuid.UUID) error
+	PauseMigration(ctx context.Context, id uuid.UUID) error
+	CancelMigration(ctx context.Context, id uuid.UUID) error
+	RollbackMigration(ctx context.Context, id uuid.UUID) error
+}
+
+// SetMigrationJob sets the job that copies artifacts between storage backends
+func (h *StorageHandlers) SetMigrationJob(migrationJob StorageMigrationJobInterface) {
+	h.migrationJob = migrationJob
+}
+
+// CreateStorageMigrationRequest is the request body for starting a storage migration
+type CreateStorageMigrationRequest struct {
+	SourceConfigID         *uuid.UUID `json:"source_config_id"` // Omit for the server's configured backend
+	TargetConfigID         uuid.UUID  `json:"target_config_id" binding:"required"`
+	DeleteTargetOnRollback *bool      `json:"delete_target_on_rollback"`
+}
+
+// storageMigrationResponse is a storage migration with its progress
+type storageMigrationResponse struct {
+	*models.StorageMigration
+	Progress *models.StorageMigrationProgress `json:"progress"`
+}
+
+// ListStorageMigrations lists storage migrations with their progress
+// GET /api/v1/storage/migrations
+func (h *StorageHandlers) ListStorageMigrations(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	migrations, err := h.migrationRepo.ListMigrations(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage migrations"})
+		return
+	}
+
+	response := make([]storageMigrationResponse, 0, len(migrations))
+	for _, migration := range migrations {
+		progress, err := h.migrationRepo.GetProgress(ctx, migration.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage migration progress"})
+			return
+		}
+		response = append(response, storageMigrationResponse{StorageMigration: migration, Progress: progress})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateStorageMigration starts copying every stored artifact to a storage configuration
+// POST /api/v1/storage/migrations
+func (h *StorageHandlers) CreateStorageMigration(c *gin.Context) {
+	var req CreateStorageMigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deleteTargetOnRollback := true
+	if req.DeleteTargetOnRollback != nil {
+		deleteTargetOnRollback = *req.DeleteTargetOnRollback
+	}
+
+	h.startMigration(c, req.SourceConfigID, req.TargetConfigID, deleteTargetOnRollback)
+}
+
+// GetStorageMigration returns a storage migration and its progress
+// GET /api/v1/storage/migrations/:id
+func (h *StorageHandlers) GetStorageMigration(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration ID"})
+		return
+	}
+
+	migration, err := h.migrationRepo.GetMigration(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage migration"})
+		return
+	}
+	if migration == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "storage migration not found"})
+		return
+	}
+
+	progress, err := h.migrationRepo.GetProgress(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage migration progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, storageMigrationResponse{StorageMigration: migration, Progress: progress})
+}
+
+// ListStorageMigrationItems lists the artifacts of a storage migration, optionally filtered by status
+// GET /api/v1/storage/migrations/:id/items
+func (h *StorageHandlers) ListStorageMigrationItems(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	items, err := h.migrationRepo.ListItems(c.Request.Context(), id, c.Query("status"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage migration items"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "limit": limit, "offset": offset})
+}
+
+// PauseStorageMigration pauses a running storage migration
+// POST /api/v1/storage/migrations/:id/pause
+func (h *StorageHandlers) PauseStorageMigration(c *gin.Context) {
+	h.controlMigration(c, "paused", StorageMigrationJobInterface.PauseMigration)
+}
+
+// ResumeStorageMigration resumes a paused, failed or cancelled storage migration
+// POST /api/v1/storage/migrations/:id/resume
+func (h *StorageHandlers) ResumeStorageMigration(c *gin.Context) {
+	h.controlMigration(c, "resumed", StorageMigrationJobInterface.ResumeMigration)
+}
+
+// CancelStorageMigration cancels a storage migration
+// POST /api/v1/storage/migrations/:id/cancel
+func (h *StorageHandlers) CancelStorageMigration(c *gin.Context) {
+	h.controlMigration(c, "cancelled", StorageMigrationJobInterface.CancelMigration)
+}
+
+// RollbackStorageMigration points migrated artifacts back at the source backend
+// POST /api/v1/storage/migrations/:id/rollback
+func (h *StorageHandlers) RollbackStorageMigration(c *gin.Context) {
+	h.controlMigration(c, "rollback started", StorageMigrationJobInterface.RollbackMigration)
+}
+
+// controlMigration applies a state change to the migration named in the path
+func (h *StorageHandlers) controlMigration(c *gin.Context, message string, action func(StorageMigrationJobInterface, context.Context, uuid.UUID) error) {
+	if h.migrationJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage migrations are not available"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid migration ID"})
+		return
+	}
+
+	if err := action(h.migrationJob, c.Request.Context(), id); err != nil {
+		if errors.Is(err, jobs.ErrStorageMigrationState) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "storage migration " + message})
+}
+
+// startMigration starts a storage migration and writes the response
+func (h *StorageHandlers) startMigration(c *gin.Context, sourceConfigID *uuid.UUID, targetConfigID uuid.UUID, deleteTargetOnRollback bool) {
+	ctx := c.Request.Context()
+
+	if h.migrationJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage migrations are not available"})
+		return
+	}
+
+	target, err := h.storageConfigRepo.GetStorageConfig(ctx, targetConfigID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage configuration"})
+		return
+	}
+	if target == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "target storage configuration not found"})
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				createdBy = &id
+			}
+		}
+	}
+
+	migration, err := h.migrationJob.StartMigration(ctx, sourceConfigID, targetConfigID, deleteTargetOnRollback, createdBy)
+	if err != nil {
+		if errors.Is(err, jobs.ErrStorageMigrationInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, jobs.ErrStorageMigrationSameBackend) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, migration)
+}
@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// SetStorageQuotaRequest is the request body for setting an organization's storage quota
+type SetStorageQuotaRequest struct {
+	// MaxBytes caps the bytes the organization may store; 0 is unlimited
+	MaxBytes *int64 `json:"max_bytes" binding:"required"`
+}
+
+// SetStorageQuotas sets the repositories and service behind storage usage and quotas
+func (h *StorageHandlers) SetStorageQuotas(quotaRepo *repositories.StorageQuotaRepository, quotas *services.StorageQuotaService, orgRepo *repositories.OrganizationRepository) {
+	h.quotaRepo = quotaRepo
+	h.quotas = quotas
+	h.orgRepo = orgRepo
+}
+
+// ListStorageUsage returns the bytes stored by every organization
+// GET /api/v1/storage/usage
+func (h *StorageHandlers) ListStorageUsage(c *gin.Context) {
+	if h.quotas == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage quotas not available"})
+		return
+	}
+
+	usage, err := h.quotaRepo.ListOrganizationUsage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage usage"})
+		return
+	}
+	if usage == nil {
+		usage = []*models.OrganizationStorageUsage{}
+	}
+
+	var total int64
+	for _, org := range usage {
+		total += org.Bytes
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enforced":          h.quotas.Enabled(),
+		"default_max_bytes": h.cfg.Storage.Quotas.DefaultMaxBytes,
+		"total_bytes":       total,
+		"organizations":     usage,
+	})
+}
+
+// GetOrganizationStorageUsage returns an organization's usage against its quota, broken down by
+// namespace, artifact type and backend
+// GET /api/v1/storage/usage/:org_id
+func (h *StorageHandlers) GetOrganizationStorageUsage(c *gin.Context) {
+	org, ok := h.lookupQuotaOrganization(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.quotas.Usage(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage usage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"organization_name": org.Name,
+		"usage":             report,
+	})
+}
+
+// GetStorageQuota returns an organization's own storage quota
+// GET /api/v1/storage/quotas/:org_id
+func (h *StorageHandlers) GetStorageQuota(c *gin.Context) {
+	org, ok := h.lookupQuotaOrganization(c)
+	if !ok {
+		return
+	}
+
+	quota, err := h.quotaRepo.GetQuota(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage quota"})
+		return
+	}
+	if quota == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization has no storage quota of its own"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// SetStorageQuota creates or replaces an organization's storage quota
+// PUT /api/v1/storage/quotas/:org_id
+func (h *StorageHandlers) SetStorageQuota(c *gin.Context) {
+	org, ok := h.lookupQuotaOrganization(c)
+	if !ok {
+		return
+	}
+
+	var req SetStorageQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.MaxBytes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_bytes must not be negative"})
+		return
+	}
+
+	quota := &models.StorageQuota{OrganizationID: org.ID, MaxBytes: *req.MaxBytes}
+	if err := h.quotaRepo.SetQuota(c.Request.Context(), quota); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save storage quota"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+// DeleteStorageQuota removes an organization's storage quota, returning it to the default quota
+// DELETE /api/v1/storage/quotas/:org_id
+func (h *StorageHandlers) DeleteStorageQuota(c *gin.Context) {
+	org, ok := h.lookupQuotaOrganization(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.quotaRepo.DeleteQuota(c.Request.Context(), org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete storage quota"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization has no storage quota of its own"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "storage quota deleted"})
+}
+
+// lookupQuotaOrganization loads the organization named by the org_id parameter, writing an error
+// response if quotas are unavailable or the organization does not exist
+func (h *StorageHandlers) lookupQuotaOrganization(c *gin.Context) (*models.Organization, bool) {
+	if h.quotas == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage quotas not available"})
+		return nil, false
+	}
+
+	orgID, err := uuid.Parse(c.Param("org_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return nil, false
+	}
+
+	org, err := h.orgRepo.GetByID(c.Request.Context(), orgID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get organization"})
+		return nil, false
+	}
+	if org == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+		return nil, false
+	}
+
+	return org, true
+}
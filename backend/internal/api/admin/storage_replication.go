@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StorageReplicationJobInterface defines the interface for repairing replicated storage
+type StorageReplicationJobInterface interface {
+	TriggerRepair(ctx context.Context, configID uuid.UUID) error
+}
+
+// SetReplicationJob sets the job that keeps replicated storage configurations in step
+func (h *StorageHandlers) SetReplicationJob(replicationJob StorageReplicationJobInterface) {
+	h.replicationJob = replicationJob
+}
+
+// GetStorageReplicationStatus returns the replication queue of a replicated storage configuration
+// GET /api/v1/storage/configs/:id/replication
+func (h *StorageHandlers) GetStorageReplicationStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid configuration ID"})
+		return
+	}
+
+	storageConfig, err := h.storageConfigRepo.GetStorageConfig(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage configuration"})
+		return
+	}
+	if storageConfig == nil || storageConfig.BackendType != "replicated" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "replicated storage configuration not found"})
+		return
+	}
+
+	status, err := h.replicationRepo.GetStatus(ctx, id, 100)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get replication status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"config":  storageConfig.ToResponse(),
+		"pending": status.Pending,
+		"failing": status.Failing,
+		"items":   status.Items,
+	})
+}
+
+// RepairStorageReplication compares every artifact across the replicas of a replicated storage
+// configuration in the background and queues repairs for copies that diverged
+// POST /api/v1/storage/configs/:id/replication/repair
+func (h *StorageHandlers) RepairStorageReplication(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid configuration ID"})
+		return
+	}
+
+	if h.replicationJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage replication is not available"})
+		return
+	}
+
+	if err := h.replicationJob.TriggerRepair(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "replica repair started"})
+}
@@ -0,0 +1,184 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/jobs"
+)
+
+// StorageScrubJobInterface defines the interface for the storage integrity scrubber
+type StorageScrubJobInterface interface {
+	Trigger(ctx context.Context) (*models.StorageScrubRun, error)
+	Running() bool
+	Config() config.StorageScrubConfig
+	Runs(ctx context.Context, limit int) ([]*models.StorageScrubRun, error)
+	Run(ctx context.Context, id uuid.UUID) (*models.StorageScrubRun, []*models.StorageIntegrityIssue, error)
+	Orphans(ctx context.Context, status string) ([]*models.StorageOrphan, error)
+	PurgeOrphan(ctx context.Context, id uuid.UUID) error
+}
+
+// storageScrubRunLimit caps how many past scrubs are returned
+const storageScrubRunLimit = 20
+
+// SetScrubJob sets the job that checks stored artifacts and collects orphaned objects
+func (h *StorageHandlers) SetScrubJob(scrubJob StorageScrubJobInterface) {
+	h.scrubJob = scrubJob
+}
+
+// GetStorageScrubStatus returns the scrubber settings and the most recent scrub
+// GET /api/v1/storage/scrub
+func (h *StorageHandlers) GetStorageScrubStatus(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	runs, err := h.scrubJob.Runs(c.Request.Context(), 1)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage scrub runs"})
+		return
+	}
+
+	scrubCfg := h.scrubJob.Config()
+	response := gin.H{
+		"scheduled":           scrubCfg.Enabled,
+		"interval":            scrubCfg.Interval.String(),
+		"sample_size":         scrubCfg.SampleSize,
+		"orphan_grace_period": scrubCfg.OrphanGracePeriod.String(),
+		"orphan_action":       scrubCfg.OrphanAction,
+		"running":             h.scrubJob.Running(),
+	}
+	if len(runs) > 0 {
+		response["latest_run"] = runs[0]
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// TriggerStorageScrub starts a scrub in the background
+// POST /api/v1/storage/scrub
+func (h *StorageHandlers) TriggerStorageScrub(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	run, err := h.scrubJob.Trigger(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, jobs.ErrStorageScrubInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start storage scrub"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
+
+// ListStorageScrubRuns lists the most recent scrubs
+// GET /api/v1/storage/scrub/runs
+func (h *StorageHandlers) ListStorageScrubRuns(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	runs, err := h.scrubJob.Runs(c.Request.Context(), storageScrubRunLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage scrub runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// GetStorageScrubRun returns a scrub and the integrity issues it found
+// GET /api/v1/storage/scrub/runs/:id
+func (h *StorageHandlers) GetStorageScrubRun(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scrub run ID"})
+		return
+	}
+
+	run, issues, err := h.scrubJob.Run(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get storage scrub run"})
+		return
+	}
+	if run == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "storage scrub run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"run":    run,
+		"issues": issues,
+	})
+}
+
+// ListStorageOrphans lists stored objects no artifact references, optionally filtered by status
+// GET /api/v1/storage/orphans
+func (h *StorageHandlers) ListStorageOrphans(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	status := c.Query("status")
+	switch models.StorageOrphanStatus(status) {
+	case "", models.StorageOrphanPending, models.StorageOrphanQuarantined, models.StorageOrphanDeleted:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status filter"})
+		return
+	}
+
+	orphans, err := h.scrubJob.Orphans(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list storage orphans"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orphans": orphans})
+}
+
+// PurgeStorageOrphan deletes an orphaned object, or its quarantined copy, without waiting for
+// the grace period
+// DELETE /api/v1/storage/orphans/:id
+func (h *StorageHandlers) PurgeStorageOrphan(c *gin.Context) {
+	if h.scrubJob == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "storage scrubber not available"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid orphan ID"})
+		return
+	}
+
+	if err := h.scrubJob.PurgeOrphan(c.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrStorageOrphanNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, jobs.ErrStorageOrphanReferenced):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "orphan purged"})
+}
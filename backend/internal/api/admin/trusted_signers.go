@@ -0,0 +1,245 @@
+package admin
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/attestation"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// TrustedSignerHandlers handles management of trusted module signers
+type TrustedSignerHandlers struct {
+	signerRepo *repositories.TrustedSignerRepository
+}
+
+// NewTrustedSignerHandlers creates a new trusted signer handlers instance
+func NewTrustedSignerHandlers(signerRepo *repositories.TrustedSignerRepository) *TrustedSignerHandlers {
+	return &TrustedSignerHandlers{signerRepo: signerRepo}
+}
+
+// TrustedSignerRequest represents the request to create or update a trusted signer
+type TrustedSignerRequest struct {
+	OrganizationID      *string `json:"organization_id"`
+	Name                string  `json:"name" binding:"required"`
+	Description         string  `json:"description"`
+	SignerType          string  `json:"signer_type" binding:"required"` // "public_key" or "keyless"
+	PublicKey           *string `json:"public_key"`
+	CertificateIdentity *string `json:"certificate_identity"`
+	CertificateIssuer   *string `json:"certificate_issuer"`
+	RootCertificates    *string `json:"root_certificates"`
+	BuilderIDPattern    *string `json:"builder_id_pattern"`
+	NamespacePattern    *string `json:"namespace_pattern"`
+	IsActive            bool    `json:"is_active"`
+}
+
+// validate checks the signer type and the key or certificate settings it requires
+func (req *TrustedSignerRequest) validate() string {
+	switch models.TrustedSignerType(req.SignerType) {
+	case models.TrustedSignerPublicKey:
+		if req.PublicKey == nil || strings.TrimSpace(*req.PublicKey) == "" {
+			return "Public key is required for public_key signers"
+		}
+		if _, err := attestation.ParsePublicKey(*req.PublicKey); err != nil {
+			return "Public key must be a PEM-encoded ECDSA, RSA or Ed25519 public key"
+		}
+	case models.TrustedSignerKeyless:
+		if req.CertificateIdentity == nil || *req.CertificateIdentity == "" {
+			return "Certificate identity is required for keyless signers"
+		}
+		if _, err := regexp.Compile(*req.CertificateIdentity); err != nil {
+			return "Certificate identity must be a valid regular expression"
+		}
+		if req.RootCertificates == nil || strings.TrimSpace(*req.RootCertificates) == "" {
+			return "Root certificates are required for keyless signers"
+		}
+		if _, _, err := attestation.ParseCertificateChain([]byte(*req.RootCertificates)); err != nil {
+			return "Root certificates must be PEM-encoded X.509 certificates"
+		}
+	default:
+		return "Signer type must be 'public_key' or 'keyless'"
+	}
+
+	if req.BuilderIDPattern != nil && *req.BuilderIDPattern != "" {
+		if _, err := regexp.Compile(*req.BuilderIDPattern); err != nil {
+			return "Builder ID pattern must be a valid regular expression"
+		}
+	}
+	return ""
+}
+
+// ListTrustedSigners lists all trusted signers
+// GET /api/v1/admin/trusted-signers
+func (h *TrustedSignerHandlers) ListTrustedSigners(c *gin.Context) {
+	var orgID *uuid.UUID
+	if orgIDStr := c.Query("organization_id"); orgIDStr != "" {
+		id, err := uuid.Parse(orgIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	signers, err := h.signerRepo.List(c.Request.Context(), orgID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list trusted signers"})
+		return
+	}
+	if signers == nil {
+		signers = []*models.TrustedSigner{}
+	}
+
+	c.JSON(http.StatusOK, signers)
+}
+
+// GetTrustedSigner returns a single trusted signer
+// GET /api/v1/admin/trusted-signers/:id
+func (h *TrustedSignerHandlers) GetTrustedSigner(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signer ID"})
+		return
+	}
+
+	signer, err := h.signerRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trusted signer"})
+		return
+	}
+	if signer == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trusted signer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, signer)
+}
+
+// CreateTrustedSigner creates a new trusted signer
+// POST /api/v1/admin/trusted-signers
+func (h *TrustedSignerHandlers) CreateTrustedSigner(c *gin.Context) {
+	var req TrustedSignerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var orgID *uuid.UUID
+	if req.OrganizationID != nil {
+		id, err := uuid.Parse(*req.OrganizationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+			return
+		}
+		orgID = &id
+	}
+
+	// Get creator ID from context
+	var createdBy *uuid.UUID
+	if userIDStr, exists := c.Get("user_id"); exists {
+		if idStr, ok := userIDStr.(string); ok {
+			if id, err := uuid.Parse(idStr); err == nil {
+				createdBy = &id
+			}
+		}
+	}
+
+	signer := &models.TrustedSigner{
+		ID:                  uuid.New(),
+		OrganizationID:      orgID,
+		Name:                req.Name,
+		Description:         &req.Description,
+		SignerType:          models.TrustedSignerType(req.SignerType),
+		PublicKey:           req.PublicKey,
+		CertificateIdentity: req.CertificateIdentity,
+		CertificateIssuer:   req.CertificateIssuer,
+		RootCertificates:    req.RootCertificates,
+		BuilderIDPattern:    req.BuilderIDPattern,
+		NamespacePattern:    req.NamespacePattern,
+		IsActive:            req.IsActive,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+		CreatedBy:           createdBy,
+	}
+
+	if err := h.signerRepo.Create(c.Request.Context(), signer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create trusted signer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, signer)
+}
+
+// UpdateTrustedSigner updates an existing trusted signer
+// PUT /api/v1/admin/trusted-signers/:id
+func (h *TrustedSignerHandlers) UpdateTrustedSigner(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signer ID"})
+		return
+	}
+
+	existing, err := h.signerRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trusted signer"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trusted signer not found"})
+		return
+	}
+
+	var req TrustedSignerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	existing.Name = req.Name
+	existing.Description = &req.Description
+	existing.SignerType = models.TrustedSignerType(req.SignerType)
+	existing.PublicKey = req.PublicKey
+	existing.CertificateIdentity = req.CertificateIdentity
+	existing.CertificateIssuer = req.CertificateIssuer
+	existing.RootCertificates = req.RootCertificates
+	existing.BuilderIDPattern = req.BuilderIDPattern
+	existing.NamespacePattern = req.NamespacePattern
+	existing.IsActive = req.IsActive
+
+	if err := h.signerRepo.Update(c.Request.Context(), existing); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update trusted signer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, existing)
+}
+
+// DeleteTrustedSigner deletes a trusted signer
+// DELETE /api/v1/admin/trusted-signers/:id
+func (h *TrustedSignerHandlers) DeleteTrustedSigner(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signer ID"})
+		return
+	}
+
+	if err := h.signerRepo.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete trusted signer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trusted signer deleted"})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
@@ -18,7 +19,7 @@ import (
 // PlatformIndexHandler handles network mirror platform index requests
 // Implements: GET /terraform/providers/:hostname/:namespace/:type/:version.json
 // Returns download URLs and hashes for all platforms of a specific version
-func PlatformIndexHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
+func PlatformIndexHandler(db *sql.DB, backends *storage.Registry, cfg *config.Config) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
 
@@ -104,15 +105,6 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Get storage backend
-		storageBackend, err := storage.NewStorage(cfg)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to initialize storage backend",
-			})
-			return
-		}
-
 		// Format response per Network Mirror Protocol spec
 		// https://www.terraform.io/docs/internals/provider-network-mirror-protocol.html
 		//
@@ -135,9 +127,9 @@ func PlatformIndexHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			// Generate platform key (os_arch)
 			platformKey := fmt.Sprintf("%s_%s", platform.OS, platform.Arch)
 
-			// Get download URL from storage
+			// Get download URL from the backend the platform is stored in
 			// For Network Mirror, we use a longer TTL (1 hour)
-			downloadURL, err := storageBackend.GetURL(c.Request.Context(), platform.StoragePath, 1*time.Hour)
+			downloadURL, err := backends.GetURL(storage.WithPrincipal(c.Request.Context(), middleware.Principal(c)), platform.StorageBackend, platform.StoragePath, 1*time.Hour)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": fmt.Sprintf("Failed to generate download URL for %s", platformKey),
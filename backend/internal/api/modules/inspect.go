@@ -0,0 +1,94 @@
+package modules
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// uploadFormMemory is how much of a multipart upload is held in memory; larger file parts
+// are spooled to temp files by net/http and removed once the request completes
+const uploadFormMemory = 8 << 20
+
+// archiveInspection is everything learned from a single read of an uploaded module archive
+type archiveInspection struct {
+	Digest         []byte // SHA256 of the archive
+	Checksum       string // Hex-encoded Digest
+	Size           int64
+	Contents       *validation.ModuleContents
+	SecretFindings []validation.SecretFinding
+	OCIZipDigest   string // Digest of the archive converted to the zip served over OCI; empty if it can't be converted
+	OCIZipSize     int64
+	ArchiveErr     error // Set when the archive is not a valid module archive
+	ScanErr        error // Set when the secret scan could not complete
+}
+
+// inspectModuleArchive reads an archive once, hashing it while its tar structure is validated,
+// its Terraform files, README and license are loaded, it is scanned for secrets and the zip it
+// is served as over OCI is digested, all concurrently. The returned error is only set when the archive itself could not be read.
+func inspectModuleArchive(archive io.Reader, secretScanner *services.SecretScanner) (*archiveInspection, error) {
+	inspection := &archiveInspection{}
+	hasher := sha256.New()
+
+	errs, err := validation.ReadConcurrently(archive,
+		func(r io.Reader) error {
+			n, err := io.Copy(hasher, r)
+			inspection.Size = n
+			return err
+		},
+		func(r io.Reader) error {
+			return validation.ValidateArchive(r, validation.MaxArchiveSize)
+		},
+		func(r io.Reader) (err error) {
+			inspection.Contents, err = validation.LoadModuleContents(r)
+			return err
+		},
+		func(r io.Reader) (err error) {
+			inspection.SecretFindings, err = secretScanner.Scan(r)
+			return err
+		},
+		func(r io.Reader) (err error) {
+			inspection.OCIZipDigest, inspection.OCIZipSize, err = validation.ModuleZipDigest(r)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection.Digest = hasher.Sum(nil)
+	inspection.Checksum = hex.EncodeToString(inspection.Digest)
+	if errs[1] != nil {
+		inspection.ArchiveErr = errs[1]
+	} else {
+		inspection.ArchiveErr = errs[2]
+	}
+	inspection.ScanErr = errs[3]
+	if errs[4] != nil {
+		inspection.OCIZipDigest, inspection.OCIZipSize = "", 0
+	}
+
+	return inspection, nil
+}
+
+// readArchive reads the inspected archive back from file, for Ed25519 signatures that sign the
+// whole archive rather than its digest. The bytes are checked against the digest streamed during
+// inspection, so signatures are only ever verified over the archive that was inspected.
+func (i *archiveInspection) readArchive(file io.ReaderAt) ([]byte, error) {
+	if i.Size > validation.MaxArchiveSize {
+		return nil, fmt.Errorf("archive exceeds maximum size of %d bytes", validation.MaxArchiveSize)
+	}
+	data := make([]byte, i.Size)
+	if _, err := io.ReadFull(io.NewSectionReader(file, 0, i.Size), data); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if digest := sha256.Sum256(data); !bytes.Equal(digest[:], i.Digest) {
+		return nil, fmt.Errorf("archive changed after it was inspected")
+	}
+	return data, nil
+}
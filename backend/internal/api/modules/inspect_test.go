@@ -0,0 +1,68 @@
+package modules
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+func TestReadArchiveChecksInspectedDigest(t *testing.T) {
+	archive := []byte("module archive contents")
+	digest := sha256.Sum256(archive)
+	inspection := &archiveInspection{Digest: digest[:], Size: int64(len(archive))}
+
+	data, err := inspection.readArchive(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	if !bytes.Equal(data, archive) {
+		t.Errorf("readArchive() = %q, want %q", data, archive)
+	}
+
+	tampered := bytes.Replace(archive, []byte("module"), []byte("MODULE"), 1)
+	if _, err := inspection.readArchive(bytes.NewReader(tampered)); err == nil {
+		t.Error("readArchive accepted an archive that differs from the inspected one")
+	}
+	if _, err := inspection.readArchive(bytes.NewReader(archive[:10])); err == nil {
+		t.Error("readArchive accepted a truncated archive")
+	}
+}
+
+func TestInspectRecordsOCIZipDigest(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("resource \"null_resource\" \"x\" {}\n")
+	tw.WriteHeader(&tar.Header{Name: "main.tf", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	archive := buf.Bytes()
+
+	scanner := services.NewSecretScanner(config.SecretScanningConfig{}, nil)
+	inspection, err := inspectModuleArchive(bytes.NewReader(archive), scanner)
+	if err != nil {
+		t.Fatalf("inspectModuleArchive: %v", err)
+	}
+	digest, size, err := validation.ModuleZipDigest(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("ModuleZipDigest: %v", err)
+	}
+	if inspection.OCIZipDigest != digest || inspection.OCIZipSize != size {
+		t.Errorf("inspection OCI zip = %s %d, want %s %d", inspection.OCIZipDigest, inspection.OCIZipSize, digest, size)
+	}
+
+	invalid, err := inspectModuleArchive(bytes.NewReader([]byte("not an archive")), scanner)
+	if err != nil {
+		t.Fatalf("inspectModuleArchive: %v", err)
+	}
+	if invalid.ArchiveErr == nil || invalid.OCIZipDigest != "" {
+		t.Errorf("invalid archive inspection = %v %q, want an archive error and no zip digest", invalid.ArchiveErr, invalid.OCIZipDigest)
+	}
+}
@@ -0,0 +1,127 @@
+package modules
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// ManifestHandler serves the registry-signed manifest of a module version as a DSSE envelope.
+// The manifest records the archive digest, size, publish time and publisher, so a download
+// can be verified against the registry's public keys regardless of where it was served from.
+// Implements: GET /v1/modules/:namespace/:name/:system/:version/manifest
+func ManifestHandler(db *sql.DB, moduleSigner *services.ModuleSigner) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		name := c.Param("name")
+		system := c.Param("system")
+		version := c.Param("version")
+
+		// Validate semantic versioning
+		if err := validation.ValidateSemver(version); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"Invalid version format - must be valid semantic versioning"},
+			})
+			return
+		}
+
+		// Get organization context
+		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get organization context",
+			})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Default organization not found - please run migrations",
+			})
+			return
+		}
+
+		// Get module
+		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query module",
+			})
+			return
+		}
+		if module == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Module not found"},
+			})
+			return
+		}
+
+		// Get specific version
+		moduleVersion, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query module version",
+			})
+			return
+		}
+		if moduleVersion == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Module version not found"},
+			})
+			return
+		}
+
+		manifest, err := moduleSigner.ModuleVersionManifest(c.Request.Context(), org.ID, module, moduleVersion)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to get module manifest: %v", err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, manifest)
+	}
+}
+
+// SigningKeysHandler serves the registry's public signing key set as a JWK set.
+// Retired keys are included so manifests signed before a rotation remain verifiable.
+// Implements: GET /v1/signing-keys
+func SigningKeysHandler(db *sql.DB, moduleSigner *services.ModuleSigner) gin.HandlerFunc {
+	orgRepo := repositories.NewOrganizationRepository(db)
+
+	return func(c *gin.Context) {
+		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get organization context",
+			})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Default organization not found - please run migrations",
+			})
+			return
+		}
+
+		keys, err := moduleSigner.PublicKeys(c.Request.Context(), org.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to list signing keys",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"payload_type": services.ModuleManifestPayloadType,
+			"keys":         keys,
+		})
+	}
+}
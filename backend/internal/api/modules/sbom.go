@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// SBOMHandler serves the software bill of materials for a module version
+// Implements: GET /v1/modules/:namespace/:name/:system/:version/sbom?format=cyclonedx|spdx
+// SBOMs missing from storage (e.g. for versions published before SBOM support) are regenerated
+func SBOMHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+	moduleRepo := repositories.NewModuleRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	sbomService := services.NewSBOMService(repositories.NewProviderRepository(db), storageBackend, cfg.Server.BaseURL)
+
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		name := c.Param("name")
+		system := c.Param("system")
+		version := c.Param("version")
+
+		format, err := sbom.ParseFormat(c.Query("format"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		// Validate semantic versioning
+		if err := validation.ValidateSemver(version); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"Invalid version format - must be valid semantic versioning"},
+			})
+			return
+		}
+
+		// Get organization context
+		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get organization context",
+			})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Default organization not found - please run migrations",
+			})
+			return
+		}
+
+		// Get module
+		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query module",
+			})
+			return
+		}
+		if module == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Module not found"},
+			})
+			return
+		}
+
+		// Get specific version
+		moduleVersion, err := moduleRepo.GetVersion(c.Request.Context(), module.ID, version)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query module version",
+			})
+			return
+		}
+		if moduleVersion == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Module version not found"},
+			})
+			return
+		}
+
+		document, err := sbomService.ModuleSBOM(c.Request.Context(), module, moduleVersion, format)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to generate SBOM: %v", err),
+			})
+			return
+		}
+
+		filename := fmt.Sprintf("%s-%s-%s-%s.%s.json", namespace, name, system, version, format)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, format.ContentType(), document)
+	}
+}
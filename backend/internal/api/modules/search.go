@@ -79,9 +79,11 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			// Get latest version for each module
 			versions, _ := moduleRepo.ListVersions(c.Request.Context(), m.ID)
 			var latestVersion string
+			var license *string
 			var totalDownloads int64
 			if len(versions) > 0 {
 				latestVersion = versions[0].Version
+				license = versions[0].LicenseSPDX
 				// Sum up downloads across all versions
 				for _, v := range versions {
 					totalDownloads += v.DownloadCount
@@ -96,6 +98,7 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				"description":     m.Description,
 				"source":          m.Source,
 				"latest_version":  latestVersion,
+				"license":         license,
 				"download_count":  totalDownloads,
 				"created_by":      m.CreatedBy,
 				"created_by_name": m.CreatedByName,
@@ -1,17 +1,29 @@
 package modules
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 )
 
 // ServeFileHandler handles direct file serving for local storage
-// Implements: GET /v1/files/*filepath
-// Only used when local storage has ServeDirectly: true
-func ServeFileHandler(storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+// Implements: GET /v1/files/*filepath and HEAD /v1/files/*filepath
+// Supports single byte ranges (Range, If-Range) and conditional requests (If-None-Match,
+// If-Modified-Since) validated against the file's checksum and modification time
+// Used when local storage has ServeDirectly: true, or when the storage cache proxies downloads
+// Files in backends other than the default are addressed with the ?backend= query parameter
+// URLs must carry the expiry and signature added by the storage registry's URL signer
+func ServeFileHandler(backends *storage.Registry, cfg *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get file path from URL
 		filePath := c.Param("filepath")
@@ -27,6 +39,32 @@ func ServeFileHandler(storageBackend storage.Storage, cfg *config.Config) gin.Ha
 			filePath = filePath[1:]
 		}
 
+		// The route has no authentication of its own; only URLs the registry signed are served
+		if signer := backends.URLSigner(); signer != nil {
+			err := signer.Verify(filePath, c.Request.URL.Query(), middleware.Principal(c), time.Now())
+			if errors.Is(err, storage.ErrURLExpired) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Download URL has expired",
+				})
+				return
+			}
+			if err != nil {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Invalid or missing download URL signature",
+				})
+				return
+			}
+		}
+
+		// Resolve the backend the file lives in
+		storageBackend, err := backends.Resolve(c.Request.Context(), c.Query(storage.BackendQueryParam))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+			return
+		}
+
 		// Check if file exists
 		exists, err := storageBackend.Exists(c.Request.Context(), filePath)
 		if err != nil {
@@ -51,8 +89,58 @@ func ServeFileHandler(storageBackend storage.Storage, cfg *config.Config) gin.Ha
 			return
 		}
 
+		// Validators let clients and proxies revalidate and resume downloads
+		contentType := fileContentType(filePath)
+		etag := ""
+		if metadata.Checksum != "" {
+			etag = `"` + metadata.Checksum + `"`
+			c.Header("ETag", etag)
+		}
+		if !metadata.LastModified.IsZero() {
+			c.Header("Last-Modified", metadata.LastModified.UTC().Format(http.TimeFormat))
+		}
+		c.Header("Accept-Ranges", "bytes")
+		c.Header("Content-Disposition", "attachment")
+		c.Header("X-Checksum-SHA256", metadata.Checksum)
+
+		if notModified(c.Request, etag, metadata.LastModified) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+
+		// Serve a single byte range when asked, unless If-Range says the client's copy is stale
+		status := http.StatusOK
+		offset, length := int64(0), metadata.Size
+		if rangeHeader := c.GetHeader("Range"); rangeHeader != "" && rangeStillValid(c.Request, etag, metadata.LastModified) {
+			start, end, ok, err := parseByteRange(rangeHeader, metadata.Size)
+			if err != nil {
+				c.Header("Content-Range", fmt.Sprintf("bytes */%d", metadata.Size))
+				c.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{
+					"error": "Requested range not satisfiable",
+				})
+				return
+			}
+			if ok {
+				status = http.StatusPartialContent
+				offset, length = start, end-start+1
+				c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, metadata.Size))
+			}
+		}
+
+		if c.Request.Method == http.MethodHead {
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Length", strconv.FormatInt(length, 10))
+			c.Status(status)
+			return
+		}
+
 		// Download file from storage
-		reader, err := storageBackend.Download(c.Request.Context(), filePath)
+		var reader io.ReadCloser
+		if status == http.StatusPartialContent {
+			reader, err = storageBackend.DownloadRange(c.Request.Context(), filePath, offset, length)
+		} else {
+			reader, err = storageBackend.Download(c.Request.Context(), filePath)
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to read file",
@@ -61,12 +149,108 @@ func ServeFileHandler(storageBackend storage.Storage, cfg *config.Config) gin.Ha
 		}
 		defer reader.Close()
 
-		// Set response headers
-		c.Header("Content-Type", "application/gzip")
-		c.Header("Content-Disposition", "attachment")
-		c.Header("X-Checksum-SHA256", metadata.Checksum)
-
 		// Stream file to client
-		c.DataFromReader(http.StatusOK, metadata.Size, "application/gzip", reader, nil)
+		c.DataFromReader(status, length, contentType, reader, nil)
+	}
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since when it is absent
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etag != "" && etagListMatches(ifNoneMatch, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// rangeStillValid evaluates If-Range: a range is only served when the validator the client
+// holds still matches the file
+func rangeStillValid(r *http.Request, etag string, lastModified time.Time) bool {
+	ifRange := r.Header.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return etag != "" && ifRange == etag
+	}
+	date, err := http.ParseTime(ifRange)
+	return err == nil && !lastModified.IsZero() && lastModified.UTC().Truncate(time.Second).Equal(date)
+}
+
+// etagListMatches reports whether a comma-separated If-None-Match list names etag, comparing weakly
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseByteRange parses a Range header against a file of size bytes, returning the inclusive
+// start and end of the range. ok is false when the header should be ignored, which includes
+// requests for several ranges; an error means the range cannot be satisfied.
+func parseByteRange(header string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// A suffix range: the last n bytes
+		n, parseErr := strconv.ParseInt(last, 10, 64)
+		if parseErr != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, errors.New("range not satisfiable")
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+
+	start, parseErr := strconv.ParseInt(first, 10, 64)
+	if parseErr != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	if start >= size {
+		return 0, 0, false, errors.New("range not satisfiable")
+	}
+	end = size - 1
+	if last != "" {
+		requested, parseErr := strconv.ParseInt(last, 10, 64)
+		if parseErr != nil || requested < start {
+			return 0, 0, false, nil
+		}
+		if requested < end {
+			end = requested
+		}
+	}
+	return start, end, true, nil
+}
+
+// fileContentType returns the content type of a served file. Files proxied from cloud backends
+// include provider zips and SBOM documents as well as module archives.
+func fileContentType(filePath string) string {
+	switch path.Ext(filePath) {
+	case ".zip":
+		return "application/zip"
+	case ".json":
+		return "application/json"
+	default:
+		return "application/gzip"
 	}
 }
@@ -0,0 +1,98 @@
+package modules
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/storage/local"
+)
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		header     string
+		start, end int64
+		ok         bool
+		wantErr    bool
+	}{
+		{"bytes=0-3", 0, 3, true, false},
+		{"bytes=4-", 4, 9, true, false},
+		{"bytes=8-100", 8, 9, true, false},
+		{"bytes=-3", 7, 9, true, false},
+		{"bytes=-100", 0, 9, true, false},
+		{"bytes=10-", 0, 0, false, true},
+		{"bytes=-0", 0, 0, false, true},
+		{"bytes=5-2", 0, 0, false, false},
+		{"bytes=0-1,4-5", 0, 0, false, false},
+		{"items=0-3", 0, 0, false, false},
+		{"bytes=abc", 0, 0, false, false},
+	}
+	for _, tt := range tests {
+		start, end, ok, err := parseByteRange(tt.header, 10)
+		if (err != nil) != tt.wantErr || ok != tt.ok || start != tt.start || end != tt.end {
+			t.Errorf("parseByteRange(%q) = %d, %d, %v, %v; want %d, %d, %v, error %v",
+				tt.header, start, end, ok, err, tt.start, tt.end, tt.ok, tt.wantErr)
+		}
+	}
+}
+
+func TestServeFileRanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Server.BaseURL = "http://registry.example.com"
+	cfg.Storage.DefaultBackend = "local"
+	backend, err := local.New(&config.LocalStorageConfig{BasePath: t.TempDir()}, cfg.Server.BaseURL)
+	if err != nil {
+		t.Fatalf("local.New: %v", err)
+	}
+	const content = "0123456789"
+	result, err := backend.Upload(context.Background(), "modules/a/b/c/b-1.0.0.tar.gz", strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	etag := `"` + result.Checksum + `"`
+
+	router := gin.New()
+	router.GET("/v1/files/*filepath", ServeFileHandler(storage.NewRegistry(cfg, backend, nil, nil, nil), cfg))
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{"full file", nil, http.StatusOK, content, ""},
+		{"byte range", map[string]string{"Range": "bytes=2-5"}, http.StatusPartialContent, "2345", "bytes 2-5/10"},
+		{"suffix range", map[string]string{"Range": "bytes=-3"}, http.StatusPartialContent, "789", "bytes 7-9/10"},
+		{"matching If-Range", map[string]string{"Range": "bytes=8-", "If-Range": etag}, http.StatusPartialContent, "89", "bytes 8-9/10"},
+		{"stale If-Range", map[string]string{"Range": "bytes=8-", "If-Range": `"stale"`}, http.StatusOK, content, ""},
+		{"unsatisfiable range", map[string]string{"Range": "bytes=20-"}, http.StatusRequestedRangeNotSatisfiable, "", "bytes */10"},
+		{"not modified", map[string]string{"If-None-Match": etag}, http.StatusNotModified, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/files/modules/a/b/c/b-1.0.0.tar.gz", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusRequestedRangeNotSatisfiable && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if got := rec.Header().Get("Content-Range"); got != tt.contentRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.contentRange)
+			}
+		})
+	}
+}
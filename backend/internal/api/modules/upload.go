@@ -1,16 +1,18 @@
 package modules
 
 import (
-	"bytes"
 	"database/sql"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/api/uploads"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
@@ -18,13 +20,24 @@ import (
 // UploadHandler handles module upload requests
 // Implements: POST /api/v1/modules
 // Accepts multipart form with: namespace, name, system, version, description (optional), file
-func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+// (or upload_id naming a completed resumable upload),
+// and optional signing material: signature, certificate, provenance, provenance_certificate
+func UploadHandler(db *sql.DB, backends *storage.Registry, cfg *config.Config, moduleSigner *services.ModuleSigner, uploadSessions *services.UploadSessionService, quotaService *services.StorageQuotaService) gin.HandlerFunc {
+	// New artifacts are written to the default backend
+	storageBackend := backends.Default()
 	moduleRepo := repositories.NewModuleRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	secretScanner := services.NewSecretScanner(cfg.Security.SecretScanning, moduleRepo)
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	policyEvaluator := services.NewPublishPolicyEvaluator(repositories.NewPublishRuleRepository(sqlxDB))
+	admissionController := services.NewAdmissionController(repositories.NewAdmissionWebhookRepository(sqlxDB), backends)
+	licenseEvaluator := services.NewLicensePolicyEvaluator(repositories.NewLicensePolicyRepository(sqlxDB))
+	sbomService := services.NewSBOMService(repositories.NewProviderRepository(db), storageBackend, cfg.Server.BaseURL)
+	signatureVerifier := services.NewSignatureVerifier(repositories.NewTrustedSignerRepository(sqlxDB))
 
 	return func(c *gin.Context) {
-		// Parse multipart form (max 100MB)
-		if err := c.Request.ParseMultipartForm(100 << 20); err != nil {
+		// Parse multipart form, spooling large archives to disk rather than memory
+		if err := c.Request.ParseMultipartForm(uploadFormMemory); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Failed to parse multipart form",
 			})
@@ -55,19 +68,20 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
-		// Get uploaded file
-		file, header, err := c.Request.FormFile("file")
+		// Get the uploaded file, sent as a form file or as a completed resumable upload
+		artifact, status, err := uploads.OpenArtifact(c, uploadSessions, models.ArtifactTypeModule)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Missing or invalid file upload",
+			c.JSON(status, gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
-		defer file.Close()
+		defer artifact.Close()
+		file := artifact.File
 
-		// Read file into buffer for validation and upload
-		fileBuffer := &bytes.Buffer{}
-		size, err := io.Copy(fileBuffer, file)
+		// Read the archive once: hash it, validate its structure, load its contents and
+		// scan it for secrets concurrently
+		inspection, err := inspectModuleArchive(file, secretScanner)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to read uploaded file",
@@ -76,9 +90,25 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		}
 
 		// Validate archive format
-		if err := validation.ValidateArchive(bytes.NewReader(fileBuffer.Bytes()), validation.MaxArchiveSize); err != nil {
+		if inspection.ArchiveErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Invalid archive: %v", err),
+				"error": fmt.Sprintf("Invalid archive: %v", inspection.ArchiveErr),
+			})
+			return
+		}
+
+		// Check the archive contents for secrets
+		if inspection.ScanErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Failed to scan archive: %v", inspection.ScanErr),
+			})
+			return
+		}
+		secretFindings := inspection.SecretFindings
+		if secretScanner.Blocks(secretFindings) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":           "Archive contains potential secrets",
+				"secret_findings": secretFindings,
 			})
 			return
 		}
@@ -98,6 +128,85 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// Verify any uploaded signature and provenance against trusted signers
+		signingMaterial, err := readSigningMaterial(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		signedArchive := services.SignedArchive{
+			Digest: inspection.Digest,
+			Read: func() ([]byte, error) {
+				return inspection.readArchive(file)
+			},
+		}
+		signatureReport, err := signatureVerifier.Verify(c.Request.Context(), org.ID, namespace, signedArchive, signingMaterial)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to verify signatures: %v", err),
+			})
+			return
+		}
+
+		// Evaluate organization publish rules against the archive
+		contents := inspection.Contents
+		policyReport, err := policyEvaluator.Evaluate(c.Request.Context(), services.PublishRequest{
+			OrganizationID: org.ID,
+			Namespace:      namespace,
+			Version:        version,
+			Signatures:     signatureReport,
+		}, contents)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to evaluate publish rules: %v", err),
+			})
+			return
+		}
+		if policyReport.Blocked {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":          "Module does not satisfy publish rules",
+				"policy_results": policyReport.Results,
+			})
+			return
+		}
+
+		// Detect the module license and check it against license policies
+		license := validation.DetectLicense(contents.LicenseText)
+		licenseDecision, err := licenseEvaluator.Check(c.Request.Context(), org.ID, models.ArtifactTypeModule, license)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to evaluate license policies: %v", err),
+			})
+			return
+		}
+		if !licenseDecision.Allowed {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   fmt.Sprintf("Publish rejected: %s", licenseDecision.Message),
+				"license": licenseDecision,
+			})
+			return
+		}
+
+		// Reserve room for the archive in the organization's storage quota. The reservation
+		// holds until the version is recorded, so concurrent uploads can't exceed the quota together.
+		quotaDecision, releaseQuota, err := quotaService.Reserve(c.Request.Context(), org.ID, inspection.Size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to check storage quota: %v", err),
+			})
+			return
+		}
+		if !quotaDecision.Allowed {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("Publish rejected: %s", quotaDecision.Message),
+				"quota": quotaDecision,
+			})
+			return
+		}
+		defer releaseQuota()
+
 		// Check if module already exists, create if not
 		module, err := moduleRepo.GetModule(c.Request.Context(), org.ID, namespace, name, system)
 		if err != nil {
@@ -168,12 +277,12 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		// Generate storage path: modules/{namespace}/{name}/{system}/{version}.tar.gz
 		storagePath := fmt.Sprintf("modules/%s/%s/%s/%s.tar.gz", namespace, name, system, version)
 
-		// Upload to storage backend
+		// Upload to storage backend, streaming from the start of the spooled archive
 		uploadResult, err := storageBackend.Upload(
 			c.Request.Context(),
 			storagePath,
-			bytes.NewReader(fileBuffer.Bytes()),
-			size,
+			storage.WithChecksum(io.NewSectionReader(file, 0, inspection.Size), inspection.Checksum),
+			inspection.Size,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -182,21 +291,53 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
-		// Extract README from tarball
-		readme, err := validation.ExtractReadme(bytes.NewReader(fileBuffer.Bytes()))
+		// Consult admission webhooks before committing the version
+		admissionReq := services.AdmissionRequest{
+			ArtifactType:   models.ArtifactTypeModule,
+			OrganizationID: org.ID,
+			Namespace:      namespace,
+			Name:           name,
+			System:         system,
+			Version:        version,
+			Filename:       artifact.Filename,
+			Checksum:       uploadResult.Checksum,
+			SizeBytes:      uploadResult.Size,
+		}
+		if userID, exists := c.Get("user_id"); exists {
+			if uid, ok := userID.(string); ok {
+				admissionReq.PublishedBy = uid
+			}
+		}
+		admission, err := admissionController.Review(c.Request.Context(), admissionReq, uploadResult.Path)
 		if err != nil {
-			// Log warning but don't fail the upload
-			fmt.Printf("Warning: Failed to extract README: %v\n", err)
+			storageBackend.Delete(c.Request.Context(), uploadResult.Path)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to run admission webhooks: %v", err),
+			})
+			return
+		}
+		if !admission.Allowed {
+			storageBackend.Delete(c.Request.Context(), uploadResult.Path)
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":             fmt.Sprintf("Publish rejected: %s", admission.DenialMessage()),
+				"admission_results": admission.Results,
+			})
+			return
 		}
 
+		// README was extracted while the archive was inspected
+		readme := contents.Readme
+
 		// Create version record
 		moduleVersion := &models.ModuleVersion{
-			ModuleID:       module.ID,
-			Version:        version,
-			StoragePath:    uploadResult.Path,
-			StorageBackend: cfg.Storage.DefaultBackend,
-			SizeBytes:      uploadResult.Size,
-			Checksum:       uploadResult.Checksum,
+			ModuleID:         module.ID,
+			Version:          version,
+			StoragePath:      uploadResult.Path,
+			StorageBackend:   cfg.Storage.DefaultBackend,
+			SizeBytes:        uploadResult.Size,
+			Checksum:         uploadResult.Checksum,
+			SignatureStatus:  string(signatureReport.SignatureStatus),
+			ProvenanceStatus: string(signatureReport.ProvenanceStatus),
 		}
 		// Set published_by for audit tracking
 		if userID, exists := c.Get("user_id"); exists {
@@ -209,6 +350,13 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		if readme != "" {
 			moduleVersion.Readme = &readme
 		}
+		if license != "" {
+			moduleVersion.LicenseSPDX = &license
+		}
+		if inspection.OCIZipDigest != "" {
+			moduleVersion.OCIZipDigest = &inspection.OCIZipDigest
+			moduleVersion.OCIZipSize = &inspection.OCIZipSize
+		}
 
 		if err := moduleRepo.CreateVersion(c.Request.Context(), moduleVersion); err != nil {
 			// Try to clean up uploaded file
@@ -219,9 +367,40 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			})
 			return
 		}
+		artifact.Published(c.Request.Context())
+
+		// Record secret findings for admin review
+		if err := secretScanner.Record(c.Request.Context(), moduleVersion.ID, secretFindings); err != nil {
+			// Log warning but don't fail the upload
+			fmt.Printf("Warning: Failed to record secret findings: %v\n", err)
+		}
+
+		// Record publish rule results
+		if err := policyEvaluator.Record(c.Request.Context(), moduleVersion.ID, policyReport); err != nil {
+			// Log warning but don't fail the upload
+			fmt.Printf("Warning: Failed to record publish rule results: %v\n", err)
+		}
+
+		// Record uploaded signatures and provenance with their verification results
+		if err := signatureVerifier.Record(c.Request.Context(), moduleVersion.ID, signatureReport); err != nil {
+			// Log warning but don't fail the upload
+			fmt.Printf("Warning: Failed to record module attestations: %v\n", err)
+		}
+
+		// Store the SBOM alongside the archive
+		if err := sbomService.GenerateModuleSBOM(c.Request.Context(), module, moduleVersion, contents); err != nil {
+			// Log warning but don't fail the upload; the SBOM is regenerated on request
+			fmt.Printf("Warning: Failed to generate SBOM: %v\n", err)
+		}
+
+		// Sign the archive manifest so consumers can verify downloads independently of storage
+		if _, err := moduleSigner.SignModuleVersion(c.Request.Context(), org.ID, module, moduleVersion); err != nil {
+			// Log warning but don't fail the upload; the manifest is signed on request
+			fmt.Printf("Warning: Failed to sign module manifest: %v\n", err)
+		}
 
 		// Return success response with module metadata
-		c.JSON(http.StatusCreated, gin.H{
+		response := gin.H{
 			"id":         module.ID,
 			"namespace":  module.Namespace,
 			"name":       module.Name,
@@ -229,8 +408,62 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			"version":    moduleVersion.Version,
 			"checksum":   moduleVersion.Checksum,
 			"size_bytes": moduleVersion.SizeBytes,
-			"filename":   header.Filename,
+			"filename":   artifact.Filename,
 			"created_at": moduleVersion.CreatedAt,
-		})
+		}
+		if license != "" {
+			response["license"] = license
+		}
+		if signatureReport.SignatureStatus != models.AttestationStatusNone || signatureReport.ProvenanceStatus != models.AttestationStatusNone {
+			response["signatures"] = signatureReport
+		}
+		if len(secretFindings) > 0 {
+			response["secret_findings"] = secretFindings
+		}
+		if len(policyReport.Results) > 0 {
+			response["policy_results"] = policyReport.Results
+		}
+		if len(admission.Results) > 0 {
+			response["admission_results"] = admission.Results
+		}
+		c.JSON(http.StatusCreated, response)
+	}
+}
+
+// maxSigningMaterialSize caps each uploaded signature, certificate or provenance document
+const maxSigningMaterialSize = 4 << 20
+
+// readSigningMaterial collects the optional signing material from the multipart form.
+// Each field may be sent as a file part or as a plain form value.
+func readSigningMaterial(c *gin.Context) (services.ModuleSignatures, error) {
+	var material services.ModuleSignatures
+	fields := []struct {
+		name   string
+		target *[]byte
+	}{
+		{"signature", &material.Signature},
+		{"certificate", &material.Certificate},
+		{"provenance", &material.Provenance},
+		{"provenance_certificate", &material.ProvenanceCertificate},
+	}
+
+	for _, field := range fields {
+		if file, _, err := c.Request.FormFile(field.name); err == nil {
+			data, err := io.ReadAll(io.LimitReader(file, maxSigningMaterialSize+1))
+			file.Close()
+			if err != nil {
+				return material, fmt.Errorf("Failed to read %s upload", field.name)
+			}
+			if len(data) > maxSigningMaterialSize {
+				return material, fmt.Errorf("%s exceeds the maximum size", field.name)
+			}
+			*field.target = data
+			continue
+		}
+		if value := c.PostForm(field.name); value != "" {
+			*field.target = []byte(value)
+		}
 	}
+
+	return material, nil
 }
@@ -71,6 +71,9 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				"published_at":   v.CreatedAt.Format(time.RFC3339),
 				"download_count": v.DownloadCount,
 				"deprecated":     v.Deprecated,
+				// Cosign signature and SLSA provenance verification status
+				"signature_status":  v.SignatureStatus,
+				"provenance_status": v.ProvenanceStatus,
 			}
 
 			// Include deprecation info if deprecated
@@ -80,6 +83,9 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			if v.DeprecationMessage != nil {
 				versionData["deprecation_message"] = *v.DeprecationMessage
 			}
+			if v.LicenseSPDX != nil {
+				versionData["license"] = *v.LicenseSPDX
+			}
 
 			// Include README if present
 			if v.Readme != nil {
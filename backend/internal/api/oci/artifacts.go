@@ -0,0 +1,198 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// Media and artifact types of the layout OpenTofu installs providers and modules from
+const (
+	indexMediaType    = "application/vnd.oci.image.index.v1+json"
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+	zipMediaType      = "archive/zip"
+
+	providerArtifactType       = "application/vnd.opentofu.provider"
+	providerTargetArtifactType = "application/vnd.opentofu.provider-target"
+	moduleArtifactType         = "application/vnd.opentofu.modulepkg"
+)
+
+// Annotations set on generated manifests
+const (
+	annotationTitle   = "org.opencontainers.image.title"
+	annotationVersion = "org.opencontainers.image.version"
+	annotationCreated = "org.opencontainers.image.created"
+)
+
+// emptyConfig is the config blob of every generated manifest
+var emptyConfig = []byte("{}")
+
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+}
+
+type descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	Platform     *platform         `json:"platform,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+type imageManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        descriptor        `json:"config"`
+	Layers        []descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+type imageIndex struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Manifests     []descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// document is a generated manifest or index with its digest
+type document struct {
+	MediaType string
+	Body      []byte
+	Digest    string
+}
+
+func newDocument(mediaType string, v interface{}) (*document, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OCI %s: %w", mediaType, err)
+	}
+	return &document{MediaType: mediaType, Body: body, Digest: digestOf(body)}, nil
+}
+
+func (d *document) descriptor() descriptor {
+	return descriptor{MediaType: d.MediaType, Digest: d.Digest, Size: int64(len(d.Body))}
+}
+
+func emptyConfigDescriptor() descriptor {
+	return descriptor{MediaType: emptyMediaType, Digest: digestOf(emptyConfig), Size: int64(len(emptyConfig))}
+}
+
+// providerDocuments generates the index of a provider version and the manifest of each of its
+// platforms. Layers are the stored provider zips, addressed by their recorded SHA256.
+func providerDocuments(version *models.ProviderVersion, platforms []*models.ProviderPlatform) (*document, []*document, error) {
+	index := imageIndex{
+		SchemaVersion: 2,
+		MediaType:     indexMediaType,
+		ArtifactType:  providerArtifactType,
+		Annotations:   versionAnnotations(version.Version, version.CreatedAt.UTC().Format(time.RFC3339)),
+	}
+
+	manifests := make([]*document, 0, len(platforms))
+	for _, p := range platforms {
+		manifest, err := newDocument(manifestMediaType, imageManifest{
+			SchemaVersion: 2,
+			MediaType:     manifestMediaType,
+			ArtifactType:  providerTargetArtifactType,
+			Config:        emptyConfigDescriptor(),
+			Layers: []descriptor{{
+				MediaType:   zipMediaType,
+				Digest:      "sha256:" + p.Shasum,
+				Size:        p.SizeBytes,
+				Annotations: map[string]string{annotationTitle: p.Filename},
+			}},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		manifests = append(manifests, manifest)
+
+		entry := manifest.descriptor()
+		entry.ArtifactType = providerTargetArtifactType
+		entry.Platform = &platform{OS: p.OS, Architecture: p.Arch}
+		index.Manifests = append(index.Manifests, entry)
+	}
+
+	indexDocument, err := newDocument(indexMediaType, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return indexDocument, manifests, nil
+}
+
+// moduleDocument generates the manifest of a module version whose archive converts to a zip
+// with the given digest and size
+func moduleDocument(version *models.ModuleVersion, zipDigest string, zipSize int64) (*document, error) {
+	return newDocument(manifestMediaType, imageManifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		ArtifactType:  moduleArtifactType,
+		Config:        emptyConfigDescriptor(),
+		Layers: []descriptor{{
+			MediaType: zipMediaType,
+			Digest:    zipDigest,
+			Size:      zipSize,
+		}},
+		Annotations: versionAnnotations(version.Version, version.CreatedAt.UTC().Format(time.RFC3339)),
+	})
+}
+
+func versionAnnotations(version, created string) map[string]string {
+	return map[string]string{
+		annotationVersion: version,
+		annotationCreated: created,
+	}
+}
+
+// zipInfo is the digest and size of a module version's archive converted to a zip
+type zipInfo struct {
+	Digest string
+	Size   int64
+}
+
+// documentCacheSize caps the documents cached before the cache is cleared
+const documentCacheSize = 4096
+
+// artifactCache remembers generated documents by digest, so the platform manifests clients
+// fetch after a provider index are served without regenerating it
+type artifactCache struct {
+	documents map[string]*document // Keyed by repository and digest
+	mu        sync.Mutex
+}
+
+func newArtifactCache() *artifactCache {
+	return &artifactCache{
+		documents: make(map[string]*document),
+	}
+}
+
+func (a *artifactCache) putDocuments(repository string, documents ...*document) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.documents)+len(documents) > documentCacheSize {
+		a.documents = make(map[string]*document)
+	}
+	for _, d := range documents {
+		a.documents[repository+"@"+d.Digest] = d
+	}
+}
+
+func (a *artifactCache) document(repository, digest string) *document {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.documents[repository+"@"+digest]
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
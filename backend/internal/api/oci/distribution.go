@@ -0,0 +1,453 @@
+package oci
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// Error codes of the OCI Distribution spec
+const (
+	errNameUnknown     = "NAME_UNKNOWN"
+	errManifestUnknown = "MANIFEST_UNKNOWN"
+	errBlobUnknown     = "BLOB_UNKNOWN"
+	errUnsupported     = "UNSUPPORTED"
+	errDigestInvalid   = "DIGEST_INVALID"
+)
+
+// blobURLTTL is how long the backend URLs provider zips are redirected to remain valid
+const blobURLTTL = 15 * time.Minute
+
+// distribution serves providers and modules read-only over the OCI Distribution API
+type distribution struct {
+	providerRepo *repositories.ProviderRepository
+	moduleRepo   *repositories.ModuleRepository
+	orgRepo      *repositories.OrganizationRepository
+	backends     *storage.Registry
+	cache        *artifactCache
+}
+
+// repository is a parsed repository name
+type repository struct {
+	name     string
+	provider *models.Provider
+	module   *models.Module
+}
+
+// DistributionHandler serves the OCI Distribution read API OpenTofu installs providers and
+// modules from. Repositories are named providers/{namespace}/{type} and
+// modules/{namespace}/{name}/{system}, and tagged with versions ("+" in a version becomes "_").
+// Provider versions are image indexes with a manifest per platform whose layer is the stored
+// provider zip; module versions are manifests whose layer is the stored archive converted to
+// a zip on the fly. Nothing is stored twice.
+// Implements: GET/HEAD /v2/, /v2/{name}/manifests/{reference}, /v2/{name}/blobs/{digest}
+// and /v2/{name}/tags/list
+func DistributionHandler(db *sql.DB, backends *storage.Registry, cfg *config.Config) gin.HandlerFunc {
+	d := &distribution{
+		providerRepo: repositories.NewProviderRepository(db),
+		moduleRepo:   repositories.NewModuleRepository(db),
+		orgRepo:      repositories.NewOrganizationRepository(db),
+		backends:     backends,
+		cache:        newArtifactCache(),
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Docker-Distribution-API-Version", "registry/2.0")
+
+		path := strings.Trim(c.Param("path"), "/")
+		if path == "" {
+			// API version check
+			c.JSON(http.StatusOK, gin.H{})
+			return
+		}
+
+		var name, reference, action string
+		for _, endpoint := range []string{"/manifests/", "/blobs/"} {
+			if i := strings.LastIndex(path, endpoint); i > 0 {
+				name, reference, action = path[:i], path[i+len(endpoint):], strings.Trim(endpoint, "/")
+				break
+			}
+		}
+		if strings.HasSuffix(path, "/tags/list") {
+			name, action = strings.TrimSuffix(path, "/tags/list"), "tags"
+		}
+		if action == "" {
+			ociError(c, http.StatusNotFound, errUnsupported, "unsupported endpoint")
+			return
+		}
+
+		repo, err := d.repository(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to look up repository"})
+			return
+		}
+		if repo == nil {
+			ociError(c, http.StatusNotFound, errNameUnknown, fmt.Sprintf("repository %s not found", name))
+			return
+		}
+
+		switch action {
+		case "manifests":
+			d.serveManifest(c, repo, reference)
+		case "blobs":
+			d.serveBlob(c, repo, reference)
+		case "tags":
+			d.serveTags(c, repo)
+		}
+	}
+}
+
+// repository resolves a repository name to the provider or module it presents, or nil
+func (d *distribution) repository(ctx context.Context, name string) (*repository, error) {
+	org, err := d.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, fmt.Errorf("default organization not found")
+	}
+
+	parts := strings.Split(name, "/")
+	switch {
+	case parts[0] == "providers" && len(parts) == 3:
+		provider, err := d.providerRepo.GetProvider(ctx, org.ID, parts[1], parts[2])
+		if err != nil || provider == nil {
+			return nil, err
+		}
+		return &repository{name: name, provider: provider}, nil
+
+	case parts[0] == "modules" && len(parts) == 4:
+		module, err := d.moduleRepo.GetModule(ctx, org.ID, parts[1], parts[2], parts[3])
+		if err != nil || module == nil {
+			return nil, err
+		}
+		return &repository{name: name, module: module}, nil
+	}
+
+	return nil, nil
+}
+
+// serveManifest serves the index or manifest of a version by tag, or any generated document by digest
+func (d *distribution) serveManifest(c *gin.Context, repo *repository, reference string) {
+	ctx := c.Request.Context()
+
+	var doc *document
+	var err error
+	if strings.HasPrefix(reference, "sha256:") {
+		doc, err = d.documentByDigest(ctx, repo, reference)
+	} else if repo.provider != nil {
+		doc, err = d.providerIndex(ctx, repo, tagVersion(reference))
+	} else {
+		doc, err = d.moduleManifest(ctx, repo, tagVersion(reference))
+	}
+	if err != nil {
+		log.Printf("Failed to generate OCI manifest for %s:%s: %v", repo.name, reference, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate manifest"})
+		return
+	}
+	if doc == nil {
+		ociError(c, http.StatusNotFound, errManifestUnknown, fmt.Sprintf("manifest %s not found in %s", reference, repo.name))
+		return
+	}
+
+	c.Header("Docker-Content-Digest", doc.Digest)
+	c.Data(http.StatusOK, doc.MediaType, doc.Body)
+}
+
+// providerIndex generates the index of a provider version, or nil if it has no platforms
+func (d *distribution) providerIndex(ctx context.Context, repo *repository, version string) (*document, error) {
+	providerVersion, err := d.providerRepo.GetVersion(ctx, repo.provider.ID, version)
+	if err != nil || providerVersion == nil {
+		return nil, err
+	}
+	return d.providerVersionIndex(ctx, repo, providerVersion)
+}
+
+func (d *distribution) providerVersionIndex(ctx context.Context, repo *repository, providerVersion *models.ProviderVersion) (*document, error) {
+	platforms, err := d.providerRepo.ListPlatforms(ctx, providerVersion.ID)
+	if err != nil || len(platforms) == 0 {
+		return nil, err
+	}
+
+	index, manifests, err := providerDocuments(providerVersion, platforms)
+	if err != nil {
+		return nil, err
+	}
+	// Clients fetch the platform manifests by digest next
+	d.cache.putDocuments(repo.name, append(manifests, index)...)
+	return index, nil
+}
+
+// moduleManifest generates the manifest of a module version, or nil if it does not exist
+func (d *distribution) moduleManifest(ctx context.Context, repo *repository, version string) (*document, error) {
+	moduleVersion, err := d.moduleRepo.GetVersion(ctx, repo.module.ID, version)
+	if err != nil || moduleVersion == nil {
+		return nil, err
+	}
+	return d.moduleVersionManifest(ctx, repo, moduleVersion)
+}
+
+func (d *distribution) moduleVersionManifest(ctx context.Context, repo *repository, moduleVersion *models.ModuleVersion) (*document, error) {
+	info, err := d.moduleZip(ctx, moduleVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := moduleDocument(moduleVersion, info.Digest, info.Size)
+	if err != nil {
+		return nil, err
+	}
+	d.cache.putDocuments(repo.name, manifest)
+	return manifest, nil
+}
+
+// moduleZip returns the digest and size of a module version's archive converted to a zip.
+// They are recorded when the archive is stored; versions stored before they were are
+// converted once and recorded then.
+func (d *distribution) moduleZip(ctx context.Context, moduleVersion *models.ModuleVersion) (zipInfo, error) {
+	if moduleVersion.OCIZipDigest != nil && moduleVersion.OCIZipSize != nil {
+		return zipInfo{Digest: *moduleVersion.OCIZipDigest, Size: *moduleVersion.OCIZipSize}, nil
+	}
+
+	archive, err := d.downloadModule(ctx, moduleVersion)
+	if err != nil {
+		return zipInfo{}, err
+	}
+	defer archive.Close()
+
+	digest, size, err := validation.ModuleZipDigest(archive)
+	if err != nil {
+		return zipInfo{}, err
+	}
+	if err := d.moduleRepo.SetOCIZip(ctx, moduleVersion.ID, digest, size); err != nil {
+		log.Printf("Failed to record OCI zip of module version %s: %v", moduleVersion.ID, err)
+	}
+	moduleVersion.OCIZipDigest, moduleVersion.OCIZipSize = &digest, &size
+	return zipInfo{Digest: digest, Size: size}, nil
+}
+
+// convertModule writes a module version's archive to w as a zip
+func (d *distribution) convertModule(ctx context.Context, moduleVersion *models.ModuleVersion, w io.Writer) error {
+	archive, err := d.downloadModule(ctx, moduleVersion)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	return validation.ModuleArchiveToZip(archive, w)
+}
+
+// downloadModule opens a module version's stored archive
+func (d *distribution) downloadModule(ctx context.Context, moduleVersion *models.ModuleVersion) (io.ReadCloser, error) {
+	backend, err := d.backends.Resolve(ctx, moduleVersion.StorageBackend)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := backend.Download(ctx, moduleVersion.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download module archive: %w", err)
+	}
+	return archive, nil
+}
+
+// documentByDigest finds a generated document by digest, regenerating the repository's documents
+// if it is not cached. Module manifests are regenerated only from recorded zips, so no archive
+// is converted for a digest the repository does not have.
+func (d *distribution) documentByDigest(ctx context.Context, repo *repository, digest string) (*document, error) {
+	if doc := d.cache.document(repo.name, digest); doc != nil {
+		return doc, nil
+	}
+
+	if repo.provider != nil {
+		versions, err := d.providerRepo.ListVersions(ctx, repo.provider.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			if _, err := d.providerVersionIndex(ctx, repo, version); err != nil {
+				return nil, err
+			}
+			if doc := d.cache.document(repo.name, digest); doc != nil {
+				return doc, nil
+			}
+		}
+		return nil, nil
+	}
+
+	versions, err := d.moduleRepo.ListVersions(ctx, repo.module.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		// A version without a recorded zip has had no manifest served since it was recorded
+		if version.OCIZipDigest == nil || version.OCIZipSize == nil {
+			continue
+		}
+		manifest, err := moduleDocument(version, *version.OCIZipDigest, *version.OCIZipSize)
+		if err != nil {
+			return nil, err
+		}
+		if manifest.Digest == digest {
+			d.cache.putDocuments(repo.name, manifest)
+			return manifest, nil
+		}
+	}
+	return nil, nil
+}
+
+// serveBlob serves a layer or config blob. Provider zips are redirected to the backend holding
+// them; module zips are converted from the stored archive as they are sent.
+func (d *distribution) serveBlob(c *gin.Context, repo *repository, digest string) {
+	ctx := c.Request.Context()
+	if !strings.HasPrefix(digest, "sha256:") || len(digest) != len("sha256:")+64 {
+		ociError(c, http.StatusBadRequest, errDigestInvalid, fmt.Sprintf("invalid digest %s", digest))
+		return
+	}
+
+	if digest == digestOf(emptyConfig) {
+		c.Header("Docker-Content-Digest", digest)
+		c.Data(http.StatusOK, emptyMediaType, emptyConfig)
+		return
+	}
+
+	if repo.provider != nil {
+		platform, err := d.providerRepo.GetPlatformByShasum(ctx, repo.provider.ID, strings.TrimPrefix(digest, "sha256:"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query provider platform"})
+			return
+		}
+		if platform == nil {
+			ociError(c, http.StatusNotFound, errBlobUnknown, fmt.Sprintf("blob %s not found in %s", digest, repo.name))
+			return
+		}
+
+		c.Header("Docker-Content-Digest", digest)
+		if c.Request.Method == http.MethodHead {
+			c.Header("Content-Length", strconv.FormatInt(platform.SizeBytes, 10))
+			c.Header("Content-Type", "application/octet-stream")
+			c.Status(http.StatusOK)
+			return
+		}
+
+		downloadURL, err := d.backends.GetURL(storage.WithPrincipal(ctx, middleware.Principal(c)), platform.StorageBackend, platform.StoragePath, blobURLTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate download URL"})
+			return
+		}
+		c.Redirect(http.StatusTemporaryRedirect, downloadURL)
+		return
+	}
+
+	moduleVersion, info, err := d.moduleVersionByZip(ctx, repo, digest)
+	if err != nil {
+		log.Printf("Failed to find OCI blob %s in %s: %v", digest, repo.name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find blob"})
+		return
+	}
+	if moduleVersion == nil {
+		ociError(c, http.StatusNotFound, errBlobUnknown, fmt.Sprintf("blob %s not found in %s", digest, repo.name))
+		return
+	}
+
+	c.Header("Docker-Content-Digest", digest)
+	c.Header("Content-Length", strconv.FormatInt(info.Size, 10))
+	c.Header("Content-Type", "application/zip")
+	c.Status(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+	if err := d.convertModule(ctx, moduleVersion, c.Writer); err != nil {
+		// Headers are sent; the client sees a short body that fails digest verification
+		log.Printf("Failed to send OCI blob %s of %s: %v", digest, repo.name, err)
+	}
+}
+
+// moduleVersionByZip finds the module version whose archive converts to the zip with digest,
+// looking it up by the zip digest recorded for each version
+func (d *distribution) moduleVersionByZip(ctx context.Context, repo *repository, digest string) (*models.ModuleVersion, zipInfo, error) {
+	version, err := d.moduleRepo.GetVersionByOCIZipDigest(ctx, repo.module.ID, digest)
+	if err != nil || version == nil {
+		return nil, zipInfo{}, err
+	}
+	info, err := d.moduleZip(ctx, version)
+	return version, info, err
+}
+
+// serveTags lists the repository's tags in lexical order, paginated by the n and last parameters
+func (d *distribution) serveTags(c *gin.Context, repo *repository) {
+	ctx := c.Request.Context()
+
+	var tags []string
+	if repo.provider != nil {
+		versions, err := d.providerRepo.ListVersions(ctx, repo.provider.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list provider versions"})
+			return
+		}
+		for _, version := range versions {
+			tags = append(tags, versionTag(version.Version))
+		}
+	} else {
+		versions, err := d.moduleRepo.ListVersions(ctx, repo.module.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list module versions"})
+			return
+		}
+		for _, version := range versions {
+			tags = append(tags, versionTag(version.Version))
+		}
+	}
+	sort.Strings(tags)
+
+	if last := c.Query("last"); last != "" {
+		start := sort.SearchStrings(tags, last)
+		if start < len(tags) && tags[start] == last {
+			start++
+		}
+		tags = tags[start:]
+	}
+	if n, err := strconv.Atoi(c.Query("n")); err == nil && n >= 0 && n < len(tags) {
+		tags = tags[:n]
+		c.Header("Link", fmt.Sprintf(`</v2/%s/tags/list?n=%d&last=%s>; rel="next"`, repo.name, n, tags[len(tags)-1]))
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"name": repo.name,
+		"tags": tags,
+	})
+}
+
+// versionTag turns a version into a tag; tags can't contain "+"
+func versionTag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+// tagVersion turns a tag back into a version; versions can't contain "_"
+func tagVersion(tag string) string {
+	return strings.ReplaceAll(tag, "_", "+")
+}
+
+// ociError responds with an error in the OCI Distribution format
+func ociError(c *gin.Context, status int, code, message string) {
+	c.JSON(status, gin.H{
+		"errors": []gin.H{{"code": code, "message": message}},
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
@@ -16,7 +17,8 @@ import (
 // DownloadHandler handles provider download requests
 // Implements: GET /v1/providers/:namespace/:type/:version/download/:os/:arch
 // Returns JSON with download URL, checksums, and signing keys
-func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+// The URL comes from whichever backend the platform binary is stored in
+func DownloadHandler(db *sql.DB, backends *storage.Registry, cfg *config.Config) gin.HandlerFunc {
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
 
@@ -105,7 +107,7 @@ func DownloadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Con
 
 		// Get download URL from storage backend
 		// TTL of 15 minutes for signed URLs
-		downloadURL, err := storageBackend.GetURL(c.Request.Context(), platform.StoragePath, 15*time.Minute)
+		downloadURL, err := backends.GetURL(storage.WithPrincipal(c.Request.Context(), middleware.Principal(c)), platform.StorageBackend, platform.StoragePath, 15*time.Minute)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to generate download URL",
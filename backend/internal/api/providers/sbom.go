@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// SBOMHandler serves the software bill of materials for a provider version
+// Implements: GET /v1/providers/:namespace/:type/:version/sbom?format=cyclonedx|spdx
+// SBOMs missing from storage (e.g. for versions published before SBOM support) are regenerated
+func SBOMHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+	providerRepo := repositories.NewProviderRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	sbomService := services.NewSBOMService(providerRepo, storageBackend, cfg.Server.BaseURL)
+
+	return func(c *gin.Context) {
+		namespace := c.Param("namespace")
+		providerType := c.Param("type")
+		version := c.Param("version")
+
+		format, err := sbom.ParseFormat(c.Query("format"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		// Validate semantic versioning
+		if err := validation.ValidateSemver(version); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"Invalid version format - must be valid semantic versioning"},
+			})
+			return
+		}
+
+		// Get organization context
+		org, err := orgRepo.GetDefaultOrganization(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to get organization context",
+			})
+			return
+		}
+		if org == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Default organization not found - please run migrations",
+			})
+			return
+		}
+
+		// Get provider
+		provider, err := providerRepo.GetProvider(c.Request.Context(), org.ID, namespace, providerType)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query provider",
+			})
+			return
+		}
+		if provider == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Provider not found"},
+			})
+			return
+		}
+
+		// Get provider version
+		providerVersion, err := providerRepo.GetVersion(c.Request.Context(), provider.ID, version)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to query provider version",
+			})
+			return
+		}
+		if providerVersion == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"errors": []string{"Provider version not found"},
+			})
+			return
+		}
+
+		document, err := sbomService.ProviderSBOM(c.Request.Context(), provider, providerVersion, format)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to generate SBOM: %v", err),
+			})
+			return
+		}
+
+		filename := fmt.Sprintf("%s-%s-%s.%s.json", namespace, providerType, version, format)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Data(http.StatusOK, format.ContentType(), document)
+	}
+}
@@ -77,8 +77,10 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			// Get latest version for each provider
 			versions, _ := providerRepo.ListVersions(c.Request.Context(), p.ID)
 			var latestVersion string
+			var license *string
 			if len(versions) > 0 {
 				latestVersion = versions[0].Version
+				license = versions[0].LicenseSPDX
 			}
 
 			// Get total downloads across all platforms for this provider
@@ -91,6 +93,7 @@ func SearchHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 				"description":     p.Description,
 				"source":          p.Source,
 				"latest_version":  latestVersion,
+				"license":         license,
 				"download_count":  totalDownloads,
 				"created_by":      p.CreatedBy,
 				"created_by_name": p.CreatedByName,
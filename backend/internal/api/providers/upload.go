@@ -1,7 +1,6 @@
 package providers
 
 import (
-	"bytes"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -9,9 +8,12 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/terraform-registry/terraform-registry/internal/api/uploads"
 	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 	"github.com/terraform-registry/terraform-registry/pkg/checksum"
@@ -20,18 +22,29 @@ import (
 const (
 	// MaxProviderBinarySize is the maximum size for a provider binary (500MB)
 	MaxProviderBinarySize = 500 << 20 // 500MB
+
+	// uploadFormMemory is how much of a multipart upload is held in memory; larger file parts
+	// are spooled to temp files by net/http and removed once the request completes
+	uploadFormMemory = 8 << 20 // 8MB
 )
 
 // UploadHandler handles provider upload requests
 // Implements: POST /api/v1/providers
 // Accepts multipart form with: namespace, type, version, os, arch, protocols, gpg_public_key, file
-func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Config) gin.HandlerFunc {
+// (or upload_id naming a completed resumable upload)
+func UploadHandler(db *sql.DB, backends *storage.Registry, cfg *config.Config, uploadSessions *services.UploadSessionService, quotaService *services.StorageQuotaService) gin.HandlerFunc {
+	// New artifacts are written to the default backend
+	storageBackend := backends.Default()
 	providerRepo := repositories.NewProviderRepository(db)
 	orgRepo := repositories.NewOrganizationRepository(db)
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	admissionController := services.NewAdmissionController(repositories.NewAdmissionWebhookRepository(sqlxDB), backends)
+	licenseEvaluator := services.NewLicensePolicyEvaluator(repositories.NewLicensePolicyRepository(sqlxDB))
+	sbomService := services.NewSBOMService(providerRepo, storageBackend, cfg.Server.BaseURL)
 
 	return func(c *gin.Context) {
-		// Parse multipart form (max 500MB for provider binaries)
-		if err := c.Request.ParseMultipartForm(MaxProviderBinarySize); err != nil {
+		// Parse multipart form, spooling provider binaries to disk rather than memory
+		if err := c.Request.ParseMultipartForm(uploadFormMemory); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Failed to parse multipart form",
 			})
@@ -99,36 +112,30 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			gpgPublicKey = validation.NormalizeGPGKey(gpgPublicKey)
 		}
 
-		// Get uploaded file
-		file, header, err := c.Request.FormFile("file")
+		// Get the uploaded file, sent as a form file or as a completed resumable upload
+		artifact, status, err := uploads.OpenArtifact(c, uploadSessions, models.ArtifactTypeProvider)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Missing or invalid file upload",
+			c.JSON(status, gin.H{
+				"error": err.Error(),
 			})
 			return
 		}
-		defer file.Close()
+		defer artifact.Close()
+		file := artifact.File
 
-		// Read file into buffer for validation and upload
-		fileBuffer := &bytes.Buffer{}
-		size, err := io.Copy(fileBuffer, file)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to read uploaded file",
-			})
-			return
-		}
+		// The binary is read in place from the spooled upload
+		size := artifact.Size
 
 		// Validate provider binary (ZIP format and size)
-		if err := validation.ValidateProviderBinary(fileBuffer.Bytes(), MaxProviderBinarySize); err != nil {
+		if err := validation.ValidateProviderArchive(file, size, MaxProviderBinarySize); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": fmt.Sprintf("Invalid provider binary: %v", err),
 			})
 			return
 		}
 
-		// Calculate SHA256 checksum
-		sha256sum, err := checksum.CalculateSHA256(bytes.NewReader(fileBuffer.Bytes()))
+		// Calculate SHA256 checksum in a single streaming pass
+		sha256sum, err := checksum.CalculateSHA256(io.NewSectionReader(file, 0, size))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to calculate checksum",
@@ -151,6 +158,41 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// Detect the provider license and check it against license policies
+		license := validation.DetectLicenseInZipReader(file, size)
+		licenseDecision, err := licenseEvaluator.Check(c.Request.Context(), org.ID, models.ArtifactTypeProvider, license)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to evaluate license policies: %v", err),
+			})
+			return
+		}
+		if !licenseDecision.Allowed {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":   fmt.Sprintf("Publish rejected: %s", licenseDecision.Message),
+				"license": licenseDecision,
+			})
+			return
+		}
+
+		// Reserve room for the binary in the organization's storage quota. The reservation
+		// holds until the platform is recorded, so concurrent uploads can't exceed the quota together.
+		quotaDecision, releaseQuota, err := quotaService.Reserve(c.Request.Context(), org.ID, size)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": fmt.Sprintf("Failed to check storage quota: %v", err),
+			})
+			return
+		}
+		if !quotaDecision.Allowed {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": fmt.Sprintf("Publish rejected: %s", quotaDecision.Message),
+				"quota": quotaDecision,
+			})
+			return
+		}
+		defer releaseQuota()
+
 		// Check if provider already exists, create if not
 		provider, err := providerRepo.GetProvider(c.Request.Context(), org.ID, namespace, providerType)
 		if err != nil {
@@ -211,6 +253,7 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		createdVersion := false
 		if providerVersion == nil {
 			// Create new version
 			// Note: shasums_url and shasums_signature_url would be set separately
@@ -229,6 +272,9 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 					providerVersion.PublishedBy = &uid
 				}
 			}
+			if license != "" {
+				providerVersion.LicenseSPDX = &license
+			}
 
 			if err := providerRepo.CreateVersion(c.Request.Context(), providerVersion); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
@@ -236,6 +282,14 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 				})
 				return
 			}
+			createdVersion = true
+		} else if providerVersion.LicenseSPDX == nil && license != "" {
+			// Record the license for versions created before it could be detected
+			if err := providerRepo.SetVersionLicense(c.Request.Context(), providerVersion.ID, &license); err != nil {
+				// Log warning but don't fail the upload
+				fmt.Printf("Warning: Failed to record provider license: %v\n", err)
+			}
+			providerVersion.LicenseSPDX = &license
 		}
 
 		// Check for duplicate platform
@@ -256,11 +310,11 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 		// Generate storage path: providers/{namespace}/{type}/{version}/{os}_{arch}.zip
 		storagePath := fmt.Sprintf("providers/%s/%s/%s/%s_%s.zip", namespace, providerType, version, os, arch)
 
-		// Upload to storage backend
+		// Upload to storage backend, streaming from the spooled binary
 		uploadResult, err := storageBackend.Upload(
 			c.Request.Context(),
 			storagePath,
-			bytes.NewReader(fileBuffer.Bytes()),
+			storage.WithChecksum(io.NewSectionReader(file, 0, size), sha256sum),
 			size,
 		)
 		if err != nil {
@@ -270,12 +324,51 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			return
 		}
 
+		// Consult admission webhooks before committing the platform
+		admissionReq := services.AdmissionRequest{
+			ArtifactType:   models.ArtifactTypeProvider,
+			OrganizationID: org.ID,
+			Namespace:      namespace,
+			Type:           providerType,
+			Version:        version,
+			OS:             os,
+			Arch:           arch,
+			Filename:       artifact.Filename,
+			Checksum:       sha256sum,
+			SizeBytes:      uploadResult.Size,
+		}
+		if userID, exists := c.Get("user_id"); exists {
+			if uid, ok := userID.(string); ok {
+				admissionReq.PublishedBy = uid
+			}
+		}
+		admission, err := admissionController.Review(c.Request.Context(), admissionReq, uploadResult.Path)
+		if err != nil || !admission.Allowed {
+			// Remove the artifact, and the version if this upload created it
+			storageBackend.Delete(c.Request.Context(), uploadResult.Path)
+			if createdVersion {
+				providerRepo.DeleteVersion(c.Request.Context(), providerVersion.ID)
+			}
+
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": fmt.Sprintf("Failed to run admission webhooks: %v", err),
+				})
+				return
+			}
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":             fmt.Sprintf("Publish rejected: %s", admission.DenialMessage()),
+				"admission_results": admission.Results,
+			})
+			return
+		}
+
 		// Create platform record
 		platform := &models.ProviderPlatform{
 			ProviderVersionID: providerVersion.ID,
 			OS:                os,
 			Arch:              arch,
-			Filename:          header.Filename,
+			Filename:          artifact.Filename,
 			StoragePath:       uploadResult.Path,
 			StorageBackend:    cfg.Storage.DefaultBackend,
 			SizeBytes:         uploadResult.Size,
@@ -291,9 +384,16 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			})
 			return
 		}
+		artifact.Published(c.Request.Context())
+
+		// Refresh the version's SBOM so it lists the new platform
+		if err := sbomService.GenerateProviderSBOM(c.Request.Context(), provider, providerVersion); err != nil {
+			// Log warning but don't fail the upload; the SBOM is regenerated on request
+			fmt.Printf("Warning: Failed to generate SBOM: %v\n", err)
+		}
 
 		// Return success response with provider metadata
-		c.JSON(http.StatusCreated, gin.H{
+		response := gin.H{
 			"id":        provider.ID,
 			"namespace": provider.Namespace,
 			"type":      provider.Type,
@@ -303,7 +403,14 @@ func UploadHandler(db *sql.DB, storageBackend storage.Storage, cfg *config.Confi
 			"protocols": providerVersion.Protocols,
 			"checksum":  platform.Shasum,
 			"size_bytes": platform.SizeBytes,
-			"filename":  header.Filename,
-		})
+			"filename":  artifact.Filename,
+		}
+		if providerVersion.LicenseSPDX != nil {
+			response["license"] = *providerVersion.LicenseSPDX
+		}
+		if len(admission.Results) > 0 {
+			response["admission_results"] = admission.Results
+		}
+		c.JSON(http.StatusCreated, response)
 	}
 }
@@ -103,6 +103,9 @@ func ListVersionsHandler(db *sql.DB, cfg *config.Config) gin.HandlerFunc {
 			if v.DeprecationMessage != nil {
 				versionData["deprecation_message"] = *v.DeprecationMessage
 			}
+			if v.LicenseSPDX != nil {
+				versionData["license"] = *v.LicenseSPDX
+			}
 			// Include published_by info for audit tracking
 			if v.PublishedBy != nil {
 				versionData["published_by"] = *v.PublishedBy
@@ -13,7 +13,9 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/api/admin"
 	"github.com/terraform-registry/terraform-registry/internal/api/mirror"
 	"github.com/terraform-registry/terraform-registry/internal/api/modules"
+	"github.com/terraform-registry/terraform-registry/internal/api/oci"
 	"github.com/terraform-registry/terraform-registry/internal/api/providers"
+	"github.com/terraform-registry/terraform-registry/internal/api/uploads"
 	"github.com/terraform-registry/terraform-registry/internal/api/webhooks"
 	"github.com/terraform-registry/terraform-registry/internal/auth"
 	"github.com/terraform-registry/terraform-registry/internal/config"
@@ -26,6 +28,7 @@ import (
 
 	// Import storage backends to register them
 	_ "github.com/terraform-registry/terraform-registry/internal/storage/local"
+	_ "github.com/terraform-registry/terraform-registry/internal/storage/oci"
 
 	// Import SCM connectors to register them via init()
 	_ "github.com/terraform-registry/terraform-registry/internal/scm/azuredevops"
@@ -45,6 +48,36 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	}
 	log.Printf("Initialized storage backend: %s", cfg.Storage.DefaultBackend)
 
+	// Cloud backends are read through a local disk cache, so repeat downloads served by the
+	// registry do not go back to the backend
+	var storageCache *storage.DiskCache
+	if cfg.Storage.Cache.Enabled && cfg.Storage.DefaultBackend != "local" {
+		storageCache, err = storage.NewDiskCache(cfg.Storage.Cache.Dir, cfg.Storage.Cache.MaxSize)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage cache: %v", err)
+		}
+		cachingStorage := storage.NewCachingStorage(storageBackend, storageCache, cfg.Storage.DefaultBackend,
+			cfg.Server.BaseURL, cfg.Storage.Cache.ProxyDownloads)
+		cachingStorage.SetEncrypted(cfg.Storage.Encryption.Enabled)
+		storageBackend = cachingStorage
+		log.Printf("Storage cache enabled (%d bytes)", cfg.Storage.Cache.MaxSize)
+	}
+
+	// Files are encrypted at rest with per-file data keys when storage encryption is enabled.
+	// Encryption wraps the cache, so cached files are encrypted too.
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	storageDataKeyRepo := repositories.NewStorageDataKeyRepository(sqlxDB)
+	var storageKeyWrapper storage.KeyWrapper
+	if cfg.Storage.Encryption.Enabled {
+		storageKeyWrapper, err = storage.NewKeyWrapper(cfg.Storage.Encryption)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage encryption: %v", err)
+		}
+		storageBackend = storage.NewEncryptedStorage(storageBackend, cfg.Storage.DefaultBackend, storageDataKeyRepo,
+			storageKeyWrapper, cfg.Server.BaseURL)
+		log.Printf("Storage encryption enabled (master key %s)", storageKeyWrapper.KeyID())
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
 	apiKeyRepo := repositories.NewAPIKeyRepository(db)
@@ -54,13 +87,29 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	orgRepo := repositories.NewOrganizationRepository(db)
 
 	// Wrap *sql.DB with sqlx for SCM and mirror repositories
-	sqlxDB := sqlx.NewDb(db, "postgres")
 	scmRepo := repositories.NewSCMRepository(sqlxDB)
 	mirrorRepo := repositories.NewMirrorRepository(sqlxDB)
 	storageConfigRepo := repositories.NewStorageConfigRepository(sqlxDB)
+	licensePolicyRepo := repositories.NewLicensePolicyRepository(sqlxDB)
+	licenseEvaluator := services.NewLicensePolicyEvaluator(licensePolicyRepo)
+	storageQuotaRepo := repositories.NewStorageQuotaRepository(sqlxDB)
+	storageQuotaService := services.NewStorageQuotaService(storageQuotaRepo, orgRepo, cfg.Storage.Quotas)
+	sbomService := services.NewSBOMService(providerRepo, storageBackend, cfg.Server.BaseURL)
+
+	// Artifacts are written through artifactStorage, which stores them once per SHA256 digest
+	// when content addressing is enabled. SBOMs and other fixed-path files bypass it.
+	artifactStorage := storageBackend
+	storageBlobRepo := repositories.NewStorageBlobRepository(sqlxDB)
+	var contentAddressedStorage *storage.ContentAddressedStorage
+	if cfg.Storage.ContentAddressable {
+		contentAddressedStorage = storage.NewContentAddressedStorage(storageBackend, cfg.Storage.DefaultBackend, storageBlobRepo)
+		artifactStorage = contentAddressedStorage
+		log.Println("Content-addressed storage enabled for the default backend")
+	}
 
 	// Initialize mirror sync job
-	mirrorSyncJob := jobs.NewMirrorSyncJob(mirrorRepo, providerRepo, storageBackend)
+	rbacRepo := repositories.NewRBACRepository(sqlxDB)
+	mirrorSyncJob := jobs.NewMirrorSyncJob(mirrorRepo, providerRepo, artifactStorage, cfg.Storage.DefaultBackend, licenseEvaluator, storageQuotaService, sbomService, rbacRepo, cfg.Mirror)
 	// Start background sync job - check every 10 minutes for mirrors that need syncing
 	mirrorSyncJob.Start(context.Background(), 10)
 	log.Println("Mirror sync job started (checking every 10 minutes)")
@@ -77,9 +126,40 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 		log.Fatalf("Failed to initialize token cipher: %v", err)
 	}
 
+	// Artifacts are read from whichever backend their rows record, including saved storage configs
+	storageReplicationRepo := repositories.NewStorageReplicationRepository(sqlxDB)
+	storageBackends := storage.NewRegistry(cfg, artifactStorage, storageConfigRepo, storageReplicationRepo, tokenCipher)
+	if storageCache != nil {
+		storageBackends.SetCache(storageCache)
+	}
+	if storageKeyWrapper != nil {
+		storageBackends.SetEncryption(storageDataKeyRepo, storageKeyWrapper)
+	}
+
+	// Files served by the registry are only reachable through signed, expiring URLs
+	fileURLSecret := cfg.Storage.FileURLs.Secret
+	if fileURLSecret == "" {
+		fileURLSecret = auth.GetJWTSecret()
+	}
+	storageBackends.SetURLSigner(storage.NewURLSigner(fileURLSecret, cfg.Storage.FileURLs.BindPrincipal))
+
+	// Module manifests are signed with organization keys sealed by the token cipher
+	moduleSigner := services.NewModuleSigner(repositories.NewSigningKeyRepository(sqlxDB), tokenCipher, cfg.Server.BaseURL)
+
+	// Resumable uploads spool large artifacts to temp files until they are published;
+	// abandoned sessions expire and are cleaned up every 15 minutes
+	uploadSessions, err := services.NewUploadSessionService(repositories.NewUploadSessionRepository(sqlxDB), cfg.Storage.Uploads)
+	if err != nil {
+		log.Fatalf("Failed to initialize resumable uploads: %v", err)
+	}
+	uploadHandlers := uploads.NewHandlers(uploadSessions, cfg.Server.BaseURL)
+	uploadSessionCleanupJob := jobs.NewUploadSessionCleanupJob(uploadSessions)
+	uploadSessionCleanupJob.Start(context.Background(), 15)
+
 	// Add middleware
 	router.Use(gin.Recovery())
 	router.Use(LoggerMiddleware(cfg))
+	router.Use(uploadHandlers.DiscoveryMiddleware("/api/v1/uploads")) // Ahead of CORS, which answers OPTIONS
 	router.Use(CORSMiddleware(cfg))
 	router.Use(middleware.SecurityHeadersMiddleware(middleware.APISecurityHeadersConfig()))
 
@@ -101,11 +181,22 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	v1Modules.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo))
 	{
 		v1Modules.GET("/:namespace/:name/:system/versions", modules.ListVersionsHandler(db, cfg))
-		v1Modules.GET("/:namespace/:name/:system/:version/download", modules.DownloadHandler(db, storageBackend, cfg))
+		v1Modules.GET("/:namespace/:name/:system/:version/download", modules.DownloadHandler(db, storageBackends, cfg))
+		v1Modules.GET("/:namespace/:name/:system/:version/sbom", modules.SBOMHandler(db, storageBackend, cfg))
+		v1Modules.GET("/:namespace/:name/:system/:version/manifest", modules.ManifestHandler(db, moduleSigner))
 	}
 
+	// Public key set for verifying registry-signed module manifests
+	router.GET("/v1/signing-keys", modules.SigningKeysHandler(db, moduleSigner))
+
 	// File serving endpoint for local storage with ServeDirectly enabled
-	router.GET("/v1/files/*filepath", modules.ServeFileHandler(storageBackend, cfg))
+	// URLs bound to the caller they were issued to need the caller's credentials checked
+	fileHandlers := []gin.HandlerFunc{modules.ServeFileHandler(storageBackends, cfg)}
+	if cfg.Storage.FileURLs.BindPrincipal {
+		fileHandlers = append([]gin.HandlerFunc{middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo)}, fileHandlers...)
+	}
+	router.GET("/v1/files/*filepath", fileHandlers...)
+	router.HEAD("/v1/files/*filepath", fileHandlers...)
 
 	// Provider Registry endpoints (v1)
 	// These are for the standard Provider Registry Protocol
@@ -113,7 +204,8 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	v1Providers.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo))
 	{
 		v1Providers.GET("/:namespace/:type/versions", providers.ListVersionsHandler(db, cfg))
-		v1Providers.GET("/:namespace/:type/:version/download/:os/:arch", providers.DownloadHandler(db, storageBackend, cfg))
+		v1Providers.GET("/:namespace/:type/:version/download/:os/:arch", providers.DownloadHandler(db, storageBackends, cfg))
+		v1Providers.GET("/:namespace/:type/:version/sbom", providers.SBOMHandler(db, storageBackend, cfg))
 	}
 
 	// Network Mirror endpoints (separate from Provider Registry to avoid routing conflicts)
@@ -122,7 +214,17 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	v1Mirror := router.Group("/terraform/providers")
 	{
 		v1Mirror.GET("/:hostname/:namespace/:type/index.json", mirror.IndexHandler(db, cfg))
-		v1Mirror.GET("/:hostname/:namespace/:type/:versionfile", mirror.PlatformIndexHandler(db, cfg))
+		v1Mirror.GET("/:hostname/:namespace/:type/:versionfile", mirror.PlatformIndexHandler(db, storageBackends, cfg))
+	}
+
+	// OCI Distribution endpoints for OpenTofu, serving providers and modules as OCI artifacts
+	// Repositories are providers/:namespace/:type and modules/:namespace/:name/:system
+	v2 := router.Group("/v2")
+	v2.Use(middleware.OptionalAuthMiddleware(cfg, userRepo, apiKeyRepo, orgRepo))
+	{
+		ociHandler := oci.DistributionHandler(db, storageBackends, cfg)
+		v2.GET("/*path", ociHandler)
+		v2.HEAD("/*path", ociHandler)
 	}
 
 	// Initialize admin handlers
@@ -137,12 +239,18 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	statsHandlers := admin.NewStatsHandler(sqlxDB)
 	mirrorHandlers := admin.NewMirrorHandler(mirrorRepo)
 	mirrorHandlers.SetSyncJob(mirrorSyncJob) // Connect sync job for manual triggers
-	providerAdminHandlers := admin.NewProviderAdminHandlers(db, storageBackend, cfg)
-	moduleAdminHandlers := admin.NewModuleAdminHandlers(db, storageBackend, cfg)
+	providerAdminHandlers := admin.NewProviderAdminHandlers(db, storageBackends, cfg)
+	moduleAdminHandlers := admin.NewModuleAdminHandlers(db, storageBackends, cfg)
 
 	// Initialize RBAC handlers
-	rbacRepo := repositories.NewRBACRepository(sqlxDB)
 	rbacHandlers := admin.NewRBACHandlers(rbacRepo)
+	publishRuleRepo := repositories.NewPublishRuleRepository(sqlxDB)
+	publishRuleHandlers := admin.NewPublishRuleHandlers(publishRuleRepo)
+	admissionWebhookRepo := repositories.NewAdmissionWebhookRepository(sqlxDB)
+	admissionWebhookHandlers := admin.NewAdmissionWebhookHandlers(admissionWebhookRepo)
+	licensePolicyHandlers := admin.NewLicensePolicyHandlers(licensePolicyRepo)
+	trustedSignerHandlers := admin.NewTrustedSignerHandlers(repositories.NewTrustedSignerRepository(sqlxDB))
+	signingKeyHandlers := admin.NewSigningKeyHandlers(db, moduleSigner)
 
 	// Initialize SCM handlers with the already-created repositories and token cipher
 	scmProviderHandlers := admin.NewSCMProviderHandlers(cfg, scmRepo, tokenCipher)
@@ -150,10 +258,40 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 	scmLinkingHandler := modules.NewSCMLinkingHandler(scmRepo, moduleRepo, tokenCipher, cfg.Server.BaseURL)
 
 	// Initialize storage configuration handlers
-	storageHandlers := admin.NewStorageHandlers(cfg, storageConfigRepo, tokenCipher)
+	storageMigrationRepo := repositories.NewStorageMigrationRepository(sqlxDB)
+	storageHandlers := admin.NewStorageHandlers(cfg, storageConfigRepo, storageMigrationRepo, storageReplicationRepo, storageBackends, tokenCipher)
+
+	// Storage migrations copy artifacts between backends; resume any interrupted by a restart
+	storageMigrationJob := jobs.NewStorageMigrationJob(storageMigrationRepo, storageBackends)
+	storageMigrationJob.Start(context.Background())
+	storageHandlers.SetMigrationJob(storageMigrationJob)
+
+	// Replicated storage: drain the replication queue and reconcile replicas every hour
+	storageReplicationJob := jobs.NewStorageReplicationJob(storageReplicationRepo, storageConfigRepo, storageBackends)
+	storageReplicationJob.Start(context.Background(), 60)
+	storageHandlers.SetReplicationJob(storageReplicationJob)
+
+	// Convert artifacts still stored by path into the content-addressed layout
+	if contentAddressedStorage != nil {
+		contentAddressingJob := jobs.NewContentAddressingJob(storageBlobRepo, contentAddressedStorage,
+			storageBackends.Aliases(cfg.Storage.DefaultBackend))
+		contentAddressingJob.Start(context.Background())
+		storageHandlers.SetContentAddressingJob(contentAddressingJob)
+	}
+
+	// Scrub storage for missing or corrupt artifacts and collect objects no artifact references
+	storageScrubJob := jobs.NewStorageScrubJob(repositories.NewStorageScrubRepository(sqlxDB), storageBlobRepo,
+		storageBackends, cfg.Storage.Scrub)
+	storageScrubJob.Start(context.Background())
+	storageHandlers.SetScrubJob(storageScrubJob)
+	storageHandlers.SetStorageQuotas(storageQuotaRepo, storageQuotaService, orgRepo)
 
 	// Initialize SCM publisher service
-	scmPublisher := services.NewSCMPublisher(scmRepo, moduleRepo, storageBackend, tokenCipher)
+	secretScanner := services.NewSecretScanner(cfg.Security.SecretScanning, moduleRepo)
+	publishPolicyEvaluator := services.NewPublishPolicyEvaluator(publishRuleRepo)
+	admissionController := services.NewAdmissionController(admissionWebhookRepo, storageBackends)
+	scmPublisher := services.NewSCMPublisher(scmRepo, moduleRepo, artifactStorage, cfg.Storage.DefaultBackend, tokenCipher,
+		secretScanner, publishPolicyEvaluator, admissionController, licenseEvaluator, sbomService, moduleSigner)
 	scmWebhookHandler := webhooks.NewSCMWebhookHandler(scmRepo, scmPublisher)
 
 	// Initialize rate limiters
@@ -205,13 +343,24 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 			authenticatedGroup.POST("/modules",
 				middleware.RateLimitMiddleware(uploadRateLimiter), // Stricter rate limit for uploads
 				middleware.RequireScope(auth.ScopeModulesWrite),
-				modules.UploadHandler(db, storageBackend, cfg))
+				modules.UploadHandler(db, storageBackends, cfg, moduleSigner, uploadSessions, storageQuotaService))
 
 			// Providers admin endpoints - require write permissions
 			authenticatedGroup.POST("/providers",
 				middleware.RateLimitMiddleware(uploadRateLimiter), // Stricter rate limit for uploads
 				middleware.RequireScope(auth.ScopeProvidersWrite),
-				providers.UploadHandler(db, storageBackend, cfg))
+				providers.UploadHandler(db, storageBackends, cfg, uploadSessions, storageQuotaService))
+
+			// Resumable (tus) uploads of large artifacts, published through the endpoints above
+			uploadsGroup := authenticatedGroup.Group("/uploads")
+			uploadsGroup.Use(middleware.RequireAnyScope(auth.ScopeModulesWrite, auth.ScopeProvidersWrite))
+			{
+				uploadsGroup.POST("", uploadHandlers.CreateUpload)
+				uploadsGroup.HEAD("/:id", uploadHandlers.GetUploadOffset)
+				uploadsGroup.GET("/:id", uploadHandlers.GetUpload)
+				uploadsGroup.PATCH("/:id", uploadHandlers.AppendUpload)
+				uploadsGroup.DELETE("/:id", uploadHandlers.DeleteUpload)
+			}
 			authenticatedGroup.GET("/providers/:namespace/:type",
 				middleware.RequireScope(auth.ScopeProvidersRead),
 				providerAdminHandlers.GetProvider)
@@ -244,6 +393,15 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 			authenticatedGroup.DELETE("/modules/:namespace/:name/:system/versions/:version/deprecate",
 				middleware.RequireScope(auth.ScopeModulesWrite),
 				moduleAdminHandlers.UndeprecateVersion)
+			authenticatedGroup.GET("/modules/:namespace/:name/:system/versions/:version/secret-findings",
+				middleware.RequireScope(auth.ScopeModulesRead),
+				moduleAdminHandlers.ListSecretFindings)
+			authenticatedGroup.GET("/modules/:namespace/:name/:system/versions/:version/policy-results",
+				middleware.RequireScope(auth.ScopeModulesRead),
+				moduleAdminHandlers.ListPolicyResults)
+			authenticatedGroup.GET("/modules/:namespace/:name/:system/versions/:version/attestations",
+				middleware.RequireScope(auth.ScopeModulesRead),
+				moduleAdminHandlers.ListAttestations)
 
 			// API Keys management - self-service for own keys
 			// Users can manage their own API keys without api_keys:manage scope
@@ -355,6 +513,8 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 				mirrorsGroup.PUT("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.UpdateMirrorConfig)
 				mirrorsGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.DeleteMirrorConfig)
 				mirrorsGroup.POST("/:id/sync", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.TriggerSync)
+				mirrorsGroup.DELETE("/:id/sync", middleware.RequireScope(auth.ScopeMirrorsManage), mirrorHandlers.CancelSync)
+				mirrorsGroup.GET("/:id/sync/events", middleware.RequireScope(auth.ScopeMirrorsRead), mirrorHandlers.StreamSyncProgress)
 			}
 
 			// Role Templates management
@@ -387,6 +547,55 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 				policiesGroup.POST("/evaluate", middleware.RequireScope(auth.ScopeMirrorsRead), rbacHandlers.EvaluatePolicy)
 			}
 
+			// Module Publish Rules
+			publishRulesGroup := authenticatedGroup.Group("/admin/publish-rules")
+			{
+				publishRulesGroup.GET("", middleware.RequireScope(auth.ScopeModulesRead), publishRuleHandlers.ListPublishRules)
+				publishRulesGroup.GET("/:id", middleware.RequireScope(auth.ScopeModulesRead), publishRuleHandlers.GetPublishRule)
+				publishRulesGroup.POST("", middleware.RequireScope(auth.ScopeAdmin), publishRuleHandlers.CreatePublishRule)
+				publishRulesGroup.PUT("/:id", middleware.RequireScope(auth.ScopeAdmin), publishRuleHandlers.UpdatePublishRule)
+				publishRulesGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeAdmin), publishRuleHandlers.DeletePublishRule)
+			}
+
+			// Admission Webhooks (external publish validation)
+			admissionGroup := authenticatedGroup.Group("/admin/admission-webhooks")
+			admissionGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			{
+				admissionGroup.GET("", admissionWebhookHandlers.ListAdmissionWebhooks)
+				admissionGroup.GET("/:id", admissionWebhookHandlers.GetAdmissionWebhook)
+				admissionGroup.POST("", admissionWebhookHandlers.CreateAdmissionWebhook)
+				admissionGroup.PUT("/:id", admissionWebhookHandlers.UpdateAdmissionWebhook)
+				admissionGroup.DELETE("/:id", admissionWebhookHandlers.DeleteAdmissionWebhook)
+			}
+
+			// License Policies (allowed/denied SPDX licenses for publishing and mirroring)
+			licensePoliciesGroup := authenticatedGroup.Group("/admin/license-policies")
+			{
+				licensePoliciesGroup.GET("", middleware.RequireScope(auth.ScopeModulesRead), licensePolicyHandlers.ListLicensePolicies)
+				licensePoliciesGroup.GET("/:id", middleware.RequireScope(auth.ScopeModulesRead), licensePolicyHandlers.GetLicensePolicy)
+				licensePoliciesGroup.POST("", middleware.RequireScope(auth.ScopeAdmin), licensePolicyHandlers.CreateLicensePolicy)
+				licensePoliciesGroup.PUT("/:id", middleware.RequireScope(auth.ScopeAdmin), licensePolicyHandlers.UpdateLicensePolicy)
+				licensePoliciesGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeAdmin), licensePolicyHandlers.DeleteLicensePolicy)
+			}
+
+			// Trusted signers for module signature and provenance verification
+			trustedSignersGroup := authenticatedGroup.Group("/admin/trusted-signers")
+			{
+				trustedSignersGroup.GET("", middleware.RequireScope(auth.ScopeModulesRead), trustedSignerHandlers.ListTrustedSigners)
+				trustedSignersGroup.GET("/:id", middleware.RequireScope(auth.ScopeModulesRead), trustedSignerHandlers.GetTrustedSigner)
+				trustedSignersGroup.POST("", middleware.RequireScope(auth.ScopeAdmin), trustedSignerHandlers.CreateTrustedSigner)
+				trustedSignersGroup.PUT("/:id", middleware.RequireScope(auth.ScopeAdmin), trustedSignerHandlers.UpdateTrustedSigner)
+				trustedSignersGroup.DELETE("/:id", middleware.RequireScope(auth.ScopeAdmin), trustedSignerHandlers.DeleteTrustedSigner)
+			}
+
+			// Registry signing keys for module manifests
+			signingKeysGroup := authenticatedGroup.Group("/admin/signing-keys")
+			signingKeysGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
+			{
+				signingKeysGroup.GET("", signingKeyHandlers.ListSigningKeys)
+				signingKeysGroup.POST("/rotate", signingKeyHandlers.RotateSigningKey)
+			}
+
 			// Storage Configuration management (requires admin scope)
 			storageGroup := authenticatedGroup.Group("/storage")
 			storageGroup.Use(middleware.RequireScope(auth.ScopeAdmin))
@@ -399,6 +608,37 @@ func NewRouter(cfg *config.Config, db *sql.DB) *gin.Engine {
 				storageGroup.DELETE("/configs/:id", storageHandlers.DeleteStorageConfig)
 				storageGroup.POST("/configs/:id/activate", storageHandlers.ActivateStorageConfig)
 				storageGroup.POST("/configs/test", storageHandlers.TestStorageConfig)
+				storageGroup.GET("/configs/:id/replication", storageHandlers.GetStorageReplicationStatus)
+				storageGroup.POST("/configs/:id/replication/repair", storageHandlers.RepairStorageReplication)
+
+				// Artifact migrations between storage backends
+				storageGroup.GET("/migrations", storageHandlers.ListStorageMigrations)
+				storageGroup.POST("/migrations", storageHandlers.CreateStorageMigration)
+				storageGroup.GET("/migrations/:id", storageHandlers.GetStorageMigration)
+				storageGroup.GET("/migrations/:id/items", storageHandlers.ListStorageMigrationItems)
+				storageGroup.POST("/migrations/:id/pause", storageHandlers.PauseStorageMigration)
+				storageGroup.POST("/migrations/:id/resume", storageHandlers.ResumeStorageMigration)
+				storageGroup.POST("/migrations/:id/cancel", storageHandlers.CancelStorageMigration)
+				storageGroup.POST("/migrations/:id/rollback", storageHandlers.RollbackStorageMigration)
+
+				// Content-addressed storage on the default backend
+				storageGroup.GET("/cas", storageHandlers.GetContentAddressedStorage)
+				storageGroup.POST("/cas/migrate", storageHandlers.MigrateToContentAddressedStorage)
+
+				// Integrity scrubs and orphaned object collection
+				storageGroup.GET("/scrub", storageHandlers.GetStorageScrubStatus)
+				storageGroup.POST("/scrub", storageHandlers.TriggerStorageScrub)
+				storageGroup.GET("/scrub/runs", storageHandlers.ListStorageScrubRuns)
+				storageGroup.GET("/scrub/runs/:id", storageHandlers.GetStorageScrubRun)
+				storageGroup.GET("/orphans", storageHandlers.ListStorageOrphans)
+				storageGroup.DELETE("/orphans/:id", storageHandlers.PurgeStorageOrphan)
+
+				// Usage accounting and per-organization quotas
+				storageGroup.GET("/usage", storageHandlers.ListStorageUsage)
+				storageGroup.GET("/usage/:org_id", storageHandlers.GetOrganizationStorageUsage)
+				storageGroup.GET("/quotas/:org_id", storageHandlers.GetStorageQuota)
+				storageGroup.PUT("/quotas/:org_id", storageHandlers.SetStorageQuota)
+				storageGroup.DELETE("/quotas/:org_id", storageHandlers.DeleteStorageQuota)
 			}
 		}
 
@@ -546,8 +786,13 @@ func CORSMiddleware(cfg *config.Config) gin.HandlerFunc {
 				c.Header("Access-Control-Allow-Origin", origin)
 			}
 			c.Header("Access-Control-Allow-Credentials", "true")
-			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With")
+			c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Requested-With, "+
+				"Tus-Resumable, Upload-Length, Upload-Metadata, Upload-Offset, Upload-Defer-Length, "+
+				"Range, If-Range, If-None-Match, If-Modified-Since")
+			c.Header("Access-Control-Expose-Headers", "Location, Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, "+
+				"Upload-Offset, Upload-Length, Upload-Metadata, Upload-Expires, "+
+				"ETag, Last-Modified, Accept-Ranges, Content-Range, X-Checksum-SHA256")
 			c.Header("Access-Control-Max-Age", "3600")
 		}
 
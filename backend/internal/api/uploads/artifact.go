@@ -0,0 +1,83 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// Artifact is an archive being published, received either as the "file" part of a multipart
+// form or as a completed resumable upload named by the form's "upload_id" field
+type Artifact struct {
+	File     multipart.File
+	Filename string
+	Size     int64
+
+	sessions  *services.UploadSessionService
+	sessionID uuid.UUID
+}
+
+// OpenArtifact opens the archive of a publish request whose multipart form has been parsed.
+// On failure it returns the HTTP status and message to respond with.
+func OpenArtifact(c *gin.Context, sessions *services.UploadSessionService, artifactType models.ArtifactType) (*Artifact, int, error) {
+	uploadID := c.PostForm("upload_id")
+	if uploadID == "" {
+		file, header, err := c.Request.FormFile("file")
+		if err != nil {
+			return nil, http.StatusBadRequest, errors.New("Missing or invalid file upload")
+		}
+		return &Artifact{File: file, Filename: header.Filename, Size: header.Size}, 0, nil
+	}
+
+	if sessions == nil {
+		return nil, http.StatusBadRequest, errors.New("Resumable uploads are not enabled")
+	}
+	id, err := uuid.Parse(uploadID)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("Invalid upload_id")
+	}
+
+	file, session, err := sessions.OpenCompleted(c.Request.Context(), id, uploadOwner(c), artifactType)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUploadSessionNotFound):
+			return nil, http.StatusNotFound, err
+		case errors.Is(err, services.ErrUploadSessionExpired):
+			return nil, http.StatusGone, err
+		case errors.Is(err, services.ErrUploadIncomplete), errors.Is(err, services.ErrUploadArtifactMismatch):
+			return nil, http.StatusConflict, err
+		default:
+			return nil, http.StatusInternalServerError, errors.New("Failed to open resumable upload")
+		}
+	}
+
+	return &Artifact{
+		File:      file,
+		Filename:  session.Filename,
+		Size:      session.UploadLength,
+		sessions:  sessions,
+		sessionID: session.ID,
+	}, 0, nil
+}
+
+// Close closes the archive
+func (a *Artifact) Close() error {
+	return a.File.Close()
+}
+
+// Published discards the resumable upload the archive came from, if any, now that it is stored
+func (a *Artifact) Published(ctx context.Context) {
+	if a.sessions == nil {
+		return
+	}
+	if err := a.sessions.Delete(ctx, a.sessionID); err != nil {
+		fmt.Printf("Warning: Failed to remove upload session %s: %v\n", a.sessionID, err)
+	}
+}
@@ -0,0 +1,302 @@
+package uploads
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/auth"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/middleware"
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+const (
+	// TusVersion is the version of the tus resumable upload protocol implemented here
+	TusVersion = "1.0.0"
+
+	// TusExtensions lists the tus protocol extensions supported
+	TusExtensions = "creation,termination,expiration"
+
+	// offsetContentType is the content type tus requires for PATCH requests
+	offsetContentType = "application/offset+octet-stream"
+)
+
+// Handlers implements resumable uploads of module archives and provider binaries with the tus
+// protocol (https://tus.io/protocols/resumable-upload). A client creates a session declaring
+// the upload length, sends the bytes in one or more PATCH requests, resuming from the offset
+// reported by HEAD after a failure, then publishes the completed upload by passing its ID as
+// the upload_id field of POST /api/v1/modules or POST /api/v1/providers.
+type Handlers struct {
+	sessions *services.UploadSessionService
+	baseURL  string
+}
+
+// NewHandlers creates new resumable upload handlers
+func NewHandlers(sessions *services.UploadSessionService, baseURL string) *Handlers {
+	return &Handlers{
+		sessions: sessions,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// DiscoveryMiddleware answers tus capability discovery. OPTIONS requests are answered by the
+// CORS middleware before reaching any route, so it must be installed ahead of it; it adds the
+// tus headers to every request under prefix and rejects unsupported protocol versions.
+func (h *Handlers) DiscoveryMiddleware(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, prefix) {
+			c.Next()
+			return
+		}
+
+		c.Header("Tus-Resumable", TusVersion)
+		if c.Request.Method == http.MethodOptions {
+			c.Header("Tus-Version", TusVersion)
+			c.Header("Tus-Extension", TusExtensions)
+			if maxSize := h.sessions.MaxSize(); maxSize > 0 {
+				c.Header("Tus-Max-Size", strconv.FormatInt(maxSize, 10))
+			}
+			c.Next()
+			return
+		}
+
+		// Plain HTTP clients may omit the header; tus clients must ask for a version we speak
+		if version := c.GetHeader("Tus-Resumable"); version != "" && version != TusVersion {
+			c.Header("Tus-Version", TusVersion)
+			c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{
+				"error": fmt.Sprintf("Unsupported tus version %q", version),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CreateUpload starts a resumable upload session
+// POST /api/v1/uploads
+// Headers: Upload-Length (required), Upload-Metadata with base64-encoded "type" (module or
+// provider, required) and "filename" (required for providers)
+func (h *Handlers) CreateUpload(c *gin.Context) {
+	owner := uploadOwner(c)
+	if owner == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Defer-Length is not supported"})
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid Upload-Length header"})
+		return
+	}
+
+	rawMetadata := c.GetHeader("Upload-Metadata")
+	metadata, err := parseMetadata(rawMetadata)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid Upload-Metadata header: %v", err)})
+		return
+	}
+
+	artifactType := models.ArtifactType(metadata["type"])
+	var requiredScope auth.Scope
+	switch artifactType {
+	case models.ArtifactTypeModule:
+		requiredScope = auth.ScopeModulesWrite
+	case models.ArtifactTypeProvider:
+		requiredScope = auth.ScopeProvidersWrite
+		if metadata["filename"] == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Metadata must include a filename for provider uploads"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Metadata type must be module or provider"})
+		return
+	}
+	scopes, _ := c.Get("scopes")
+	userScopes, _ := scopes.([]string)
+	if !auth.HasScope(userScopes, requiredScope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Missing required scope"})
+		return
+	}
+
+	session, err := h.sessions.Create(c.Request.Context(), artifactType, metadata["filename"], length, rawMetadata, owner)
+	if err != nil {
+		if errors.Is(err, services.ErrUploadTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload session"})
+		return
+	}
+
+	location := h.uploadURL(session.ID)
+	c.Header("Location", location)
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	c.JSON(http.StatusCreated, gin.H{
+		"upload":     session,
+		"upload_url": location,
+	})
+}
+
+// GetUploadOffset reports how much of an upload has been received
+// HEAD /api/v1/uploads/:id
+func (h *Handlers) GetUploadOffset(c *gin.Context) {
+	session, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Upload-Offset", strconv.FormatInt(session.UploadOffset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(session.UploadLength, 10))
+	c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	if session.UploadMetadata != "" {
+		c.Header("Upload-Metadata", session.UploadMetadata)
+	}
+	c.Status(http.StatusOK)
+}
+
+// GetUpload returns an upload session for clients not speaking tus
+// GET /api/v1/uploads/:id
+func (h *Handlers) GetUpload(c *gin.Context) {
+	session, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upload":    session,
+		"completed": session.Completed(),
+	})
+}
+
+// AppendUpload appends a chunk to an upload at the offset given by the Upload-Offset header
+// PATCH /api/v1/uploads/:id
+func (h *Handlers) AppendUpload(c *gin.Context) {
+	if c.ContentType() != offsetContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": fmt.Sprintf("Content-Type must be %s", offsetContentType)})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing or invalid Upload-Offset header"})
+		return
+	}
+
+	session, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	session, err = h.sessions.Append(c.Request.Context(), session, offset, c.Request.Body)
+	if session != nil {
+		c.Header("Upload-Offset", strconv.FormatInt(session.UploadOffset, 10))
+		c.Header("Upload-Expires", session.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUploadOffsetMismatch):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUploadSessionBusy):
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUploadExceedsLength):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUploadSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case session != nil:
+			// The chunk was cut short; the bytes received are kept and the client resumes
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the complete chunk"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload chunk"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteUpload terminates an upload and discards the bytes received
+// DELETE /api/v1/uploads/:id
+func (h *Handlers) DeleteUpload(c *gin.Context) {
+	session, ok := h.lookupSession(c)
+	if !ok {
+		return
+	}
+
+	if err := h.sessions.Delete(c.Request.Context(), session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete upload session"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lookupSession loads the session named in the path, writing the error response when it cannot
+func (h *Handlers) lookupSession(c *gin.Context) (*models.UploadSession, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrUploadSessionNotFound.Error()})
+		return nil, false
+	}
+
+	session, err := h.sessions.Get(c.Request.Context(), id, uploadOwner(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUploadSessionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUploadSessionExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get upload session"})
+		}
+		return nil, false
+	}
+
+	return session, true
+}
+
+func (h *Handlers) uploadURL(id uuid.UUID) string {
+	return h.baseURL + "/api/v1/uploads/" + id.String()
+}
+
+// uploadOwner identifies who may resume an upload: the user if there is one, otherwise the API key
+func uploadOwner(c *gin.Context) string {
+	return middleware.Principal(c)
+}
+
+// parseMetadata decodes a tus Upload-Metadata header: comma-separated pairs of a key and an
+// optional base64-encoded value
+func parseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		switch len(fields) {
+		case 1:
+			metadata[fields[0]] = ""
+		case 2:
+			value, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("value of %s is not base64 encoded", fields[0])
+			}
+			metadata[fields[0]] = string(value)
+		default:
+			return nil, fmt.Errorf("malformed pair %q", pair)
+		}
+	}
+
+	return metadata, nil
+}
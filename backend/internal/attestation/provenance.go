@@ -0,0 +1,150 @@
+package attestation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// InTotoPayloadType is the DSSE payload type of in-toto statements
+	InTotoPayloadType = "application/vnd.in-toto+json"
+
+	// SLSA provenance predicate types
+	SLSAProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	SLSAProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// Envelope is a DSSE envelope, the format cosign and the SLSA generators use for attestations
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"` // base64-encoded statement
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a single signature over an envelope's pre-authentication encoding
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // base64-encoded
+}
+
+// Statement is an in-toto attestation statement
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Subject is an artifact an in-toto statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ParseEnvelope parses a DSSE envelope. For .intoto.jsonl files holding several
+// attestations, the first envelope with an in-toto payload is used.
+func ParseEnvelope(data []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(bytes.TrimSpace(data), &envelope); err == nil && envelope.Payload != "" {
+		return &envelope, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var candidate Envelope
+		if err := json.Unmarshal(line, &candidate); err != nil {
+			return nil, fmt.Errorf("attestation: invalid envelope: %w", err)
+		}
+		if candidate.PayloadType == InTotoPayloadType {
+			return &candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("attestation: no in-toto envelope found")
+}
+
+// PayloadBytes returns the decoded envelope payload
+func (e *Envelope) PayloadBytes() ([]byte, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		payload, err = base64.URLEncoding.DecodeString(e.Payload)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("attestation: invalid envelope payload encoding: %w", err)
+	}
+	return payload, nil
+}
+
+// SignedMessage returns the DSSE pre-authentication encoding that envelope signatures cover
+func (e *Envelope) SignedMessage() ([]byte, error) {
+	payload, err := e.PayloadBytes()
+	if err != nil {
+		return nil, err
+	}
+	return PAE(e.PayloadType, payload), nil
+}
+
+// PAE computes the DSSE v1 pre-authentication encoding
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Statement decodes the in-toto statement carried by the envelope
+func (e *Envelope) Statement() (*Statement, error) {
+	if e.PayloadType != InTotoPayloadType {
+		return nil, fmt.Errorf("attestation: unexpected payload type %q", e.PayloadType)
+	}
+	payload, err := e.PayloadBytes()
+	if err != nil {
+		return nil, err
+	}
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("attestation: invalid in-toto statement: %w", err)
+	}
+	return &statement, nil
+}
+
+// HasSubjectDigest reports whether the statement is about an artifact with the given SHA-256 digest
+func (s *Statement) HasSubjectDigest(sha256Hex string) bool {
+	for _, subject := range s.Subject {
+		if strings.EqualFold(subject.Digest["sha256"], sha256Hex) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSLSAProvenance reports whether the statement carries a SLSA provenance predicate
+func (s *Statement) IsSLSAProvenance() bool {
+	return s.PredicateType == SLSAProvenanceV02 || s.PredicateType == SLSAProvenanceV1
+}
+
+// BuilderID returns the builder identity recorded in a SLSA provenance predicate
+func (s *Statement) BuilderID() string {
+	var predicate struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"` // v0.2
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"runDetails"` // v1
+	}
+	if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+		return ""
+	}
+	if predicate.RunDetails.Builder.ID != "" {
+		return predicate.RunDetails.Builder.ID
+	}
+	return predicate.Builder.ID
+}
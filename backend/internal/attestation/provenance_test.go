@@ -0,0 +1,134 @@
+package attestation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+// signedEnvelope wraps a SLSA provenance statement about blob in a DSSE envelope signed by key
+func signedEnvelope(t *testing.T, key *ecdsa.PrivateKey, blob []byte, predicateType, predicate string) []byte {
+	t.Helper()
+	digest := sha256.Sum256(blob)
+	statement, err := json.Marshal(Statement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       []Subject{{Name: "module.tar.gz", Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])}}},
+		PredicateType: predicateType,
+		Predicate:     json.RawMessage(predicate),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(Envelope{
+		PayloadType: InTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures: []EnvelopeSignature{{
+			Sig: base64.StdEncoding.EncodeToString(sign(t, key, PAE(InTotoPayloadType, statement))),
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestSignAndVerifyProvenance(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob := []byte("module archive contents")
+	digest := sha256.Sum256(blob)
+
+	tests := []struct {
+		name          string
+		predicateType string
+		predicate     string
+		builder       string
+	}{
+		{"slsa v0.2", SLSAProvenanceV02, `{"builder":{"id":"https://github.com/slsa-framework/slsa-github-generator"}}`, "https://github.com/slsa-framework/slsa-github-generator"},
+		{"slsa v1", SLSAProvenanceV1, `{"runDetails":{"builder":{"id":"https://ci.example.com/builder"}}}`, "https://ci.example.com/builder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := ParseEnvelope(signedEnvelope(t, key, blob, tt.predicateType, tt.predicate))
+			if err != nil {
+				t.Fatalf("ParseEnvelope: %v", err)
+			}
+			statement, err := envelope.Statement()
+			if err != nil {
+				t.Fatalf("Statement: %v", err)
+			}
+			if !statement.IsSLSAProvenance() {
+				t.Error("IsSLSAProvenance() = false")
+			}
+			if got := statement.BuilderID(); got != tt.builder {
+				t.Errorf("BuilderID() = %q, want %q", got, tt.builder)
+			}
+			if !statement.HasSubjectDigest(hex.EncodeToString(digest[:])) {
+				t.Error("HasSubjectDigest() = false for the signed archive")
+			}
+
+			message, err := envelope.SignedMessage()
+			if err != nil {
+				t.Fatalf("SignedMessage: %v", err)
+			}
+			signature, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Sig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifySignature(key.Public(), message, signature); err != nil {
+				t.Errorf("VerifySignature over PAE: %v", err)
+			}
+
+			// Changing the payload invalidates the signature
+			envelope.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type":"forged"}`))
+			forged, err := envelope.SignedMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifySignature(key.Public(), forged, signature); err == nil {
+				t.Error("VerifySignature accepted a forged payload")
+			}
+		})
+	}
+}
+
+func TestParseEnvelopeJSONLines(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	provenance := signedEnvelope(t, key, []byte("archive"), SLSAProvenanceV1, `{}`)
+	other := `{"payloadType":"application/vnd.example+json","payload":"e30=","signatures":[]}`
+	data := []byte(other + "\n\n" + string(provenance) + "\n")
+
+	envelope, err := ParseEnvelope(data)
+	if err != nil {
+		t.Fatalf("ParseEnvelope: %v", err)
+	}
+	if envelope.PayloadType != InTotoPayloadType {
+		t.Errorf("ParseEnvelope picked payload type %q", envelope.PayloadType)
+	}
+
+	envelope, err = ParseEnvelope([]byte(other + "\n"))
+	if err != nil {
+		t.Fatalf("ParseEnvelope: %v", err)
+	}
+	if _, err := envelope.Statement(); err == nil {
+		t.Error("Statement() accepted a payload that is not an in-toto statement")
+	}
+}
+
+func TestPAE(t *testing.T) {
+	got := string(PAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("PAE() = %q, want %q", got, want)
+	}
+}
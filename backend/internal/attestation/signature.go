@@ -0,0 +1,212 @@
+// Package attestation verifies detached cosign signatures and in-toto/SLSA provenance
+// attestations for published artifacts. Verification is offline: signatures are checked
+// against trusted public keys or against certificates issued by trusted roots, without
+// consulting a transparency log.
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidSignature   = errors.New("attestation: signature does not match")
+	ErrUnsupportedKeyType = errors.New("attestation: unsupported public key type")
+)
+
+// Object identifiers of the Sigstore Fulcio certificate extensions carrying the OIDC issuer
+var (
+	oidFulcioIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1} // Raw string value (deprecated)
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8} // DER-encoded UTF8String
+)
+
+// ParsePublicKey parses a PEM-encoded PKIX public key, as written by `cosign generate-key-pair`
+func ParsePublicKey(pemData string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(strings.TrimSpace(pemData)))
+	if block == nil {
+		return nil, fmt.Errorf("attestation: no PEM block found in public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("attestation: failed to parse public key: %w", err)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+}
+
+// DecodeSignature accepts a signature as produced by `cosign sign-blob` (base64 text)
+// or as raw bytes
+func DecodeSignature(raw []byte) []byte {
+	trimmed := strings.TrimSpace(string(raw))
+	if decoded, err := base64.StdEncoding.DecodeString(trimmed); err == nil && len(decoded) > 0 {
+		return decoded
+	}
+	return raw
+}
+
+// VerifySignature checks a signature over message. ECDSA and RSA signatures are over the
+// SHA-256 digest of the message; Ed25519 signatures are over the message itself.
+func VerifySignature(key crypto.PublicKey, message, signature []byte) error {
+	digest := sha256.Sum256(message)
+	return VerifyDigestSignature(key, digest[:], func() ([]byte, error) { return message, nil }, signature)
+}
+
+// VerifyDigestSignature checks a signature over a message given the message's SHA-256 digest,
+// so large messages need not be held in memory. Ed25519 signs the message itself, so
+// readMessage is only called to load the message for Ed25519 keys.
+func VerifyDigestSignature(key crypto.PublicKey, digest []byte, readMessage func() ([]byte, error), signature []byte) error {
+	switch pub := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signature) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature); err != nil {
+			if rsa.VerifyPSS(pub, crypto.SHA256, digest, signature, nil) != nil {
+				return ErrInvalidSignature
+			}
+		}
+	case ed25519.PublicKey:
+		message, err := readMessage()
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(pub, message, signature) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedKeyType
+	}
+	return nil
+}
+
+// ParseCertificateChain parses a PEM bundle whose first certificate is the signing
+// (leaf) certificate and whose remaining certificates are intermediates
+func ParseCertificateChain(pemData []byte) (*x509.Certificate, []*x509.Certificate, error) {
+	certs, err := parseCertificates(pemData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("attestation: no certificate found")
+	}
+	return certs[0], certs[1:], nil
+}
+
+// CertificatePolicy describes which certificates are trusted to sign: those chaining to
+// one of the roots, whose subject alternative name matches the identity pattern and,
+// when set, whose OIDC issuer extension equals the issuer.
+type CertificatePolicy struct {
+	Roots           string // PEM bundle of trusted root (and optional intermediate) certificates
+	IdentityPattern string // Regular expression matched against email and URI SANs
+	Issuer          string // Expected OIDC issuer, e.g. https://token.actions.githubusercontent.com
+}
+
+// VerifyCertificate checks a signing certificate against the policy and returns the
+// identity that matched. Short-lived certificates are validated at their issuance time,
+// since no transparency log timestamp is available offline.
+func VerifyCertificate(leaf *x509.Certificate, intermediates []*x509.Certificate, policy CertificatePolicy) (string, error) {
+	roots, err := parseCertificates([]byte(policy.Roots))
+	if err != nil {
+		return "", err
+	}
+	if len(roots) == 0 {
+		return "", fmt.Errorf("attestation: no trusted root certificates configured")
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, root := range roots {
+		rootPool.AddCert(root)
+	}
+	intermediatePool := x509.NewCertPool()
+	for _, intermediate := range intermediates {
+		intermediatePool.AddCert(intermediate)
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: intermediatePool,
+		CurrentTime:   leaf.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return "", fmt.Errorf("attestation: certificate is not trusted: %w", err)
+	}
+
+	if policy.Issuer != "" {
+		if issuer := CertificateIssuer(leaf); issuer != policy.Issuer {
+			return "", fmt.Errorf("attestation: certificate issuer %q does not match %q", issuer, policy.Issuer)
+		}
+	}
+
+	pattern, err := regexp.Compile("^(?:" + policy.IdentityPattern + ")$")
+	if err != nil {
+		return "", fmt.Errorf("attestation: invalid identity pattern: %w", err)
+	}
+	for _, identity := range CertificateIdentities(leaf) {
+		if pattern.MatchString(identity) {
+			return identity, nil
+		}
+	}
+	return "", fmt.Errorf("attestation: certificate identity %v does not match %q", CertificateIdentities(leaf), policy.IdentityPattern)
+}
+
+// CertificateIdentities returns the email and URI subject alternative names of a certificate
+func CertificateIdentities(cert *x509.Certificate) []string {
+	identities := append([]string{}, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return identities
+}
+
+// CertificateIssuer returns the OIDC issuer recorded in a Fulcio-issued certificate
+func CertificateIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidFulcioIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+func parseCertificates(pemData []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := []byte(strings.TrimSpace(string(pemData)))
+	for len(rest) > 0 {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("attestation: failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
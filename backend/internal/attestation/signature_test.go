@@ -0,0 +1,206 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// publicKeyPEM encodes a public key the way `cosign generate-key-pair` writes it
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// sign signs message as cosign does: ECDSA and RSA over its SHA-256 digest, Ed25519 over the message
+func sign(t *testing.T, key crypto.Signer, message []byte) []byte {
+	t.Helper()
+	if _, ok := key.(ed25519.PrivateKey); ok {
+		signature, err := key.Sign(rand.Reader, message, crypto.Hash(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+	digest := sha256.Sum256(message)
+	signature, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signature
+}
+
+func testKeys(t *testing.T) map[string]crypto.Signer {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]crypto.Signer{"ecdsa": ecKey, "rsa": rsaKey, "ed25519": edKey}
+}
+
+func TestSignAndVerifyBlob(t *testing.T) {
+	blob := []byte("module archive contents")
+	for name, key := range testKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			pub, err := ParsePublicKey(publicKeyPEM(t, key.Public()))
+			if err != nil {
+				t.Fatalf("ParsePublicKey: %v", err)
+			}
+
+			// cosign sign-blob writes the signature base64-encoded
+			encoded := []byte(base64.StdEncoding.EncodeToString(sign(t, key, blob)) + "\n")
+			signature := DecodeSignature(encoded)
+			if err := VerifySignature(pub, blob, signature); err != nil {
+				t.Fatalf("VerifySignature: %v", err)
+			}
+
+			digest := sha256.Sum256(blob)
+			readMessage := func() ([]byte, error) { return blob, nil }
+			if err := VerifyDigestSignature(pub, digest[:], readMessage, signature); err != nil {
+				t.Fatalf("VerifyDigestSignature: %v", err)
+			}
+
+			if err := VerifySignature(pub, []byte("tampered archive"), signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySignature(tampered) = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureWrongKey(t *testing.T) {
+	keys := testKeys(t)
+	blob := []byte("module archive contents")
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySignature(other.Public(), blob, sign(t, keys["ecdsa"], blob)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifySignature(other key) = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestParsePublicKeyRejectsGarbage(t *testing.T) {
+	if _, err := ParsePublicKey("not a key"); err == nil {
+		t.Error("ParsePublicKey accepted input without a PEM block")
+	}
+}
+
+// keylessChain issues a Fulcio-style code signing certificate for identity from a fresh root
+func keylessChain(t *testing.T, identity, issuer string) (rootPEM, leafPEM []byte, leafKey *ecdsa.PrivateKey) {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, root, root, rootKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err = x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuerValue, err := asn1.Marshal(issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       time.Now().Add(-time.Minute),
+		NotAfter:        time.Now().Add(10 * time.Minute),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses:  []string{identity},
+		ExtraExtensions: []pkix.Extension{{Id: oidFulcioIssuerV2, Value: issuerValue}},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, root, leafKey.Public(), rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER})
+	leafPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	return rootPEM, leafPEM, leafKey
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	const issuer = "https://token.actions.githubusercontent.com"
+	rootPEM, leafPEM, leafKey := keylessChain(t, "release@example.com", issuer)
+	otherRootPEM, _, _ := keylessChain(t, "release@example.com", issuer)
+
+	leaf, intermediates, err := ParseCertificateChain(leafPEM)
+	if err != nil {
+		t.Fatalf("ParseCertificateChain: %v", err)
+	}
+	if got := CertificateIssuer(leaf); got != issuer {
+		t.Errorf("CertificateIssuer() = %q, want %q", got, issuer)
+	}
+
+	tests := []struct {
+		name    string
+		policy  CertificatePolicy
+		wantErr bool
+	}{
+		{"matching identity and issuer", CertificatePolicy{Roots: string(rootPEM), IdentityPattern: `.*@example\.com`, Issuer: issuer}, false},
+		{"identity must match in full", CertificatePolicy{Roots: string(rootPEM), IdentityPattern: `release`}, true},
+		{"wrong issuer", CertificatePolicy{Roots: string(rootPEM), IdentityPattern: `.*`, Issuer: "https://accounts.google.com"}, true},
+		{"untrusted root", CertificatePolicy{Roots: string(otherRootPEM), IdentityPattern: `.*`}, true},
+		{"no roots", CertificatePolicy{IdentityPattern: `.*`}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := VerifyCertificate(leaf, intermediates, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && identity != "release@example.com" {
+				t.Errorf("VerifyCertificate() identity = %q", identity)
+			}
+		})
+	}
+
+	blob := []byte("module archive contents")
+	if err := VerifySignature(leaf.PublicKey, blob, sign(t, leafKey, blob)); err != nil {
+		t.Errorf("VerifySignature with certificate key: %v", err)
+	}
+}
@@ -20,6 +20,7 @@ type Config struct {
 	Logging       LoggingConfig       `mapstructure:"logging"`
 	Telemetry     TelemetryConfig     `mapstructure:"telemetry"`
 	Audit         AuditConfig         `mapstructure:"audit"`
+	Mirror        MirrorConfig        `mapstructure:"mirror"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -45,10 +46,19 @@ type DatabaseConfig struct {
 // StorageConfig holds storage backend configuration
 type StorageConfig struct {
 	DefaultBackend string              `mapstructure:"default_backend"`
+	// ContentAddressable stores artifacts on the default backend once per SHA256 digest
+	ContentAddressable bool            `mapstructure:"content_addressable"`
 	Azure          AzureStorageConfig  `mapstructure:"azure"`
 	S3             S3StorageConfig     `mapstructure:"s3"`
 	GCS            GCSStorageConfig    `mapstructure:"gcs"`
 	Local          LocalStorageConfig  `mapstructure:"local"`
+	OCI            OCIStorageConfig    `mapstructure:"oci"`
+	Uploads        UploadSessionConfig `mapstructure:"uploads"`
+	Scrub          StorageScrubConfig  `mapstructure:"scrub"`
+	Cache          StorageCacheConfig  `mapstructure:"cache"`
+	FileURLs       FileURLConfig       `mapstructure:"file_urls"`
+	Encryption     StorageEncryptionConfig `mapstructure:"encryption"`
+	Quotas         StorageQuotaConfig  `mapstructure:"quotas"`
 }
 
 // AzureStorageConfig holds Azure Blob Storage configuration
@@ -121,6 +131,79 @@ type LocalStorageConfig struct {
 	ServeDirectly bool   `mapstructure:"serve_directly"`
 }
 
+// OCIStorageConfig holds configuration for storing files as artifacts in an OCI registry
+type OCIStorageConfig struct {
+	// Registry is the registry host, e.g. "harbor.example.com", or its URL
+	Registry string `mapstructure:"registry"`
+	// Repository prefixes the repositories files are pushed to, e.g. "terraform-registry"
+	Repository string `mapstructure:"repository"`
+	// Credentials for basic or token authentication; anonymous access when empty
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Insecure talks to the registry over plain HTTP
+	Insecure bool `mapstructure:"insecure"`
+}
+
+// UploadSessionConfig holds configuration for resumable (tus) upload sessions
+type UploadSessionConfig struct {
+	// TempDir holds partial uploads; defaults to a directory under the system temp dir
+	TempDir string `mapstructure:"temp_dir"`
+	// SessionTTL is how long a session may sit idle before it expires and is removed
+	SessionTTL time.Duration `mapstructure:"session_ttl"`
+	// MaxSize caps the declared length of a single upload, in bytes
+	MaxSize int64 `mapstructure:"max_size"`
+}
+
+// StorageScrubConfig holds configuration for the storage integrity scrubber and orphan collector
+type StorageScrubConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval between scheduled scrubs
+	Interval time.Duration `mapstructure:"interval"`
+	// SampleSize is how many artifacts each scrub downloads and re-hashes
+	SampleSize int `mapstructure:"sample_size"`
+	// OrphanGracePeriod is how long an unreferenced object is left alone before OrphanAction applies
+	OrphanGracePeriod time.Duration `mapstructure:"orphan_grace_period"`
+	// OrphanAction is "report", "quarantine" (move under quarantine/) or "delete"
+	OrphanAction string `mapstructure:"orphan_action"`
+}
+
+// StorageCacheConfig holds configuration for the local disk cache in front of cloud backends
+type StorageCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Dir holds cached files; defaults to a directory under the system temp dir
+	Dir string `mapstructure:"dir"`
+	// MaxSize caps the total size of cached files, in bytes
+	MaxSize int64 `mapstructure:"max_size"`
+	// ProxyDownloads serves downloads through the registry instead of redirecting to the backend
+	ProxyDownloads bool `mapstructure:"proxy_downloads"`
+}
+
+// FileURLConfig holds configuration for the signed URLs of files served by the registry
+type FileURLConfig struct {
+	// Secret keys URL signatures; defaults to a key derived from TFR_JWT_SECRET
+	Secret string `mapstructure:"secret"`
+	// BindPrincipal only accepts a URL from the authenticated caller it was issued to
+	BindPrincipal bool `mapstructure:"bind_principal"`
+}
+
+// StorageEncryptionConfig holds configuration for envelope encryption of stored files
+type StorageEncryptionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// KeyProvider supplies the master keys wrapping data keys; "local" reads them from key files
+	KeyProvider string `mapstructure:"key_provider"`
+	// KeyFile holds the current master key, base64-encoded
+	KeyFile string `mapstructure:"key_file"`
+	// RetiredKeyFiles hold previous master keys, needed until their data keys are re-wrapped
+	RetiredKeyFiles []string `mapstructure:"retired_key_files"`
+}
+
+// StorageQuotaConfig holds configuration for per-organization storage quotas
+type StorageQuotaConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// DefaultMaxBytes caps the bytes stored by organizations without a quota of their own; 0 is unlimited
+	DefaultMaxBytes int64 `mapstructure:"default_max_bytes"`
+}
+
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
 	APIKeys  APIKeyConfig  `mapstructure:"api_keys"`
@@ -162,9 +245,10 @@ type MultiTenancyConfig struct {
 
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
-	CORS         CORSConfig         `mapstructure:"cors"`
-	RateLimiting RateLimitingConfig `mapstructure:"rate_limiting"`
-	TLS          TLSConfig          `mapstructure:"tls"`
+	CORS           CORSConfig           `mapstructure:"cors"`
+	RateLimiting   RateLimitingConfig   `mapstructure:"rate_limiting"`
+	TLS            TLSConfig            `mapstructure:"tls"`
+	SecretScanning SecretScanningConfig `mapstructure:"secret_scanning"`
 }
 
 // CORSConfig holds CORS configuration
@@ -187,6 +271,14 @@ type TLSConfig struct {
 	KeyFile  string `mapstructure:"key_file"`
 }
 
+// SecretScanningConfig holds configuration for scanning module archives for secrets
+type SecretScanningConfig struct {
+	Enabled          bool    `mapstructure:"enabled"`
+	Mode             string  `mapstructure:"mode"` // "block" rejects the upload, "warn" records findings only
+	EntropyThreshold float64 `mapstructure:"entropy_threshold"`
+	MaxFileSize      int64   `mapstructure:"max_file_size"`
+}
+
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level  string `mapstructure:"level"`
@@ -221,6 +313,14 @@ type ProfilingConfig struct {
 	Port    int  `mapstructure:"port"`
 }
 
+// MirrorConfig holds provider mirror sync configuration
+type MirrorConfig struct {
+	// PerHostConcurrency caps the requests in flight to each upstream host across all mirror syncs
+	PerHostConcurrency int `mapstructure:"per_host_concurrency"`
+	// MaxRetryWait caps how long a sync waits when an upstream host asks it to back off
+	MaxRetryWait time.Duration `mapstructure:"max_retry_wait"`
+}
+
 // AuditConfig holds audit logging configuration
 type AuditConfig struct {
 	// Enabled determines if audit logging is active
@@ -314,6 +414,31 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("storage.gcs.endpoint")
 	v.BindEnv("storage.local.base_path")
 	v.BindEnv("storage.local.serve_directly")
+	v.BindEnv("storage.oci.registry")
+	v.BindEnv("storage.oci.repository")
+	v.BindEnv("storage.oci.username")
+	v.BindEnv("storage.oci.password")
+	v.BindEnv("storage.oci.insecure")
+	v.BindEnv("storage.uploads.temp_dir")
+	v.BindEnv("storage.uploads.session_ttl")
+	v.BindEnv("storage.uploads.max_size")
+	v.BindEnv("storage.scrub.enabled")
+	v.BindEnv("storage.scrub.interval")
+	v.BindEnv("storage.scrub.sample_size")
+	v.BindEnv("storage.scrub.orphan_grace_period")
+	v.BindEnv("storage.scrub.orphan_action")
+	v.BindEnv("storage.cache.enabled")
+	v.BindEnv("storage.cache.dir")
+	v.BindEnv("storage.cache.max_size")
+	v.BindEnv("storage.cache.proxy_downloads")
+	v.BindEnv("storage.file_urls.secret")
+	v.BindEnv("storage.file_urls.bind_principal")
+	v.BindEnv("storage.encryption.enabled")
+	v.BindEnv("storage.encryption.key_provider")
+	v.BindEnv("storage.encryption.key_file")
+	v.BindEnv("storage.encryption.retired_key_files") // Comma-separated, e.g. /keys/2024.key,/keys/2025.key
+	v.BindEnv("storage.quotas.enabled")
+	v.BindEnv("storage.quotas.default_max_bytes")
 
 	// Auth
 	v.BindEnv("auth.api_keys.enabled")
@@ -344,6 +469,10 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("security.tls.enabled")
 	v.BindEnv("security.tls.cert_file")
 	v.BindEnv("security.tls.key_file")
+	v.BindEnv("security.secret_scanning.enabled")
+	v.BindEnv("security.secret_scanning.mode")
+	v.BindEnv("security.secret_scanning.entropy_threshold")
+	v.BindEnv("security.secret_scanning.max_file_size")
 
 	// Logging
 	v.BindEnv("logging.level")
@@ -359,6 +488,10 @@ func bindEnvVars(v *viper.Viper) {
 	v.BindEnv("telemetry.tracing.jaeger_endpoint")
 	v.BindEnv("telemetry.profiling.enabled")
 	v.BindEnv("telemetry.profiling.port")
+
+	// Mirror
+	v.BindEnv("mirror.per_host_concurrency")
+	v.BindEnv("mirror.max_retry_wait")
 }
 
 // Load loads configuration from file and environment variables
@@ -408,9 +541,15 @@ func Load(configPath string) (*Config, error) {
 	cfg.Storage.Azure.AccountKey = expandEnv(cfg.Storage.Azure.AccountKey)
 	cfg.Storage.S3.AccessKeyID = expandEnv(cfg.Storage.S3.AccessKeyID)
 	cfg.Storage.S3.SecretAccessKey = expandEnv(cfg.Storage.S3.SecretAccessKey)
+	cfg.Storage.FileURLs.Secret = expandEnv(cfg.Storage.FileURLs.Secret)
 	cfg.Auth.OIDC.ClientSecret = expandEnv(cfg.Auth.OIDC.ClientSecret)
 	cfg.Auth.AzureAD.ClientSecret = expandEnv(cfg.Auth.AzureAD.ClientSecret)
 
+	// Lists set through environment variables are comma-separated, possibly with spaces
+	for i, file := range cfg.Storage.Encryption.RetiredKeyFiles {
+		cfg.Storage.Encryption.RetiredKeyFiles[i] = strings.TrimSpace(file)
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -438,8 +577,30 @@ func setDefaults(v *viper.Viper) {
 
 	// Storage defaults
 	v.SetDefault("storage.default_backend", "local")
+	v.SetDefault("storage.content_addressable", false)
 	v.SetDefault("storage.local.base_path", "./storage")
 	v.SetDefault("storage.local.serve_directly", true)
+	v.SetDefault("storage.oci.repository", "terraform-registry")
+	v.SetDefault("storage.oci.insecure", false)
+	v.SetDefault("storage.uploads.temp_dir", "")
+	v.SetDefault("storage.uploads.session_ttl", "24h")
+	v.SetDefault("storage.uploads.max_size", 500<<20)
+	v.SetDefault("storage.scrub.enabled", true)
+	v.SetDefault("storage.scrub.interval", "24h")
+	v.SetDefault("storage.scrub.sample_size", 25)
+	v.SetDefault("storage.scrub.orphan_grace_period", "168h")
+	v.SetDefault("storage.scrub.orphan_action", "quarantine")
+	v.SetDefault("storage.cache.enabled", false)
+	v.SetDefault("storage.cache.dir", "")
+	v.SetDefault("storage.cache.max_size", int64(10<<30))
+	v.SetDefault("storage.cache.proxy_downloads", true)
+	v.SetDefault("storage.file_urls.secret", "")
+	v.SetDefault("storage.file_urls.bind_principal", false)
+	v.SetDefault("storage.encryption.enabled", false)
+	v.SetDefault("storage.encryption.key_provider", "local")
+	v.SetDefault("storage.encryption.key_file", "")
+	v.SetDefault("storage.quotas.enabled", false)
+	v.SetDefault("storage.quotas.default_max_bytes", int64(0))
 
 	// Auth defaults
 	v.SetDefault("auth.api_keys.enabled", true)
@@ -460,6 +621,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("security.rate_limiting.requests_per_minute", 60)
 	v.SetDefault("security.rate_limiting.burst", 10)
 	v.SetDefault("security.tls.enabled", false)
+	v.SetDefault("security.secret_scanning.enabled", true)
+	v.SetDefault("security.secret_scanning.mode", "warn")
+	v.SetDefault("security.secret_scanning.entropy_threshold", 4.5)
+	v.SetDefault("security.secret_scanning.max_file_size", 1048576)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -474,6 +639,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("telemetry.tracing.enabled", false)
 	v.SetDefault("telemetry.profiling.enabled", false)
 	v.SetDefault("telemetry.profiling.port", 6060)
+
+	// Mirror defaults
+	v.SetDefault("mirror.per_host_concurrency", 4)
+	v.SetDefault("mirror.max_retry_wait", "5m")
 }
 
 // expandEnv expands environment variables in the format ${VAR_NAME}
@@ -503,9 +672,9 @@ func (c *Config) Validate() error {
 	}
 
 	// Validate storage backend
-	validBackends := map[string]bool{"azure": true, "s3": true, "gcs": true, "local": true}
+	validBackends := map[string]bool{"azure": true, "s3": true, "gcs": true, "local": true, "oci": true}
 	if !validBackends[c.Storage.DefaultBackend] {
-		return fmt.Errorf("invalid storage backend: %s (must be azure, s3, gcs, or local)", c.Storage.DefaultBackend)
+		return fmt.Errorf("invalid storage backend: %s (must be azure, s3, gcs, local, or oci)", c.Storage.DefaultBackend)
 	}
 
 	// Validate Azure storage if enabled
@@ -545,6 +714,31 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate OCI storage if enabled
+	if c.Storage.DefaultBackend == "oci" {
+		if c.Storage.OCI.Registry == "" {
+			return fmt.Errorf("storage.oci.registry is required when using OCI backend")
+		}
+		if c.Storage.OCI.Repository == "" {
+			return fmt.Errorf("storage.oci.repository is required when using OCI backend")
+		}
+	}
+
+	// Validate storage encryption if enabled
+	if c.Storage.Encryption.Enabled {
+		if c.Storage.Encryption.KeyProvider != "local" {
+			return fmt.Errorf("invalid storage.encryption.key_provider: %s (must be local)", c.Storage.Encryption.KeyProvider)
+		}
+		if c.Storage.Encryption.KeyFile == "" {
+			return fmt.Errorf("storage.encryption.key_file is required when storage encryption is enabled")
+		}
+	}
+
+	// Validate storage quotas
+	if c.Storage.Quotas.DefaultMaxBytes < 0 {
+		return fmt.Errorf("storage.quotas.default_max_bytes must not be negative")
+	}
+
 	// Validate OIDC if enabled
 	if c.Auth.OIDC.Enabled {
 		if c.Auth.OIDC.IssuerURL == "" {
@@ -581,6 +775,18 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate secret scanning mode
+	if c.Security.SecretScanning.Enabled {
+		if c.Security.SecretScanning.Mode != "block" && c.Security.SecretScanning.Mode != "warn" {
+			return fmt.Errorf("invalid security.secret_scanning.mode: %s (must be block or warn)", c.Security.SecretScanning.Mode)
+		}
+	}
+
+	// Validate mirror sync limits
+	if c.Mirror.PerHostConcurrency < 1 {
+		return fmt.Errorf("mirror.per_host_concurrency must be at least 1")
+	}
+
 	// Validate logging level
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLevels[c.Logging.Level] {
@@ -0,0 +1,138 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrUnknownMasterKey is returned when unwrapping a data key wrapped by a master key the key ring does not hold
+var ErrUnknownMasterKey = errors.New("crypto: data key is wrapped by an unknown master key")
+
+// KeyRing holds the master keys that wrap the data keys of encrypted files. New data keys are
+// wrapped by the current key; retired keys are kept to unwrap data keys wrapped before a rotation
+// until they have been re-wrapped.
+type KeyRing struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// NewKeyRing creates a key ring from 32-byte master keys, the first of which is current
+func NewKeyRing(current []byte, retired ...[]byte) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string][]byte)}
+	for i, key := range append([][]byte{current}, retired...) {
+		if len(key) != 32 {
+			return nil, ErrKeyLengthInvalid
+		}
+		id := MasterKeyID(key)
+		if i == 0 {
+			ring.currentID = id
+		}
+		ring.keys[id] = append([]byte(nil), key...)
+	}
+	return ring, nil
+}
+
+// LoadKeyRing creates a key ring from key files, each holding a base64-encoded 32-byte key.
+// currentFile holds the current key and retiredFiles the keys it replaced.
+func LoadKeyRing(currentFile string, retiredFiles []string) (*KeyRing, error) {
+	current, err := readKeyFile(currentFile)
+	if err != nil {
+		return nil, err
+	}
+
+	retired := make([][]byte, 0, len(retiredFiles))
+	for _, file := range retiredFiles {
+		key, err := readKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		retired = append(retired, key)
+	}
+
+	return NewKeyRing(current, retired...)
+}
+
+// MasterKeyID identifies a master key without revealing it
+func MasterKeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// KeyID returns the ID of the current master key
+func (k *KeyRing) KeyID() string {
+	return k.currentID
+}
+
+// Wrap encrypts a data key with the current master key
+func (k *KeyRing) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(k.keys[k.currentID])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, dataKey, []byte(k.currentID)), nil
+}
+
+// Unwrap decrypts a data key wrapped by the master key identified by keyID
+func (k *KeyRing) Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	masterKey, ok := k.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownMasterKey
+	}
+
+	aead, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrCiphertextCorrupted
+	}
+
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, ciphertext, []byte(keyID))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	blockCipher, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(blockCipher)
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("key file %s is not base64 encoded", path)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key file %s: %w", path, ErrKeyLengthInvalid)
+	}
+
+	return key, nil
+}
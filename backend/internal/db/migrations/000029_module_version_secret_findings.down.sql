@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_module_version_secret_findings_version;
+DROP TABLE IF EXISTS module_version_secret_findings;
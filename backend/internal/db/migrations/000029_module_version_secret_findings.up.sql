@@ -0,0 +1,16 @@
+-- Migration 029: Module Version Secret Findings
+-- Records potential secrets detected by the upload scanner so admins can review them.
+
+CREATE TABLE IF NOT EXISTS module_version_secret_findings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    module_version_id UUID NOT NULL REFERENCES module_versions(id) ON DELETE CASCADE,
+    rule VARCHAR(100) NOT NULL,
+    file_path TEXT NOT NULL,
+    line_number INTEGER,
+    description TEXT NOT NULL,
+    redacted_match TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_version_secret_findings_version
+    ON module_version_secret_findings(module_version_id);
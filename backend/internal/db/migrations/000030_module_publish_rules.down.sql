@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS module_version_policy_results;
+DROP TABLE IF EXISTS module_publish_rules;
@@ -0,0 +1,44 @@
+-- Migration 030: Module Publish Rules
+-- Organization-scoped quality gates evaluated against module archives on publish,
+-- and the per-version results of evaluating them.
+
+CREATE TABLE IF NOT EXISTS module_publish_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE, -- NULL means global
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    rule_type VARCHAR(50) NOT NULL CHECK (rule_type IN (
+        'readme_required', 'variable_descriptions', 'variable_types',
+        'provider_version_constraints', 'license_required',
+        'no_provider_credentials', 'version_pattern'
+    )),
+    enforcement VARCHAR(10) NOT NULL DEFAULT 'warn' CHECK (enforcement IN ('block', 'warn')),
+    parameter TEXT,                 -- Rule-specific setting, e.g. the version regex
+    namespace_pattern VARCHAR(255), -- Supports wildcards; NULL means all namespaces
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_publish_rules_org ON module_publish_rules(organization_id);
+CREATE INDEX IF NOT EXISTS idx_module_publish_rules_active ON module_publish_rules(is_active) WHERE is_active = true;
+
+CREATE TABLE IF NOT EXISTS module_version_policy_results (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    module_version_id UUID NOT NULL REFERENCES module_versions(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES module_publish_rules(id) ON DELETE SET NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(50) NOT NULL,
+    enforcement VARCHAR(10) NOT NULL,
+    passed BOOLEAN NOT NULL,
+    message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_version_policy_results_version
+    ON module_version_policy_results(module_version_id);
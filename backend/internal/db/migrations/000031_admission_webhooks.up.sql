@@ -0,0 +1,24 @@
+-- Migration 031: Admission Webhooks
+-- External endpoints consulted before a module or provider version is committed.
+-- Each webhook can allow or deny the publish.
+
+CREATE TABLE IF NOT EXISTS admission_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE, -- NULL means global
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    url VARCHAR(2048) NOT NULL,
+    artifact_type VARCHAR(20) NOT NULL CHECK (artifact_type IN ('module', 'provider')),
+    timeout_seconds INTEGER NOT NULL DEFAULT 10 CHECK (timeout_seconds BETWEEN 1 AND 60),
+    failure_policy VARCHAR(20) NOT NULL DEFAULT 'fail_closed' CHECK (failure_policy IN ('fail_open', 'fail_closed')),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_admission_webhooks_org_type ON admission_webhooks(organization_id, artifact_type);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS license_policies;
+
+DROP INDEX IF EXISTS idx_provider_versions_license;
+DROP INDEX IF EXISTS idx_module_versions_license;
+
+ALTER TABLE provider_versions DROP COLUMN IF EXISTS license_spdx;
+ALTER TABLE module_versions DROP COLUMN IF EXISTS license_spdx;
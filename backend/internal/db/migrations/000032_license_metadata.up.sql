@@ -0,0 +1,30 @@
+-- Migration 032: License Metadata
+-- Stores the SPDX license identifier detected for module and provider versions,
+-- and adds policies that allow or deny publishing and mirroring by license.
+
+ALTER TABLE module_versions ADD COLUMN IF NOT EXISTS license_spdx VARCHAR(100);
+ALTER TABLE provider_versions ADD COLUMN IF NOT EXISTS license_spdx VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_license ON module_versions(license_spdx);
+CREATE INDEX IF NOT EXISTS idx_provider_versions_license ON provider_versions(license_spdx);
+
+CREATE TABLE IF NOT EXISTS license_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE, -- NULL means global
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    artifact_type VARCHAR(20) NOT NULL CHECK (artifact_type IN ('module', 'provider')), -- provider covers uploads and mirroring
+    policy_type VARCHAR(20) NOT NULL CHECK (policy_type IN ('allowlist', 'denylist')),
+    licenses JSONB NOT NULL DEFAULT '[]', -- JSON array of SPDX identifiers
+    allow_unknown BOOLEAN NOT NULL DEFAULT false, -- Whether artifacts with no detectable license are allowed
+    is_active BOOLEAN NOT NULL DEFAULT true,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_license_policies_org_type ON license_policies(organization_id, artifact_type);
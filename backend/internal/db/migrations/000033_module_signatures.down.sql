@@ -0,0 +1,13 @@
+DELETE FROM module_publish_rules WHERE rule_type = 'signature_required';
+ALTER TABLE module_publish_rules DROP CONSTRAINT IF EXISTS module_publish_rules_rule_type_check;
+ALTER TABLE module_publish_rules ADD CONSTRAINT module_publish_rules_rule_type_check CHECK (rule_type IN (
+    'readme_required', 'variable_descriptions', 'variable_types',
+    'provider_version_constraints', 'license_required',
+    'no_provider_credentials', 'version_pattern'
+));
+
+ALTER TABLE module_versions DROP COLUMN IF EXISTS provenance_status;
+ALTER TABLE module_versions DROP COLUMN IF EXISTS signature_status;
+
+DROP TABLE IF EXISTS module_version_attestations;
+DROP TABLE IF EXISTS trusted_signers;
@@ -0,0 +1,57 @@
+-- Migration 033: Module Signatures
+-- Trusted signing identities and keys, the cosign signatures and SLSA provenance
+-- attestations uploaded with module versions, and per-version verification status.
+
+CREATE TABLE IF NOT EXISTS trusted_signers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE, -- NULL means global
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+
+    signer_type VARCHAR(20) NOT NULL CHECK (signer_type IN ('public_key', 'keyless')),
+    public_key TEXT,                     -- PEM public key for public_key signers
+    certificate_identity VARCHAR(500),   -- Regex matched against certificate SANs for keyless signers
+    certificate_issuer VARCHAR(500),     -- Expected OIDC issuer for keyless signers
+    root_certificates TEXT,              -- PEM bundle of trusted roots for keyless signers
+    builder_id_pattern VARCHAR(500),     -- Regex the SLSA builder ID must match in provenance signed by this signer
+    namespace_pattern VARCHAR(255),      -- Supports wildcards; NULL means all namespaces
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    UNIQUE (organization_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trusted_signers_org ON trusted_signers(organization_id);
+
+CREATE TABLE IF NOT EXISTS module_version_attestations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    module_version_id UUID NOT NULL REFERENCES module_versions(id) ON DELETE CASCADE,
+    attestation_type VARCHAR(20) NOT NULL CHECK (attestation_type IN ('signature', 'provenance')),
+    content TEXT NOT NULL,   -- Base64 signature or DSSE envelope JSON
+    certificate TEXT,        -- PEM signing certificate chain for keyless signatures
+
+    verified BOOLEAN NOT NULL DEFAULT false,
+    signer_id UUID REFERENCES trusted_signers(id) ON DELETE SET NULL,
+    signer_identity VARCHAR(500),  -- Trusted signer name or matched certificate identity
+    predicate_type VARCHAR(255),
+    builder_id VARCHAR(500),
+    verification_error TEXT,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_module_version_attestations_version
+    ON module_version_attestations(module_version_id);
+
+ALTER TABLE module_versions ADD COLUMN IF NOT EXISTS signature_status VARCHAR(20) NOT NULL DEFAULT 'none';
+ALTER TABLE module_versions ADD COLUMN IF NOT EXISTS provenance_status VARCHAR(20) NOT NULL DEFAULT 'none';
+
+ALTER TABLE module_publish_rules DROP CONSTRAINT IF EXISTS module_publish_rules_rule_type_check;
+ALTER TABLE module_publish_rules ADD CONSTRAINT module_publish_rules_rule_type_check CHECK (rule_type IN (
+    'readme_required', 'variable_descriptions', 'variable_types',
+    'provider_version_constraints', 'license_required',
+    'no_provider_credentials', 'version_pattern', 'signature_required'
+));
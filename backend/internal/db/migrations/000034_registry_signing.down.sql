@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS module_version_manifests;
+
+DROP INDEX IF EXISTS idx_registry_signing_keys_active;
+DROP INDEX IF EXISTS idx_registry_signing_keys_org;
+DROP TABLE IF EXISTS registry_signing_keys;
@@ -0,0 +1,27 @@
+-- Migration 034: Registry Signing
+-- Organization signing keys (private halves encrypted at rest) and the signed
+-- manifests the registry issues for each stored module archive.
+
+CREATE TABLE IF NOT EXISTS registry_signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    key_id VARCHAR(64) NOT NULL UNIQUE,  -- Fingerprint of the public key
+    algorithm VARCHAR(20) NOT NULL DEFAULT 'ed25519' CHECK (algorithm IN ('ed25519')),
+    public_key TEXT NOT NULL,            -- PEM-encoded public key
+    private_key_encrypted TEXT NOT NULL, -- PEM-encoded private key, sealed with the token cipher
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'retired')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_registry_signing_keys_org ON registry_signing_keys(organization_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registry_signing_keys_active
+    ON registry_signing_keys(organization_id) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS module_version_manifests (
+    module_version_id UUID PRIMARY KEY REFERENCES module_versions(id) ON DELETE CASCADE,
+    key_id VARCHAR(64) NOT NULL,
+    payload TEXT NOT NULL,    -- Canonical manifest JSON exactly as signed
+    signature TEXT NOT NULL,  -- Base64 Ed25519 signature over the DSSE pre-authentication encoding
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS storage_migration_items;
+DROP TABLE IF EXISTS storage_migrations;
@@ -0,0 +1,42 @@
+-- Migration 035: Storage Migrations
+-- Background copies of module and provider artifacts between storage backends.
+-- Each referenced object is tracked as an item so migrations can resume after a
+-- restart and be rolled back row by row.
+
+CREATE TABLE IF NOT EXISTS storage_migrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_config_id UUID REFERENCES storage_config(id) ON DELETE SET NULL, -- NULL means the server's configured backend
+    target_config_id UUID NOT NULL REFERENCES storage_config(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN (
+        'pending', 'running', 'paused', 'completed', 'failed', 'cancelled', 'rolling_back', 'rolled_back'
+    )),
+    delete_target_on_rollback BOOLEAN NOT NULL DEFAULT true,
+    last_error TEXT,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_migrations_status ON storage_migrations(status);
+
+CREATE TABLE IF NOT EXISTS storage_migration_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    migration_id UUID NOT NULL REFERENCES storage_migrations(id) ON DELETE CASCADE,
+    artifact_type VARCHAR(20) NOT NULL CHECK (artifact_type IN ('module_version', 'provider_platform')),
+    artifact_id UUID NOT NULL,
+    source_backend VARCHAR(50) NOT NULL,
+    source_path VARCHAR(1024) NOT NULL,
+    target_path VARCHAR(1024),
+    checksum VARCHAR(64),     -- Expected SHA256 of the object
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'migrated', 'failed', 'rolled_back')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (migration_id, artifact_type, artifact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_migration_items_status ON storage_migration_items(migration_id, status);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS storage_replication_queue;
+
+DELETE FROM storage_config WHERE backend_type = 'replicated';
+
+ALTER TABLE storage_config
+    DROP COLUMN IF EXISTS replication_mode,
+    DROP COLUMN IF EXISTS replication_secondary_ids,
+    DROP COLUMN IF EXISTS replication_primary_id;
+
+ALTER TABLE storage_config DROP CONSTRAINT IF EXISTS valid_backend_type;
+ALTER TABLE storage_config ADD CONSTRAINT valid_backend_type
+    CHECK (backend_type IN ('local', 'azure', 's3', 'gcs'));
@@ -0,0 +1,30 @@
+-- Migration 036: Replicated Storage
+-- A replicated storage configuration writes to a primary configuration and one or more
+-- secondaries, either synchronously or through a persistent replication queue.
+
+ALTER TABLE storage_config DROP CONSTRAINT IF EXISTS valid_backend_type;
+ALTER TABLE storage_config ADD CONSTRAINT valid_backend_type
+    CHECK (backend_type IN ('local', 'azure', 's3', 'gcs', 'replicated'));
+
+ALTER TABLE storage_config
+    ADD COLUMN IF NOT EXISTS replication_primary_id UUID REFERENCES storage_config(id),
+    ADD COLUMN IF NOT EXISTS replication_secondary_ids UUID[],
+    ADD COLUMN IF NOT EXISTS replication_mode VARCHAR(10) CHECK (replication_mode IN ('sync', 'async'));
+
+-- Pending replica writes and deletes. Only the latest operation per object and replica is kept,
+-- since each operation fully determines the replica's end state.
+CREATE TABLE IF NOT EXISTS storage_replication_queue (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    config_id UUID NOT NULL REFERENCES storage_config(id) ON DELETE CASCADE,  -- The replicated configuration
+    replica_id UUID NOT NULL REFERENCES storage_config(id) ON DELETE CASCADE, -- The configuration to bring up to date
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('upload', 'delete')),
+    path VARCHAR(1024) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (config_id, replica_id, path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_replication_queue_due ON storage_replication_queue(next_attempt_at);
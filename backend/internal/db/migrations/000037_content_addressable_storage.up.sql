@@ -0,0 +1,14 @@
+-- Migration 037: Content-Addressable Storage
+-- With content addressing enabled, artifacts on the default backend are stored once per
+-- SHA256 digest under blobs/sha256/. Each blob counts the artifact rows referencing it so
+-- that deleting an artifact only removes the object once nothing else uses it.
+
+CREATE TABLE IF NOT EXISTS storage_blobs (
+    backend VARCHAR(50) NOT NULL,
+    digest VARCHAR(64) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    ref_count INTEGER NOT NULL DEFAULT 0 CHECK (ref_count >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (backend, digest)
+);
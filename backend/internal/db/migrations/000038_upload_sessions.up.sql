@@ -0,0 +1,19 @@
+-- Migration 038: Resumable Upload Sessions
+-- Large module archives and provider binaries can be uploaded in chunks with the tus protocol.
+-- Each session tracks how many bytes have been received into its temp file so an interrupted
+-- upload resumes where it stopped. Idle sessions expire and are removed with their temp files.
+
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    artifact_type VARCHAR(20) NOT NULL CHECK (artifact_type IN ('module', 'provider')),
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    upload_length BIGINT NOT NULL CHECK (upload_length >= 0),
+    upload_offset BIGINT NOT NULL DEFAULT 0 CHECK (upload_offset >= 0 AND upload_offset <= upload_length),
+    upload_metadata TEXT NOT NULL DEFAULT '',
+    owner VARCHAR(100) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at);
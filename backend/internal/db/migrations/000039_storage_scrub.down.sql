@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_storage_orphans_status;
+DROP TABLE IF EXISTS storage_orphans;
+DROP INDEX IF EXISTS idx_storage_integrity_issues_run_id;
+DROP TABLE IF EXISTS storage_integrity_issues;
+DROP INDEX IF EXISTS idx_storage_scrub_runs_started_at;
+DROP TABLE IF EXISTS storage_scrub_runs;
@@ -0,0 +1,51 @@
+-- Migration 039: Storage Integrity Scrubber
+-- Scheduled scrubs check that every artifact's object exists with the recorded size and
+-- checksum, re-hash a sample of artifacts, and track stored objects that no artifact
+-- references. Orphans are quarantined or deleted once they have stayed unreferenced for a
+-- grace period.
+
+CREATE TABLE IF NOT EXISTS storage_scrub_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    artifacts_checked INTEGER NOT NULL DEFAULT 0,
+    artifacts_rehashed INTEGER NOT NULL DEFAULT 0,
+    issues_found INTEGER NOT NULL DEFAULT 0,
+    objects_scanned INTEGER NOT NULL DEFAULT 0,
+    orphans_found INTEGER NOT NULL DEFAULT 0,
+    orphans_quarantined INTEGER NOT NULL DEFAULT 0,
+    orphans_deleted INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_scrub_runs_started_at ON storage_scrub_runs(started_at DESC);
+
+CREATE TABLE IF NOT EXISTS storage_integrity_issues (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES storage_scrub_runs(id) ON DELETE CASCADE,
+    artifact_type VARCHAR(30) NOT NULL,
+    artifact_id UUID NOT NULL,
+    storage_backend VARCHAR(50) NOT NULL,
+    storage_path TEXT NOT NULL,
+    issue VARCHAR(30) NOT NULL CHECK (issue IN ('missing', 'unreadable', 'size_mismatch', 'checksum_mismatch')),
+    details TEXT NOT NULL DEFAULT '',
+    detected_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_integrity_issues_run_id ON storage_integrity_issues(run_id);
+
+CREATE TABLE IF NOT EXISTS storage_orphans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    storage_backend VARCHAR(50) NOT NULL,
+    storage_path TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'quarantined', 'deleted')),
+    quarantine_path TEXT,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    acted_at TIMESTAMP,
+    UNIQUE (storage_backend, storage_path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_orphans_status ON storage_orphans(status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_storage_data_keys_master_key_id;
+DROP TABLE IF EXISTS storage_data_keys;
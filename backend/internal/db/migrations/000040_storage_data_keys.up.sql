@@ -0,0 +1,20 @@
+-- Migration 040: Envelope Encryption of Stored Files
+-- Files written while storage encryption is enabled are encrypted with their own data key.
+-- Data keys are stored wrapped by a master key, together with the size and checksum of the
+-- plaintext, so the master key can be rotated by re-wrapping keys without rewriting files.
+-- Files without a row are stored unencrypted.
+
+CREATE TABLE IF NOT EXISTS storage_data_keys (
+    storage_backend VARCHAR(255) NOT NULL,
+    storage_path TEXT NOT NULL,
+    master_key_id VARCHAR(64) NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    nonce_prefix BYTEA NOT NULL,
+    plaintext_size BIGINT NOT NULL,
+    plaintext_checksum VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP,
+    PRIMARY KEY (storage_backend, storage_path)
+);
+
+CREATE INDEX IF NOT EXISTS idx_storage_data_keys_master_key_id ON storage_data_keys(master_key_id);
@@ -0,0 +1,12 @@
+-- Migration 041: Per-Organization Storage Quotas
+-- Caps the bytes an organization may store across modules, providers and mirrored providers.
+-- Usage is computed from the size_bytes recorded with module versions and provider platforms.
+-- Organizations without a row fall back to the configured default quota.
+
+CREATE TABLE IF NOT EXISTS storage_quotas (
+    organization_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+    max_bytes BIGINT NOT NULL CHECK (max_bytes >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS mirror_sync_checkpoints;
+
+ALTER TABLE mirror_configurations
+DROP CONSTRAINT IF EXISTS valid_sync_concurrency;
+
+ALTER TABLE mirror_configurations
+DROP COLUMN IF EXISTS sync_concurrency;
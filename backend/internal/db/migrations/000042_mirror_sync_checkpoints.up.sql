@@ -0,0 +1,30 @@
+-- Migration 042: Parallel, Resumable Mirror Sync
+-- Mirrors download several provider versions at once, up to sync_concurrency.
+-- Syncs record a checkpoint per provider, version and platform as they go, so a sync
+-- interrupted by a restart resumes where it stopped instead of starting over.
+-- Checkpoints are cleared once a sync has walked every provider.
+
+ALTER TABLE mirror_configurations
+ADD COLUMN IF NOT EXISTS sync_concurrency INTEGER NOT NULL DEFAULT 4;
+
+ALTER TABLE mirror_configurations
+ADD CONSTRAINT valid_sync_concurrency CHECK (sync_concurrency > 0 AND sync_concurrency <= 32);
+
+COMMENT ON COLUMN mirror_configurations.sync_concurrency IS 'Provider versions downloaded at once during a sync';
+
+CREATE TABLE IF NOT EXISTS mirror_sync_checkpoints (
+    mirror_config_id UUID NOT NULL REFERENCES mirror_configurations(id) ON DELETE CASCADE,
+    namespace VARCHAR(255) NOT NULL,
+    provider_type VARCHAR(255) NOT NULL,
+    version VARCHAR(100) NOT NULL DEFAULT '', -- Empty for provider checkpoints
+    os VARCHAR(50) NOT NULL DEFAULT '',       -- Empty for provider and version checkpoints
+    arch VARCHAR(50) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (mirror_config_id, namespace, provider_type, version, os, arch),
+    CONSTRAINT valid_checkpoint_status CHECK (status IN ('in_progress', 'completed', 'failed'))
+);
+
+COMMENT ON TABLE mirror_sync_checkpoints IS 'Progress of the current sync of each mirror, used to resume interrupted syncs';
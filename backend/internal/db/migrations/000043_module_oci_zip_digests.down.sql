@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_module_versions_oci_zip_digest;
+
+ALTER TABLE module_versions
+DROP COLUMN IF EXISTS oci_zip_size;
+
+ALTER TABLE module_versions
+DROP COLUMN IF EXISTS oci_zip_digest;
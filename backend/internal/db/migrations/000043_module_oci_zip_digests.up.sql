@@ -0,0 +1,16 @@
+-- Migration 043: Module OCI Zip Digests
+-- Module versions are served over the OCI Distribution API as zips converted from their
+-- stored archives. The digest and size of each version's zip are recorded when its archive
+-- is stored, so blobs and manifests requested by digest are found without converting archives.
+-- Versions stored before this migration are filled in the first time they are converted.
+
+ALTER TABLE module_versions
+ADD COLUMN IF NOT EXISTS oci_zip_digest VARCHAR(71),
+ADD COLUMN IF NOT EXISTS oci_zip_size BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_module_versions_oci_zip_digest
+ON module_versions(module_id, oci_zip_digest)
+WHERE oci_zip_digest IS NOT NULL;
+
+COMMENT ON COLUMN module_versions.oci_zip_digest IS 'sha256 digest of the archive converted to the zip served over OCI';
+COMMENT ON COLUMN module_versions.oci_zip_size IS 'Size in bytes of the archive converted to the zip served over OCI';
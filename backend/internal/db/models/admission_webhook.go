@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArtifactType identifies the kind of artifact being published
+type ArtifactType string
+
+const (
+	ArtifactTypeModule   ArtifactType = "module"
+	ArtifactTypeProvider ArtifactType = "provider"
+)
+
+// AdmissionFailurePolicy controls how a publish proceeds when a webhook cannot be reached
+type AdmissionFailurePolicy string
+
+const (
+	AdmissionFailOpen   AdmissionFailurePolicy = "fail_open"   // Allow the publish if the webhook errors or times out
+	AdmissionFailClosed AdmissionFailurePolicy = "fail_closed" // Deny the publish if the webhook errors or times out
+)
+
+// AdmissionWebhook is an external endpoint that can veto a publish before the version is committed
+type AdmissionWebhook struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID *uuid.UUID `db:"organization_id" json:"organization_id,omitempty"` // NULL = global webhook
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+
+	URL            string                 `db:"url" json:"url"`
+	ArtifactType   ArtifactType           `db:"artifact_type" json:"artifact_type"`
+	TimeoutSeconds int                    `db:"timeout_seconds" json:"timeout_seconds"`
+	FailurePolicy  AdmissionFailurePolicy `db:"failure_policy" json:"failure_policy"`
+	IsActive       bool                   `db:"is_active" json:"is_active"`
+
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+}
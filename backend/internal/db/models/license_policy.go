@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LicensePolicyType controls whether a policy's license list is an allowlist or a denylist
+type LicensePolicyType string
+
+const (
+	LicensePolicyAllowlist LicensePolicyType = "allowlist" // Only the listed licenses are permitted
+	LicensePolicyDenylist  LicensePolicyType = "denylist"  // The listed licenses are rejected
+)
+
+// LicensePolicy restricts which SPDX licenses may be published or mirrored.
+// Provider policies apply to both provider uploads and mirror syncs.
+type LicensePolicy struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID *uuid.UUID `db:"organization_id" json:"organization_id,omitempty"` // NULL = global policy
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+
+	ArtifactType ArtifactType      `db:"artifact_type" json:"artifact_type"`
+	PolicyType   LicensePolicyType `db:"policy_type" json:"policy_type"`
+	Licenses     string            `db:"licenses" json:"-"` // JSON array of SPDX identifiers
+	AllowUnknown bool              `db:"allow_unknown" json:"allow_unknown"`
+	IsActive     bool              `db:"is_active" json:"is_active"`
+
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+}
+
+// LicenseList returns the policy's SPDX identifiers
+func (p *LicensePolicy) LicenseList() []string {
+	var licenses []string
+	if p.Licenses == "" {
+		return licenses
+	}
+	_ = json.Unmarshal([]byte(p.Licenses), &licenses)
+	return licenses
+}
+
+// SetLicenseList stores the policy's SPDX identifiers
+func (p *LicensePolicy) SetLicenseList(licenses []string) {
+	if licenses == nil {
+		licenses = []string{}
+	}
+	data, _ := json.Marshal(licenses)
+	p.Licenses = string(data)
+}
+
+// MarshalJSON exposes the license list as a JSON array rather than its stored string form
+func (p LicensePolicy) MarshalJSON() ([]byte, error) {
+	type alias LicensePolicy
+	licenses := p.LicenseList()
+	if licenses == nil {
+		licenses = []string{}
+	}
+	return json.Marshal(struct {
+		alias
+		Licenses []string `json:"licenses"`
+	}{alias: alias(p), Licenses: licenses})
+}
+
+// Permits reports whether the policy allows an artifact with the given SPDX identifier.
+// An empty identifier means no license could be detected.
+func (p *LicensePolicy) Permits(spdx string) bool {
+	if spdx == "" {
+		return p.AllowUnknown
+	}
+
+	listed := false
+	for _, license := range p.LicenseList() {
+		if strings.EqualFold(license, spdx) {
+			listed = true
+			break
+		}
+	}
+
+	if p.PolicyType == LicensePolicyAllowlist {
+		return listed
+	}
+	return !listed
+}
@@ -15,10 +15,11 @@ type MirrorConfiguration struct {
 	OrganizationID      *uuid.UUID `json:"organization_id,omitempty" db:"organization_id"`   // Organization for mirrored providers
 	NamespaceFilter     *string    `json:"namespace_filter,omitempty" db:"namespace_filter"` // JSON array
 	ProviderFilter      *string    `json:"provider_filter,omitempty" db:"provider_filter"`   // JSON array
-	VersionFilter       *string    `json:"version_filter,omitempty" db:"version_filter"`     // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+	VersionFilter       *string    `json:"version_filter,omitempty" db:"version_filter"`     // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
 	PlatformFilter      *string    `json:"platform_filter,omitempty" db:"platform_filter"`   // JSON array of "os/arch" strings
 	Enabled             bool       `json:"enabled" db:"enabled"`
 	SyncIntervalHours   int        `json:"sync_interval_hours" db:"sync_interval_hours"`
+	SyncConcurrency     int        `json:"sync_concurrency" db:"sync_concurrency"` // Provider versions downloaded at once
 	LastSyncAt          *time.Time `json:"last_sync_at,omitempty" db:"last_sync_at"`
 	LastSyncStatus      *string    `json:"last_sync_status,omitempty" db:"last_sync_status"` // success, failed, in_progress
 	LastSyncError       *string    `json:"last_sync_error,omitempty" db:"last_sync_error"`
@@ -64,6 +65,27 @@ type MirrorSyncHistory struct {
 	SyncDetails     *string    `json:"sync_details,omitempty" db:"sync_details"` // JSONB
 }
 
+// Statuses of mirror sync checkpoints
+const (
+	CheckpointInProgress = "in_progress"
+	CheckpointCompleted  = "completed"
+	CheckpointFailed     = "failed"
+)
+
+// MirrorSyncCheckpoint records how far the current sync of a mirror got with a provider, one of
+// its versions (OS and Arch empty) or one of a version's platforms
+type MirrorSyncCheckpoint struct {
+	MirrorConfigID uuid.UUID `json:"mirror_config_id" db:"mirror_config_id"`
+	Namespace      string    `json:"namespace" db:"namespace"`
+	ProviderType   string    `json:"provider_type" db:"provider_type"`
+	Version        string    `json:"version,omitempty" db:"version"` // Empty for provider checkpoints
+	OS             string    `json:"os,omitempty" db:"os"`
+	Arch           string    `json:"arch,omitempty" db:"arch"`
+	Status         string    `json:"status" db:"status"` // in_progress, completed, failed
+	ErrorMessage   *string   `json:"error_message,omitempty" db:"error_message"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
 // CreateMirrorConfigRequest represents the request to create a new mirror configuration
 type CreateMirrorConfigRequest struct {
 	Name                string   `json:"name" binding:"required,min=1,max=255"`
@@ -72,10 +94,12 @@ type CreateMirrorConfigRequest struct {
 	OrganizationID      *string  `json:"organization_id,omitempty"`                               // Organization for mirrored providers
 	NamespaceFilter     []string `json:"namespace_filter,omitempty"`                              // List of namespaces to mirror
 	ProviderFilter      []string `json:"provider_filter,omitempty"`                               // List of provider names to mirror
-	VersionFilter       *string  `json:"version_filter,omitempty"`                                // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+	VersionFilter       *string  `json:"version_filter,omitempty"`                                // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
 	PlatformFilter      []string `json:"platform_filter,omitempty"`                               // List of "os/arch" strings (e.g. ["linux/amd64", "windows/amd64"])
 	Enabled             *bool    `json:"enabled,omitempty"`                                       // Default: true
 	SyncIntervalHours   *int     `json:"sync_interval_hours,omitempty" binding:"omitempty,min=1"` // Default: 24
+	// Provider versions downloaded at once. Default: 4
+	SyncConcurrency *int `json:"sync_concurrency,omitempty" binding:"omitempty,min=1,max=32"`
 }
 
 // UpdateMirrorConfigRequest represents the request to update a mirror configuration
@@ -86,10 +110,11 @@ type UpdateMirrorConfigRequest struct {
 	OrganizationID      *string  `json:"organization_id,omitempty"` // Organization for mirrored providers
 	NamespaceFilter     []string `json:"namespace_filter,omitempty"`
 	ProviderFilter      []string `json:"provider_filter,omitempty"`
-	VersionFilter       *string  `json:"version_filter,omitempty"` // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+	VersionFilter       *string  `json:"version_filter,omitempty"` // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
 	PlatformFilter      []string `json:"platform_filter,omitempty"` // List of "os/arch" strings (e.g. ["linux/amd64", "windows/amd64"])
 	Enabled             *bool    `json:"enabled,omitempty"`
 	SyncIntervalHours   *int     `json:"sync_interval_hours,omitempty" binding:"omitempty,min=1"`
+	SyncConcurrency     *int     `json:"sync_concurrency,omitempty" binding:"omitempty,min=1,max=32"`
 }
 
 // TriggerSyncRequest represents the request to trigger a manual sync
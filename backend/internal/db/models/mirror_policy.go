@@ -2,6 +2,7 @@ package models
 
 import (
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -48,7 +49,7 @@ type MirrorPolicy struct {
 func (p *MirrorPolicy) Matches(registry, namespace, provider string) bool {
 	// Check registry match
 	if p.UpstreamRegistry != nil && *p.UpstreamRegistry != "" {
-		if *p.UpstreamRegistry != registry {
+		if normalizeRegistry(*p.UpstreamRegistry) != normalizeRegistry(registry) {
 			return false
 		}
 	}
@@ -72,6 +73,47 @@ func (p *MirrorPolicy) Matches(registry, namespace, provider string) bool {
 	return true
 }
 
+// normalizeRegistry reduces a registry URL to its host, so "https://registry.terraform.io/" and
+// "registry.terraform.io" match each other
+func normalizeRegistry(registry string) string {
+	registry = strings.ToLower(strings.TrimSpace(registry))
+	registry = strings.TrimPrefix(registry, "https://")
+	registry = strings.TrimPrefix(registry, "http://")
+	return strings.TrimSuffix(registry, "/")
+}
+
+// EvaluateMirrorPolicies evaluates policies for a provider. The first active policy that matches,
+// in the given (priority) order, decides; a provider no policy matches is denied.
+func EvaluateMirrorPolicies(policies []*MirrorPolicy, registry, namespace, provider string) *PolicyEvaluationResult {
+	result := &PolicyEvaluationResult{
+		Allowed:          false,
+		RequiresApproval: false,
+		Reason:           "No matching policy found",
+	}
+
+	for _, policy := range policies {
+		if !policy.IsActive {
+			continue
+		}
+
+		if policy.Matches(registry, namespace, provider) {
+			result.MatchedPolicy = policy
+			result.RequiresApproval = policy.RequiresApproval
+
+			if policy.PolicyType == PolicyTypeAllow {
+				result.Allowed = true
+				result.Reason = "Allowed by policy: " + policy.Name
+			} else {
+				result.Allowed = false
+				result.Reason = "Denied by policy: " + policy.Name
+			}
+			return result
+		}
+	}
+
+	return result
+}
+
 // PolicyEvaluationResult represents the result of evaluating policies
 type PolicyEvaluationResult struct {
 	Allowed          bool          `json:"allowed"`
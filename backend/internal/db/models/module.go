@@ -33,7 +33,24 @@ type ModuleVersion struct {
 	Deprecated         bool       // Whether this version is deprecated
 	DeprecatedAt       *time.Time // When the version was deprecated
 	DeprecationMessage *string    // Optional message explaining deprecation
+	LicenseSPDX        *string    // SPDX identifier detected from the archive's license file
+	SignatureStatus    string     // Cosign signature verification status: none, verified or unverified
+	ProvenanceStatus   string     // SLSA provenance verification status: none, verified or unverified
+	OCIZipDigest       *string    // Digest of the archive converted to the zip served over OCI; nil until computed
+	OCIZipSize         *int64     // Size of that zip
 	CreatedAt          time.Time
 	// Joined fields (not stored in module_versions table)
 	PublishedByName *string // User name who published this version (joined from users table)
 }
+
+// ModuleVersionSecretFinding records a potential secret detected in a published module archive
+type ModuleVersionSecretFinding struct {
+	ID              string
+	ModuleVersionID string
+	Rule            string
+	FilePath        string
+	LineNumber      *int
+	Description     string
+	RedactedMatch   *string
+	CreatedAt       time.Time
+}
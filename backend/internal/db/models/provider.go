@@ -30,6 +30,7 @@ type ProviderVersion struct {
 	Deprecated          bool       // Whether this version is deprecated
 	DeprecatedAt        *time.Time // When the version was deprecated
 	DeprecationMessage  *string    // Optional message explaining deprecation
+	LicenseSPDX         *string    // SPDX identifier detected from the provider's license file or upstream metadata
 	CreatedAt           time.Time
 	// Joined fields (not stored in provider_versions table)
 	PublishedByName *string // User name who published this version (joined from users table)
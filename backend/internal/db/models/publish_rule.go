@@ -0,0 +1,95 @@
+package models
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PublishRuleType identifies the check a module publish rule performs
+type PublishRuleType string
+
+const (
+	PublishRuleReadmeRequired             PublishRuleType = "readme_required"
+	PublishRuleVariableDescriptions       PublishRuleType = "variable_descriptions"
+	PublishRuleVariableTypes              PublishRuleType = "variable_types"
+	PublishRuleProviderVersionConstraints PublishRuleType = "provider_version_constraints"
+	PublishRuleLicenseRequired            PublishRuleType = "license_required"
+	PublishRuleNoProviderCredentials      PublishRuleType = "no_provider_credentials"
+	PublishRuleVersionPattern             PublishRuleType = "version_pattern"
+	PublishRuleSignatureRequired          PublishRuleType = "signature_required" // Parameter "provenance" also requires verified provenance; not applied to SCM publishes
+)
+
+// ValidPublishRuleTypes lists every supported publish rule type
+var ValidPublishRuleTypes = []PublishRuleType{
+	PublishRuleReadmeRequired,
+	PublishRuleVariableDescriptions,
+	PublishRuleVariableTypes,
+	PublishRuleProviderVersionConstraints,
+	PublishRuleLicenseRequired,
+	PublishRuleNoProviderCredentials,
+	PublishRuleVersionPattern,
+	PublishRuleSignatureRequired,
+}
+
+// IsValid reports whether t is a supported publish rule type
+func (t PublishRuleType) IsValid() bool {
+	for _, valid := range ValidPublishRuleTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleEnforcement controls what happens when a publish rule fails
+type RuleEnforcement string
+
+const (
+	RuleEnforcementBlock RuleEnforcement = "block"
+	RuleEnforcementWarn  RuleEnforcement = "warn"
+)
+
+// ModulePublishRule is an organization-scoped quality gate evaluated when a module version is published
+type ModulePublishRule struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID *uuid.UUID `db:"organization_id" json:"organization_id,omitempty"` // NULL = global rule
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+
+	RuleType    PublishRuleType `db:"rule_type" json:"rule_type"`
+	Enforcement RuleEnforcement `db:"enforcement" json:"enforcement"`
+	Parameter   *string         `db:"parameter" json:"parameter,omitempty"` // Rule-specific setting, e.g. the version regex
+
+	// What this rule applies to (supports wildcards)
+	NamespacePattern *string `db:"namespace_pattern" json:"namespace_pattern,omitempty"` // NULL = all namespaces
+
+	IsActive bool `db:"is_active" json:"is_active"`
+
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+}
+
+// Matches checks if this rule applies to the given module namespace
+func (r *ModulePublishRule) Matches(namespace string) bool {
+	if r.NamespacePattern == nil || *r.NamespacePattern == "" || *r.NamespacePattern == "*" {
+		return true
+	}
+	matched, _ := filepath.Match(*r.NamespacePattern, namespace)
+	return matched
+}
+
+// ModuleVersionPolicyResult records the outcome of a publish rule for a module version
+type ModuleVersionPolicyResult struct {
+	ID              uuid.UUID       `db:"id" json:"id"`
+	ModuleVersionID uuid.UUID       `db:"module_version_id" json:"module_version_id"`
+	RuleID          *uuid.UUID      `db:"rule_id" json:"rule_id,omitempty"`
+	RuleName        string          `db:"rule_name" json:"rule_name"`
+	RuleType        PublishRuleType `db:"rule_type" json:"rule_type"`
+	Enforcement     RuleEnforcement `db:"enforcement" json:"enforcement"`
+	Passed          bool            `db:"passed" json:"passed"`
+	Message         *string         `db:"message" json:"message,omitempty"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+}
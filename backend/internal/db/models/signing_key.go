@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningKeyStatus tracks whether a registry signing key still signs new manifests
+type SigningKeyStatus string
+
+const (
+	SigningKeyActive  SigningKeyStatus = "active"  // Signs new manifests
+	SigningKeyRetired SigningKeyStatus = "retired" // Kept only so existing manifests remain verifiable
+)
+
+// RegistrySigningKey is an organization key the registry uses to sign module manifests
+type RegistrySigningKey struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	OrganizationID      uuid.UUID        `db:"organization_id" json:"organization_id"`
+	KeyID               string           `db:"key_id" json:"key_id"`
+	Algorithm           string           `db:"algorithm" json:"algorithm"`
+	PublicKey           string           `db:"public_key" json:"public_key"`
+	PrivateKeyEncrypted string           `db:"private_key_encrypted" json:"-"` // Never expose
+	Status              SigningKeyStatus `db:"status" json:"status"`
+	CreatedAt           time.Time        `db:"created_at" json:"created_at"`
+	RetiredAt           *time.Time       `db:"retired_at" json:"retired_at,omitempty"`
+}
+
+// ModuleVersionManifest is the registry-signed manifest of a stored module archive
+type ModuleVersionManifest struct {
+	ModuleVersionID uuid.UUID `db:"module_version_id" json:"module_version_id"`
+	KeyID           string    `db:"key_id" json:"key_id"`
+	Payload         string    `db:"payload" json:"payload"`
+	Signature       string    `db:"signature" json:"signature"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+}
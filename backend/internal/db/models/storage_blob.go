@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageBlob is a content-addressed object on a storage backend, shared by every artifact
+// whose contents hash to its digest
+type StorageBlob struct {
+	Backend   string    `db:"backend" json:"backend"`
+	Digest    string    `db:"digest" json:"digest"`
+	SizeBytes int64     `db:"size_bytes" json:"size_bytes"`
+	RefCount  int       `db:"ref_count" json:"ref_count"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// StoredArtifact is a module version archive or provider platform binary and its storage location
+type StoredArtifact struct {
+	ArtifactType   string    `db:"artifact_type" json:"artifact_type"`
+	ArtifactID     uuid.UUID `db:"artifact_id" json:"artifact_id"`
+	StorageBackend string    `db:"storage_backend" json:"storage_backend"`
+	StoragePath    string    `db:"storage_path" json:"storage_path"`
+	Checksum       string    `db:"checksum" json:"checksum"`
+	SizeBytes      int64     `db:"size_bytes" json:"size_bytes"`
+}
+
+// ContentAddressedStats summarizes deduplication on the default backend
+type ContentAddressedStats struct {
+	Blobs              int   `db:"blobs" json:"blobs"`
+	References         int64 `db:"reference_count" json:"references"`
+	StoredBytes        int64 `db:"stored_bytes" json:"stored_bytes"`
+	LogicalBytes       int64 `db:"logical_bytes" json:"logical_bytes"` // Bytes stored had every reference its own copy
+	PathBasedArtifacts int   `db:"path_based_artifacts" json:"path_based_artifacts"`
+	Migrating          bool  `db:"-" json:"migrating"`
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // SystemSettings holds global system settings (singleton)
@@ -53,6 +54,11 @@ type StorageConfig struct {
 	GCSCredentialsJSONEncrypted sql.NullString `db:"gcs_credentials_json_encrypted" json:"-"` // Never expose
 	GCSEndpoint                sql.NullString `db:"gcs_endpoint" json:"gcs_endpoint,omitempty"`
 
+	// Replicated storage settings
+	ReplicationPrimaryID    uuid.NullUUID  `db:"replication_primary_id" json:"replication_primary_id,omitempty"`
+	ReplicationSecondaryIDs pq.StringArray `db:"replication_secondary_ids" json:"replication_secondary_ids,omitempty"`
+	ReplicationMode         sql.NullString `db:"replication_mode" json:"replication_mode,omitempty"` // sync, async
+
 	// Metadata
 	CreatedAt time.Time     `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
@@ -62,7 +68,7 @@ type StorageConfig struct {
 
 // StorageConfigInput is used for creating/updating storage configuration
 type StorageConfigInput struct {
-	BackendType string `json:"backend_type" binding:"required,oneof=local azure s3 gcs"`
+	BackendType string `json:"backend_type" binding:"required,oneof=local azure s3 gcs replicated"`
 
 	// Local storage settings
 	LocalBasePath      string `json:"local_base_path,omitempty"`
@@ -93,6 +99,11 @@ type StorageConfigInput struct {
 	GCSCredentialsFile string `json:"gcs_credentials_file,omitempty"`
 	GCSCredentialsJSON string `json:"gcs_credentials_json,omitempty"` // Plain text input
 	GCSEndpoint        string `json:"gcs_endpoint,omitempty"`
+
+	// Replicated storage settings (IDs of other storage configurations)
+	ReplicationPrimaryID    *uuid.UUID  `json:"replication_primary_id,omitempty"`
+	ReplicationSecondaryIDs []uuid.UUID `json:"replication_secondary_ids,omitempty"`
+	ReplicationMode         string      `json:"replication_mode,omitempty"` // sync (default) or async
 }
 
 // StorageConfigResponse is the API response for storage configuration
@@ -132,6 +143,11 @@ type StorageConfigResponse struct {
 	GCSCredentialsJSONSet bool   `json:"gcs_credentials_json_set"`
 	GCSEndpoint           string `json:"gcs_endpoint,omitempty"`
 
+	// Replicated storage settings
+	ReplicationPrimaryID    *uuid.UUID `json:"replication_primary_id,omitempty"`
+	ReplicationSecondaryIDs []string   `json:"replication_secondary_ids,omitempty"`
+	ReplicationMode         string     `json:"replication_mode,omitempty"`
+
 	// Metadata
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -214,5 +230,15 @@ func (s *StorageConfig) ToResponse() StorageConfigResponse {
 		resp.GCSEndpoint = s.GCSEndpoint.String
 	}
 
+	// Replicated
+	if s.ReplicationPrimaryID.Valid {
+		primary := s.ReplicationPrimaryID.UUID
+		resp.ReplicationPrimaryID = &primary
+	}
+	resp.ReplicationSecondaryIDs = s.ReplicationSecondaryIDs
+	if s.ReplicationMode.Valid {
+		resp.ReplicationMode = s.ReplicationMode.String
+	}
+
 	return resp
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// StorageDataKey is the wrapped data key a stored file was encrypted with
+type StorageDataKey struct {
+	StorageBackend    string     `db:"storage_backend" json:"storage_backend"`
+	StoragePath       string     `db:"storage_path" json:"storage_path"`
+	MasterKeyID       string     `db:"master_key_id" json:"master_key_id"`
+	WrappedKey        []byte     `db:"wrapped_key" json:"-"`
+	NoncePrefix       []byte     `db:"nonce_prefix" json:"-"`
+	PlaintextSize     int64      `db:"plaintext_size" json:"plaintext_size"`
+	PlaintextChecksum string     `db:"plaintext_checksum" json:"plaintext_checksum"`
+	CreatedAt         time.Time  `db:"created_at" json:"created_at"`
+	RotatedAt         *time.Time `db:"rotated_at" json:"rotated_at,omitempty"`
+}
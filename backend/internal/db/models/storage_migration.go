@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageMigrationStatus tracks the lifecycle of a storage migration
+type StorageMigrationStatus string
+
+const (
+	StorageMigrationPending     StorageMigrationStatus = "pending"
+	StorageMigrationRunning     StorageMigrationStatus = "running"
+	StorageMigrationPaused      StorageMigrationStatus = "paused"
+	StorageMigrationCompleted   StorageMigrationStatus = "completed"
+	StorageMigrationFailed      StorageMigrationStatus = "failed" // Finished with items that could not be copied
+	StorageMigrationCancelled   StorageMigrationStatus = "cancelled"
+	StorageMigrationRollingBack StorageMigrationStatus = "rolling_back"
+	StorageMigrationRolledBack  StorageMigrationStatus = "rolled_back"
+)
+
+// StorageMigrationItemStatus tracks the copy state of a single artifact
+type StorageMigrationItemStatus string
+
+const (
+	StorageMigrationItemPending    StorageMigrationItemStatus = "pending"
+	StorageMigrationItemMigrated   StorageMigrationItemStatus = "migrated"
+	StorageMigrationItemFailed     StorageMigrationItemStatus = "failed"
+	StorageMigrationItemRolledBack StorageMigrationItemStatus = "rolled_back"
+)
+
+// Artifact types tracked by storage migrations
+const (
+	StorageArtifactModuleVersion    = "module_version"
+	StorageArtifactProviderPlatform = "provider_platform"
+)
+
+// StorageMigration copies every stored artifact from one storage backend to another
+type StorageMigration struct {
+	ID                     uuid.UUID              `db:"id" json:"id"`
+	SourceConfigID         *uuid.UUID             `db:"source_config_id" json:"source_config_id,omitempty"` // nil for the server's configured backend
+	TargetConfigID         uuid.UUID              `db:"target_config_id" json:"target_config_id"`
+	Status                 StorageMigrationStatus `db:"status" json:"status"`
+	DeleteTargetOnRollback bool                   `db:"delete_target_on_rollback" json:"delete_target_on_rollback"`
+	LastError              *string                `db:"last_error" json:"last_error,omitempty"`
+	StartedAt              *time.Time             `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt            *time.Time             `db:"completed_at" json:"completed_at,omitempty"`
+	CreatedAt              time.Time              `db:"created_at" json:"created_at"`
+	UpdatedAt              time.Time              `db:"updated_at" json:"updated_at"`
+	CreatedBy              *uuid.UUID             `db:"created_by" json:"created_by,omitempty"`
+}
+
+// StorageMigrationItem is a single stored object copied by a storage migration
+type StorageMigrationItem struct {
+	ID            uuid.UUID                  `db:"id" json:"id"`
+	MigrationID   uuid.UUID                  `db:"migration_id" json:"migration_id"`
+	ArtifactType  string                     `db:"artifact_type" json:"artifact_type"`
+	ArtifactID    uuid.UUID                  `db:"artifact_id" json:"artifact_id"`
+	SourceBackend string                     `db:"source_backend" json:"source_backend"`
+	SourcePath    string                     `db:"source_path" json:"source_path"`
+	TargetPath    *string                    `db:"target_path" json:"target_path,omitempty"`
+	Checksum      *string                    `db:"checksum" json:"checksum,omitempty"`
+	SizeBytes     int64                      `db:"size_bytes" json:"size_bytes"`
+	Status        StorageMigrationItemStatus `db:"status" json:"status"`
+	Attempts      int                        `db:"attempts" json:"attempts"`
+	Error         *string                    `db:"error" json:"error,omitempty"`
+	UpdatedAt     time.Time                  `db:"updated_at" json:"updated_at"`
+}
+
+// StorageMigrationProgress summarizes how far a storage migration has come
+type StorageMigrationProgress struct {
+	Total      int   `json:"total"`
+	Pending    int   `json:"pending"`
+	Migrated   int   `json:"migrated"`
+	Failed     int   `json:"failed"`
+	RolledBack int   `json:"rolled_back"`
+	BytesTotal int64 `json:"bytes_total"`
+	BytesDone  int64 `json:"bytes_migrated"`
+}
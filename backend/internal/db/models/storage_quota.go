@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Artifact types storage usage is broken down by. Mirrored providers are counted apart from
+// providers published to the registry.
+const (
+	StorageUsageModule           = "module"
+	StorageUsageProvider         = "provider"
+	StorageUsageMirroredProvider = "mirrored_provider"
+)
+
+// StorageQuota caps the bytes an organization may store
+type StorageQuota struct {
+	OrganizationID string    `db:"organization_id" json:"organization_id"`
+	MaxBytes       int64     `db:"max_bytes" json:"max_bytes"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// StorageUsage is the bytes an organization stores for one namespace, artifact type and backend
+type StorageUsage struct {
+	Namespace      string `db:"namespace" json:"namespace"`
+	ArtifactType   string `db:"artifact_type" json:"artifact_type"`
+	StorageBackend string `db:"storage_backend" json:"storage_backend"`
+	Objects        int64  `db:"objects" json:"objects"`
+	Bytes          int64  `db:"bytes" json:"bytes"`
+}
+
+// OrganizationStorageUsage is the total bytes an organization stores
+type OrganizationStorageUsage struct {
+	OrganizationID   string `db:"organization_id" json:"organization_id"`
+	OrganizationName string `db:"organization_name" json:"organization_name"`
+	Objects          int64  `db:"objects" json:"objects"`
+	Bytes            int64  `db:"bytes" json:"bytes"`
+	MaxBytes         *int64 `db:"max_bytes" json:"max_bytes,omitempty"` // Quota of the organization's own, if any
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Replication modes of a replicated storage configuration
+const (
+	ReplicationModeSync  = "sync"  // Uploads return once every replica has the object
+	ReplicationModeAsync = "async" // Secondaries are brought up to date by the replication queue
+)
+
+// Replication queue operations
+const (
+	ReplicationOpUpload = "upload"
+	ReplicationOpDelete = "delete"
+)
+
+// StorageReplicationQueueItem is a pending write or delete on one replica of a replicated configuration
+type StorageReplicationQueueItem struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	ConfigID      uuid.UUID `db:"config_id" json:"config_id"`
+	ReplicaID     uuid.UUID `db:"replica_id" json:"replica_id"`
+	Operation     string    `db:"operation" json:"operation"`
+	Path          string    `db:"path" json:"path"`
+	Attempts      int       `db:"attempts" json:"attempts"`
+	LastError     *string   `db:"last_error" json:"last_error,omitempty"`
+	NextAttemptAt time.Time `db:"next_attempt_at" json:"next_attempt_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// StorageReplicationStatus summarizes the replication queue of a replicated configuration
+type StorageReplicationStatus struct {
+	ConfigID uuid.UUID                      `json:"config_id"`
+	Pending  int                            `json:"pending"`
+	Failing  int                            `json:"failing"` // Items that have failed at least once
+	Items    []*StorageReplicationQueueItem `json:"items"`
+}
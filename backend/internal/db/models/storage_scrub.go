@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StorageScrubStatus tracks the lifecycle of a storage scrub
+type StorageScrubStatus string
+
+const (
+	StorageScrubRunning   StorageScrubStatus = "running"
+	StorageScrubCompleted StorageScrubStatus = "completed"
+	StorageScrubFailed    StorageScrubStatus = "failed" // Stopped early; counts cover the work done
+)
+
+// StorageIntegrityIssueKind classifies a problem found with an artifact's stored object
+type StorageIntegrityIssueKind string
+
+const (
+	StorageIssueMissing          StorageIntegrityIssueKind = "missing"
+	StorageIssueUnreadable       StorageIntegrityIssueKind = "unreadable"
+	StorageIssueSizeMismatch     StorageIntegrityIssueKind = "size_mismatch"
+	StorageIssueChecksumMismatch StorageIntegrityIssueKind = "checksum_mismatch"
+)
+
+// StorageOrphanStatus tracks what has been done with an unreferenced stored object
+type StorageOrphanStatus string
+
+const (
+	StorageOrphanPending     StorageOrphanStatus = "pending" // Within the grace period, or orphan_action is "report"
+	StorageOrphanQuarantined StorageOrphanStatus = "quarantined"
+	StorageOrphanDeleted     StorageOrphanStatus = "deleted"
+)
+
+// StorageScrubRun is one pass of the integrity scrubber over every stored artifact and object
+type StorageScrubRun struct {
+	ID                 uuid.UUID          `db:"id" json:"id"`
+	Status             StorageScrubStatus `db:"status" json:"status"`
+	ArtifactsChecked   int                `db:"artifacts_checked" json:"artifacts_checked"`
+	ArtifactsRehashed  int                `db:"artifacts_rehashed" json:"artifacts_rehashed"`
+	IssuesFound        int                `db:"issues_found" json:"issues_found"`
+	ObjectsScanned     int                `db:"objects_scanned" json:"objects_scanned"`
+	OrphansFound       int                `db:"orphans_found" json:"orphans_found"`
+	OrphansQuarantined int                `db:"orphans_quarantined" json:"orphans_quarantined"`
+	OrphansDeleted     int                `db:"orphans_deleted" json:"orphans_deleted"`
+	ErrorMessage       *string            `db:"error_message" json:"error_message,omitempty"`
+	StartedAt          time.Time          `db:"started_at" json:"started_at"`
+	CompletedAt        *time.Time         `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// StorageIntegrityIssue is a problem a scrub found with an artifact's stored object
+type StorageIntegrityIssue struct {
+	ID             uuid.UUID                 `db:"id" json:"id"`
+	RunID          uuid.UUID                 `db:"run_id" json:"run_id"`
+	ArtifactType   string                    `db:"artifact_type" json:"artifact_type"`
+	ArtifactID     uuid.UUID                 `db:"artifact_id" json:"artifact_id"`
+	StorageBackend string                    `db:"storage_backend" json:"storage_backend"`
+	StoragePath    string                    `db:"storage_path" json:"storage_path"`
+	Issue          StorageIntegrityIssueKind `db:"issue" json:"issue"`
+	Details        string                    `db:"details" json:"details"`
+	DetectedAt     time.Time                 `db:"detected_at" json:"detected_at"`
+}
+
+// StorageOrphan is a stored object that no artifact references
+type StorageOrphan struct {
+	ID             uuid.UUID           `db:"id" json:"id"`
+	StorageBackend string              `db:"storage_backend" json:"storage_backend"`
+	StoragePath    string              `db:"storage_path" json:"storage_path"`
+	SizeBytes      int64               `db:"size_bytes" json:"size_bytes"`
+	Status         StorageOrphanStatus `db:"status" json:"status"`
+	QuarantinePath *string             `db:"quarantine_path" json:"quarantine_path,omitempty"`
+	FirstSeenAt    time.Time           `db:"first_seen_at" json:"first_seen_at"`
+	LastSeenAt     time.Time           `db:"last_seen_at" json:"last_seen_at"`
+	ActedAt        *time.Time          `db:"acted_at" json:"acted_at,omitempty"`
+}
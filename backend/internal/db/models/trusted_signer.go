@@ -0,0 +1,85 @@
+package models
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrustedSignerType identifies how a trusted signer's signatures are verified
+type TrustedSignerType string
+
+const (
+	TrustedSignerPublicKey TrustedSignerType = "public_key" // Signatures verified against a PEM public key
+	TrustedSignerKeyless   TrustedSignerType = "keyless"    // Signatures verified against a certificate identity and trusted roots
+)
+
+// TrustedSigner is an identity or key whose cosign signatures and provenance attestations
+// are accepted for module versions
+type TrustedSigner struct {
+	ID             uuid.UUID  `db:"id" json:"id"`
+	OrganizationID *uuid.UUID `db:"organization_id" json:"organization_id,omitempty"` // NULL = global signer
+	Name           string     `db:"name" json:"name"`
+	Description    *string    `db:"description" json:"description,omitempty"`
+
+	SignerType          TrustedSignerType `db:"signer_type" json:"signer_type"`
+	PublicKey           *string           `db:"public_key" json:"public_key,omitempty"`
+	CertificateIdentity *string           `db:"certificate_identity" json:"certificate_identity,omitempty"` // Regex matched against certificate SANs
+	CertificateIssuer   *string           `db:"certificate_issuer" json:"certificate_issuer,omitempty"`
+	RootCertificates    *string           `db:"root_certificates" json:"root_certificates,omitempty"`
+	BuilderIDPattern    *string           `db:"builder_id_pattern" json:"builder_id_pattern,omitempty"` // Regex the SLSA builder ID must match
+
+	// What this signer may sign for (supports wildcards)
+	NamespacePattern *string `db:"namespace_pattern" json:"namespace_pattern,omitempty"` // NULL = all namespaces
+
+	IsActive bool `db:"is_active" json:"is_active"`
+
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+	CreatedBy *uuid.UUID `db:"created_by" json:"created_by,omitempty"`
+}
+
+// Matches checks if this signer is trusted for the given module namespace
+func (s *TrustedSigner) Matches(namespace string) bool {
+	if s.NamespacePattern == nil || *s.NamespacePattern == "" || *s.NamespacePattern == "*" {
+		return true
+	}
+	matched, _ := filepath.Match(*s.NamespacePattern, namespace)
+	return matched
+}
+
+// AttestationStatus summarizes the verification of a module version's signature or provenance
+type AttestationStatus string
+
+const (
+	AttestationStatusNone       AttestationStatus = "none"       // Nothing was uploaded
+	AttestationStatusVerified   AttestationStatus = "verified"   // Verified against a trusted signer
+	AttestationStatusUnverified AttestationStatus = "unverified" // Uploaded but not verified by any trusted signer
+)
+
+// AttestationType distinguishes signatures from provenance attestations
+type AttestationType string
+
+const (
+	AttestationTypeSignature  AttestationType = "signature"
+	AttestationTypeProvenance AttestationType = "provenance"
+)
+
+// ModuleVersionAttestation is a signature or provenance attestation uploaded with a module version
+type ModuleVersionAttestation struct {
+	ID              uuid.UUID       `db:"id" json:"id"`
+	ModuleVersionID uuid.UUID       `db:"module_version_id" json:"module_version_id"`
+	AttestationType AttestationType `db:"attestation_type" json:"attestation_type"`
+	Content         string          `db:"content" json:"content"`
+	Certificate     *string         `db:"certificate" json:"certificate,omitempty"`
+
+	Verified          bool       `db:"verified" json:"verified"`
+	SignerID          *uuid.UUID `db:"signer_id" json:"signer_id,omitempty"`
+	SignerIdentity    *string    `db:"signer_identity" json:"signer_identity,omitempty"`
+	PredicateType     *string    `db:"predicate_type" json:"predicate_type,omitempty"`
+	BuilderID         *string    `db:"builder_id" json:"builder_id,omitempty"`
+	VerificationError *string    `db:"verification_error" json:"verification_error,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadSession is a resumable upload of a module archive or provider binary. Bytes are
+// appended to a temp file until UploadOffset reaches UploadLength, after which the upload can
+// be published through the regular module or provider upload endpoint.
+type UploadSession struct {
+	ID             uuid.UUID    `db:"id" json:"id"`
+	ArtifactType   ArtifactType `db:"artifact_type" json:"artifact_type"`
+	Filename       string       `db:"filename" json:"filename"`
+	UploadLength   int64        `db:"upload_length" json:"upload_length"`
+	UploadOffset   int64        `db:"upload_offset" json:"upload_offset"`
+	UploadMetadata string       `db:"upload_metadata" json:"-"` // Raw tus Upload-Metadata header
+	Owner          string       `db:"owner" json:"-"`
+	ExpiresAt      time.Time    `db:"expires_at" json:"expires_at"`
+	CreatedAt      time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time    `db:"updated_at" json:"updated_at"`
+}
+
+// Completed reports whether every byte of the upload has been received
+func (s *UploadSession) Completed() bool {
+	return s.UploadOffset == s.UploadLength
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// AdmissionWebhookRepository handles database operations for admission webhooks
+type AdmissionWebhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewAdmissionWebhookRepository creates a new admission webhook repository
+func NewAdmissionWebhookRepository(db *sqlx.DB) *AdmissionWebhookRepository {
+	return &AdmissionWebhookRepository{db: db}
+}
+
+const admissionWebhookColumns = `id, organization_id, name, description, url, artifact_type, timeout_seconds,
+		       failure_policy, is_active, created_at, updated_at, created_by`
+
+// Create creates a new admission webhook
+func (r *AdmissionWebhookRepository) Create(ctx context.Context, hook *models.AdmissionWebhook) error {
+	query := `
+		INSERT INTO admission_webhooks (
+			id, organization_id, name, description, url, artifact_type, timeout_seconds,
+			failure_policy, is_active, created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		hook.ID,
+		hook.OrganizationID,
+		hook.Name,
+		hook.Description,
+		hook.URL,
+		hook.ArtifactType,
+		hook.TimeoutSeconds,
+		hook.FailurePolicy,
+		hook.IsActive,
+		hook.CreatedAt,
+		hook.UpdatedAt,
+		hook.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create admission webhook: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an admission webhook by ID
+func (r *AdmissionWebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AdmissionWebhook, error) {
+	query := `SELECT ` + admissionWebhookColumns + ` FROM admission_webhooks WHERE id = $1`
+
+	var hook models.AdmissionWebhook
+	err := r.db.GetContext(ctx, &hook, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admission webhook: %w", err)
+	}
+
+	return &hook, nil
+}
+
+// List lists global admission webhooks and, when orgID is set, the organization's webhooks
+func (r *AdmissionWebhookRepository) List(ctx context.Context, orgID *uuid.UUID) ([]*models.AdmissionWebhook, error) {
+	query := `SELECT ` + admissionWebhookColumns + ` FROM admission_webhooks WHERE organization_id IS NULL`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY name`
+
+	var hooks []*models.AdmissionWebhook
+	if err := r.db.SelectContext(ctx, &hooks, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list admission webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// ListActive lists the active webhooks that apply to an organization and artifact type
+func (r *AdmissionWebhookRepository) ListActive(ctx context.Context, orgID *uuid.UUID, artifactType models.ArtifactType) ([]*models.AdmissionWebhook, error) {
+	query := `SELECT ` + admissionWebhookColumns + `
+		FROM admission_webhooks
+		WHERE is_active = true AND artifact_type = $1 AND (organization_id IS NULL OR organization_id = $2)
+		ORDER BY name`
+
+	var hooks []*models.AdmissionWebhook
+	if err := r.db.SelectContext(ctx, &hooks, query, artifactType, orgID); err != nil {
+		return nil, fmt.Errorf("failed to list active admission webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// Update updates an existing admission webhook
+func (r *AdmissionWebhookRepository) Update(ctx context.Context, hook *models.AdmissionWebhook) error {
+	query := `
+		UPDATE admission_webhooks
+		SET name = $2, description = $3, url = $4, artifact_type = $5, timeout_seconds = $6,
+		    failure_policy = $7, is_active = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	hook.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query,
+		hook.ID,
+		hook.Name,
+		hook.Description,
+		hook.URL,
+		hook.ArtifactType,
+		hook.TimeoutSeconds,
+		hook.FailurePolicy,
+		hook.IsActive,
+		hook.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update admission webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes an admission webhook
+func (r *AdmissionWebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM admission_webhooks WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete admission webhook: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// LicensePolicyRepository handles database operations for license policies
+type LicensePolicyRepository struct {
+	db *sqlx.DB
+}
+
+// NewLicensePolicyRepository creates a new license policy repository
+func NewLicensePolicyRepository(db *sqlx.DB) *LicensePolicyRepository {
+	return &LicensePolicyRepository{db: db}
+}
+
+const licensePolicyColumns = `id, organization_id, name, description, artifact_type, policy_type, licenses,
+		       allow_unknown, is_active, created_at, updated_at, created_by`
+
+// Create creates a new license policy
+func (r *LicensePolicyRepository) Create(ctx context.Context, policy *models.LicensePolicy) error {
+	query := `
+		INSERT INTO license_policies (
+			id, organization_id, name, description, artifact_type, policy_type, licenses,
+			allow_unknown, is_active, created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.OrganizationID,
+		policy.Name,
+		policy.Description,
+		policy.ArtifactType,
+		policy.PolicyType,
+		policy.Licenses,
+		policy.AllowUnknown,
+		policy.IsActive,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+		policy.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create license policy: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a license policy by ID
+func (r *LicensePolicyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.LicensePolicy, error) {
+	query := `SELECT ` + licensePolicyColumns + ` FROM license_policies WHERE id = $1`
+
+	var policy models.LicensePolicy
+	err := r.db.GetContext(ctx, &policy, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get license policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// List lists global license policies and, when orgID is set, the organization's policies
+func (r *LicensePolicyRepository) List(ctx context.Context, orgID *uuid.UUID) ([]*models.LicensePolicy, error) {
+	query := `SELECT ` + licensePolicyColumns + ` FROM license_policies WHERE organization_id IS NULL`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY name`
+
+	var policies []*models.LicensePolicy
+	if err := r.db.SelectContext(ctx, &policies, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list license policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// ListActive lists the active policies that apply to an organization and artifact type
+func (r *LicensePolicyRepository) ListActive(ctx context.Context, orgID *uuid.UUID, artifactType models.ArtifactType) ([]*models.LicensePolicy, error) {
+	query := `SELECT ` + licensePolicyColumns + `
+		FROM license_policies
+		WHERE is_active = true AND artifact_type = $1 AND (organization_id IS NULL OR organization_id = $2)
+		ORDER BY name`
+
+	var policies []*models.LicensePolicy
+	if err := r.db.SelectContext(ctx, &policies, query, artifactType, orgID); err != nil {
+		return nil, fmt.Errorf("failed to list active license policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Update updates an existing license policy
+func (r *LicensePolicyRepository) Update(ctx context.Context, policy *models.LicensePolicy) error {
+	query := `
+		UPDATE license_policies
+		SET name = $2, description = $3, artifact_type = $4, policy_type = $5, licenses = $6,
+		    allow_unknown = $7, is_active = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	policy.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query,
+		policy.ID,
+		policy.Name,
+		policy.Description,
+		policy.ArtifactType,
+		policy.PolicyType,
+		policy.Licenses,
+		policy.AllowUnknown,
+		policy.IsActive,
+		policy.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update license policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a license policy
+func (r *LicensePolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM license_policies WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete license policy: %w", err)
+	}
+	return nil
+}
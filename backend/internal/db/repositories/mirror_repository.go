@@ -28,8 +28,8 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 	query := `
 		INSERT INTO mirror_configurations (
 			id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
-			version_filter, platform_filter, enabled, sync_interval_hours, created_at, updated_at, created_by
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+			version_filter, platform_filter, enabled, sync_interval_hours, sync_concurrency, created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -44,6 +44,7 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 		config.PlatformFilter,
 		config.Enabled,
 		config.SyncIntervalHours,
+		config.SyncConcurrency,
 		config.CreatedAt,
 		config.UpdatedAt,
 		config.CreatedBy,
@@ -60,7 +61,7 @@ func (r *MirrorRepository) Create(ctx context.Context, config *models.MirrorConf
 func (r *MirrorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MirrorConfiguration, error) {
 	query := `
 		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
-		       version_filter, platform_filter, enabled, sync_interval_hours, last_sync_at, last_sync_status, last_sync_error,
+		       version_filter, platform_filter, enabled, sync_interval_hours, sync_concurrency, last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE id = $1
@@ -82,7 +83,7 @@ func (r *MirrorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.M
 func (r *MirrorRepository) GetByName(ctx context.Context, name string) (*models.MirrorConfiguration, error) {
 	query := `
 		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
-		       version_filter, platform_filter, enabled, sync_interval_hours, last_sync_at, last_sync_status, last_sync_error,
+		       version_filter, platform_filter, enabled, sync_interval_hours, sync_concurrency, last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE name = $1
@@ -104,7 +105,7 @@ func (r *MirrorRepository) GetByName(ctx context.Context, name string) (*models.
 func (r *MirrorRepository) List(ctx context.Context, enabledOnly bool) ([]models.MirrorConfiguration, error) {
 	query := `
 		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
-		       version_filter, platform_filter, enabled, sync_interval_hours, last_sync_at, last_sync_status, last_sync_error,
+		       version_filter, platform_filter, enabled, sync_interval_hours, sync_concurrency, last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 	`
@@ -132,7 +133,7 @@ func (r *MirrorRepository) Update(ctx context.Context, config *models.MirrorConf
 		UPDATE mirror_configurations
 		SET name = $2, description = $3, upstream_registry_url = $4, organization_id = $5,
 		    namespace_filter = $6, provider_filter = $7, version_filter = $8, platform_filter = $9,
-		    enabled = $10, sync_interval_hours = $11, updated_at = $12
+		    enabled = $10, sync_interval_hours = $11, sync_concurrency = $12, updated_at = $13
 		WHERE id = $1
 	`
 
@@ -148,6 +149,7 @@ func (r *MirrorRepository) Update(ctx context.Context, config *models.MirrorConf
 		config.PlatformFilter,
 		config.Enabled,
 		config.SyncIntervalHours,
+		config.SyncConcurrency,
 		config.UpdatedAt,
 	)
 
@@ -210,13 +212,14 @@ func (r *MirrorRepository) UpdateSyncStatus(ctx context.Context, id uuid.UUID, s
 func (r *MirrorRepository) GetMirrorsNeedingSync(ctx context.Context) ([]models.MirrorConfiguration, error) {
 	query := `
 		SELECT id, name, description, upstream_registry_url, organization_id, namespace_filter, provider_filter,
-		       version_filter, platform_filter, enabled, sync_interval_hours, last_sync_at, last_sync_status, last_sync_error,
+		       version_filter, platform_filter, enabled, sync_interval_hours, sync_concurrency, last_sync_at, last_sync_status, last_sync_error,
 		       created_at, updated_at, created_by
 		FROM mirror_configurations
 		WHERE enabled = true
 		  AND (
 		      last_sync_at IS NULL
 		      OR last_sync_at < NOW() - (sync_interval_hours || ' hours')::INTERVAL
+		      OR EXISTS (SELECT 1 FROM mirror_sync_checkpoints WHERE mirror_config_id = mirror_configurations.id)
 		  )
 		  AND (last_sync_status IS NULL OR last_sync_status != 'in_progress')
 		ORDER BY last_sync_at NULLS FIRST
@@ -325,6 +328,101 @@ func (r *MirrorRepository) GetActiveSyncHistory(ctx context.Context, mirrorConfi
 	return &history, nil
 }
 
+// MarkInterruptedSyncs fails the syncs left running by a previous run of the server, so their
+// mirrors are picked up again and resume from their checkpoints
+func (r *MirrorRepository) MarkInterruptedSyncs(ctx context.Context) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const message = "sync interrupted by a server restart"
+	result, err := tx.ExecContext(ctx, `
+		UPDATE mirror_sync_history
+		SET status = 'failed', completed_at = NOW(), error_message = $1
+		WHERE status = 'running'
+	`, message)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted sync history: %w", err)
+	}
+	interrupted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE mirror_configurations
+		SET last_sync_status = 'failed', last_sync_error = $1, updated_at = NOW()
+		WHERE last_sync_status = 'in_progress'
+	`, message); err != nil {
+		return 0, fmt.Errorf("failed to mark interrupted mirrors: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return interrupted, nil
+}
+
+// ListSyncCheckpoints retrieves the checkpoints recorded by the current sync of a mirror
+func (r *MirrorRepository) ListSyncCheckpoints(ctx context.Context, mirrorConfigID uuid.UUID) ([]models.MirrorSyncCheckpoint, error) {
+	query := `
+		SELECT mirror_config_id, namespace, provider_type, version, os, arch, status, error_message, updated_at
+		FROM mirror_sync_checkpoints
+		WHERE mirror_config_id = $1
+	`
+
+	var checkpoints []models.MirrorSyncCheckpoint
+	err := r.db.SelectContext(ctx, &checkpoints, query, mirrorConfigID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// SaveSyncCheckpoint records the status of a provider, version or platform in the current sync of a mirror
+func (r *MirrorRepository) SaveSyncCheckpoint(ctx context.Context, checkpoint *models.MirrorSyncCheckpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO mirror_sync_checkpoints (
+			mirror_config_id, namespace, provider_type, version, os, arch, status, error_message, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (mirror_config_id, namespace, provider_type, version, os, arch)
+		DO UPDATE SET status = EXCLUDED.status, error_message = EXCLUDED.error_message, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		checkpoint.MirrorConfigID,
+		checkpoint.Namespace,
+		checkpoint.ProviderType,
+		checkpoint.Version,
+		checkpoint.OS,
+		checkpoint.Arch,
+		checkpoint.Status,
+		checkpoint.ErrorMessage,
+		checkpoint.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save sync checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ClearSyncCheckpoints removes the checkpoints of a mirror once its sync has finished
+func (r *MirrorRepository) ClearSyncCheckpoints(ctx context.Context, mirrorConfigID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM mirror_sync_checkpoints WHERE mirror_config_id = $1`, mirrorConfigID)
+	if err != nil {
+		return fmt.Errorf("failed to clear sync checkpoints: %w", err)
+	}
+
+	return nil
+}
 
 // Helper function to convert JSON string to string array
 func jsonToStringArray(jsonStr *string) ([]string, error) {
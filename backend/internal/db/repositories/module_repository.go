@@ -78,6 +78,41 @@ func (r *ModuleRepository) GetModule(ctx context.Context, orgID, namespace, name
 	return module, nil
 }
 
+// GetModuleByID retrieves a module by its ID
+func (r *ModuleRepository) GetModuleByID(ctx context.Context, id string) (*models.Module, error) {
+	query := `
+		SELECT m.id, m.organization_id, m.namespace, m.name, m.system, m.description, m.source,
+		       m.created_by, m.created_at, m.updated_at, u.name as created_by_name
+		FROM modules m
+		LEFT JOIN users u ON m.created_by = u.id
+		WHERE m.id = $1
+	`
+
+	module := &models.Module{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&module.ID,
+		&module.OrganizationID,
+		&module.Namespace,
+		&module.Name,
+		&module.System,
+		&module.Description,
+		&module.Source,
+		&module.CreatedBy,
+		&module.CreatedAt,
+		&module.UpdatedAt,
+		&module.CreatedByName,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get module: %w", err)
+	}
+
+	return module, nil
+}
+
 // UpdateModule updates an existing module's metadata
 func (r *ModuleRepository) UpdateModule(ctx context.Context, module *models.Module) error {
 	query := `
@@ -103,8 +138,9 @@ func (r *ModuleRepository) UpdateModule(ctx context.Context, module *models.Modu
 // CreateVersion inserts a new module version
 func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.ModuleVersion) error {
 	query := `
-		INSERT INTO module_versions (module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO module_versions (module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by, license_spdx,
+		                             signature_status, provenance_status, oci_zip_digest, oci_zip_size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at
 	`
 
@@ -117,6 +153,11 @@ func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.Mo
 		version.Checksum,
 		version.Readme,
 		version.PublishedBy,
+		version.LicenseSPDX,
+		attestationStatusOrNone(version.SignatureStatus),
+		attestationStatusOrNone(version.ProvenanceStatus),
+		version.OCIZipDigest,
+		version.OCIZipSize,
 	).Scan(&version.ID, &version.CreatedAt)
 
 	if err != nil {
@@ -130,7 +171,8 @@ func (r *ModuleRepository) CreateVersion(ctx context.Context, version *models.Mo
 func (r *ModuleRepository) GetVersion(ctx context.Context, moduleID, version string) (*models.ModuleVersion, error) {
 	query := `
 		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by, download_count,
-		       COALESCE(deprecated, false), deprecated_at, deprecation_message, created_at
+		       COALESCE(deprecated, false), deprecated_at, deprecation_message, license_spdx,
+		       signature_status, provenance_status, oci_zip_digest, oci_zip_size, created_at
 		FROM module_versions
 		WHERE module_id = $1 AND version = $2
 	`
@@ -150,6 +192,11 @@ func (r *ModuleRepository) GetVersion(ctx context.Context, moduleID, version str
 		&v.Deprecated,
 		&v.DeprecatedAt,
 		&v.DeprecationMessage,
+		&v.LicenseSPDX,
+		&v.SignatureStatus,
+		&v.ProvenanceStatus,
+		&v.OCIZipDigest,
+		&v.OCIZipSize,
 		&v.CreatedAt,
 	)
 
@@ -168,7 +215,8 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 	query := `
 		SELECT mv.id, mv.module_id, mv.version, mv.storage_path, mv.storage_backend, mv.size_bytes, mv.checksum, mv.readme,
 		       mv.published_by, u.name as published_by_name, mv.download_count,
-		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.created_at
+		       COALESCE(mv.deprecated, false), mv.deprecated_at, mv.deprecation_message, mv.license_spdx,
+		       mv.signature_status, mv.provenance_status, mv.oci_zip_digest, mv.oci_zip_size, mv.created_at
 		FROM module_versions mv
 		LEFT JOIN users u ON mv.published_by = u.id
 		WHERE mv.module_id = $1
@@ -199,6 +247,11 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 			&v.Deprecated,
 			&v.DeprecatedAt,
 			&v.DeprecationMessage,
+			&v.LicenseSPDX,
+			&v.SignatureStatus,
+			&v.ProvenanceStatus,
+			&v.OCIZipDigest,
+			&v.OCIZipSize,
 			&v.CreatedAt,
 		)
 		if err != nil {
@@ -214,6 +267,68 @@ func (r *ModuleRepository) ListVersions(ctx context.Context, moduleID string) ([
 	return versions, nil
 }
 
+// GetVersionByOCIZipDigest retrieves the version of a module whose archive converts to the
+// OCI zip with digest, or nil if no version has recorded it
+func (r *ModuleRepository) GetVersionByOCIZipDigest(ctx context.Context, moduleID, digest string) (*models.ModuleVersion, error) {
+	query := `
+		SELECT id, module_id, version, storage_path, storage_backend, size_bytes, checksum, readme, published_by, download_count,
+		       COALESCE(deprecated, false), deprecated_at, deprecation_message, license_spdx,
+		       signature_status, provenance_status, oci_zip_digest, oci_zip_size, created_at
+		FROM module_versions
+		WHERE module_id = $1 AND oci_zip_digest = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	v := &models.ModuleVersion{}
+	err := r.db.QueryRowContext(ctx, query, moduleID, digest).Scan(
+		&v.ID,
+		&v.ModuleID,
+		&v.Version,
+		&v.StoragePath,
+		&v.StorageBackend,
+		&v.SizeBytes,
+		&v.Checksum,
+		&v.Readme,
+		&v.PublishedBy,
+		&v.DownloadCount,
+		&v.Deprecated,
+		&v.DeprecatedAt,
+		&v.DeprecationMessage,
+		&v.LicenseSPDX,
+		&v.SignatureStatus,
+		&v.ProvenanceStatus,
+		&v.OCIZipDigest,
+		&v.OCIZipSize,
+		&v.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get module version by OCI zip digest: %w", err)
+	}
+
+	return v, nil
+}
+
+// SetOCIZip records the digest and size of the OCI zip a version's archive converts to
+func (r *ModuleRepository) SetOCIZip(ctx context.Context, versionID, digest string, size int64) error {
+	query := `
+		UPDATE module_versions
+		SET oci_zip_digest = $2, oci_zip_size = $3
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, versionID, digest, size)
+	if err != nil {
+		return fmt.Errorf("failed to record module OCI zip: %w", err)
+	}
+
+	return nil
+}
+
 // IncrementDownloadCount increments the download counter for a version
 func (r *ModuleRepository) IncrementDownloadCount(ctx context.Context, versionID string) error {
 	query := `
@@ -411,3 +526,76 @@ func (r *ModuleRepository) UndeprecateVersion(ctx context.Context, versionID str
 
 	return nil
 }
+
+// CreateSecretFindings records secret scan findings for a module version
+func (r *ModuleRepository) CreateSecretFindings(ctx context.Context, findings []*models.ModuleVersionSecretFinding) error {
+	query := `
+		INSERT INTO module_version_secret_findings (module_version_id, rule, file_path, line_number, description, redacted_match)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	for _, f := range findings {
+		err := r.db.QueryRowContext(ctx, query,
+			f.ModuleVersionID,
+			f.Rule,
+			f.FilePath,
+			f.LineNumber,
+			f.Description,
+			f.RedactedMatch,
+		).Scan(&f.ID, &f.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create secret finding: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListSecretFindings retrieves the secret scan findings recorded for a module version
+func (r *ModuleRepository) ListSecretFindings(ctx context.Context, versionID string) ([]*models.ModuleVersionSecretFinding, error) {
+	query := `
+		SELECT id, module_version_id, rule, file_path, line_number, description, redacted_match, created_at
+		FROM module_version_secret_findings
+		WHERE module_version_id = $1
+		ORDER BY file_path, line_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secret findings: %w", err)
+	}
+	defer rows.Close()
+
+	var findings []*models.ModuleVersionSecretFinding
+	for rows.Next() {
+		f := &models.ModuleVersionSecretFinding{}
+		if err := rows.Scan(
+			&f.ID,
+			&f.ModuleVersionID,
+			&f.Rule,
+			&f.FilePath,
+			&f.LineNumber,
+			&f.Description,
+			&f.RedactedMatch,
+			&f.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan secret finding: %w", err)
+		}
+		findings = append(findings, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating secret findings: %w", err)
+	}
+
+	return findings, nil
+}
+
+// attestationStatusOrNone defaults an unset verification status to "none"
+func attestationStatusOrNone(status string) string {
+	if status == "" {
+		return string(models.AttestationStatusNone)
+	}
+	return status
+}
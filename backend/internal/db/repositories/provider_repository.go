@@ -199,8 +199,8 @@ func (r *ProviderRepository) CreateVersion(ctx context.Context, version *models.
 	}
 
 	query := `
-		INSERT INTO provider_versions (provider_id, version, protocols, gpg_public_key, shasums_url, shasums_signature_url, published_by)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO provider_versions (provider_id, version, protocols, gpg_public_key, shasums_url, shasums_signature_url, published_by, license_spdx)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`
 
@@ -212,6 +212,7 @@ func (r *ProviderRepository) CreateVersion(ctx context.Context, version *models.
 		version.ShasumURL,
 		version.ShasumSignatureURL,
 		version.PublishedBy,
+		version.LicenseSPDX,
 	).Scan(&version.ID, &version.CreatedAt)
 
 	if err != nil {
@@ -225,7 +226,7 @@ func (r *ProviderRepository) CreateVersion(ctx context.Context, version *models.
 func (r *ProviderRepository) GetVersion(ctx context.Context, providerID, version string) (*models.ProviderVersion, error) {
 	query := `
 		SELECT id, provider_id, version, protocols, gpg_public_key, shasums_url, shasums_signature_url, published_by,
-		       COALESCE(deprecated, false), deprecated_at, deprecation_message, created_at
+		       COALESCE(deprecated, false), deprecated_at, deprecation_message, license_spdx, created_at
 		FROM provider_versions
 		WHERE provider_id = $1 AND version = $2
 	`
@@ -245,6 +246,7 @@ func (r *ProviderRepository) GetVersion(ctx context.Context, providerID, version
 		&v.Deprecated,
 		&v.DeprecatedAt,
 		&v.DeprecationMessage,
+		&v.LicenseSPDX,
 		&v.CreatedAt,
 	)
 
@@ -268,7 +270,7 @@ func (r *ProviderRepository) ListVersions(ctx context.Context, providerID string
 	query := `
 		SELECT pv.id, pv.provider_id, pv.version, pv.protocols, pv.gpg_public_key, pv.shasums_url, pv.shasums_signature_url,
 		       pv.published_by, u.name as published_by_name,
-		       COALESCE(pv.deprecated, false), pv.deprecated_at, pv.deprecation_message, pv.created_at
+		       COALESCE(pv.deprecated, false), pv.deprecated_at, pv.deprecation_message, pv.license_spdx, pv.created_at
 		FROM provider_versions pv
 		LEFT JOIN users u ON pv.published_by = u.id
 		WHERE pv.provider_id = $1
@@ -298,6 +300,7 @@ func (r *ProviderRepository) ListVersions(ctx context.Context, providerID string
 			&v.Deprecated,
 			&v.DeprecatedAt,
 			&v.DeprecationMessage,
+			&v.LicenseSPDX,
 			&v.CreatedAt,
 		)
 		if err != nil {
@@ -395,6 +398,17 @@ func (r *ProviderRepository) UndeprecateVersion(ctx context.Context, versionID s
 	return nil
 }
 
+// SetVersionLicense records the SPDX license identifier detected for a provider version
+func (r *ProviderRepository) SetVersionLicense(ctx context.Context, versionID string, spdx *string) error {
+	query := `UPDATE provider_versions SET license_spdx = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, versionID, spdx); err != nil {
+		return fmt.Errorf("failed to set provider version license: %w", err)
+	}
+
+	return nil
+}
+
 // CreatePlatform inserts a new platform binary record
 func (r *ProviderRepository) CreatePlatform(ctx context.Context, platform *models.ProviderPlatform) error {
 	query := `
@@ -453,6 +467,41 @@ func (r *ProviderRepository) GetPlatform(ctx context.Context, versionID, os, arc
 	return platform, nil
 }
 
+// GetPlatformByShasum retrieves a platform binary of a provider by its SHA256 checksum
+func (r *ProviderRepository) GetPlatformByShasum(ctx context.Context, providerID, shasum string) (*models.ProviderPlatform, error) {
+	query := `
+		SELECT pp.id, pp.provider_version_id, pp.os, pp.arch, pp.filename, pp.storage_path, pp.storage_backend,
+		       pp.size_bytes, pp.shasum, pp.download_count
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		WHERE pv.provider_id = $1 AND pp.shasum = $2
+		LIMIT 1
+	`
+
+	platform := &models.ProviderPlatform{}
+	err := r.db.QueryRowContext(ctx, query, providerID, shasum).Scan(
+		&platform.ID,
+		&platform.ProviderVersionID,
+		&platform.OS,
+		&platform.Arch,
+		&platform.Filename,
+		&platform.StoragePath,
+		&platform.StorageBackend,
+		&platform.SizeBytes,
+		&platform.Shasum,
+		&platform.DownloadCount,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get provider platform: %w", err)
+	}
+
+	return platform, nil
+}
+
 // ListPlatforms retrieves all platform binaries for a provider version
 func (r *ProviderRepository) ListPlatforms(ctx context.Context, versionID string) ([]*models.ProviderPlatform, error) {
 	query := `
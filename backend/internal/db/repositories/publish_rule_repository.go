@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// PublishRuleRepository handles database operations for module publish rules
+type PublishRuleRepository struct {
+	db *sqlx.DB
+}
+
+// NewPublishRuleRepository creates a new publish rule repository
+func NewPublishRuleRepository(db *sqlx.DB) *PublishRuleRepository {
+	return &PublishRuleRepository{db: db}
+}
+
+const publishRuleColumns = `id, organization_id, name, description, rule_type, enforcement, parameter,
+		       namespace_pattern, is_active, created_at, updated_at, created_by`
+
+// CreateRule creates a new publish rule
+func (r *PublishRuleRepository) CreateRule(ctx context.Context, rule *models.ModulePublishRule) error {
+	query := `
+		INSERT INTO module_publish_rules (
+			id, organization_id, name, description, rule_type, enforcement, parameter,
+			namespace_pattern, is_active, created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.OrganizationID,
+		rule.Name,
+		rule.Description,
+		rule.RuleType,
+		rule.Enforcement,
+		rule.Parameter,
+		rule.NamespacePattern,
+		rule.IsActive,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+		rule.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create publish rule: %w", err)
+	}
+
+	return nil
+}
+
+// GetRule retrieves a publish rule by ID
+func (r *PublishRuleRepository) GetRule(ctx context.Context, id uuid.UUID) (*models.ModulePublishRule, error) {
+	query := `SELECT ` + publishRuleColumns + ` FROM module_publish_rules WHERE id = $1`
+
+	var rule models.ModulePublishRule
+	err := r.db.GetContext(ctx, &rule, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get publish rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// ListRules lists global publish rules and, when orgID is set, the organization's rules
+func (r *PublishRuleRepository) ListRules(ctx context.Context, orgID *uuid.UUID) ([]*models.ModulePublishRule, error) {
+	query := `SELECT ` + publishRuleColumns + ` FROM module_publish_rules WHERE organization_id IS NULL`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY name`
+
+	var rules []*models.ModulePublishRule
+	if err := r.db.SelectContext(ctx, &rules, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list publish rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// UpdateRule updates an existing publish rule
+func (r *PublishRuleRepository) UpdateRule(ctx context.Context, rule *models.ModulePublishRule) error {
+	query := `
+		UPDATE module_publish_rules
+		SET name = $2, description = $3, rule_type = $4, enforcement = $5, parameter = $6,
+		    namespace_pattern = $7, is_active = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	rule.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Description,
+		rule.RuleType,
+		rule.Enforcement,
+		rule.Parameter,
+		rule.NamespacePattern,
+		rule.IsActive,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update publish rule: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteRule deletes a publish rule
+func (r *PublishRuleRepository) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM module_publish_rules WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete publish rule: %w", err)
+	}
+	return nil
+}
+
+// CreateVersionResults stores the publish rule results for a module version
+func (r *PublishRuleRepository) CreateVersionResults(ctx context.Context, results []*models.ModuleVersionPolicyResult) error {
+	query := `
+		INSERT INTO module_version_policy_results (
+			id, module_version_id, rule_id, rule_name, rule_type, enforcement, passed, message, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	for _, result := range results {
+		if result.ID == uuid.Nil {
+			result.ID = uuid.New()
+		}
+		if result.CreatedAt.IsZero() {
+			result.CreatedAt = time.Now()
+		}
+		_, err := r.db.ExecContext(ctx, query,
+			result.ID,
+			result.ModuleVersionID,
+			result.RuleID,
+			result.RuleName,
+			result.RuleType,
+			result.Enforcement,
+			result.Passed,
+			result.Message,
+			result.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create policy result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListVersionResults retrieves the publish rule results recorded for a module version
+func (r *PublishRuleRepository) ListVersionResults(ctx context.Context, moduleVersionID uuid.UUID) ([]*models.ModuleVersionPolicyResult, error) {
+	query := `
+		SELECT id, module_version_id, rule_id, rule_name, rule_type, enforcement, passed, message, created_at
+		FROM module_version_policy_results
+		WHERE module_version_id = $1
+		ORDER BY passed, rule_name
+	`
+
+	var results []*models.ModuleVersionPolicyResult
+	if err := r.db.SelectContext(ctx, &results, query, moduleVersionID); err != nil {
+		return nil, fmt.Errorf("failed to list policy results: %w", err)
+	}
+
+	return results, nil
+}
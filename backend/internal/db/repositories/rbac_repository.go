@@ -262,6 +262,30 @@ func (r *RBACRepository) CheckApproval(ctx context.Context, mirrorConfigID uuid.
 	return &req, err
 }
 
+// GetLatestApprovalRequest retrieves the most recent approval request, in any status, covering a
+// provider of a mirror
+func (r *RBACRepository) GetLatestApprovalRequest(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error) {
+	query := `SELECT id, mirror_config_id, organization_id, requested_by, provider_namespace, provider_name,
+			  reason, status, reviewed_by, reviewed_at, review_notes, auto_approved, created_at, updated_at, expires_at
+			  FROM mirror_approval_requests
+			  WHERE mirror_config_id = $1
+			    AND provider_namespace = $2
+			    AND (provider_name IS NULL OR provider_name = $3)
+			  ORDER BY created_at DESC
+			  LIMIT 1`
+
+	var req models.MirrorApprovalRequest
+	err := r.db.QueryRowxContext(ctx, query, mirrorConfigID, namespace, provider).Scan(
+		&req.ID, &req.MirrorConfigID, &req.OrganizationID, &req.RequestedBy,
+		&req.ProviderNamespace, &req.ProviderName, &req.Reason, &req.Status,
+		&req.ReviewedBy, &req.ReviewedAt, &req.ReviewNotes, &req.AutoApproved,
+		&req.CreatedAt, &req.UpdatedAt, &req.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &req, err
+}
+
 // ============================================================================
 // Mirror Policies
 // ============================================================================
@@ -374,33 +398,6 @@ func (r *RBACRepository) EvaluatePolicies(ctx context.Context, orgID *uuid.UUID,
 		return nil, err
 	}
 
-	// Default: deny if no policies match
-	result := &models.PolicyEvaluationResult{
-		Allowed:          false,
-		RequiresApproval: false,
-		Reason:           "No matching policy found",
-	}
-
-	// Evaluate policies in priority order (highest first)
-	for _, policy := range policies {
-		if !policy.IsActive {
-			continue
-		}
-
-		if policy.Matches(registry, namespace, provider) {
-			result.MatchedPolicy = policy
-			result.RequiresApproval = policy.RequiresApproval
-
-			if policy.PolicyType == models.PolicyTypeAllow {
-				result.Allowed = true
-				result.Reason = "Allowed by policy: " + policy.Name
-			} else {
-				result.Allowed = false
-				result.Reason = "Denied by policy: " + policy.Name
-			}
-			return result, nil
-		}
-	}
-
-	return result, nil
+	// Policies are listed in priority order (highest first)
+	return models.EvaluateMirrorPolicies(policies, registry, namespace, provider), nil
 }
@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// SigningKeyRepository handles database operations for registry signing keys and module manifests
+type SigningKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewSigningKeyRepository creates a new signing key repository
+func NewSigningKeyRepository(db *sqlx.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: db}
+}
+
+const signingKeyColumns = `id, organization_id, key_id, algorithm, public_key, private_key_encrypted, status,
+		       created_at, retired_at`
+
+// CreateKey stores a new signing key. When the key is active, any previously active key
+// for the organization is retired in the same transaction.
+func (r *SigningKeyRepository) CreateKey(ctx context.Context, key *models.RegistrySigningKey) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if key.Status == models.SigningKeyActive {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE registry_signing_keys SET status = $1, retired_at = $2 WHERE organization_id = $3 AND status = $4`,
+			models.SigningKeyRetired, time.Now(), key.OrganizationID, models.SigningKeyActive,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to retire signing keys: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO registry_signing_keys (
+			id, organization_id, key_id, algorithm, public_key, private_key_encrypted, status, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		key.ID,
+		key.OrganizationID,
+		key.KeyID,
+		key.Algorithm,
+		key.PublicKey,
+		key.PrivateKeyEncrypted,
+		key.Status,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveKey retrieves the organization's active signing key
+func (r *SigningKeyRepository) GetActiveKey(ctx context.Context, orgID uuid.UUID) (*models.RegistrySigningKey, error) {
+	query := `SELECT ` + signingKeyColumns + ` FROM registry_signing_keys WHERE organization_id = $1 AND status = $2`
+
+	var key models.RegistrySigningKey
+	err := r.db.GetContext(ctx, &key, query, orgID, models.SigningKeyActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active signing key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// GetKey retrieves a signing key by its key ID (fingerprint)
+func (r *SigningKeyRepository) GetKey(ctx context.Context, keyID string) (*models.RegistrySigningKey, error) {
+	query := `SELECT ` + signingKeyColumns + ` FROM registry_signing_keys WHERE key_id = $1`
+
+	var key models.RegistrySigningKey
+	err := r.db.GetContext(ctx, &key, query, keyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// ListKeys lists an organization's signing keys, newest first
+func (r *SigningKeyRepository) ListKeys(ctx context.Context, orgID uuid.UUID) ([]*models.RegistrySigningKey, error) {
+	query := `SELECT ` + signingKeyColumns + `
+		FROM registry_signing_keys
+		WHERE organization_id = $1
+		ORDER BY created_at DESC`
+
+	var keys []*models.RegistrySigningKey
+	if err := r.db.SelectContext(ctx, &keys, query, orgID); err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// SaveManifest stores the signed manifest of a module version, replacing any earlier one
+func (r *SigningKeyRepository) SaveManifest(ctx context.Context, manifest *models.ModuleVersionManifest) error {
+	query := `
+		INSERT INTO module_version_manifests (module_version_id, key_id, payload, signature, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (module_version_id) DO UPDATE
+		SET key_id = EXCLUDED.key_id, payload = EXCLUDED.payload,
+		    signature = EXCLUDED.signature, created_at = EXCLUDED.created_at
+	`
+
+	if manifest.CreatedAt.IsZero() {
+		manifest.CreatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, query,
+		manifest.ModuleVersionID,
+		manifest.KeyID,
+		manifest.Payload,
+		manifest.Signature,
+		manifest.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save module manifest: %w", err)
+	}
+
+	return nil
+}
+
+// GetManifest retrieves the signed manifest of a module version
+func (r *SigningKeyRepository) GetManifest(ctx context.Context, moduleVersionID uuid.UUID) (*models.ModuleVersionManifest, error) {
+	query := `
+		SELECT module_version_id, key_id, payload, signature, created_at
+		FROM module_version_manifests
+		WHERE module_version_id = $1
+	`
+
+	var manifest models.ModuleVersionManifest
+	err := r.db.GetContext(ctx, &manifest, query, moduleVersionID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module manifest: %w", err)
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// StorageBlobRepository handles database operations for content-addressed storage blobs
+type StorageBlobRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageBlobRepository creates a new storage blob repository
+func NewStorageBlobRepository(db *sqlx.DB) *StorageBlobRepository {
+	return &StorageBlobRepository{db: db}
+}
+
+// AcquireBlob records a new reference to a blob and returns its reference count. A count of 1
+// means the blob was not referenced before and its object may still need to be written.
+func (r *StorageBlobRepository) AcquireBlob(ctx context.Context, backend, digest string, size int64) (int, error) {
+	query := `
+		INSERT INTO storage_blobs (backend, digest, size_bytes, ref_count, created_at, updated_at)
+		VALUES ($1, $2, $3, 1, $4, $4)
+		ON CONFLICT (backend, digest) DO UPDATE
+		SET ref_count = storage_blobs.ref_count + 1, updated_at = EXCLUDED.updated_at
+		RETURNING ref_count
+	`
+
+	var refCount int
+	if err := r.db.GetContext(ctx, &refCount, query, backend, digest, size, time.Now()); err != nil {
+		return 0, fmt.Errorf("failed to acquire storage blob: %w", err)
+	}
+
+	return refCount, nil
+}
+
+// ReleaseBlob drops one reference to a blob. When none remain, deleteObject is called while
+// the transaction holds the blob's row lock, so a concurrent AcquireBlob of the same digest
+// waits until the object is gone and then starts a new blob. The row is removed once the object
+// is deleted; if that fails, the row is kept unreferenced for the storage scrub to collect.
+// Unknown blobs are left alone.
+func (r *StorageBlobRepository) ReleaseBlob(ctx context.Context, backend, digest string, deleteObject func() error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var refCount int
+	err = tx.GetContext(ctx, &refCount,
+		`UPDATE storage_blobs SET ref_count = ref_count - 1, updated_at = $3
+		 WHERE backend = $1 AND digest = $2 AND ref_count > 0
+		 RETURNING ref_count`,
+		backend, digest, time.Now(),
+	)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to release storage blob: %w", err)
+	}
+
+	var deleteErr error
+	if refCount == 0 {
+		if deleteErr = deleteObject(); deleteErr == nil {
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM storage_blobs WHERE backend = $1 AND digest = $2 AND ref_count = 0`,
+				backend, digest,
+			); err != nil {
+				return fmt.Errorf("failed to remove storage blob: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return deleteErr
+}
+
+// DeleteBlob removes a blob's row whatever its reference count, once its object is gone
+func (r *StorageBlobRepository) DeleteBlob(ctx context.Context, backend, digest string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM storage_blobs WHERE backend = $1 AND digest = $2`, backend, digest)
+	if err != nil {
+		return fmt.Errorf("failed to delete storage blob: %w", err)
+	}
+	return nil
+}
+
+// ListPathBasedArtifacts retrieves up to limit artifacts stored on one of backends whose path
+// is not under blobPrefix, ordered by ID and starting after afterID
+func (r *StorageBlobRepository) ListPathBasedArtifacts(ctx context.Context, backends []string, blobPrefix string, afterID uuid.UUID, limit int) ([]*models.StoredArtifact, error) {
+	query := `
+		SELECT artifact_type, artifact_id, storage_backend, storage_path, checksum, size_bytes FROM (
+			SELECT 'module_version' AS artifact_type, id AS artifact_id, storage_backend, storage_path,
+			       checksum, size_bytes
+			FROM module_versions
+			UNION ALL
+			SELECT 'provider_platform', id, storage_backend, storage_path, shasum, size_bytes
+			FROM provider_platforms
+		) artifacts
+		WHERE storage_backend = ANY($1) AND storage_path NOT LIKE $2 || '%' AND artifact_id > $3
+		ORDER BY artifact_id
+		LIMIT $4
+	`
+
+	var artifacts []*models.StoredArtifact
+	if err := r.db.SelectContext(ctx, &artifacts, query, pq.Array(backends), blobPrefix, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list path-based artifacts: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+// UpdateArtifactPath points an artifact at a new path on the same backend, provided it still
+// references oldPath. It reports whether the artifact was updated.
+func (r *StorageBlobRepository) UpdateArtifactPath(ctx context.Context, artifact *models.StoredArtifact, newPath string) (bool, error) {
+	table, err := artifactTable(artifact.ArtifactType)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE `+table+` SET storage_path = $1 WHERE id = $2 AND storage_backend = $3 AND storage_path = $4`,
+		newPath, artifact.ArtifactID, artifact.StorageBackend, artifact.StoragePath,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update artifact storage path: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// CountPathReferences counts the artifacts on one of backends stored at path
+func (r *StorageBlobRepository) CountPathReferences(ctx context.Context, backends []string, path string) (int, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM module_versions WHERE storage_backend = ANY($1) AND storage_path = $2)
+		     + (SELECT COUNT(*) FROM provider_platforms WHERE storage_backend = ANY($1) AND storage_path = $2)
+	`
+
+	var count int
+	if err := r.db.GetContext(ctx, &count, query, pq.Array(backends), path); err != nil {
+		return 0, fmt.Errorf("failed to count artifacts stored at path: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetStats summarizes the blobs of a backend and the artifacts on backends still stored by path
+func (r *StorageBlobRepository) GetStats(ctx context.Context, backend string, backends []string, blobPrefix string) (*models.ContentAddressedStats, error) {
+	query := `
+		SELECT COUNT(*) AS blobs,
+		       COALESCE(SUM(ref_count), 0) AS reference_count,
+		       COALESCE(SUM(size_bytes), 0) AS stored_bytes,
+		       COALESCE(SUM(size_bytes * ref_count), 0) AS logical_bytes,
+		       (SELECT COUNT(*) FROM module_versions
+		        WHERE storage_backend = ANY($2) AND storage_path NOT LIKE $3 || '%')
+		     + (SELECT COUNT(*) FROM provider_platforms
+		        WHERE storage_backend = ANY($2) AND storage_path NOT LIKE $3 || '%') AS path_based_artifacts
+		FROM storage_blobs
+		WHERE backend = $1
+	`
+
+	var stats models.ContentAddressedStats
+	if err := r.db.GetContext(ctx, &stats, query, backend, pq.Array(backends), blobPrefix); err != nil {
+		return nil, fmt.Errorf("failed to get content-addressed storage stats: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -72,6 +72,7 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 			s3_role_arn, s3_role_session_name, s3_external_id, s3_web_identity_token_file,
 			gcs_bucket, gcs_project_id, gcs_auth_method, gcs_credentials_file,
 			gcs_credentials_json_encrypted, gcs_endpoint,
+			replication_primary_id, replication_secondary_ids, replication_mode,
 			created_at, updated_at, created_by, updated_by
 		) VALUES (
 			$1, $2, $3,
@@ -82,7 +83,8 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 			$16, $17, $18, $19,
 			$20, $21, $22, $23,
 			$24, $25,
-			$26, $27, $28, $29
+			$26, $27, $28,
+			$29, $30, $31, $32
 		)`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -94,6 +96,7 @@ func (r *StorageConfigRepository) CreateStorageConfig(ctx context.Context, confi
 		config.S3RoleARN, config.S3RoleSessionName, config.S3ExternalID, config.S3WebIdentityTokenFile,
 		config.GCSBucket, config.GCSProjectID, config.GCSAuthMethod, config.GCSCredentialsFile,
 		config.GCSCredentialsJSONEncrypted, config.GCSEndpoint,
+		config.ReplicationPrimaryID, config.ReplicationSecondaryIDs, config.ReplicationMode,
 		config.CreatedAt, config.UpdatedAt, config.CreatedBy, config.UpdatedBy,
 	)
 	return err
@@ -143,7 +146,8 @@ func (r *StorageConfigRepository) UpdateStorageConfig(ctx context.Context, confi
 			s3_web_identity_token_file = $19,
 			gcs_bucket = $20, gcs_project_id = $21, gcs_auth_method = $22,
 			gcs_credentials_file = $23, gcs_credentials_json_encrypted = $24, gcs_endpoint = $25,
-			updated_at = $26, updated_by = $27
+			replication_primary_id = $26, replication_secondary_ids = $27, replication_mode = $28,
+			updated_at = $29, updated_by = $30
 		WHERE id = $1`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -158,6 +162,7 @@ func (r *StorageConfigRepository) UpdateStorageConfig(ctx context.Context, confi
 		config.S3WebIdentityTokenFile,
 		config.GCSBucket, config.GCSProjectID, config.GCSAuthMethod,
 		config.GCSCredentialsFile, config.GCSCredentialsJSONEncrypted, config.GCSEndpoint,
+		config.ReplicationPrimaryID, config.ReplicationSecondaryIDs, config.ReplicationMode,
 		time.Now(), config.UpdatedBy,
 	)
 	return err
@@ -202,3 +207,31 @@ func (r *StorageConfigRepository) ActivateStorageConfig(ctx context.Context, id
 
 	return tx.Commit()
 }
+
+// CountStoredArtifacts counts the module versions and provider platforms stored in a configuration
+func (r *StorageConfigRepository) CountStoredArtifacts(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	query := `
+		SELECT (SELECT COUNT(*) FROM module_versions WHERE storage_backend = $1)
+		     + (SELECT COUNT(*) FROM provider_platforms WHERE storage_backend = $1)`
+	err := r.db.GetContext(ctx, &count, query, id.String())
+	return count, err
+}
+
+// CountReplicaReferences counts the replicated configurations that use a configuration as a replica
+func (r *StorageConfigRepository) CountReplicaReferences(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM storage_config
+		WHERE replication_primary_id = $1 OR $1 = ANY(replication_secondary_ids)`
+	err := r.db.GetContext(ctx, &count, query, id)
+	return count, err
+}
+
+// ListReplicatedStorageConfigs lists the replicated storage configurations
+func (r *StorageConfigRepository) ListReplicatedStorageConfigs(ctx context.Context) ([]*models.StorageConfig, error) {
+	var configs []*models.StorageConfig
+	query := `SELECT * FROM storage_config WHERE backend_type = 'replicated' ORDER BY created_at`
+	err := r.db.SelectContext(ctx, &configs, query)
+	return configs, err
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageDataKeyRepository handles database operations for the data keys of encrypted files
+type StorageDataKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageDataKeyRepository creates a new storage data key repository
+func NewStorageDataKeyRepository(db *sqlx.DB) *StorageDataKeyRepository {
+	return &StorageDataKeyRepository{db: db}
+}
+
+// GetDataKey retrieves the data key of a file, or nil if the file is not encrypted
+func (r *StorageDataKeyRepository) GetDataKey(ctx context.Context, backend, path string) (*models.StorageDataKey, error) {
+	var key models.StorageDataKey
+	err := r.db.GetContext(ctx, &key,
+		`SELECT * FROM storage_data_keys WHERE storage_backend = $1 AND storage_path = $2`, backend, path,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage data key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// PutDataKey records the data key of a file, replacing the key of a file it overwrote
+func (r *StorageDataKeyRepository) PutDataKey(ctx context.Context, key *models.StorageDataKey) error {
+	query := `
+		INSERT INTO storage_data_keys (storage_backend, storage_path, master_key_id, wrapped_key, nonce_prefix,
+		                               plaintext_size, plaintext_checksum, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (storage_backend, storage_path) DO UPDATE
+		SET master_key_id = EXCLUDED.master_key_id, wrapped_key = EXCLUDED.wrapped_key,
+		    nonce_prefix = EXCLUDED.nonce_prefix, plaintext_size = EXCLUDED.plaintext_size,
+		    plaintext_checksum = EXCLUDED.plaintext_checksum, created_at = EXCLUDED.created_at,
+		    rotated_at = NULL
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		key.StorageBackend, key.StoragePath, key.MasterKeyID, key.WrappedKey, key.NoncePrefix,
+		key.PlaintextSize, key.PlaintextChecksum, time.Now(),
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save storage data key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDataKey removes the data key of a deleted file
+func (r *StorageDataKeyRepository) DeleteDataKey(ctx context.Context, backend, path string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM storage_data_keys WHERE storage_backend = $1 AND storage_path = $2`, backend, path,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete storage data key: %w", err)
+	}
+
+	return nil
+}
+
+// ListDataKeysNotWrappedBy retrieves up to limit data keys wrapped by a master key other than masterKeyID
+func (r *StorageDataKeyRepository) ListDataKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]*models.StorageDataKey, error) {
+	var keys []*models.StorageDataKey
+	err := r.db.SelectContext(ctx, &keys,
+		`SELECT * FROM storage_data_keys WHERE master_key_id <> $1 ORDER BY storage_backend, storage_path LIMIT $2`,
+		masterKeyID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage data keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RewrapDataKey replaces the wrapped form of a data key, provided it is still wrapped by
+// oldMasterKeyID. It reports whether the key was updated.
+func (r *StorageDataKeyRepository) RewrapDataKey(ctx context.Context, key *models.StorageDataKey, oldMasterKeyID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE storage_data_keys SET master_key_id = $3, wrapped_key = $4, rotated_at = $5
+		WHERE storage_backend = $1 AND storage_path = $2 AND master_key_id = $6
+	`, key.StorageBackend, key.StoragePath, key.MasterKeyID, key.WrappedKey, time.Now(), oldMasterKeyID)
+	if err != nil {
+		return false, fmt.Errorf("failed to rewrap storage data key: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to rewrap storage data key: %w", err)
+	}
+
+	return rows > 0, nil
+}
@@ -0,0 +1,335 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// StorageMigrationRepository handles database operations for storage migrations and their items
+type StorageMigrationRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageMigrationRepository creates a new storage migration repository
+func NewStorageMigrationRepository(db *sqlx.DB) *StorageMigrationRepository {
+	return &StorageMigrationRepository{db: db}
+}
+
+const storageMigrationColumns = `id, source_config_id, target_config_id, status, delete_target_on_rollback,
+		       last_error, started_at, completed_at, created_at, updated_at, created_by`
+
+const storageMigrationItemColumns = `id, migration_id, artifact_type, artifact_id, source_backend, source_path,
+		       target_path, checksum, size_bytes, status, attempts, error, updated_at`
+
+// artifactTable returns the table holding the storage location of an artifact type
+func artifactTable(artifactType string) (string, error) {
+	switch artifactType {
+	case models.StorageArtifactModuleVersion:
+		return "module_versions", nil
+	case models.StorageArtifactProviderPlatform:
+		return "provider_platforms", nil
+	default:
+		return "", fmt.Errorf("unknown artifact type: %s", artifactType)
+	}
+}
+
+// CreateMigration creates a new storage migration
+func (r *StorageMigrationRepository) CreateMigration(ctx context.Context, migration *models.StorageMigration) error {
+	query := `
+		INSERT INTO storage_migrations (
+			id, source_config_id, target_config_id, status, delete_target_on_rollback, created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	now := time.Now()
+	migration.CreatedAt = now
+	migration.UpdatedAt = now
+	_, err := r.db.ExecContext(ctx, query,
+		migration.ID,
+		migration.SourceConfigID,
+		migration.TargetConfigID,
+		migration.Status,
+		migration.DeleteTargetOnRollback,
+		migration.CreatedAt,
+		migration.UpdatedAt,
+		migration.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create storage migration: %w", err)
+	}
+
+	return nil
+}
+
+// GetMigration retrieves a storage migration by ID
+func (r *StorageMigrationRepository) GetMigration(ctx context.Context, id uuid.UUID) (*models.StorageMigration, error) {
+	query := `SELECT ` + storageMigrationColumns + ` FROM storage_migrations WHERE id = $1`
+
+	var migration models.StorageMigration
+	err := r.db.GetContext(ctx, &migration, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage migration: %w", err)
+	}
+
+	return &migration, nil
+}
+
+// ListMigrations lists storage migrations, newest first
+func (r *StorageMigrationRepository) ListMigrations(ctx context.Context) ([]*models.StorageMigration, error) {
+	query := `SELECT ` + storageMigrationColumns + ` FROM storage_migrations ORDER BY created_at DESC`
+
+	var migrations []*models.StorageMigration
+	if err := r.db.SelectContext(ctx, &migrations, query); err != nil {
+		return nil, fmt.Errorf("failed to list storage migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// ListMigrationsByStatus lists storage migrations in any of the given statuses, oldest first
+func (r *StorageMigrationRepository) ListMigrationsByStatus(ctx context.Context, statuses ...models.StorageMigrationStatus) ([]*models.StorageMigration, error) {
+	query, args, err := sqlx.In(`SELECT `+storageMigrationColumns+` FROM storage_migrations WHERE status IN (?) ORDER BY created_at`, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage migration query: %w", err)
+	}
+
+	var migrations []*models.StorageMigration
+	if err := r.db.SelectContext(ctx, &migrations, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list storage migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// UpdateMigrationStatus moves a storage migration to a new status. started_at is set the
+// first time the migration runs and completed_at whenever it reaches a final status.
+func (r *StorageMigrationRepository) UpdateMigrationStatus(ctx context.Context, id uuid.UUID, status models.StorageMigrationStatus, lastError *string) error {
+	query := `
+		UPDATE storage_migrations SET
+			status = $2,
+			last_error = $3,
+			started_at = CASE WHEN $2 = 'running' THEN COALESCE(started_at, $4) ELSE started_at END,
+			completed_at = CASE WHEN $2 IN ('completed', 'failed', 'cancelled', 'rolled_back') THEN $4 ELSE NULL END,
+			updated_at = $4
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, status, lastError, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update storage migration status: %w", err)
+	}
+
+	return nil
+}
+
+// EnqueueItems records every module version and provider platform stored on the source
+// backend, recorded under any of sourceBackends, as an item of the migration. Items already
+// enqueued are left untouched, so calling this again picks up artifacts published since the
+// migration was created.
+func (r *StorageMigrationRepository) EnqueueItems(ctx context.Context, migrationID uuid.UUID, sourceBackends []string, targetBackend string) (int64, error) {
+	query := `
+		INSERT INTO storage_migration_items (
+			migration_id, artifact_type, artifact_id, source_backend, source_path, checksum, size_bytes
+		)
+		SELECT $1, 'module_version', id, storage_backend, storage_path, checksum, size_bytes
+		FROM module_versions WHERE storage_backend = ANY($2) AND storage_backend <> $3
+		UNION ALL
+		SELECT $1, 'provider_platform', id, storage_backend, storage_path, shasum, size_bytes
+		FROM provider_platforms WHERE storage_backend = ANY($2) AND storage_backend <> $3
+		ON CONFLICT (migration_id, artifact_type, artifact_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, migrationID, pq.Array(sourceBackends), targetBackend)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue storage migration items: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ListItemsByStatus retrieves up to limit items of a migration in the given status
+func (r *StorageMigrationRepository) ListItemsByStatus(ctx context.Context, migrationID uuid.UUID, status models.StorageMigrationItemStatus, limit int) ([]*models.StorageMigrationItem, error) {
+	query := `SELECT ` + storageMigrationItemColumns + `
+		FROM storage_migration_items
+		WHERE migration_id = $1 AND status = $2
+		ORDER BY artifact_type, artifact_id
+		LIMIT $3`
+
+	var items []*models.StorageMigrationItem
+	if err := r.db.SelectContext(ctx, &items, query, migrationID, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to list storage migration items: %w", err)
+	}
+
+	return items, nil
+}
+
+// ListItems lists the items of a migration, optionally filtered by status
+func (r *StorageMigrationRepository) ListItems(ctx context.Context, migrationID uuid.UUID, status string, limit, offset int) ([]*models.StorageMigrationItem, error) {
+	query := `SELECT ` + storageMigrationItemColumns + `
+		FROM storage_migration_items
+		WHERE migration_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY updated_at DESC
+		LIMIT $3 OFFSET $4`
+
+	var items []*models.StorageMigrationItem
+	if err := r.db.SelectContext(ctx, &items, query, migrationID, status, limit, offset); err != nil {
+		return nil, fmt.Errorf("failed to list storage migration items: %w", err)
+	}
+
+	return items, nil
+}
+
+// GetProgress summarizes the item states of a migration
+func (r *StorageMigrationRepository) GetProgress(ctx context.Context, migrationID uuid.UUID) (*models.StorageMigrationProgress, error) {
+	query := `
+		SELECT status, COUNT(*) AS count, COALESCE(SUM(size_bytes), 0) AS bytes
+		FROM storage_migration_items
+		WHERE migration_id = $1
+		GROUP BY status
+	`
+
+	var rows []struct {
+		Status models.StorageMigrationItemStatus `db:"status"`
+		Count  int                               `db:"count"`
+		Bytes  int64                             `db:"bytes"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, migrationID); err != nil {
+		return nil, fmt.Errorf("failed to get storage migration progress: %w", err)
+	}
+
+	progress := &models.StorageMigrationProgress{}
+	for _, row := range rows {
+		progress.Total += row.Count
+		progress.BytesTotal += row.Bytes
+		switch row.Status {
+		case models.StorageMigrationItemPending:
+			progress.Pending = row.Count
+		case models.StorageMigrationItemMigrated:
+			progress.Migrated = row.Count
+			progress.BytesDone = row.Bytes
+		case models.StorageMigrationItemFailed:
+			progress.Failed = row.Count
+		case models.StorageMigrationItemRolledBack:
+			progress.RolledBack = row.Count
+		}
+	}
+
+	return progress, nil
+}
+
+// CompleteItem points the item's artifact at its copy on targetBackend and marks the item
+// migrated. The artifact is only updated while it still references the source object, so
+// versions re-uploaded or deleted during the migration are reported instead of overwritten.
+func (r *StorageMigrationRepository) CompleteItem(ctx context.Context, item *models.StorageMigrationItem, targetBackend, targetPath string) error {
+	table, err := artifactTable(item.ArtifactType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE `+table+` SET storage_backend = $1, storage_path = $2
+		 WHERE id = $3 AND storage_backend = $4 AND storage_path = $5`,
+		targetBackend, targetPath, item.ArtifactID, item.SourceBackend, item.SourcePath,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update artifact storage location: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%s %s no longer references %s", item.ArtifactType, item.ArtifactID, item.SourcePath)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE storage_migration_items SET status = $1, target_path = $2, attempts = attempts + 1, error = NULL, updated_at = $3
+		 WHERE id = $4`,
+		models.StorageMigrationItemMigrated, targetPath, time.Now(), item.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update storage migration item: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// FailItem records a failed copy attempt
+func (r *StorageMigrationRepository) FailItem(ctx context.Context, itemID uuid.UUID, message string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE storage_migration_items SET status = $1, attempts = attempts + 1, error = $2, updated_at = $3 WHERE id = $4`,
+		models.StorageMigrationItemFailed, message, time.Now(), itemID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update storage migration item: %w", err)
+	}
+
+	return nil
+}
+
+// RetryFailedItems returns the failed items of a migration to the pending state
+func (r *StorageMigrationRepository) RetryFailedItems(ctx context.Context, migrationID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE storage_migration_items SET status = $1, updated_at = $2 WHERE migration_id = $3 AND status = $4`,
+		models.StorageMigrationItemPending, time.Now(), migrationID, models.StorageMigrationItemFailed,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to retry storage migration items: %w", err)
+	}
+
+	return nil
+}
+
+// RollbackItem points a migrated item's artifact back at its source object and marks the
+// item rolled back. Artifacts that have moved on since the migration are left alone.
+func (r *StorageMigrationRepository) RollbackItem(ctx context.Context, item *models.StorageMigrationItem, targetBackend string) error {
+	table, err := artifactTable(item.ArtifactType)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE `+table+` SET storage_backend = $1, storage_path = $2
+		 WHERE id = $3 AND storage_backend = $4 AND storage_path = $5`,
+		item.SourceBackend, item.SourcePath, item.ArtifactID, targetBackend, derefItemPath(item),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore artifact storage location: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE storage_migration_items SET status = $1, updated_at = $2 WHERE id = $3`,
+		models.StorageMigrationItemRolledBack, time.Now(), item.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update storage migration item: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func derefItemPath(item *models.StorageMigrationItem) string {
+	if item.TargetPath != nil {
+		return *item.TargetPath
+	}
+	return item.SourcePath
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageQuotaRepository handles database operations for organization storage quotas and usage
+type StorageQuotaRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageQuotaRepository creates a new storage quota repository
+func NewStorageQuotaRepository(db *sqlx.DB) *StorageQuotaRepository {
+	return &StorageQuotaRepository{db: db}
+}
+
+// storageUsageQuery lists every stored artifact with the organization it is charged to. Artifacts
+// of global mirrors and single-tenant deployments have no organization and are charged to the
+// default organization.
+const storageUsageQuery = `
+	WITH default_org AS (
+		SELECT id FROM organizations WHERE name = 'default'
+	), artifacts AS (
+		SELECT COALESCE(m.organization_id, (SELECT id FROM default_org)) AS organization_id,
+		       m.namespace, 'module' AS artifact_type, mv.storage_backend, mv.size_bytes
+		FROM module_versions mv
+		JOIN modules m ON m.id = mv.module_id
+		UNION ALL
+		SELECT COALESCE(p.organization_id, (SELECT id FROM default_org)) AS organization_id,
+		       p.namespace,
+		       CASE WHEN mp.id IS NULL THEN 'provider' ELSE 'mirrored_provider' END AS artifact_type,
+		       pp.storage_backend, pp.size_bytes
+		FROM provider_platforms pp
+		JOIN provider_versions pv ON pv.id = pp.provider_version_id
+		JOIN providers p ON p.id = pv.provider_id
+		LEFT JOIN mirrored_providers mp ON mp.provider_id = p.id
+	)
+`
+
+// GetQuota retrieves the quota of an organization, or nil if it has none of its own
+func (r *StorageQuotaRepository) GetQuota(ctx context.Context, organizationID string) (*models.StorageQuota, error) {
+	var quota models.StorageQuota
+	err := r.db.GetContext(ctx, &quota, `SELECT * FROM storage_quotas WHERE organization_id = $1`, organizationID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage quota: %w", err)
+	}
+
+	return &quota, nil
+}
+
+// SetQuota creates or replaces the quota of an organization
+func (r *StorageQuotaRepository) SetQuota(ctx context.Context, quota *models.StorageQuota) error {
+	query := `
+		INSERT INTO storage_quotas (organization_id, max_bytes, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (organization_id) DO UPDATE
+		SET max_bytes = EXCLUDED.max_bytes, updated_at = EXCLUDED.updated_at
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, quota.OrganizationID, quota.MaxBytes, time.Now()).
+		Scan(&quota.CreatedAt, &quota.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save storage quota: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteQuota removes the quota of an organization, returning it to the default quota. It
+// reports whether the organization had a quota.
+func (r *StorageQuotaRepository) DeleteQuota(ctx context.Context, organizationID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM storage_quotas WHERE organization_id = $1`, organizationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete storage quota: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete storage quota: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// GetUsedBytes returns the total bytes stored by an organization
+func (r *StorageQuotaRepository) GetUsedBytes(ctx context.Context, organizationID string) (int64, error) {
+	var used int64
+	err := r.db.GetContext(ctx, &used,
+		storageUsageQuery+`SELECT COALESCE(SUM(size_bytes), 0)::BIGINT FROM artifacts WHERE organization_id = $1`,
+		organizationID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// GetUsageBreakdown returns the bytes stored by an organization per namespace, artifact type and backend
+func (r *StorageQuotaRepository) GetUsageBreakdown(ctx context.Context, organizationID string) ([]*models.StorageUsage, error) {
+	var usage []*models.StorageUsage
+	err := r.db.SelectContext(ctx, &usage, storageUsageQuery+`
+		SELECT namespace, artifact_type, storage_backend, COUNT(*) AS objects, COALESCE(SUM(size_bytes), 0)::BIGINT AS bytes
+		FROM artifacts
+		WHERE organization_id = $1
+		GROUP BY namespace, artifact_type, storage_backend
+		ORDER BY bytes DESC, namespace, artifact_type, storage_backend
+	`, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage usage breakdown: %w", err)
+	}
+
+	return usage, nil
+}
+
+// ListOrganizationUsage returns the total bytes stored by every organization, largest first
+func (r *StorageQuotaRepository) ListOrganizationUsage(ctx context.Context) ([]*models.OrganizationStorageUsage, error) {
+	var usage []*models.OrganizationStorageUsage
+	err := r.db.SelectContext(ctx, &usage, storageUsageQuery+`
+		SELECT o.id AS organization_id, o.name AS organization_name,
+		       COUNT(u.size_bytes) AS objects, COALESCE(SUM(u.size_bytes), 0)::BIGINT AS bytes, q.max_bytes
+		FROM organizations o
+		LEFT JOIN artifacts u ON u.organization_id = o.id
+		LEFT JOIN storage_quotas q ON q.organization_id = o.id
+		GROUP BY o.id, o.name, q.max_bytes
+		ORDER BY bytes DESC, o.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization storage usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// StorageReplicationRepository handles database operations for the storage replication queue
+type StorageReplicationRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageReplicationRepository creates a new storage replication repository
+func NewStorageReplicationRepository(db *sqlx.DB) *StorageReplicationRepository {
+	return &StorageReplicationRepository{db: db}
+}
+
+const replicationQueueColumns = `id, config_id, replica_id, operation, path, attempts, last_error, next_attempt_at, created_at`
+
+// EnqueueReplication queues an operation on one replica. A pending operation for the same
+// object and replica is replaced, since only the latest one determines the replica's state.
+func (r *StorageReplicationRepository) EnqueueReplication(ctx context.Context, configID, replicaID uuid.UUID, operation, path string) error {
+	query := `
+		INSERT INTO storage_replication_queue (config_id, replica_id, operation, path, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (config_id, replica_id, path) DO UPDATE
+		SET operation = EXCLUDED.operation, attempts = 0, last_error = NULL,
+		    next_attempt_at = EXCLUDED.next_attempt_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, configID, replicaID, operation, path, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to enqueue replication: %w", err)
+	}
+
+	return nil
+}
+
+// ListDue retrieves up to limit queued operations whose next attempt is due
+func (r *StorageReplicationRepository) ListDue(ctx context.Context, limit int) ([]*models.StorageReplicationQueueItem, error) {
+	query := `SELECT ` + replicationQueueColumns + `
+		FROM storage_replication_queue
+		WHERE next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2`
+
+	var items []*models.StorageReplicationQueueItem
+	if err := r.db.SelectContext(ctx, &items, query, time.Now(), limit); err != nil {
+		return nil, fmt.Errorf("failed to list replication queue: %w", err)
+	}
+
+	return items, nil
+}
+
+// CompleteItem removes a processed operation, unless it was replaced by a newer one meanwhile
+func (r *StorageReplicationRepository) CompleteItem(ctx context.Context, item *models.StorageReplicationQueueItem) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM storage_replication_queue WHERE id = $1 AND created_at = $2`,
+		item.ID, item.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete replication: %w", err)
+	}
+
+	return nil
+}
+
+// FailItem records a failed attempt and schedules the next one
+func (r *StorageReplicationRepository) FailItem(ctx context.Context, item *models.StorageReplicationQueueItem, message string, nextAttemptAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE storage_replication_queue SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		 WHERE id = $3 AND created_at = $4`,
+		message, nextAttemptAt, item.ID, item.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update replication: %w", err)
+	}
+
+	return nil
+}
+
+// GetStatus summarizes the queue of a replicated configuration, including its oldest items
+func (r *StorageReplicationRepository) GetStatus(ctx context.Context, configID uuid.UUID, limit int) (*models.StorageReplicationStatus, error) {
+	status := &models.StorageReplicationStatus{ConfigID: configID}
+
+	err := r.db.QueryRowxContext(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE attempts > 0) FROM storage_replication_queue WHERE config_id = $1`,
+		configID,
+	).Scan(&status.Pending, &status.Failing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count replication queue: %w", err)
+	}
+
+	query := `SELECT ` + replicationQueueColumns + `
+		FROM storage_replication_queue
+		WHERE config_id = $1
+		ORDER BY created_at
+		LIMIT $2`
+	if err := r.db.SelectContext(ctx, &status.Items, query, configID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list replication queue: %w", err)
+	}
+
+	return status, nil
+}
+
+// ListArtifactPaths lists the storage paths of module versions and provider platforms stored
+// in a backend, ordered by path and starting after afterPath
+func (r *StorageReplicationRepository) ListArtifactPaths(ctx context.Context, backend, afterPath string, limit int) ([]string, error) {
+	query := `
+		SELECT storage_path FROM (
+			SELECT storage_path FROM module_versions WHERE storage_backend = $1
+			UNION
+			SELECT storage_path FROM provider_platforms WHERE storage_backend = $1
+		) paths
+		WHERE storage_path > $2
+		ORDER BY storage_path
+		LIMIT $3
+	`
+
+	var paths []string
+	if err := r.db.SelectContext(ctx, &paths, query, backend, afterPath, limit); err != nil {
+		return nil, fmt.Errorf("failed to list artifact paths: %w", err)
+	}
+
+	return paths, nil
+}
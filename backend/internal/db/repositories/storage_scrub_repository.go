@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// storedArtifactsQuery selects every module version archive and provider platform binary
+const storedArtifactsQuery = `
+	SELECT 'module_version' AS artifact_type, id AS artifact_id, storage_backend, storage_path,
+	       checksum, size_bytes
+	FROM module_versions
+	UNION ALL
+	SELECT 'provider_platform', id, storage_backend, storage_path, shasum, size_bytes
+	FROM provider_platforms
+`
+
+// StorageScrubRepository handles database operations for the storage integrity scrubber
+type StorageScrubRepository struct {
+	db *sqlx.DB
+}
+
+// NewStorageScrubRepository creates a new storage scrub repository
+func NewStorageScrubRepository(db *sqlx.DB) *StorageScrubRepository {
+	return &StorageScrubRepository{db: db}
+}
+
+// CreateRun records the start of a scrub
+func (r *StorageScrubRepository) CreateRun(ctx context.Context) (*models.StorageScrubRun, error) {
+	var run models.StorageScrubRun
+	err := r.db.GetContext(ctx, &run,
+		`INSERT INTO storage_scrub_runs (status, started_at) VALUES ($1, $2) RETURNING *`,
+		models.StorageScrubRunning, time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage scrub run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// UpdateRun saves a scrub's counts, status, error and completion time
+func (r *StorageScrubRepository) UpdateRun(ctx context.Context, run *models.StorageScrubRun) error {
+	query := `
+		UPDATE storage_scrub_runs
+		SET status = :status, artifacts_checked = :artifacts_checked, artifacts_rehashed = :artifacts_rehashed,
+		    issues_found = :issues_found, objects_scanned = :objects_scanned, orphans_found = :orphans_found,
+		    orphans_quarantined = :orphans_quarantined, orphans_deleted = :orphans_deleted,
+		    error_message = :error_message, completed_at = :completed_at
+		WHERE id = :id
+	`
+
+	if _, err := r.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to update storage scrub run: %w", err)
+	}
+
+	return nil
+}
+
+// FailInterruptedRuns marks scrubs left running by a restart as failed
+func (r *StorageScrubRepository) FailInterruptedRuns(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE storage_scrub_runs SET status = $1, error_message = $2, completed_at = $3 WHERE status = $4`,
+		models.StorageScrubFailed, "interrupted by a restart", time.Now(), models.StorageScrubRunning,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update interrupted storage scrub runs: %w", err)
+	}
+
+	return nil
+}
+
+// GetRun retrieves a scrub by ID
+func (r *StorageScrubRepository) GetRun(ctx context.Context, id uuid.UUID) (*models.StorageScrubRun, error) {
+	var run models.StorageScrubRun
+	err := r.db.GetContext(ctx, &run, `SELECT * FROM storage_scrub_runs WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage scrub run: %w", err)
+	}
+
+	return &run, nil
+}
+
+// ListRuns retrieves the most recent scrubs, newest first
+func (r *StorageScrubRepository) ListRuns(ctx context.Context, limit int) ([]*models.StorageScrubRun, error) {
+	var runs []*models.StorageScrubRun
+	err := r.db.SelectContext(ctx, &runs,
+		`SELECT * FROM storage_scrub_runs ORDER BY started_at DESC LIMIT $1`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage scrub runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// CreateIssue records a problem found with an artifact's stored object
+func (r *StorageScrubRepository) CreateIssue(ctx context.Context, issue *models.StorageIntegrityIssue) error {
+	query := `
+		INSERT INTO storage_integrity_issues (run_id, artifact_type, artifact_id, storage_backend,
+		                                      storage_path, issue, details, detected_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, detected_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		issue.RunID, issue.ArtifactType, issue.ArtifactID, issue.StorageBackend,
+		issue.StoragePath, issue.Issue, issue.Details, time.Now(),
+	).Scan(&issue.ID, &issue.DetectedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create storage integrity issue: %w", err)
+	}
+
+	return nil
+}
+
+// ListIssues retrieves the problems found by a scrub
+func (r *StorageScrubRepository) ListIssues(ctx context.Context, runID uuid.UUID) ([]*models.StorageIntegrityIssue, error) {
+	var issues []*models.StorageIntegrityIssue
+	err := r.db.SelectContext(ctx, &issues,
+		`SELECT * FROM storage_integrity_issues WHERE run_id = $1 ORDER BY detected_at`, runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage integrity issues: %w", err)
+	}
+
+	return issues, nil
+}
+
+// ListArtifacts retrieves up to limit stored artifacts ordered by ID, starting after afterID
+func (r *StorageScrubRepository) ListArtifacts(ctx context.Context, afterID uuid.UUID, limit int) ([]*models.StoredArtifact, error) {
+	query := `SELECT * FROM (` + storedArtifactsQuery + `) artifacts
+		WHERE artifact_id > $1 ORDER BY artifact_id LIMIT $2`
+
+	var artifacts []*models.StoredArtifact
+	if err := r.db.SelectContext(ctx, &artifacts, query, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list stored artifacts: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+// SampleArtifacts retrieves up to n stored artifacts chosen at random
+func (r *StorageScrubRepository) SampleArtifacts(ctx context.Context, n int) ([]*models.StoredArtifact, error) {
+	query := `SELECT * FROM (` + storedArtifactsQuery + `) artifacts ORDER BY random() LIMIT $1`
+
+	var artifacts []*models.StoredArtifact
+	if err := r.db.SelectContext(ctx, &artifacts, query, n); err != nil {
+		return nil, fmt.Errorf("failed to sample stored artifacts: %w", err)
+	}
+
+	return artifacts, nil
+}
+
+// ListArtifactBackends retrieves the distinct storage_backend values of stored artifacts
+func (r *StorageScrubRepository) ListArtifactBackends(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT storage_backend FROM module_versions
+		UNION
+		SELECT storage_backend FROM provider_platforms
+	`
+
+	var backends []string
+	if err := r.db.SelectContext(ctx, &backends, query); err != nil {
+		return nil, fmt.Errorf("failed to list artifact storage backends: %w", err)
+	}
+
+	return backends, nil
+}
+
+// ListReferencedPaths retrieves the storage paths of artifacts stored on one of backends
+func (r *StorageScrubRepository) ListReferencedPaths(ctx context.Context, backends []string) ([]string, error) {
+	query := `
+		SELECT storage_path FROM module_versions WHERE storage_backend = ANY($1)
+		UNION
+		SELECT storage_path FROM provider_platforms WHERE storage_backend = ANY($1)
+	`
+
+	var paths []string
+	if err := r.db.SelectContext(ctx, &paths, query, pq.Array(backends)); err != nil {
+		return nil, fmt.Errorf("failed to list referenced storage paths: %w", err)
+	}
+
+	return paths, nil
+}
+
+// RecordOrphan notes that an unreferenced object was seen at seenAt. An object seen again
+// keeps its first sighting; one that reappears after being acted on starts over as pending.
+func (r *StorageScrubRepository) RecordOrphan(ctx context.Context, backend, path string, size int64, seenAt time.Time) (*models.StorageOrphan, error) {
+	query := `
+		INSERT INTO storage_orphans (storage_backend, storage_path, size_bytes, status, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, 'pending', $4, $4)
+		ON CONFLICT (storage_backend, storage_path) DO UPDATE
+		SET size_bytes = EXCLUDED.size_bytes,
+		    last_seen_at = EXCLUDED.last_seen_at,
+		    first_seen_at = CASE WHEN storage_orphans.status = 'pending'
+		                         THEN storage_orphans.first_seen_at ELSE EXCLUDED.first_seen_at END,
+		    quarantine_path = CASE WHEN storage_orphans.status = 'pending'
+		                           THEN storage_orphans.quarantine_path ELSE NULL END,
+		    acted_at = CASE WHEN storage_orphans.status = 'pending' THEN storage_orphans.acted_at ELSE NULL END,
+		    status = 'pending'
+		RETURNING *
+	`
+
+	var orphan models.StorageOrphan
+	if err := r.db.GetContext(ctx, &orphan, query, backend, path, size, seenAt); err != nil {
+		return nil, fmt.Errorf("failed to record storage orphan: %w", err)
+	}
+
+	return &orphan, nil
+}
+
+// MarkOrphan records what was done with an orphan
+func (r *StorageScrubRepository) MarkOrphan(ctx context.Context, id uuid.UUID, status models.StorageOrphanStatus, quarantinePath *string) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE storage_orphans SET status = $2, quarantine_path = $3, acted_at = $4 WHERE id = $1`,
+		id, status, quarantinePath, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update storage orphan: %w", err)
+	}
+
+	return nil
+}
+
+// ForgetStaleOrphans removes pending orphans on backend not seen since before, which have
+// since been removed or become referenced
+func (r *StorageScrubRepository) ForgetStaleOrphans(ctx context.Context, backend string, before time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM storage_orphans WHERE storage_backend = $1 AND status = 'pending' AND last_seen_at < $2`,
+		backend, before,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove stale storage orphans: %w", err)
+	}
+
+	return nil
+}
+
+// GetOrphan retrieves an orphan by ID
+func (r *StorageScrubRepository) GetOrphan(ctx context.Context, id uuid.UUID) (*models.StorageOrphan, error) {
+	var orphan models.StorageOrphan
+	err := r.db.GetContext(ctx, &orphan, `SELECT * FROM storage_orphans WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage orphan: %w", err)
+	}
+
+	return &orphan, nil
+}
+
+// ListOrphans retrieves orphans, optionally only those with the given status
+func (r *StorageScrubRepository) ListOrphans(ctx context.Context, status string) ([]*models.StorageOrphan, error) {
+	var orphans []*models.StorageOrphan
+	var err error
+	if status == "" {
+		err = r.db.SelectContext(ctx, &orphans, `SELECT * FROM storage_orphans ORDER BY first_seen_at`)
+	} else {
+		err = r.db.SelectContext(ctx, &orphans,
+			`SELECT * FROM storage_orphans WHERE status = $1 ORDER BY first_seen_at`, status,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage orphans: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// DeleteOrphan removes an orphan's record
+func (r *StorageScrubRepository) DeleteOrphan(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM storage_orphans WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete storage orphan: %w", err)
+	}
+	return nil
+}
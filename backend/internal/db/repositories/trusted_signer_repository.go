@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// TrustedSignerRepository handles database operations for trusted signers and module version attestations
+type TrustedSignerRepository struct {
+	db *sqlx.DB
+}
+
+// NewTrustedSignerRepository creates a new trusted signer repository
+func NewTrustedSignerRepository(db *sqlx.DB) *TrustedSignerRepository {
+	return &TrustedSignerRepository{db: db}
+}
+
+const trustedSignerColumns = `id, organization_id, name, description, signer_type, public_key, certificate_identity,
+		       certificate_issuer, root_certificates, builder_id_pattern, namespace_pattern, is_active,
+		       created_at, updated_at, created_by`
+
+// Create creates a new trusted signer
+func (r *TrustedSignerRepository) Create(ctx context.Context, signer *models.TrustedSigner) error {
+	query := `
+		INSERT INTO trusted_signers (
+			id, organization_id, name, description, signer_type, public_key, certificate_identity,
+			certificate_issuer, root_certificates, builder_id_pattern, namespace_pattern, is_active,
+			created_at, updated_at, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		signer.ID,
+		signer.OrganizationID,
+		signer.Name,
+		signer.Description,
+		signer.SignerType,
+		signer.PublicKey,
+		signer.CertificateIdentity,
+		signer.CertificateIssuer,
+		signer.RootCertificates,
+		signer.BuilderIDPattern,
+		signer.NamespacePattern,
+		signer.IsActive,
+		signer.CreatedAt,
+		signer.UpdatedAt,
+		signer.CreatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trusted signer: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a trusted signer by ID
+func (r *TrustedSignerRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.TrustedSigner, error) {
+	query := `SELECT ` + trustedSignerColumns + ` FROM trusted_signers WHERE id = $1`
+
+	var signer models.TrustedSigner
+	err := r.db.GetContext(ctx, &signer, query, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trusted signer: %w", err)
+	}
+
+	return &signer, nil
+}
+
+// List lists global trusted signers and, when orgID is set, the organization's signers
+func (r *TrustedSignerRepository) List(ctx context.Context, orgID *uuid.UUID) ([]*models.TrustedSigner, error) {
+	query := `SELECT ` + trustedSignerColumns + ` FROM trusted_signers WHERE organization_id IS NULL`
+	args := []interface{}{}
+	if orgID != nil {
+		query += ` OR organization_id = $1`
+		args = append(args, *orgID)
+	}
+	query += ` ORDER BY name`
+
+	var signers []*models.TrustedSigner
+	if err := r.db.SelectContext(ctx, &signers, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list trusted signers: %w", err)
+	}
+
+	return signers, nil
+}
+
+// ListActive lists the active signers that apply to an organization
+func (r *TrustedSignerRepository) ListActive(ctx context.Context, orgID *uuid.UUID) ([]*models.TrustedSigner, error) {
+	query := `SELECT ` + trustedSignerColumns + `
+		FROM trusted_signers
+		WHERE is_active = true AND (organization_id IS NULL OR organization_id = $1)
+		ORDER BY name`
+
+	var signers []*models.TrustedSigner
+	if err := r.db.SelectContext(ctx, &signers, query, orgID); err != nil {
+		return nil, fmt.Errorf("failed to list active trusted signers: %w", err)
+	}
+
+	return signers, nil
+}
+
+// Update updates an existing trusted signer
+func (r *TrustedSignerRepository) Update(ctx context.Context, signer *models.TrustedSigner) error {
+	query := `
+		UPDATE trusted_signers
+		SET name = $2, description = $3, signer_type = $4, public_key = $5, certificate_identity = $6,
+		    certificate_issuer = $7, root_certificates = $8, builder_id_pattern = $9,
+		    namespace_pattern = $10, is_active = $11, updated_at = $12
+		WHERE id = $1
+	`
+
+	signer.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, query,
+		signer.ID,
+		signer.Name,
+		signer.Description,
+		signer.SignerType,
+		signer.PublicKey,
+		signer.CertificateIdentity,
+		signer.CertificateIssuer,
+		signer.RootCertificates,
+		signer.BuilderIDPattern,
+		signer.NamespacePattern,
+		signer.IsActive,
+		signer.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update trusted signer: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a trusted signer
+func (r *TrustedSignerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM trusted_signers WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete trusted signer: %w", err)
+	}
+	return nil
+}
+
+// CreateAttestations stores the signatures and provenance attestations uploaded with a module version
+func (r *TrustedSignerRepository) CreateAttestations(ctx context.Context, attestations []*models.ModuleVersionAttestation) error {
+	query := `
+		INSERT INTO module_version_attestations (
+			id, module_version_id, attestation_type, content, certificate, verified, signer_id,
+			signer_identity, predicate_type, builder_id, verification_error, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	for _, attestation := range attestations {
+		if attestation.ID == uuid.Nil {
+			attestation.ID = uuid.New()
+		}
+		if attestation.CreatedAt.IsZero() {
+			attestation.CreatedAt = time.Now()
+		}
+		_, err := r.db.ExecContext(ctx, query,
+			attestation.ID,
+			attestation.ModuleVersionID,
+			attestation.AttestationType,
+			attestation.Content,
+			attestation.Certificate,
+			attestation.Verified,
+			attestation.SignerID,
+			attestation.SignerIdentity,
+			attestation.PredicateType,
+			attestation.BuilderID,
+			attestation.VerificationError,
+			attestation.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create module version attestation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListAttestations retrieves the attestations recorded for a module version
+func (r *TrustedSignerRepository) ListAttestations(ctx context.Context, moduleVersionID uuid.UUID) ([]*models.ModuleVersionAttestation, error) {
+	query := `
+		SELECT id, module_version_id, attestation_type, content, certificate, verified, signer_id,
+		       signer_identity, predicate_type, builder_id, verification_error, created_at
+		FROM module_version_attestations
+		WHERE module_version_id = $1
+		ORDER BY attestation_type, created_at
+	`
+
+	var attestations []*models.ModuleVersionAttestation
+	if err := r.db.SelectContext(ctx, &attestations, query, moduleVersionID); err != nil {
+		return nil, fmt.Errorf("failed to list module version attestations: %w", err)
+	}
+
+	return attestations, nil
+}
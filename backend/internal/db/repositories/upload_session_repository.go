@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UploadSessionRepository handles database operations for resumable upload sessions
+type UploadSessionRepository struct {
+	db *sqlx.DB
+}
+
+// NewUploadSessionRepository creates a new upload session repository
+func NewUploadSessionRepository(db *sqlx.DB) *UploadSessionRepository {
+	return &UploadSessionRepository{db: db}
+}
+
+// CreateSession inserts a new upload session
+func (r *UploadSessionRepository) CreateSession(ctx context.Context, session *models.UploadSession) error {
+	query := `
+		INSERT INTO upload_sessions (artifact_type, filename, upload_length, upload_offset, upload_metadata,
+		                             owner, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	now := time.Now()
+	err := r.db.QueryRowxContext(ctx, query,
+		session.ArtifactType, session.Filename, session.UploadLength, session.UploadOffset,
+		session.UploadMetadata, session.Owner, session.ExpiresAt, now,
+	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession retrieves an upload session by ID
+func (r *UploadSessionRepository) GetSession(ctx context.Context, id uuid.UUID) (*models.UploadSession, error) {
+	var session models.UploadSession
+	err := r.db.GetContext(ctx, &session, `SELECT * FROM upload_sessions WHERE id = $1`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// AdvanceOffset moves a session's offset from oldOffset to newOffset and extends its expiry.
+// It reports false when the offset has moved since the caller read it.
+func (r *UploadSessionRepository) AdvanceOffset(ctx context.Context, id uuid.UUID, oldOffset, newOffset int64, expiresAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE upload_sessions SET upload_offset = $3, expires_at = $4, updated_at = $5
+		 WHERE id = $1 AND upload_offset = $2`,
+		id, oldOffset, newOffset, expiresAt, time.Now(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update upload offset: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows > 0, nil
+}
+
+// DeleteSession removes an upload session
+func (r *UploadSessionRepository) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM upload_sessions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete upload session: %w", err)
+	}
+	return nil
+}
+
+// ListExpiredSessions retrieves up to limit sessions that expired before now
+func (r *UploadSessionRepository) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]*models.UploadSession, error) {
+	var sessions []*models.UploadSession
+	err := r.db.SelectContext(ctx, &sessions,
+		`SELECT * FROM upload_sessions WHERE expires_at < $1 ORDER BY expires_at LIMIT $2`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// ListSessionIDs retrieves the IDs of every upload session
+func (r *UploadSessionRepository) ListSessionIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.SelectContext(ctx, &ids, `SELECT id FROM upload_sessions`); err != nil {
+		return nil, fmt.Errorf("failed to list upload sessions: %w", err)
+	}
+	return ids, nil
+}
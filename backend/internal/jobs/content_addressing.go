@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const contentAddressingBatchSize = 50
+
+// ErrContentAddressingInProgress is returned when a conversion to content-addressed storage is already running
+var ErrContentAddressingInProgress = errors.New("content-addressed storage conversion already in progress")
+
+// ContentAddressingJob converts artifacts stored by path on the default backend into the
+// content-addressed layout. Each artifact is hashed and stored as a blob, its row is pointed
+// at the blob, and the path-based object is removed once no artifact references it. Rows
+// already under the blob layout are skipped, so an interrupted conversion simply runs again.
+type ContentAddressingJob struct {
+	blobRepo    *repositories.StorageBlobRepository
+	cas         *storage.ContentAddressedStorage
+	backendKeys []string
+
+	running      bool
+	runningMutex sync.Mutex
+}
+
+// NewContentAddressingJob creates a new conversion job. backendKeys are the storage_backend
+// values of artifacts stored on the backend wrapped by cas.
+func NewContentAddressingJob(blobRepo *repositories.StorageBlobRepository, cas *storage.ContentAddressedStorage, backendKeys []string) *ContentAddressingJob {
+	return &ContentAddressingJob{
+		blobRepo:    blobRepo,
+		cas:         cas,
+		backendKeys: backendKeys,
+	}
+}
+
+// Start converts any remaining path-based artifacts in the background
+func (j *ContentAddressingJob) Start(ctx context.Context) {
+	if err := j.Trigger(ctx); err != nil {
+		log.Printf("Content-addressed storage conversion not started: %v", err)
+	}
+}
+
+// Trigger starts a conversion in the background
+func (j *ContentAddressingJob) Trigger(ctx context.Context) error {
+	j.runningMutex.Lock()
+	defer j.runningMutex.Unlock()
+	if j.running {
+		return ErrContentAddressingInProgress
+	}
+	j.running = true
+
+	go func() {
+		defer func() {
+			j.runningMutex.Lock()
+			j.running = false
+			j.runningMutex.Unlock()
+		}()
+		j.run(context.Background())
+	}()
+
+	return nil
+}
+
+// Stats summarizes deduplication on the default backend and the conversion's progress
+func (j *ContentAddressingJob) Stats(ctx context.Context) (*models.ContentAddressedStats, error) {
+	stats, err := j.blobRepo.GetStats(ctx, j.cas.Key(), j.backendKeys, storage.BlobPathPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	j.runningMutex.Lock()
+	stats.Migrating = j.running
+	j.runningMutex.Unlock()
+
+	return stats, nil
+}
+
+func (j *ContentAddressingJob) run(ctx context.Context) {
+	log.Println("Converting path-based artifacts to content-addressed storage")
+
+	var converted, failed int
+	afterID := uuid.Nil
+	for {
+		artifacts, err := j.blobRepo.ListPathBasedArtifacts(ctx, j.backendKeys, storage.BlobPathPrefix, afterID, contentAddressingBatchSize)
+		if err != nil {
+			log.Printf("Content-addressed storage conversion stopped: %v", err)
+			return
+		}
+		if len(artifacts) == 0 {
+			break
+		}
+
+		for _, artifact := range artifacts {
+			afterID = artifact.ArtifactID
+			if err := j.convert(ctx, artifact); err != nil {
+				log.Printf("Failed to convert %s %s to content-addressed storage: %v", artifact.ArtifactType, artifact.ArtifactID, err)
+				failed++
+				continue
+			}
+			converted++
+		}
+	}
+
+	log.Printf("Content-addressed storage conversion finished: %d converted, %d failed", converted, failed)
+}
+
+// convert stores one artifact as a blob and repoints its row at it
+func (j *ContentAddressingJob) convert(ctx context.Context, artifact *models.StoredArtifact) error {
+	reader, err := j.cas.Download(ctx, artifact.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	result, err := j.cas.Upload(ctx, artifact.StoragePath, reader, -1)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	if artifact.Checksum != "" && !strings.EqualFold(result.Checksum, artifact.Checksum) {
+		j.release(ctx, result.Path)
+		return fmt.Errorf("checksum mismatch: recorded %s, stored object hashes to %s", artifact.Checksum, result.Checksum)
+	}
+
+	updated, err := j.blobRepo.UpdateArtifactPath(ctx, artifact, result.Path)
+	if err != nil || !updated {
+		j.release(ctx, result.Path)
+		if err == nil {
+			err = fmt.Errorf("artifact no longer references %s", artifact.StoragePath)
+		}
+		return err
+	}
+
+	// Remove the path-based object unless another artifact still points at it
+	references, err := j.blobRepo.CountPathReferences(ctx, j.backendKeys, artifact.StoragePath)
+	if err != nil {
+		log.Printf("Keeping %s: %v", artifact.StoragePath, err)
+		return nil
+	}
+	if references == 0 {
+		if err := j.cas.Delete(ctx, artifact.StoragePath); err != nil {
+			log.Printf("Failed to remove path-based object %s: %v", artifact.StoragePath, err)
+		}
+	}
+
+	return nil
+}
+
+func (j *ContentAddressingJob) release(ctx context.Context, blobPath string) {
+	if err := j.cas.Delete(ctx, blobPath); err != nil {
+		log.Printf("Failed to release blob %s: %v", blobPath, err)
+	}
+}
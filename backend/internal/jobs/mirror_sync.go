@@ -1,205 +1,29 @@
 package jobs
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/terraform-registry/terraform-registry/internal/config"
 	"github.com/terraform-registry/terraform-registry/internal/db/models"
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/mirror"
+	"github.com/terraform-registry/terraform-registry/internal/services"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
 	"github.com/terraform-registry/terraform-registry/internal/validation"
 
 	"github.com/google/uuid"
 )
 
-// filterVersions filters a list of provider versions based on the version filter string
-// Supported filter formats:
-//   - "3." or "3.x" - all versions starting with "3."
-//   - "latest:5" - the latest 5 versions (sorted by semver)
-//   - "3.74.0,3.73.0" - specific comma-separated versions
-//   - ">=3.0.0" - versions >= 3.0.0 (semver comparison)
-//   - "" or nil - all versions
-func filterVersions(versions []mirror.ProviderVersion, filter *string) []mirror.ProviderVersion {
-	if filter == nil || *filter == "" {
-		return versions
-	}
-
-	filterStr := strings.TrimSpace(*filter)
-
-	// Handle "latest:N" format
-	if strings.HasPrefix(filterStr, "latest:") {
-		countStr := strings.TrimPrefix(filterStr, "latest:")
-		count, err := strconv.Atoi(countStr)
-		if err != nil || count <= 0 {
-			log.Printf("Invalid latest:N filter format: %s, using all versions", filterStr)
-			return versions
-		}
-		return filterLatestVersions(versions, count)
-	}
-
-	// Handle prefix matching (e.g., "3." or "3.x" or "3.7")
-	if strings.HasSuffix(filterStr, ".") || strings.HasSuffix(filterStr, ".x") {
-		prefix := strings.TrimSuffix(filterStr, "x")
-		return filterVersionsByPrefix(versions, prefix)
-	}
-
-	// Handle semver constraints (>=, >, <=, <)
-	if strings.HasPrefix(filterStr, ">=") || strings.HasPrefix(filterStr, ">") ||
-		strings.HasPrefix(filterStr, "<=") || strings.HasPrefix(filterStr, "<") {
-		return filterVersionsBySemverConstraint(versions, filterStr)
-	}
-
-	// Handle comma-separated specific versions
-	if strings.Contains(filterStr, ",") {
-		return filterVersionsByList(versions, filterStr)
-	}
-
-	// Single version or prefix without trailing dot
-	// Try as prefix first
-	filtered := filterVersionsByPrefix(versions, filterStr+".")
-	if len(filtered) > 0 {
-		return filtered
-	}
-
-	// Try as exact version
-	return filterVersionsByList(versions, filterStr)
-}
-
-// filterLatestVersions returns the N most recent versions sorted by semver
-func filterLatestVersions(versions []mirror.ProviderVersion, count int) []mirror.ProviderVersion {
-	if len(versions) <= count {
-		return versions
-	}
-
-	// Sort by semver descending
-	sorted := make([]mirror.ProviderVersion, len(versions))
-	copy(sorted, versions)
-	sort.Slice(sorted, func(i, j int) bool {
-		return compareSemver(sorted[i].Version, sorted[j].Version) > 0
-	})
-
-	return sorted[:count]
-}
-
-// filterVersionsByPrefix returns versions that start with the given prefix
-func filterVersionsByPrefix(versions []mirror.ProviderVersion, prefix string) []mirror.ProviderVersion {
-	var filtered []mirror.ProviderVersion
-	for _, v := range versions {
-		if strings.HasPrefix(v.Version, prefix) {
-			filtered = append(filtered, v)
-		}
-	}
-	return filtered
-}
-
-// filterVersionsByList returns versions that match any in the comma-separated list
-func filterVersionsByList(versions []mirror.ProviderVersion, list string) []mirror.ProviderVersion {
-	wantedVersions := make(map[string]bool)
-	for _, v := range strings.Split(list, ",") {
-		wantedVersions[strings.TrimSpace(v)] = true
-	}
-
-	var filtered []mirror.ProviderVersion
-	for _, v := range versions {
-		if wantedVersions[v.Version] {
-			filtered = append(filtered, v)
-		}
-	}
-	return filtered
-}
-
-// filterVersionsBySemverConstraint returns versions matching the semver constraint
-func filterVersionsBySemverConstraint(versions []mirror.ProviderVersion, constraint string) []mirror.ProviderVersion {
-	var op string
-	var targetVersion string
-
-	if strings.HasPrefix(constraint, ">=") {
-		op = ">="
-		targetVersion = strings.TrimPrefix(constraint, ">=")
-	} else if strings.HasPrefix(constraint, "<=") {
-		op = "<="
-		targetVersion = strings.TrimPrefix(constraint, "<=")
-	} else if strings.HasPrefix(constraint, ">") {
-		op = ">"
-		targetVersion = strings.TrimPrefix(constraint, ">")
-	} else if strings.HasPrefix(constraint, "<") {
-		op = "<"
-		targetVersion = strings.TrimPrefix(constraint, "<")
-	} else {
-		return versions
-	}
-
-	targetVersion = strings.TrimSpace(targetVersion)
-
-	var filtered []mirror.ProviderVersion
-	for _, v := range versions {
-		cmp := compareSemver(v.Version, targetVersion)
-		switch op {
-		case ">=":
-			if cmp >= 0 {
-				filtered = append(filtered, v)
-			}
-		case ">":
-			if cmp > 0 {
-				filtered = append(filtered, v)
-			}
-		case "<=":
-			if cmp <= 0 {
-				filtered = append(filtered, v)
-			}
-		case "<":
-			if cmp < 0 {
-				filtered = append(filtered, v)
-			}
-		}
-	}
-	return filtered
-}
-
-// compareSemver compares two semver strings
-// Returns: -1 if a < b, 0 if a == b, 1 if a > b
-func compareSemver(a, b string) int {
-	aParts := parseSemverParts(a)
-	bParts := parseSemverParts(b)
-
-	for i := 0; i < 3; i++ {
-		if aParts[i] < bParts[i] {
-			return -1
-		}
-		if aParts[i] > bParts[i] {
-			return 1
-		}
-	}
-	return 0
-}
-
-// parseSemverParts extracts major, minor, patch from a version string
-func parseSemverParts(version string) [3]int {
-	// Remove any pre-release suffix (e.g., -alpha, -beta)
-	if idx := strings.Index(version, "-"); idx != -1 {
-		version = version[:idx]
-	}
-
-	parts := strings.Split(version, ".")
-	var result [3]int
-	for i := 0; i < 3 && i < len(parts); i++ {
-		val, _ := strconv.Atoi(parts[i])
-		result[i] = val
-	}
-	return result
-}
-
 // safeString returns the string value or "(none)" if nil
 func safeString(s *string) string {
 	if s == nil {
@@ -249,7 +73,14 @@ type MirrorSyncJob struct {
 	mirrorRepo       *repositories.MirrorRepository
 	providerRepo     *repositories.ProviderRepository
 	storageBackend   storage.Storage
-	activeSyncs      map[uuid.UUID]bool
+	storageKey       string // Registry key of storageBackend, recorded on the platforms it stores
+	licenses         *services.LicensePolicyEvaluator
+	quotas           *services.StorageQuotaService
+	sboms            *services.SBOMService
+	rbacRepo         mirrorPolicyStore
+	hosts            *mirror.HostLimiter // Shared by all syncs so they don't overload an upstream together
+	maxRetryWait     time.Duration
+	activeSyncs      map[uuid.UUID]*activeSync
 	activeSyncsMutex sync.Mutex
 	stopCh           chan struct{}
 	wg               sync.WaitGroup
@@ -260,12 +91,25 @@ func NewMirrorSyncJob(
 	mirrorRepo *repositories.MirrorRepository,
 	providerRepo *repositories.ProviderRepository,
 	storageBackend storage.Storage,
+	storageKey string,
+	licenses *services.LicensePolicyEvaluator,
+	quotas *services.StorageQuotaService,
+	sboms *services.SBOMService,
+	rbacRepo *repositories.RBACRepository,
+	cfg config.MirrorConfig,
 ) *MirrorSyncJob {
 	return &MirrorSyncJob{
 		mirrorRepo:       mirrorRepo,
 		providerRepo:     providerRepo,
 		storageBackend:   storageBackend,
-		activeSyncs:      make(map[uuid.UUID]bool),
+		storageKey:       storageKey,
+		licenses:         licenses,
+		quotas:           quotas,
+		sboms:            sboms,
+		rbacRepo:         rbacRepo,
+		hosts:            mirror.NewHostLimiter(cfg.PerHostConcurrency),
+		maxRetryWait:     cfg.MaxRetryWait,
+		activeSyncs:      make(map[uuid.UUID]*activeSync),
 		activeSyncsMutex: sync.Mutex{},
 		stopCh:           make(chan struct{}),
 	}
@@ -275,6 +119,13 @@ func NewMirrorSyncJob(
 func (j *MirrorSyncJob) Start(ctx context.Context, intervalMinutes int) {
 	log.Printf("Starting mirror sync job with interval of %d minutes", intervalMinutes)
 
+	// Syncs still marked running were cut short by a restart; failing them lets them resume
+	if interrupted, err := j.mirrorRepo.MarkInterruptedSyncs(ctx); err != nil {
+		log.Printf("Error marking interrupted mirror syncs: %v", err)
+	} else if interrupted > 0 {
+		log.Printf("Marked %d interrupted mirror syncs as failed; they resume from their checkpoints", interrupted)
+	}
+
 	j.wg.Add(1)
 	go func() {
 		defer j.wg.Done()
@@ -323,29 +174,23 @@ func (j *MirrorSyncJob) runScheduledSyncs(ctx context.Context) {
 
 	for _, mirror := range mirrors {
 		// Check if this mirror is already syncing
-		j.activeSyncsMutex.Lock()
-		if j.activeSyncs[mirror.ID] {
+		syncCtx, active, ok := j.reserveSync(ctx, mirror.ID)
+		if !ok {
 			log.Printf("Mirror %s is already syncing, skipping", mirror.Name)
-			j.activeSyncsMutex.Unlock()
 			continue
 		}
-		j.activeSyncs[mirror.ID] = true
-		j.activeSyncsMutex.Unlock()
 
 		// Run sync in a goroutine
-		go j.syncMirror(ctx, mirror)
+		go j.syncMirror(syncCtx, mirror, active)
 	}
 }
 
 // syncMirror performs the actual synchronization of a mirror
-func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConfiguration) {
-	defer func() {
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, config.ID)
-		j.activeSyncsMutex.Unlock()
-	}()
+func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConfiguration, active *activeSync) {
+	defer j.releaseSync(config.ID)
 
 	log.Printf("Starting sync for mirror: %s (ID: %s)", config.Name, config.ID)
+	active.progress.publish(SyncEvent{Type: SyncEventStarted})
 
 	// Create sync history record
 	syncHistory := &models.MirrorSyncHistory{
@@ -366,7 +211,7 @@ func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConf
 	}
 
 	// Perform the actual sync
-	syncDetails, err := j.performSync(ctx, config)
+	syncDetails, err := j.performSync(ctx, config, active.progress)
 
 	// Create a new context for cleanup operations to ensure they complete even if the original context is cancelled
 	// Use a background context with a reasonable timeout
@@ -383,7 +228,21 @@ func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConf
 		syncHistory.ProvidersFailed = syncDetails.ProvidersFailed
 	}
 
-	if err != nil {
+	if err != nil && j.wasCancelled(config.ID) {
+		log.Printf("Sync cancelled for mirror %s", config.Name)
+		syncHistory.Status = "cancelled"
+		errMsg := "sync cancelled by an administrator"
+		syncHistory.ErrorMessage = &errMsg
+
+		// A cancelled sync is not resumed; the next one starts afresh at the usual interval.
+		// The versions it interrupted were removed, so they are synced again then.
+		if clearErr := j.mirrorRepo.ClearSyncCheckpoints(cleanupCtx, config.ID); clearErr != nil {
+			log.Printf("ERROR: Failed to clear sync checkpoints of cancelled sync: %v", clearErr)
+		}
+		if updateErr := j.mirrorRepo.UpdateSyncStatus(cleanupCtx, config.ID, "cancelled", &errMsg); updateErr != nil {
+			log.Printf("ERROR: Failed to update mirror config status to 'cancelled': %v", updateErr)
+		}
+	} else if err != nil {
 		log.Printf("Sync failed for mirror %s: %v", config.Name, err)
 		syncHistory.Status = "failed"
 		errMsg := err.Error()
@@ -420,16 +279,24 @@ func (j *MirrorSyncJob) syncMirror(ctx context.Context, config models.MirrorConf
 	} else {
 		log.Printf("Successfully updated sync history for mirror %s", config.Name)
 	}
+
+	completed := SyncEvent{Type: SyncEventCompleted, Status: syncHistory.Status}
+	if syncHistory.ErrorMessage != nil {
+		completed.Error = *syncHistory.ErrorMessage
+	}
+	active.progress.publish(completed)
 }
 
 // SyncDetails contains detailed information about a sync operation
 type SyncDetails struct {
-	Namespaces      []string         `json:"namespaces"`
-	ProvidersFound  int              `json:"providers_found"`
-	ProvidersSynced int              `json:"providers_synced"`
-	ProvidersFailed int              `json:"providers_failed"`
-	Errors          []string         `json:"errors,omitempty"`
-	SyncedProviders []SyncedProvider `json:"synced_providers,omitempty"`
+	Namespaces       []string         `json:"namespaces"`
+	ProvidersFound   int              `json:"providers_found"`
+	ProvidersSynced  int              `json:"providers_synced"`
+	ProvidersFailed  int              `json:"providers_failed"`
+	ProvidersSkipped int              `json:"providers_skipped"` // Kept from syncing by the mirror policies
+	Errors           []string         `json:"errors,omitempty"`
+	SyncedProviders  []SyncedProvider `json:"synced_providers,omitempty"`
+	PolicyDecisions  []PolicyDecision `json:"policy_decisions,omitempty"`
 }
 
 // SyncedProvider contains information about a synced provider
@@ -440,14 +307,110 @@ type SyncedProvider struct {
 	VersionsNew int      `json:"versions_new"`
 }
 
-// performSync performs the actual provider synchronization
-func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorConfiguration) (*SyncDetails, error) {
+// syncRun holds what the workers of one mirror sync share
+type syncRun struct {
+	config        models.MirrorConfiguration
+	upstream      *mirror.UpstreamRegistry
+	versionFilter *mirror.VersionFilter // nil when every version is synced
+	checkpoints   *syncCheckpoints
+	progress      *syncProgress
+	// versionSlots caps the versions synced at once, platformSlots the binaries downloaded at once
+	versionSlots  chan struct{}
+	platformSlots chan struct{}
+}
+
+// acquire waits for a free slot, returning false if ctx is done first
+func acquire(ctx context.Context, slots chan struct{}) bool {
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// syncCheckpoints records how far a mirror sync got with each provider, version and platform,
+// so a sync interrupted by a restart skips the work already done
+type syncCheckpoints struct {
+	repo     *repositories.MirrorRepository
+	mirrorID uuid.UUID
+	statuses map[string]string
+	mu       sync.Mutex
+}
+
+// loadCheckpoints reads the checkpoints left by an interrupted sync of a mirror
+func (j *MirrorSyncJob) loadCheckpoints(ctx context.Context, mirrorID uuid.UUID) (*syncCheckpoints, error) {
+	saved, err := j.mirrorRepo.ListSyncCheckpoints(ctx, mirrorID)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := &syncCheckpoints{
+		repo:     j.mirrorRepo,
+		mirrorID: mirrorID,
+		statuses: make(map[string]string, len(saved)),
+	}
+	for _, cp := range saved {
+		checkpoints.statuses[checkpointKey(cp.Namespace, cp.ProviderType, cp.Version, cp.OS, cp.Arch)] = cp.Status
+	}
+	return checkpoints, nil
+}
+
+func checkpointKey(namespace, providerType, version, os, arch string) string {
+	return strings.Join([]string{namespace, providerType, version, os, arch}, "/")
+}
+
+// status returns the recorded status of a provider (empty version), version (empty os and arch)
+// or platform, or "" if the sync has not reached it
+func (c *syncCheckpoints) status(namespace, providerType, version, os, arch string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.statuses[checkpointKey(namespace, providerType, version, os, arch)]
+}
+
+// save records the status of a provider, version or platform. Failing to record it only costs
+// repeating the work after an interruption, so errors are logged.
+func (c *syncCheckpoints) save(ctx context.Context, namespace, providerType, version, os, arch, status string, syncErr error) {
+	c.mu.Lock()
+	c.statuses[checkpointKey(namespace, providerType, version, os, arch)] = status
+	c.mu.Unlock()
+
+	checkpoint := &models.MirrorSyncCheckpoint{
+		MirrorConfigID: c.mirrorID,
+		Namespace:      namespace,
+		ProviderType:   providerType,
+		Version:        version,
+		OS:             os,
+		Arch:           arch,
+		Status:         status,
+	}
+	if syncErr != nil {
+		errMsg := syncErr.Error()
+		checkpoint.ErrorMessage = &errMsg
+	}
+	if err := c.repo.SaveSyncCheckpoint(ctx, checkpoint); err != nil {
+		log.Printf("Warning: failed to save sync checkpoint for %s/%s %s %s/%s: %v", namespace, providerType, version, os, arch, err)
+	}
+}
+
+// resuming reports whether checkpoints were left by an interrupted sync
+func (c *syncCheckpoints) resuming() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.statuses) > 0
+}
+
+// performSync performs the actual provider synchronization. Providers are synced side by side,
+// with up to the mirror's sync concurrency of versions, and of binaries, downloaded at once.
+func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorConfiguration, progress *syncProgress) (*SyncDetails, error) {
 	details := &SyncDetails{
 		Errors: []string{},
 	}
 
 	// Create upstream registry client
 	upstreamClient := mirror.NewUpstreamRegistry(config.UpstreamRegistryURL)
+	upstreamClient.Limiter = j.hosts
+	upstreamClient.MaxRetryWait = j.maxRetryWait
 
 	// Test service discovery first
 	_, err := upstreamClient.DiscoverServices(ctx)
@@ -465,6 +428,13 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 		}
 	}
 
+	var versionFilter *mirror.VersionFilter
+	if config.VersionFilter != nil && *config.VersionFilter != "" {
+		if versionFilter, err = mirror.ParseVersionFilter(*config.VersionFilter); err != nil {
+			return details, fmt.Errorf("invalid version filter: %w", err)
+		}
+	}
+
 	if config.ProviderFilter != nil && *config.ProviderFilter != "" {
 		if err := json.Unmarshal([]byte(*config.ProviderFilter), &providerNames); err != nil {
 			return details, fmt.Errorf("invalid provider filter: %w", err)
@@ -490,30 +460,145 @@ func (j *MirrorSyncJob) performSync(ctx context.Context, config models.MirrorCon
 		return details, fmt.Errorf("provider enumeration not yet implemented - please also configure provider filters (e.g., 'aws', 'azurerm', 'google')")
 	}
 
-	// Sync all namespace/provider combinations
+	policies, err := j.loadMirrorPolicies(ctx, config)
+	if err != nil {
+		return details, fmt.Errorf("failed to load mirror policies: %w", err)
+	}
+
+	checkpoints, err := j.loadCheckpoints(ctx, config.ID)
+	if err != nil {
+		return details, fmt.Errorf("failed to load sync checkpoints: %w", err)
+	}
+	if checkpoints.resuming() {
+		log.Printf("Resuming interrupted sync of mirror %s", config.Name)
+	}
+
+	concurrency := config.SyncConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	run := &syncRun{
+		config:        config,
+		upstream:      upstreamClient,
+		versionFilter: versionFilter,
+		checkpoints:   checkpoints,
+		progress:      progress,
+		versionSlots:  make(chan struct{}, concurrency),
+		platformSlots: make(chan struct{}, concurrency),
+	}
+
+	// Sync all namespace/provider combinations. Running out of quota stops the whole sync,
+	// since every remaining provider would be downloaded only to be rejected.
+	syncCtx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	var quotaErr error
+	var detailsMu sync.Mutex
+	var wg sync.WaitGroup
 	for _, namespace := range namespaces {
 		for _, providerName := range providerNames {
-			syncedProvider, err := j.syncProvider(ctx, upstreamClient, config, namespace, providerName)
-			if err != nil {
-				details.ProvidersFailed++
-				details.Errors = append(details.Errors, fmt.Sprintf("%s/%s: %v", namespace, providerName, err))
-				log.Printf("Error syncing provider %s/%s: %v", namespace, providerName, err)
-			} else {
+			if len(policies) > 0 {
+				decision, err := j.evaluatePolicy(syncCtx, config, policies, namespace, providerName)
+
+				detailsMu.Lock()
+				if err != nil {
+					// Without a decision the provider is not synced
+					details.ProvidersFailed++
+					details.Errors = append(details.Errors, fmt.Sprintf("%s/%s: %v", namespace, providerName, err))
+					log.Printf("Error evaluating mirror policies for %s/%s: %v", namespace, providerName, err)
+					detailsMu.Unlock()
+					continue
+				}
+				details.PolicyDecisions = append(details.PolicyDecisions, decision)
+				if decision.Decision != PolicyDecisionAllowed {
+					details.ProvidersSkipped++
+					detailsMu.Unlock()
+					log.Printf("Skipping provider %s/%s: %s", namespace, providerName, decision.Reason)
+					progress.publish(SyncEvent{Type: SyncEventProviderSkipped, Namespace: namespace, Provider: providerName, Error: decision.Reason})
+					continue
+				}
+				detailsMu.Unlock()
+			}
+
+			wg.Add(1)
+			go func(namespace, providerName string) {
+				defer wg.Done()
+				syncedProvider, err := j.syncProvider(syncCtx, run, namespace, providerName)
+
+				detailsMu.Lock()
+				defer detailsMu.Unlock()
+				if errors.Is(err, services.ErrStorageQuotaExceeded) && quotaErr == nil {
+					quotaErr = err
+					stop(err)
+					log.Printf("Stopping sync of mirror %s: %v", config.Name, err)
+				}
+				if err != nil {
+					if quotaErr != nil && errors.Is(err, context.Canceled) {
+						err = fmt.Errorf("not synced: %w", quotaErr)
+					}
+					details.ProvidersFailed++
+					details.Errors = append(details.Errors, fmt.Sprintf("%s/%s: %v", namespace, providerName, err))
+					log.Printf("Error syncing provider %s/%s: %v", namespace, providerName, err)
+					if ctx.Err() == nil {
+						progress.publish(SyncEvent{Type: SyncEventProviderFailed, Namespace: namespace, Provider: providerName, Error: err.Error()})
+					}
+					return
+				}
 				details.ProvidersSynced++
 				details.SyncedProviders = append(details.SyncedProviders, *syncedProvider)
 				log.Printf("Successfully synced provider %s/%s (%d versions)", namespace, providerName, len(syncedProvider.Versions))
-			}
+			}(namespace, providerName)
 		}
 	}
+	wg.Wait()
+
+	sort.Slice(details.SyncedProviders, func(a, b int) bool {
+		pa, pb := details.SyncedProviders[a], details.SyncedProviders[b]
+		if pa.Namespace != pb.Namespace {
+			return pa.Namespace < pb.Namespace
+		}
+		return pa.Name < pb.Name
+	})
+	sort.Strings(details.Errors)
 
 	details.Namespaces = namespaces
 	details.ProvidersFound = len(namespaces) * len(providerNames)
 
+	if quotaErr != nil {
+		// The versions the quota interrupted were removed, so they are synced afresh next time
+		return details, fmt.Errorf("sync stopped: %w", quotaErr)
+	}
+	if syncCtx.Err() != nil {
+		// Keep the checkpoints so the next sync resumes from here
+		return details, fmt.Errorf("sync interrupted: %w", syncCtx.Err())
+	}
+
+	// Every provider has been walked, so the next sync starts afresh
+	if err := j.mirrorRepo.ClearSyncCheckpoints(ctx, config.ID); err != nil {
+		log.Printf("Warning: failed to clear sync checkpoints of mirror %s: %v", config.Name, err)
+	}
+
 	return details, nil
 }
 
 // syncProvider syncs a single provider from upstream
-func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient *mirror.UpstreamRegistry, config models.MirrorConfiguration, namespace, providerName string) (*SyncedProvider, error) {
+func (j *MirrorSyncJob) syncProvider(ctx context.Context, run *syncRun, namespace, providerName string) (*SyncedProvider, error) {
+	config := run.config
+	upstreamClient := run.upstream
+
+	syncedProvider := &SyncedProvider{
+		Namespace: namespace,
+		Name:      providerName,
+		Versions:  []string{},
+	}
+
+	if run.checkpoints.status(namespace, providerName, "", "", "") == models.CheckpointCompleted {
+		log.Printf("Provider %s/%s was synced before the sync was interrupted, skipping", namespace, providerName)
+		return syncedProvider, nil
+	}
+	run.checkpoints.save(ctx, namespace, providerName, "", "", "", models.CheckpointInProgress, nil)
+	run.progress.publish(SyncEvent{Type: SyncEventProviderStarted, Namespace: namespace, Provider: providerName})
+
 	// List versions from upstream
 	allVersions, err := upstreamClient.ListProviderVersions(ctx, namespace, providerName)
 	if err != nil {
@@ -525,7 +610,7 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient *mirror
 	}
 
 	// Apply version filter
-	versions := filterVersions(allVersions, config.VersionFilter)
+	versions := run.versionFilter.Apply(allVersions)
 	if len(versions) == 0 {
 		return nil, fmt.Errorf("no versions match filter %q (found %d total versions)", *config.VersionFilter, len(allVersions))
 	}
@@ -533,12 +618,6 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient *mirror
 	log.Printf("Filtered %d versions to %d versions using filter %q for %s/%s",
 		len(allVersions), len(versions), safeString(config.VersionFilter), namespace, providerName)
 
-	syncedProvider := &SyncedProvider{
-		Namespace: namespace,
-		Name:      providerName,
-		Versions:  []string{},
-	}
-
 	// Determine organization ID for the provider
 	// In multi-tenant mode, use the org from config; in single-tenant mode, we'll need a default org
 	var orgID string
@@ -637,55 +716,68 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient *mirror
 		existingVersionMap[v.Version] = v
 	}
 
-	// Sync each version
+	// Prefer the license reported by upstream; otherwise it is detected from each version's binaries
+	upstreamLicense := ""
+	if metadata, err := upstreamClient.GetProviderMetadata(ctx, namespace, providerName); err != nil {
+		log.Printf("Warning: failed to get provider metadata for %s/%s: %v", namespace, providerName, err)
+	} else {
+		upstreamLicense = metadata.License
+	}
+
+	// Sync the versions, several at once. Versions an interrupted sync had started are resumed.
+	var quotaErr error
+	var versionsMu sync.Mutex
+	var wg sync.WaitGroup
 	for _, version := range versions {
 		syncedProvider.Versions = append(syncedProvider.Versions, version.Version)
 
+		versionStatus := run.checkpoints.status(namespace, providerName, version.Version, "", "")
+		if versionStatus == models.CheckpointCompleted {
+			continue
+		}
+
 		// Check if version already exists
-		if existingVersion, exists := existingVersionMap[version.Version]; exists {
+		existingVersion, exists := existingVersionMap[version.Version]
+		if exists && versionStatus != models.CheckpointInProgress {
 			log.Printf("Version %s of %s/%s already exists, skipping download", version.Version, namespace, providerName)
-
-			// But ensure the mirrored_provider_version tracking record exists
-			if mirroredProvider != nil {
-				versionUUID, _ := uuid.Parse(existingVersion.ID)
-				existingTracking, err := j.mirrorRepo.GetMirroredProviderVersionByVersionID(ctx, versionUUID)
-
-				if err != nil || existingTracking == nil {
-					// Create tracking record for this existing version
-					log.Printf("Creating tracking record for existing version %s (ID: %s)", version.Version, versionUUID)
-					mpv := &models.MirroredProviderVersion{
-						ID:                 uuid.New(),
-						MirroredProviderID: mirroredProvider.ID,
-						ProviderVersionID:  versionUUID,
-						UpstreamVersion:    version.Version,
-						SyncedAt:           time.Now(),
-						ShasumVerified:     false, // Unknown for existing versions
-						GPGVerified:        false, // Unknown for existing versions
-					}
-					if err := j.mirrorRepo.CreateMirroredProviderVersion(ctx, mpv); err != nil {
-						log.Printf("Warning: failed to create tracking for existing version: %v", err)
-					} else {
-						log.Printf("Successfully created tracking for version %s", version.Version)
-					}
-				} else {
-					log.Printf("Version %s already has tracking record (ID: %s)", version.Version, existingTracking.ID)
-				}
-			} else {
-				log.Printf("WARNING: mirroredProvider is nil, cannot create version tracking for %s", version.Version)
-			}
+			j.ensureVersionTracking(ctx, mirroredProvider, existingVersion, version.Version)
 			continue
 		}
 
-		// Sync this version (download and create)
-		err := j.syncProviderVersion(ctx, upstreamClient, localProvider, mirroredProvider, namespace, providerName, version, config.PlatformFilter)
-		if err != nil {
-			log.Printf("Error syncing version %s of %s/%s: %v", version.Version, namespace, providerName, err)
-			// Continue with other versions
-			continue
+		if !acquire(ctx, run.versionSlots) {
+			break
 		}
+		wg.Add(1)
+		go func(version mirror.ProviderVersion, existingVersion *models.ProviderVersion) {
+			defer wg.Done()
+			defer func() { <-run.versionSlots }()
+
+			// Sync this version (download and create)
+			err := j.syncProviderVersion(ctx, run, localProvider, mirroredProvider, namespace, providerName, version, existingVersion, upstreamLicense)
+
+			versionsMu.Lock()
+			defer versionsMu.Unlock()
+			if errors.Is(err, services.ErrStorageQuotaExceeded) {
+				quotaErr = err
+				return
+			}
+			if err != nil {
+				log.Printf("Error syncing version %s of %s/%s: %v", version.Version, namespace, providerName, err)
+				// Continue with other versions
+				return
+			}
+
+			syncedProvider.VersionsNew++
+			log.Printf("Successfully synced version %s of %s/%s", version.Version, namespace, providerName)
+		}(version, existingVersion)
+	}
+	wg.Wait()
 
-		syncedProvider.VersionsNew++
-		log.Printf("Successfully synced version %s of %s/%s", version.Version, namespace, providerName)
+	if quotaErr != nil {
+		return nil, quotaErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
 	// Update mirrored provider sync time
@@ -697,24 +789,87 @@ func (j *MirrorSyncJob) syncProvider(ctx context.Context, upstreamClient *mirror
 		j.mirrorRepo.UpdateMirroredProvider(ctx, mirroredProvider)
 	}
 
+	run.checkpoints.save(ctx, namespace, providerName, "", "", "", models.CheckpointCompleted, nil)
+	run.progress.publish(SyncEvent{Type: SyncEventProviderCompleted, Namespace: namespace, Provider: providerName})
+
 	log.Printf("Synced %s/%s: %d total versions, %d new",
 		namespace, providerName, len(versions), syncedProvider.VersionsNew)
 
 	return syncedProvider, nil
 }
 
-// syncProviderVersion downloads and stores a single version of a provider
+// ensureVersionTracking creates the mirrored_provider_version tracking record of a version
+// that was already stored locally, if it has none
+func (j *MirrorSyncJob) ensureVersionTracking(ctx context.Context, mirroredProvider *models.MirroredProvider, existingVersion *models.ProviderVersion, version string) {
+	if mirroredProvider == nil {
+		log.Printf("WARNING: mirroredProvider is nil, cannot create version tracking for %s", version)
+		return
+	}
+
+	versionUUID, _ := uuid.Parse(existingVersion.ID)
+	existingTracking, err := j.mirrorRepo.GetMirroredProviderVersionByVersionID(ctx, versionUUID)
+	if err == nil && existingTracking != nil {
+		log.Printf("Version %s already has tracking record (ID: %s)", version, existingTracking.ID)
+		return
+	}
+
+	// Create tracking record for this existing version
+	log.Printf("Creating tracking record for existing version %s (ID: %s)", version, versionUUID)
+	mpv := &models.MirroredProviderVersion{
+		ID:                 uuid.New(),
+		MirroredProviderID: mirroredProvider.ID,
+		ProviderVersionID:  versionUUID,
+		UpstreamVersion:    version,
+		SyncedAt:           time.Now(),
+		ShasumVerified:     false, // Unknown for existing versions
+		GPGVerified:        false, // Unknown for existing versions
+	}
+	if err := j.mirrorRepo.CreateMirroredProviderVersion(ctx, mpv); err != nil {
+		log.Printf("Warning: failed to create tracking for existing version: %v", err)
+	} else {
+		log.Printf("Successfully created tracking for version %s", version)
+	}
+}
+
+// syncProviderVersion downloads and stores a single version of a provider. existingVersion is the
+// version record left by an interrupted sync, whose stored platforms are kept.
 func (j *MirrorSyncJob) syncProviderVersion(
 	ctx context.Context,
-	upstreamClient *mirror.UpstreamRegistry,
+	run *syncRun,
 	localProvider *models.Provider,
 	mirroredProvider *models.MirroredProvider,
 	namespace, providerName string,
 	version mirror.ProviderVersion,
-	platformFilter *string,
-) error {
+	existingVersion *models.ProviderVersion,
+	upstreamLicense string,
+) (err error) {
+	upstreamClient := run.upstream
+
+	// Record the version as started before its record is created, so an interruption leaves it resumable
+	run.checkpoints.save(ctx, namespace, providerName, version.Version, "", "", models.CheckpointInProgress, nil)
+	run.progress.publish(SyncEvent{Type: SyncEventVersionStarted, Namespace: namespace, Provider: providerName, Version: version.Version})
+	var versionRecord *models.ProviderVersion
+	defer func() {
+		if ctx.Err() != nil {
+			// Interrupted; resume the version next time, unless the quota or an administrator
+			// stopped the sync. Such a version is not resumed, so it's removed.
+			cause := context.Cause(ctx)
+			if versionRecord != nil && (errors.Is(cause, services.ErrStorageQuotaExceeded) || errors.Is(cause, errSyncCancelled)) {
+				j.removeVersion(context.WithoutCancel(ctx), versionRecord)
+			}
+			return
+		}
+		if err != nil {
+			run.checkpoints.save(ctx, namespace, providerName, version.Version, "", "", models.CheckpointFailed, err)
+			run.progress.publish(SyncEvent{Type: SyncEventVersionFailed, Namespace: namespace, Provider: providerName, Version: version.Version, Error: err.Error()})
+			return
+		}
+		run.checkpoints.save(ctx, namespace, providerName, version.Version, "", "", models.CheckpointCompleted, nil)
+		run.progress.publish(SyncEvent{Type: SyncEventVersionCompleted, Namespace: namespace, Provider: providerName, Version: version.Version})
+	}()
+
 	// Filter platforms if a filter is specified
-	platforms := filterPlatforms(version.Platforms, platformFilter)
+	platforms := filterPlatforms(version.Platforms, run.config.PlatformFilter)
 
 	log.Printf("Syncing version %s of %s/%s with %d platforms (filtered from %d)",
 		version.Version, namespace, providerName, len(platforms), len(version.Platforms))
@@ -772,34 +927,171 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		}
 	}
 
+	shasumVerified := len(shasumContent) > 0
+	run.progress.publish(SyncEvent{
+		Type:           SyncEventVerification,
+		Namespace:      namespace,
+		Provider:       providerName,
+		Version:        version.Version,
+		ShasumVerified: &shasumVerified,
+		GPGVerified:    &gpgVerified,
+	})
+
 	// Parse SHASUM file into a map
 	shasumMap := parseSHASUMFile(string(shasumContent))
 
-	// Create the version record
-	versionRecord := &models.ProviderVersion{
-		ProviderID:         localProvider.ID,
-		Version:            version.Version,
-		Protocols:          version.Protocols,
-		GPGPublicKey:       gpgPublicKey,
-		ShasumURL:          packageInfo.SHASumsURL,
-		ShasumSignatureURL: packageInfo.SHASumsSignatureURL,
+	// Create the version record, or pick up the platforms already stored for it
+	storedPlatforms := make(map[string]bool)
+	versionRecord = existingVersion
+	if versionRecord != nil {
+		log.Printf("Resuming interrupted sync of version %s of %s/%s", version.Version, namespace, providerName)
+		stored, err := j.providerRepo.ListPlatforms(ctx, versionRecord.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list stored platforms: %w", err)
+		}
+		for _, p := range stored {
+			storedPlatforms[p.OS+"/"+p.Arch] = true
+		}
+	} else {
+		versionRecord = &models.ProviderVersion{
+			ProviderID:         localProvider.ID,
+			Version:            version.Version,
+			Protocols:          version.Protocols,
+			GPGPublicKey:       gpgPublicKey,
+			ShasumURL:          packageInfo.SHASumsURL,
+			ShasumSignatureURL: packageInfo.SHASumsSignatureURL,
+		}
+
+		if err := j.providerRepo.CreateVersion(ctx, versionRecord); err != nil {
+			return fmt.Errorf("failed to create version record: %w", err)
+		}
 	}
 
-	if err := j.providerRepo.CreateVersion(ctx, versionRecord); err != nil {
-		return fmt.Errorf("failed to create version record: %w", err)
+	// Check the license against license policies on the first downloaded binary, before anything is stored.
+	// Binaries are downloaded concurrently, so the check is serialized.
+	var licenseErr error
+	var licenseMu sync.Mutex
+	licenseChecked := len(storedPlatforms) > 0 // Checked when the stored platforms were synced
+	admit := func(binary io.ReaderAt, size int64) error {
+		licenseMu.Lock()
+		defer licenseMu.Unlock()
+		if licenseErr != nil {
+			return licenseErr
+		}
+		if licenseChecked {
+			return nil
+		}
+		license := upstreamLicense
+		if license == "" {
+			license = validation.DetectLicenseInZipReader(binary, size)
+		}
+		decision, err := j.licenses.Check(ctx, localProvider.OrganizationID, models.ArtifactTypeProvider, license)
+		if err != nil {
+			licenseErr = fmt.Errorf("failed to evaluate license policies: %w", err)
+			return licenseErr
+		}
+		if !decision.Allowed {
+			licenseErr = fmt.Errorf("mirroring rejected: %s", decision.Message)
+			return licenseErr
+		}
+		licenseChecked = true
+		if license != "" {
+			versionRecord.LicenseSPDX = &license
+			if err := j.providerRepo.SetVersionLicense(ctx, versionRecord.ID, &license); err != nil {
+				log.Printf("Warning: failed to record license for %s/%s@%s: %v", namespace, providerName, version.Version, err)
+			}
+		}
+		return nil
 	}
 
-	// Download and store each platform binary (using filtered platforms)
+	// Reserve room in the organization's storage quota for each binary before it is stored.
+	// Binaries are stored concurrently, so each holds its reservation until it is recorded.
+	var quotaErr error
+	var quotaMu sync.Mutex
+	reserve := func(size int64) (func(), error) {
+		decision, release, err := j.quotas.Reserve(ctx, localProvider.OrganizationID, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check storage quota: %w", err)
+		}
+		if err := decision.Err(); err != nil {
+			quotaMu.Lock()
+			quotaErr = err
+			quotaMu.Unlock()
+			return nil, err
+		}
+		return release, nil
+	}
+
+	// Download and store each platform binary (using filtered platforms), several at once.
+	// A rejected license or exhausted quota stops the remaining downloads.
+	platformCtx, stopPlatforms := context.WithCancel(ctx)
+	defer stopPlatforms()
+
 	platformsDownloaded := 0
+	var platformsMu sync.Mutex
+	var wg sync.WaitGroup
 	for _, platform := range platforms {
-		err := j.syncPlatformBinary(ctx, upstreamClient, versionRecord, namespace, providerName, version.Version, platform, shasumMap)
-		if err != nil {
-			log.Printf("Error syncing platform %s/%s for %s/%s@%s: %v",
-				platform.OS, platform.Arch, namespace, providerName, version.Version, err)
-			// Continue with other platforms
+		if storedPlatforms[platform.OS+"/"+platform.Arch] {
+			platformsMu.Lock()
+			platformsDownloaded++
+			platformsMu.Unlock()
 			continue
 		}
-		platformsDownloaded++
+
+		if !acquire(platformCtx, run.platformSlots) {
+			break
+		}
+		wg.Add(1)
+		go func(platform mirror.ProviderPlatform) {
+			defer wg.Done()
+			defer func() { <-run.platformSlots }()
+
+			size, err := j.syncPlatformBinary(platformCtx, run, versionRecord, namespace, providerName, version.Version, platform, shasumMap, admit, reserve)
+			if platformCtx.Err() != nil {
+				return
+			}
+			if err != nil {
+				licenseMu.Lock()
+				rejected := licenseErr != nil
+				licenseMu.Unlock()
+				if rejected || errors.Is(err, services.ErrStorageQuotaExceeded) {
+					stopPlatforms()
+					return
+				}
+				log.Printf("Error syncing platform %s/%s for %s/%s@%s: %v",
+					platform.OS, platform.Arch, namespace, providerName, version.Version, err)
+				run.checkpoints.save(ctx, namespace, providerName, version.Version, platform.OS, platform.Arch, models.CheckpointFailed, err)
+				run.progress.publish(SyncEvent{
+					Type: SyncEventPlatformFailed, Namespace: namespace, Provider: providerName, Version: version.Version,
+					OS: platform.OS, Arch: platform.Arch, Error: err.Error(),
+				})
+				// Continue with other platforms
+				return
+			}
+			run.checkpoints.save(ctx, namespace, providerName, version.Version, platform.OS, platform.Arch, models.CheckpointCompleted, nil)
+			run.progress.publish(SyncEvent{
+				Type: SyncEventPlatformStored, Namespace: namespace, Provider: providerName, Version: version.Version,
+				OS: platform.OS, Arch: platform.Arch, Bytes: size,
+			})
+
+			platformsMu.Lock()
+			platformsDownloaded++
+			platformsMu.Unlock()
+		}(platform)
+	}
+	wg.Wait()
+
+	if licenseErr != nil || quotaErr != nil {
+		// Don't leave a version behind that is missing platforms
+		j.removeVersion(context.WithoutCancel(ctx), versionRecord)
+		versionRecord = nil
+		if licenseErr != nil {
+			return licenseErr
+		}
+		return quotaErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
 
 	if platformsDownloaded == 0 && len(platforms) > 0 {
@@ -814,6 +1106,11 @@ func (j *MirrorSyncJob) syncProviderVersion(
 		return fmt.Errorf("no platforms match filter for version %s", version.Version)
 	}
 
+	// Store the SBOM listing the mirrored platforms
+	if err := j.sboms.GenerateProviderSBOM(ctx, localProvider, versionRecord); err != nil {
+		log.Printf("Warning: failed to generate SBOM for %s/%s@%s: %v", namespace, providerName, version.Version, err)
+	}
+
 	// Track the mirrored version
 	if mirroredProvider != nil {
 		mpv := &models.MirroredProviderVersion{
@@ -822,7 +1119,7 @@ func (j *MirrorSyncJob) syncProviderVersion(
 			ProviderVersionID:  uuid.MustParse(versionRecord.ID),
 			UpstreamVersion:    version.Version,
 			SyncedAt:           time.Now(),
-			ShasumVerified:     len(shasumContent) > 0,
+			ShasumVerified:     shasumVerified,
 			GPGVerified:        gpgVerified,
 		}
 		j.mirrorRepo.CreateMirroredProviderVersion(ctx, mpv)
@@ -832,32 +1129,58 @@ func (j *MirrorSyncJob) syncProviderVersion(
 	return nil
 }
 
-// syncPlatformBinary downloads and stores a single platform binary
+// syncPlatformBinary downloads and stores a single platform binary, returning its size. The
+// binary is streamed through a temporary file rather than held in memory, reporting its progress.
 func (j *MirrorSyncJob) syncPlatformBinary(
 	ctx context.Context,
-	upstreamClient *mirror.UpstreamRegistry,
+	run *syncRun,
 	versionRecord *models.ProviderVersion,
 	namespace, providerName, version string,
 	platform mirror.ProviderPlatform,
 	shasumMap map[string]string,
-) error {
+	admit func(binary io.ReaderAt, size int64) error,
+	reserve func(size int64) (func(), error),
+) (int64, error) {
+	upstreamClient := run.upstream
+
 	// Get download info for this platform
 	packageInfo, err := upstreamClient.GetProviderPackage(ctx, namespace, providerName, version, platform.OS, platform.Arch)
 	if err != nil {
-		return fmt.Errorf("failed to get package info: %w", err)
+		return 0, fmt.Errorf("failed to get package info: %w", err)
 	}
 
 	log.Printf("Downloading %s from %s", packageInfo.Filename, packageInfo.DownloadURL)
 
-	// Download the binary
-	binaryContent, err := upstreamClient.DownloadFile(ctx, packageInfo.DownloadURL)
+	// Download the binary, calculating its SHA256 checksum on the way
+	tmpFile, err := os.CreateTemp("", "mirror-provider-*.zip")
 	if err != nil {
-		return fmt.Errorf("failed to download binary: %w", err)
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
 	}
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
 
-	// Calculate SHA256 checksum
-	checksum := sha256.Sum256(binaryContent)
-	checksumHex := hex.EncodeToString(checksum[:])
+	var counted int64
+	var lastReport time.Time
+	reportProgress := func(downloaded int64) {
+		if downloaded < counted {
+			counted = 0 // A retry started the download over
+		}
+		run.progress.downloaded(downloaded - counted)
+		counted = downloaded
+		if time.Since(lastReport) < syncProgressInterval {
+			return
+		}
+		lastReport = time.Now()
+		run.progress.publish(SyncEvent{
+			Type: SyncEventPlatformProgress, Namespace: namespace, Provider: providerName, Version: version,
+			OS: platform.OS, Arch: platform.Arch, Bytes: downloaded,
+		})
+	}
+
+	size, checksumHex, err := upstreamClient.DownloadToFile(ctx, packageInfo.DownloadURL, tmpFile, reportProgress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download binary: %w", err)
+	}
 
 	// Verify checksum if we have SHASUM data
 	expectedChecksum := packageInfo.SHA256Sum
@@ -869,18 +1192,28 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 	}
 
 	if expectedChecksum != "" && checksumHex != expectedChecksum {
-		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, checksumHex)
+		return 0, fmt.Errorf("checksum mismatch: expected %s, got %s", expectedChecksum, checksumHex)
 	}
 
 	log.Printf("Checksum verified for %s: %s", packageInfo.Filename, checksumHex)
 
+	if err := admit(tmpFile, size); err != nil {
+		return 0, err
+	}
+	release, err := reserve(size)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
 	// Store the binary
 	storagePath := fmt.Sprintf("providers/%s/%s/%s/%s/%s/%s",
 		namespace, providerName, version, platform.OS, platform.Arch, packageInfo.Filename)
 
-	uploadResult, err := j.storageBackend.Upload(ctx, storagePath, bytes.NewReader(binaryContent), int64(len(binaryContent)))
+	content := storage.WithChecksum(io.NewSectionReader(tmpFile, 0, size), checksumHex)
+	uploadResult, err := j.storageBackend.Upload(ctx, storagePath, content, size)
 	if err != nil {
-		return fmt.Errorf("failed to store binary: %w", err)
+		return 0, fmt.Errorf("failed to store binary: %w", err)
 	}
 
 	// Create platform record
@@ -890,17 +1223,37 @@ func (j *MirrorSyncJob) syncPlatformBinary(
 		Arch:              platform.Arch,
 		Filename:          packageInfo.Filename,
 		StoragePath:       uploadResult.Path,
-		StorageBackend:    "local", // TODO: Get from config
-		SizeBytes:         int64(len(binaryContent)),
+		StorageBackend:    j.storageKey,
+		SizeBytes:         size,
 		Shasum:            checksumHex,
 	}
 
 	if err := j.providerRepo.CreatePlatform(ctx, platformRecord); err != nil {
-		return fmt.Errorf("failed to create platform record: %w", err)
+		// Drop the stored copy, or its blob reference under content-addressed storage
+		j.storageBackend.Delete(ctx, uploadResult.Path)
+		return 0, fmt.Errorf("failed to create platform record: %w", err)
 	}
 
-	log.Printf("Stored platform %s/%s: %s (%d bytes)", platform.OS, platform.Arch, storagePath, len(binaryContent))
-	return nil
+	log.Printf("Stored platform %s/%s: %s (%d bytes)", platform.OS, platform.Arch, storagePath, size)
+	return size, nil
+}
+
+// removeVersion deletes a partly synced version along with the binaries already stored for it.
+// A version whose platforms can't be listed is kept, since deleting it would orphan its binaries.
+func (j *MirrorSyncJob) removeVersion(ctx context.Context, versionRecord *models.ProviderVersion) {
+	platforms, err := j.providerRepo.ListPlatforms(ctx, versionRecord.ID)
+	if err != nil {
+		log.Printf("Warning: failed to list platforms of version %s, keeping it: %v", versionRecord.Version, err)
+		return
+	}
+	for _, platform := range platforms {
+		if err := j.storageBackend.Delete(ctx, platform.StoragePath); err != nil {
+			log.Printf("Warning: failed to delete %s: %v", platform.StoragePath, err)
+		}
+	}
+	if err := j.providerRepo.DeleteVersion(ctx, versionRecord.ID); err != nil {
+		log.Printf("Warning: failed to delete version %s: %v", versionRecord.Version, err)
+	}
 }
 
 // parseSHASUMFile parses a SHA256SUMS file into a map of filename -> checksum
@@ -940,35 +1293,28 @@ func verifyGPGSignature(shasumContent, signatureContent []byte, publicKeys []str
 
 // TriggerManualSync triggers a manual sync for a specific mirror
 func (j *MirrorSyncJob) TriggerManualSync(ctx context.Context, mirrorID uuid.UUID) error {
-	// Check if already syncing and mark as active atomically
-	j.activeSyncsMutex.Lock()
-	if j.activeSyncs[mirrorID] {
-		j.activeSyncsMutex.Unlock()
+	// Check if already syncing and mark as active atomically.
+	// Use a background context for the sync operation since the HTTP request
+	// context will be cancelled when the response is sent
+	syncCtx, active, ok := j.reserveSync(context.Background(), mirrorID)
+	if !ok {
 		return fmt.Errorf("sync already in progress for this mirror")
 	}
-	// Mark as active immediately to prevent race conditions
-	j.activeSyncs[mirrorID] = true
 
 	// Get mirror config using the request context
 	config, err := j.mirrorRepo.GetByID(ctx, mirrorID)
 	if err != nil {
 		// If we fail to get config, clean up the active sync flag
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, mirrorID)
-		j.activeSyncsMutex.Unlock()
+		j.releaseSync(mirrorID)
 		return fmt.Errorf("failed to get mirror configuration: %w", err)
 	}
 	if config == nil {
 		// If config not found, clean up the active sync flag
-		j.activeSyncsMutex.Lock()
-		delete(j.activeSyncs, mirrorID)
-		j.activeSyncsMutex.Unlock()
+		j.releaseSync(mirrorID)
 		return fmt.Errorf("mirror configuration not found")
 	}
 
-	// Use a background context for the sync operation since the HTTP request
-	// context will be cancelled when the response is sent
-	go j.syncMirror(context.Background(), *config)
+	go j.syncMirror(syncCtx, *config, active)
 
 	return nil
 }
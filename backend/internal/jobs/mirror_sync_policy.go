@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+// Outcomes of evaluating the mirror policies for an upstream provider
+const (
+	PolicyDecisionAllowed         = "allowed"
+	PolicyDecisionDenied          = "denied"
+	PolicyDecisionPendingApproval = "pending_approval"
+	PolicyDecisionRejected        = "rejected"
+)
+
+// PolicyDecision records how the mirror policies treated an upstream provider during a sync
+type PolicyDecision struct {
+	Namespace         string     `json:"namespace"`
+	Provider          string     `json:"provider"`
+	Decision          string     `json:"decision"`
+	Policy            string     `json:"policy,omitempty"` // Name of the policy that matched
+	Reason            string     `json:"reason"`
+	ApprovalRequestID *uuid.UUID `json:"approval_request_id,omitempty"`
+}
+
+// mirrorPolicyStore is the part of the RBAC repository the sync consults for mirror policies and
+// approval requests
+type mirrorPolicyStore interface {
+	ListMirrorPolicies(ctx context.Context, orgID *uuid.UUID) ([]*models.MirrorPolicy, error)
+	CheckApproval(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error)
+	GetLatestApprovalRequest(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error)
+	CreateApprovalRequest(ctx context.Context, req *models.MirrorApprovalRequest) error
+}
+
+// loadMirrorPolicies returns the active policies, in priority order, that apply to a mirror's
+// organization. Without any, mirrors sync whatever their filters select.
+func (j *MirrorSyncJob) loadMirrorPolicies(ctx context.Context, config models.MirrorConfiguration) ([]*models.MirrorPolicy, error) {
+	policies, err := j.rbacRepo.ListMirrorPolicies(ctx, config.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []*models.MirrorPolicy
+	for _, policy := range policies {
+		if policy.IsActive {
+			active = append(active, policy)
+		}
+	}
+	return active, nil
+}
+
+// evaluatePolicy decides whether a mirror may sync an upstream provider. A provider whose policy
+// requires approval is synced only under an approved, unexpired approval request; one is
+// requested on its behalf the first time the sync comes across it.
+func (j *MirrorSyncJob) evaluatePolicy(ctx context.Context, config models.MirrorConfiguration, policies []*models.MirrorPolicy, namespace, providerName string) (PolicyDecision, error) {
+	result := models.EvaluateMirrorPolicies(policies, config.UpstreamRegistryURL, namespace, providerName)
+
+	decision := PolicyDecision{
+		Namespace: namespace,
+		Provider:  providerName,
+		Reason:    result.Reason,
+	}
+	if result.MatchedPolicy != nil {
+		decision.Policy = result.MatchedPolicy.Name
+	}
+
+	if !result.Allowed {
+		decision.Decision = PolicyDecisionDenied
+		return decision, nil
+	}
+	if !result.RequiresApproval {
+		decision.Decision = PolicyDecisionAllowed
+		return decision, nil
+	}
+
+	approval, err := j.rbacRepo.CheckApproval(ctx, config.ID, namespace, providerName)
+	if err != nil {
+		return decision, fmt.Errorf("failed to check approval: %w", err)
+	}
+	if approval != nil {
+		decision.Decision = PolicyDecisionAllowed
+		decision.Reason = result.Reason + "; approved"
+		decision.ApprovalRequestID = &approval.ID
+		return decision, nil
+	}
+
+	// Not approved yet. Wait on an open request rather than piling up new ones each sync.
+	latest, err := j.rbacRepo.GetLatestApprovalRequest(ctx, config.ID, namespace, providerName)
+	if err != nil {
+		return decision, fmt.Errorf("failed to look up approval requests: %w", err)
+	}
+	switch {
+	case latest != nil && latest.Status == models.ApprovalStatusPending:
+		decision.Decision = PolicyDecisionPendingApproval
+		decision.Reason = result.Reason + "; awaiting approval"
+		decision.ApprovalRequestID = &latest.ID
+		return decision, nil
+	case latest != nil && latest.Status == models.ApprovalStatusRejected:
+		decision.Decision = PolicyDecisionRejected
+		decision.Reason = result.Reason + "; approval rejected"
+		decision.ApprovalRequestID = &latest.ID
+		return decision, nil
+	}
+
+	// No request yet, or the last approval has expired
+	request := &models.MirrorApprovalRequest{
+		ID:                uuid.New(),
+		MirrorConfigID:    config.ID,
+		OrganizationID:    config.OrganizationID,
+		ProviderNamespace: namespace,
+		ProviderName:      &providerName,
+		Reason:            fmt.Sprintf("Requested by the sync of mirror %s. %s", config.Name, result.Reason),
+		Status:            models.ApprovalStatusPending,
+		AutoApproved:      false,
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+	if err := j.rbacRepo.CreateApprovalRequest(ctx, request); err != nil {
+		return decision, fmt.Errorf("failed to request approval: %w", err)
+	}
+	log.Printf("Requested approval to mirror %s/%s for mirror %s (request %s)", namespace, providerName, config.Name, request.ID)
+
+	decision.Decision = PolicyDecisionPendingApproval
+	decision.Reason = result.Reason + "; approval requested"
+	decision.ApprovalRequestID = &request.ID
+	return decision, nil
+}
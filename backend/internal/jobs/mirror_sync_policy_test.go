@@ -0,0 +1,167 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+
+	"github.com/google/uuid"
+)
+
+// memoryPolicyStore keeps approval requests in memory, answering the way the RBAC repository's
+// queries do
+type memoryPolicyStore struct {
+	requests []*models.MirrorApprovalRequest
+	created  []*models.MirrorApprovalRequest
+}
+
+func (m *memoryPolicyStore) ListMirrorPolicies(ctx context.Context, orgID *uuid.UUID) ([]*models.MirrorPolicy, error) {
+	return nil, nil
+}
+
+func (m *memoryPolicyStore) CheckApproval(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error) {
+	for _, req := range m.requests {
+		if m.covers(req, mirrorConfigID, namespace, provider) && req.IsValid() {
+			return req, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryPolicyStore) GetLatestApprovalRequest(ctx context.Context, mirrorConfigID uuid.UUID, namespace, provider string) (*models.MirrorApprovalRequest, error) {
+	var latest *models.MirrorApprovalRequest
+	for _, req := range m.requests {
+		if m.covers(req, mirrorConfigID, namespace, provider) && (latest == nil || req.CreatedAt.After(latest.CreatedAt)) {
+			latest = req
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryPolicyStore) CreateApprovalRequest(ctx context.Context, req *models.MirrorApprovalRequest) error {
+	m.requests = append(m.requests, req)
+	m.created = append(m.created, req)
+	return nil
+}
+
+func (m *memoryPolicyStore) covers(req *models.MirrorApprovalRequest, mirrorConfigID uuid.UUID, namespace, provider string) bool {
+	return req.MirrorConfigID == mirrorConfigID && req.ProviderNamespace == namespace &&
+		(req.ProviderName == nil || *req.ProviderName == provider)
+}
+
+func TestMirrorSyncEvaluatePolicy(t *testing.T) {
+	mirrorConfig := models.MirrorConfiguration{
+		ID:                  uuid.New(),
+		Name:                "public",
+		UpstreamRegistryURL: "https://registry.terraform.io",
+	}
+	namespace, provider := "hashicorp", "aws"
+	policy := func(policyType models.PolicyType, requiresApproval bool) []*models.MirrorPolicy {
+		return []*models.MirrorPolicy{{
+			Name:             "hashicorp",
+			PolicyType:       policyType,
+			NamespacePattern: &namespace,
+			IsActive:         true,
+			RequiresApproval: requiresApproval,
+		}}
+	}
+	request := func(status models.ApprovalStatus, age, expiresIn time.Duration) *models.MirrorApprovalRequest {
+		req := &models.MirrorApprovalRequest{
+			ID:                uuid.New(),
+			MirrorConfigID:    mirrorConfig.ID,
+			ProviderNamespace: "hashicorp",
+			ProviderName:      &provider,
+			Status:            status,
+			CreatedAt:         time.Now().Add(-age),
+		}
+		if expiresIn != 0 {
+			expires := time.Now().Add(expiresIn)
+			req.ExpiresAt = &expires
+		}
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		policies []*models.MirrorPolicy
+		requests []*models.MirrorApprovalRequest // On record before the sync
+		want     string
+		created  bool // Whether the sync requests approval
+	}{
+		{name: "denied", policies: policy(models.PolicyTypeDeny, false), want: PolicyDecisionDenied},
+		{name: "no matching policy", policies: nil, want: PolicyDecisionDenied},
+		{name: "allowed", policies: policy(models.PolicyTypeAllow, false), want: PolicyDecisionAllowed},
+		{name: "approval requested", policies: policy(models.PolicyTypeAllow, true), want: PolicyDecisionPendingApproval, created: true},
+		{
+			name:     "approval pending",
+			policies: policy(models.PolicyTypeAllow, true),
+			requests: []*models.MirrorApprovalRequest{request(models.ApprovalStatusPending, time.Hour, 0)},
+			want:     PolicyDecisionPendingApproval,
+		},
+		{
+			name:     "approved",
+			policies: policy(models.PolicyTypeAllow, true),
+			requests: []*models.MirrorApprovalRequest{request(models.ApprovalStatusApproved, time.Hour, time.Hour)},
+			want:     PolicyDecisionAllowed,
+		},
+		{
+			name:     "approval rejected",
+			policies: policy(models.PolicyTypeAllow, true),
+			requests: []*models.MirrorApprovalRequest{request(models.ApprovalStatusRejected, time.Hour, 0)},
+			want:     PolicyDecisionRejected,
+		},
+		{
+			name:     "approval expired",
+			policies: policy(models.PolicyTypeAllow, true),
+			requests: []*models.MirrorApprovalRequest{request(models.ApprovalStatusApproved, 48*time.Hour, -time.Hour)},
+			want:     PolicyDecisionPendingApproval,
+			created:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryPolicyStore{requests: tt.requests}
+			job := &MirrorSyncJob{rbacRepo: store}
+
+			decision, err := job.evaluatePolicy(context.Background(), mirrorConfig, tt.policies, "hashicorp", "aws")
+			if err != nil {
+				t.Fatalf("evaluatePolicy: %v", err)
+			}
+			if decision.Decision != tt.want {
+				t.Errorf("decision = %q (%s), want %q", decision.Decision, decision.Reason, tt.want)
+			}
+
+			if !tt.created {
+				if len(store.created) != 0 {
+					t.Errorf("created %d approval requests, want none", len(store.created))
+				}
+				if len(tt.requests) > 0 && (decision.ApprovalRequestID == nil || *decision.ApprovalRequestID != tt.requests[0].ID) {
+					t.Errorf("decision approval request = %v, want %s", decision.ApprovalRequestID, tt.requests[0].ID)
+				}
+				return
+			}
+			if len(store.created) != 1 {
+				t.Fatalf("created %d approval requests, want 1", len(store.created))
+			}
+			created := store.created[0]
+			if created.Status != models.ApprovalStatusPending || created.MirrorConfigID != mirrorConfig.ID || *created.ProviderName != "aws" {
+				t.Errorf("created request = %+v, want a pending request for hashicorp/aws", created)
+			}
+			if decision.ApprovalRequestID == nil || *decision.ApprovalRequestID != created.ID {
+				t.Errorf("decision approval request = %v, want %s", decision.ApprovalRequestID, created.ID)
+			}
+
+			// The next sync waits on the request instead of filing another
+			again, err := job.evaluatePolicy(context.Background(), mirrorConfig, tt.policies, "hashicorp", "aws")
+			if err != nil {
+				t.Fatalf("evaluatePolicy again: %v", err)
+			}
+			if again.Decision != PolicyDecisionPendingApproval || len(store.created) != 1 {
+				t.Errorf("second sync = %q with %d requests created, want pending on the first", again.Decision, len(store.created))
+			}
+		})
+	}
+}
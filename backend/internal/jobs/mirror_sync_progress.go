@@ -0,0 +1,224 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoActiveSync is returned when cancelling or following a mirror that is not syncing
+var ErrNoActiveSync = errors.New("no sync in progress for this mirror")
+
+// errSyncCancelled is the cause of a sync's context when an administrator cancelled it
+var errSyncCancelled = errors.New("sync cancelled by an administrator")
+
+// Types of mirror sync progress events
+const (
+	SyncEventStarted           = "sync_started"
+	SyncEventProviderStarted   = "provider_started"
+	SyncEventProviderCompleted = "provider_completed"
+	SyncEventProviderFailed    = "provider_failed"
+	SyncEventProviderSkipped   = "provider_skipped" // Kept from syncing by the mirror policies
+	SyncEventVersionStarted    = "version_started"
+	SyncEventVersionCompleted  = "version_completed"
+	SyncEventVersionFailed     = "version_failed"
+	SyncEventVerification      = "verification"
+	SyncEventPlatformProgress  = "platform_progress" // Bytes of a binary downloaded so far, sent while it downloads
+	SyncEventPlatformStored    = "platform_stored"
+	SyncEventPlatformFailed    = "platform_failed"
+	SyncEventCompleted         = "sync_completed"
+)
+
+// syncEventHistory is how many recent events are replayed to a subscriber joining a running sync
+const syncEventHistory = 200
+
+// syncProgressInterval is how often a binary being downloaded reports its progress
+const syncProgressInterval = time.Second
+
+// syncEventBuffer is how many events a subscriber may fall behind before events are dropped for it
+const syncEventBuffer = 256
+
+// SyncEvent is a progress event of a running mirror sync
+type SyncEvent struct {
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	OS        string    `json:"os,omitempty"`
+	Arch      string    `json:"arch,omitempty"`
+	// Bytes is the size of a stored platform binary, or the bytes of a binary downloaded so far;
+	// BytesTotal the bytes downloaded by the sync so far
+	Bytes      int64 `json:"bytes,omitempty"`
+	BytesTotal int64 `json:"bytes_total,omitempty"`
+	// Verification results of a version's SHA256SUMS file
+	ShasumVerified *bool  `json:"shasum_verified,omitempty"`
+	GPGVerified    *bool  `json:"gpg_verified,omitempty"`
+	Status         string `json:"status,omitempty"` // Final status of the sync: success, failed or cancelled
+	Error          string `json:"error,omitempty"`
+}
+
+// activeSync is a mirror sync in progress, which can be cancelled and followed
+type activeSync struct {
+	cancel    context.CancelCauseFunc
+	cancelled bool // Set when an administrator cancelled the sync
+	progress  *syncProgress
+}
+
+// syncProgress fans a sync's progress events out to subscribers, keeping recent events for
+// subscribers that join late. Slow subscribers miss events rather than stall the sync.
+// Download progress events are only sent live; later ones supersede them.
+type syncProgress struct {
+	recent      []SyncEvent
+	subscribers map[chan SyncEvent]struct{}
+	bytesTotal  int64
+	closed      bool
+	mu          sync.Mutex
+}
+
+func newSyncProgress() *syncProgress {
+	return &syncProgress{subscribers: make(map[chan SyncEvent]struct{})}
+}
+
+// publish sends an event to every subscriber
+func (p *syncProgress) publish(event SyncEvent) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+
+	event.Time = time.Now()
+	if event.Bytes > 0 || event.Type == SyncEventCompleted {
+		event.BytesTotal = p.bytesTotal
+	}
+
+	if event.Type != SyncEventPlatformProgress {
+		if len(p.recent) == syncEventHistory {
+			p.recent = append(p.recent[:0], p.recent[1:]...)
+		}
+		p.recent = append(p.recent, event)
+	}
+
+	for ch := range p.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// downloaded counts bytes downloaded by the sync, reported in the next events' BytesTotal
+func (p *syncProgress) downloaded(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytesTotal += n
+}
+
+// subscribe returns a channel receiving the recent events followed by new ones, closed when the
+// sync ends, and a function to stop receiving them
+func (p *syncProgress) subscribe() (<-chan SyncEvent, func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ch := make(chan SyncEvent, syncEventHistory+syncEventBuffer)
+	for _, event := range p.recent {
+		ch <- event
+	}
+	if p.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	p.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := p.subscribers[ch]; ok {
+			delete(p.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// close ends every subscription once the sync is over
+func (p *syncProgress) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for ch := range p.subscribers {
+		close(ch)
+	}
+	p.subscribers = make(map[chan SyncEvent]struct{})
+}
+
+// reserveSync marks a mirror as syncing, returning the context the sync runs under. It returns
+// false if the mirror is already syncing.
+func (j *MirrorSyncJob) reserveSync(ctx context.Context, mirrorID uuid.UUID) (context.Context, *activeSync, bool) {
+	j.activeSyncsMutex.Lock()
+	defer j.activeSyncsMutex.Unlock()
+	if _, ok := j.activeSyncs[mirrorID]; ok {
+		return nil, nil, false
+	}
+
+	syncCtx, cancel := context.WithCancelCause(ctx)
+	active := &activeSync{cancel: cancel, progress: newSyncProgress()}
+	j.activeSyncs[mirrorID] = active
+	return syncCtx, active, true
+}
+
+// releaseSync marks a mirror as no longer syncing
+func (j *MirrorSyncJob) releaseSync(mirrorID uuid.UUID) {
+	j.activeSyncsMutex.Lock()
+	defer j.activeSyncsMutex.Unlock()
+	if active, ok := j.activeSyncs[mirrorID]; ok {
+		active.cancel(nil)
+		active.progress.close()
+		delete(j.activeSyncs, mirrorID)
+	}
+}
+
+// CancelSync stops the running sync of a mirror. Versions being downloaded are removed and
+// the sync is recorded as cancelled.
+func (j *MirrorSyncJob) CancelSync(ctx context.Context, mirrorID uuid.UUID) error {
+	j.activeSyncsMutex.Lock()
+	defer j.activeSyncsMutex.Unlock()
+	active, ok := j.activeSyncs[mirrorID]
+	if !ok {
+		return ErrNoActiveSync
+	}
+	active.cancelled = true
+	active.cancel(errSyncCancelled)
+	return nil
+}
+
+// SubscribeSync follows the progress events of the running sync of a mirror. The channel is
+// closed when the sync ends; call the returned function to stop following earlier.
+func (j *MirrorSyncJob) SubscribeSync(mirrorID uuid.UUID) (<-chan SyncEvent, func(), error) {
+	j.activeSyncsMutex.Lock()
+	active, ok := j.activeSyncs[mirrorID]
+	j.activeSyncsMutex.Unlock()
+	if !ok {
+		return nil, nil, ErrNoActiveSync
+	}
+
+	events, unsubscribe := active.progress.subscribe()
+	return events, unsubscribe, nil
+}
+
+// wasCancelled reports whether an administrator cancelled the running sync of a mirror
+func (j *MirrorSyncJob) wasCancelled(mirrorID uuid.UUID) bool {
+	j.activeSyncsMutex.Lock()
+	defer j.activeSyncsMutex.Unlock()
+	active, ok := j.activeSyncs[mirrorID]
+	return ok && active.cancelled
+}
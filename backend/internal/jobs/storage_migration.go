@@ -0,0 +1,463 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// storageMigrationBatchSize is how many items a migration copies between status checks
+const storageMigrationBatchSize = 50
+
+var (
+	// ErrStorageMigrationInProgress is returned when another migration has not finished yet
+	ErrStorageMigrationInProgress = errors.New("another storage migration is in progress")
+	// ErrStorageMigrationState is returned when a migration cannot make the requested transition
+	ErrStorageMigrationState = errors.New("storage migration cannot be changed from its current status")
+	// ErrStorageMigrationSameBackend is returned when a migration's source is its target
+	ErrStorageMigrationSameBackend = errors.New("source and target storage configs must differ")
+)
+
+// StorageMigrationJob copies stored module and provider artifacts from one storage backend
+// to another. Progress is tracked per artifact in the database, so migrations survive
+// restarts, can be paused and resumed, and can be rolled back row by row.
+type StorageMigrationJob struct {
+	migrationRepo *repositories.StorageMigrationRepository
+	backends      *storage.Registry
+
+	active      map[uuid.UUID]bool
+	activeMutex sync.Mutex
+	stopCh      chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewStorageMigrationJob creates a new storage migration job. Source and target backends are
+// resolved through the backend registry by their storage config IDs, or for the server's
+// configured backend by its type, the keys storage_backend values record per artifact.
+func NewStorageMigrationJob(migrationRepo *repositories.StorageMigrationRepository, backends *storage.Registry) *StorageMigrationJob {
+	return &StorageMigrationJob{
+		migrationRepo: migrationRepo,
+		backends:      backends,
+		active:        make(map[uuid.UUID]bool),
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// Start resumes migrations that were running or rolling back when the server stopped
+func (j *StorageMigrationJob) Start(ctx context.Context) {
+	migrations, err := j.migrationRepo.ListMigrationsByStatus(ctx, models.StorageMigrationRunning, models.StorageMigrationRollingBack)
+	if err != nil {
+		log.Printf("Failed to list interrupted storage migrations: %v", err)
+		return
+	}
+
+	for _, migration := range migrations {
+		log.Printf("Resuming storage migration %s (%s)", migration.ID, migration.Status)
+		j.launch(migration)
+	}
+}
+
+// Stop signals running migrations to stop after their current item and waits for them.
+// Their status is left unchanged so they resume on the next start.
+func (j *StorageMigrationJob) Stop() {
+	close(j.stopCh)
+	j.wg.Wait()
+}
+
+// StartMigration creates a migration of every artifact stored on the source backend and
+// starts copying it to the target config in the background. A nil sourceConfigID means the server's configured backend.
+func (j *StorageMigrationJob) StartMigration(ctx context.Context, sourceConfigID *uuid.UUID, targetConfigID uuid.UUID, deleteTargetOnRollback bool, createdBy *uuid.UUID) (*models.StorageMigration, error) {
+	unfinished, err := j.migrationRepo.ListMigrationsByStatus(ctx,
+		models.StorageMigrationPending, models.StorageMigrationRunning,
+		models.StorageMigrationPaused, models.StorageMigrationRollingBack,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(unfinished) > 0 {
+		return nil, ErrStorageMigrationInProgress
+	}
+
+	if sourceConfigID != nil && *sourceConfigID == targetConfigID {
+		return nil, ErrStorageMigrationSameBackend
+	}
+
+	// Make sure both backends can be reached before anything is enqueued
+	if _, err := j.backends.Resolve(ctx, j.sourceKey(sourceConfigID)); err != nil {
+		return nil, err
+	}
+	if _, err := j.backends.Resolve(ctx, targetConfigID.String()); err != nil {
+		return nil, err
+	}
+
+	migration := &models.StorageMigration{
+		ID:                     uuid.New(),
+		SourceConfigID:         sourceConfigID,
+		TargetConfigID:         targetConfigID,
+		Status:                 models.StorageMigrationPending,
+		DeleteTargetOnRollback: deleteTargetOnRollback,
+		CreatedBy:              createdBy,
+	}
+	if err := j.migrationRepo.CreateMigration(ctx, migration); err != nil {
+		return nil, err
+	}
+
+	count, err := j.enqueue(ctx, migration)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Storage migration %s: %d artifacts to copy", migration.ID, count)
+
+	if err := j.migrationRepo.UpdateMigrationStatus(ctx, migration.ID, models.StorageMigrationRunning, nil); err != nil {
+		return nil, err
+	}
+	migration.Status = models.StorageMigrationRunning
+
+	j.launch(migration)
+	return migration, nil
+}
+
+// ResumeMigration restarts a paused, failed or cancelled migration. Failed items are retried
+// and artifacts published since the migration was created are picked up.
+func (j *StorageMigrationJob) ResumeMigration(ctx context.Context, id uuid.UUID) error {
+	migration, err := j.migrationRepo.GetMigration(ctx, id)
+	if err != nil {
+		return err
+	}
+	if migration == nil {
+		return fmt.Errorf("storage migration not found")
+	}
+	switch migration.Status {
+	case models.StorageMigrationPaused, models.StorageMigrationFailed, models.StorageMigrationCancelled:
+	default:
+		return ErrStorageMigrationState
+	}
+
+	if err := j.migrationRepo.RetryFailedItems(ctx, id); err != nil {
+		return err
+	}
+	if _, err := j.enqueue(ctx, migration); err != nil {
+		return err
+	}
+	if err := j.migrationRepo.UpdateMigrationStatus(ctx, id, models.StorageMigrationRunning, nil); err != nil {
+		return err
+	}
+	migration.Status = models.StorageMigrationRunning
+
+	j.launch(migration)
+	return nil
+}
+
+// sourceKey returns the registry key of a migration source. A nil config ID means the
+// server's configured backend.
+func (j *StorageMigrationJob) sourceKey(sourceConfigID *uuid.UUID) string {
+	if sourceConfigID == nil {
+		return j.backends.DefaultKey()
+	}
+	return sourceConfigID.String()
+}
+
+// enqueue records the artifacts still stored on the migration's source backend as its items
+func (j *StorageMigrationJob) enqueue(ctx context.Context, migration *models.StorageMigration) (int64, error) {
+	sources := j.backends.Aliases(j.sourceKey(migration.SourceConfigID))
+	return j.migrationRepo.EnqueueItems(ctx, migration.ID, sources, migration.TargetConfigID.String())
+}
+
+// PauseMigration stops a running migration after its current item
+func (j *StorageMigrationJob) PauseMigration(ctx context.Context, id uuid.UUID) error {
+	return j.transition(ctx, id, models.StorageMigrationPaused, models.StorageMigrationRunning)
+}
+
+// CancelMigration stops a migration for good. Artifacts already copied stay on the target
+// until the migration is rolled back.
+func (j *StorageMigrationJob) CancelMigration(ctx context.Context, id uuid.UUID) error {
+	return j.transition(ctx, id, models.StorageMigrationCancelled, models.StorageMigrationRunning, models.StorageMigrationPaused, models.StorageMigrationPending)
+}
+
+// RollbackMigration points every migrated artifact back at its source object. Source objects
+// are never deleted by a migration, so rolling back only rewrites the database rows and,
+// when requested at creation, removes the copies from the target. An interrupted rollback
+// can be retried.
+func (j *StorageMigrationJob) RollbackMigration(ctx context.Context, id uuid.UUID) error {
+	if err := j.transition(ctx, id, models.StorageMigrationRollingBack,
+		models.StorageMigrationPaused, models.StorageMigrationCompleted,
+		models.StorageMigrationFailed, models.StorageMigrationCancelled,
+		models.StorageMigrationRollingBack,
+	); err != nil {
+		return err
+	}
+
+	migration, err := j.migrationRepo.GetMigration(ctx, id)
+	if err != nil {
+		return err
+	}
+	j.launch(migration)
+	return nil
+}
+
+// transition moves a migration to status when it is currently in one of from
+func (j *StorageMigrationJob) transition(ctx context.Context, id uuid.UUID, status models.StorageMigrationStatus, from ...models.StorageMigrationStatus) error {
+	migration, err := j.migrationRepo.GetMigration(ctx, id)
+	if err != nil {
+		return err
+	}
+	if migration == nil {
+		return fmt.Errorf("storage migration not found")
+	}
+
+	for _, allowed := range from {
+		if migration.Status == allowed {
+			return j.migrationRepo.UpdateMigrationStatus(ctx, id, status, nil)
+		}
+	}
+	return ErrStorageMigrationState
+}
+
+// launch runs a migration in the background unless it is already being processed
+func (j *StorageMigrationJob) launch(migration *models.StorageMigration) {
+	j.activeMutex.Lock()
+	if j.active[migration.ID] {
+		j.activeMutex.Unlock()
+		return
+	}
+	j.active[migration.ID] = true
+	j.activeMutex.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		defer func() {
+			j.activeMutex.Lock()
+			delete(j.active, migration.ID)
+			j.activeMutex.Unlock()
+		}()
+
+		ctx := context.Background()
+		if migration.Status == models.StorageMigrationRollingBack {
+			j.rollback(ctx, migration)
+		} else {
+			j.run(ctx, migration)
+		}
+	}()
+}
+
+// stopping reports whether the job is shutting down
+func (j *StorageMigrationJob) stopping() bool {
+	select {
+	case <-j.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// currentStatus re-reads a migration's status so pause and cancel requests are noticed
+func (j *StorageMigrationJob) currentStatus(ctx context.Context, id uuid.UUID) (models.StorageMigrationStatus, error) {
+	migration, err := j.migrationRepo.GetMigration(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if migration == nil {
+		return "", fmt.Errorf("storage migration not found")
+	}
+	return migration.Status, nil
+}
+
+// run copies pending items in batches until none remain or the migration is paused or cancelled
+func (j *StorageMigrationJob) run(ctx context.Context, migration *models.StorageMigration) {
+	source, err := j.backends.Resolve(ctx, j.sourceKey(migration.SourceConfigID))
+	if err != nil {
+		j.finish(ctx, migration.ID, models.StorageMigrationFailed, fmt.Sprintf("failed to open source storage: %v", err))
+		return
+	}
+	targetKey := migration.TargetConfigID.String()
+	target, err := j.backends.Resolve(ctx, targetKey)
+	if err != nil {
+		j.finish(ctx, migration.ID, models.StorageMigrationFailed, fmt.Sprintf("failed to open target storage: %v", err))
+		return
+	}
+
+	for {
+		if j.stopping() {
+			return
+		}
+		status, err := j.currentStatus(ctx, migration.ID)
+		if err != nil {
+			log.Printf("Storage migration %s: %v", migration.ID, err)
+			return
+		}
+		if status != models.StorageMigrationRunning {
+			log.Printf("Storage migration %s stopped (%s)", migration.ID, status)
+			return
+		}
+
+		items, err := j.migrationRepo.ListItemsByStatus(ctx, migration.ID, models.StorageMigrationItemPending, storageMigrationBatchSize)
+		if err != nil {
+			j.finish(ctx, migration.ID, models.StorageMigrationFailed, err.Error())
+			return
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		for _, item := range items {
+			if err := j.migrateItem(ctx, item, source, target, targetKey); err != nil {
+				log.Printf("Storage migration %s: failed to migrate %s %s: %v", migration.ID, item.ArtifactType, item.ArtifactID, err)
+				if err := j.migrationRepo.FailItem(ctx, item.ID, err.Error()); err != nil {
+					log.Printf("Storage migration %s: %v", migration.ID, err)
+				}
+			}
+		}
+	}
+
+	progress, err := j.migrationRepo.GetProgress(ctx, migration.ID)
+	if err != nil {
+		j.finish(ctx, migration.ID, models.StorageMigrationFailed, err.Error())
+		return
+	}
+	if progress.Failed > 0 {
+		j.finish(ctx, migration.ID, models.StorageMigrationFailed, fmt.Sprintf("%d of %d artifacts could not be migrated", progress.Failed, progress.Total))
+		return
+	}
+
+	log.Printf("Storage migration %s completed: %d artifacts, %d bytes", migration.ID, progress.Migrated, progress.BytesDone)
+	j.finish(ctx, migration.ID, models.StorageMigrationCompleted, "")
+}
+
+// migrateItem copies one object from the source to the target, verifies it against the
+// recorded checksum and points the artifact at the copy
+func (j *StorageMigrationJob) migrateItem(ctx context.Context, item *models.StorageMigrationItem, source, target storage.Storage, targetKey string) error {
+	expected := ""
+	if item.Checksum != nil {
+		expected = strings.ToLower(*item.Checksum)
+	}
+
+	// A copy left behind by an interrupted run is reused when it already matches
+	copied := false
+	if exists, err := target.Exists(ctx, item.SourcePath); err == nil && exists && expected != "" {
+		if digest, err := hashObject(ctx, target, item.SourcePath); err == nil && digest == expected {
+			copied = true
+		}
+	}
+
+	if !copied {
+		reader, err := source.Download(ctx, item.SourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to read source object: %w", err)
+		}
+		hasher := sha256.New()
+		result, err := target.Upload(ctx, item.SourcePath, io.TeeReader(reader, hasher), item.SizeBytes)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write target object: %w", err)
+		}
+
+		digest := hex.EncodeToString(hasher.Sum(nil))
+		if (expected != "" && digest != expected) || (result.Checksum != "" && !strings.EqualFold(result.Checksum, digest)) {
+			j.discard(ctx, target, item.SourcePath)
+			return fmt.Errorf("checksum mismatch: expected %s, copied %s", expected, digest)
+		}
+	}
+
+	metadata, err := target.GetMetadata(ctx, item.SourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to verify target object: %w", err)
+	}
+	if item.SizeBytes > 0 && metadata.Size != item.SizeBytes {
+		j.discard(ctx, target, item.SourcePath)
+		return fmt.Errorf("size mismatch: expected %d bytes, copied %d", item.SizeBytes, metadata.Size)
+	}
+
+	if err := j.migrationRepo.CompleteItem(ctx, item, targetKey, item.SourcePath); err != nil {
+		j.discard(ctx, target, item.SourcePath)
+		return err
+	}
+
+	return nil
+}
+
+// rollback restores the source location of every migrated item
+func (j *StorageMigrationJob) rollback(ctx context.Context, migration *models.StorageMigration) {
+	targetKey := migration.TargetConfigID.String()
+	var target storage.Storage
+	if migration.DeleteTargetOnRollback {
+		var err error
+		if target, err = j.backends.Resolve(ctx, targetKey); err != nil {
+			log.Printf("Storage migration %s: target storage unavailable, copies will be kept: %v", migration.ID, err)
+		}
+	}
+
+	for {
+		if j.stopping() {
+			return
+		}
+
+		items, err := j.migrationRepo.ListItemsByStatus(ctx, migration.ID, models.StorageMigrationItemMigrated, storageMigrationBatchSize)
+		if err != nil {
+			j.finish(ctx, migration.ID, models.StorageMigrationRollingBack, err.Error())
+			return
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		for _, item := range items {
+			if err := j.migrationRepo.RollbackItem(ctx, item, targetKey); err != nil {
+				j.finish(ctx, migration.ID, models.StorageMigrationRollingBack, err.Error())
+				return
+			}
+			if target != nil && item.TargetPath != nil {
+				j.discard(ctx, target, *item.TargetPath)
+			}
+		}
+	}
+
+	log.Printf("Storage migration %s rolled back", migration.ID)
+	j.finish(ctx, migration.ID, models.StorageMigrationRolledBack, "")
+}
+
+// finish records the outcome of a migration run
+func (j *StorageMigrationJob) finish(ctx context.Context, id uuid.UUID, status models.StorageMigrationStatus, message string) {
+	var lastError *string
+	if message != "" {
+		lastError = &message
+		log.Printf("Storage migration %s: %s", id, message)
+	}
+	if err := j.migrationRepo.UpdateMigrationStatus(ctx, id, status, lastError); err != nil {
+		log.Printf("Failed to update storage migration %s: %v", id, err)
+	}
+}
+
+// discard removes a copy from the target, logging failures
+func (j *StorageMigrationJob) discard(ctx context.Context, target storage.Storage, path string) {
+	if err := target.Delete(ctx, path); err != nil {
+		log.Printf("Failed to delete migrated copy %s: %v", path, err)
+	}
+}
+
+// hashObject returns the SHA256 hex digest of a stored object
+func hashObject(ctx context.Context, backend storage.Storage, path string) (string, error) {
+	reader, err := backend.Download(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,254 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// replicationQueueInterval is how often the replication queue is drained
+	replicationQueueInterval  = 30 * time.Second
+	replicationQueueBatchSize = 100
+	replicationMaxBackoff     = time.Hour
+	replicationScanBatchSize  = 500
+)
+
+// StorageReplicationJob keeps the replicas of replicated storage configurations in step. It
+// carries out queued replica writes and deletes, and periodically compares every stored
+// artifact across replicas, queueing repairs for copies that are missing or differ.
+type StorageReplicationJob struct {
+	replicationRepo   *repositories.StorageReplicationRepository
+	storageConfigRepo *repositories.StorageConfigRepository
+	backends          *storage.Registry
+
+	activeRepairs      map[uuid.UUID]bool
+	activeRepairsMutex sync.Mutex
+	stopCh             chan struct{}
+	wg                 sync.WaitGroup
+}
+
+// NewStorageReplicationJob creates a new storage replication job
+func NewStorageReplicationJob(
+	replicationRepo *repositories.StorageReplicationRepository,
+	storageConfigRepo *repositories.StorageConfigRepository,
+	backends *storage.Registry,
+) *StorageReplicationJob {
+	return &StorageReplicationJob{
+		replicationRepo:   replicationRepo,
+		storageConfigRepo: storageConfigRepo,
+		backends:          backends,
+		activeRepairs:     make(map[uuid.UUID]bool),
+		stopCh:            make(chan struct{}),
+	}
+}
+
+// Start drains the replication queue continuously and reconciles every replicated
+// configuration at the given interval
+func (j *StorageReplicationJob) Start(ctx context.Context, reconcileIntervalMinutes int) {
+	log.Printf("Starting storage replication job with reconcile interval of %d minutes", reconcileIntervalMinutes)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		queueTicker := time.NewTicker(replicationQueueInterval)
+		defer queueTicker.Stop()
+		reconcileTicker := time.NewTicker(time.Duration(reconcileIntervalMinutes) * time.Minute)
+		defer reconcileTicker.Stop()
+
+		j.processQueue(ctx)
+
+		for {
+			select {
+			case <-queueTicker.C:
+				j.processQueue(ctx)
+			case <-reconcileTicker.C:
+				j.reconcileAll(ctx)
+			case <-j.stopCh:
+				log.Println("Storage replication job stopped")
+				return
+			case <-ctx.Done():
+				log.Println("Storage replication job context cancelled")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the replication job
+func (j *StorageReplicationJob) Stop() {
+	close(j.stopCh)
+	j.wg.Wait()
+}
+
+// TriggerRepair reconciles the replicas of one replicated configuration in the background
+func (j *StorageReplicationJob) TriggerRepair(ctx context.Context, configID uuid.UUID) error {
+	storageConfig, err := j.storageConfigRepo.GetStorageConfig(ctx, configID)
+	if err != nil {
+		return fmt.Errorf("failed to get storage config: %w", err)
+	}
+	if storageConfig == nil || storageConfig.BackendType != "replicated" {
+		return fmt.Errorf("storage config %s is not a replicated configuration", configID)
+	}
+
+	if !j.beginRepair(configID) {
+		return fmt.Errorf("repair already in progress for storage config %s", configID)
+	}
+
+	go func() {
+		defer j.endRepair(configID)
+		j.reconcile(context.Background(), storageConfig)
+		j.processQueue(context.Background())
+	}()
+
+	return nil
+}
+
+// processQueue carries out the replica operations that are due
+func (j *StorageReplicationJob) processQueue(ctx context.Context) {
+	items, err := j.replicationRepo.ListDue(ctx, replicationQueueBatchSize)
+	if err != nil {
+		log.Printf("Failed to list replication queue: %v", err)
+		return
+	}
+
+	for _, item := range items {
+		if err := j.apply(ctx, item); err != nil {
+			backoff := time.Minute << min(item.Attempts, 6)
+			if backoff > replicationMaxBackoff {
+				backoff = replicationMaxBackoff
+			}
+			log.Printf("Replication of %s to %s failed (attempt %d): %v", item.Path, item.ReplicaID, item.Attempts+1, err)
+			if err := j.replicationRepo.FailItem(ctx, item, err.Error(), time.Now().Add(backoff)); err != nil {
+				log.Printf("Failed to update replication queue: %v", err)
+			}
+			continue
+		}
+		if err := j.replicationRepo.CompleteItem(ctx, item); err != nil {
+			log.Printf("Failed to update replication queue: %v", err)
+		}
+	}
+}
+
+// apply carries out one queued replica operation
+func (j *StorageReplicationJob) apply(ctx context.Context, item *models.StorageReplicationQueueItem) error {
+	replicated, err := j.replicatedStorage(ctx, item.ConfigID)
+	if err != nil {
+		return err
+	}
+
+	switch item.Operation {
+	case models.ReplicationOpUpload:
+		return replicated.Repair(ctx, item.ReplicaID.String(), item.Path)
+	case models.ReplicationOpDelete:
+		return replicated.RemoveFrom(ctx, item.ReplicaID.String(), item.Path)
+	default:
+		return fmt.Errorf("unknown replication operation: %s", item.Operation)
+	}
+}
+
+// reconcileAll reconciles every replicated configuration not already being repaired
+func (j *StorageReplicationJob) reconcileAll(ctx context.Context) {
+	configs, err := j.storageConfigRepo.ListReplicatedStorageConfigs(ctx)
+	if err != nil {
+		log.Printf("Failed to list replicated storage configs: %v", err)
+		return
+	}
+
+	for _, storageConfig := range configs {
+		if !j.beginRepair(storageConfig.ID) {
+			continue
+		}
+		j.reconcile(ctx, storageConfig)
+		j.endRepair(storageConfig.ID)
+	}
+}
+
+// reconcile compares every artifact stored in a replicated configuration across its replicas
+// and queues a repair for each replica whose copy is missing or differs
+func (j *StorageReplicationJob) reconcile(ctx context.Context, storageConfig *models.StorageConfig) {
+	replicated, err := j.replicatedStorage(ctx, storageConfig.ID)
+	if err != nil {
+		log.Printf("Failed to open replicated storage %s: %v", storageConfig.ID, err)
+		return
+	}
+
+	checked, queued := 0, 0
+	after := ""
+	for {
+		select {
+		case <-j.stopCh:
+			return
+		default:
+		}
+
+		paths, err := j.replicationRepo.ListArtifactPaths(ctx, storageConfig.ID.String(), after, replicationScanBatchSize)
+		if err != nil {
+			log.Printf("Failed to list artifacts of storage config %s: %v", storageConfig.ID, err)
+			return
+		}
+		if len(paths) == 0 {
+			break
+		}
+
+		for _, path := range paths {
+			checked++
+			diverged, err := replicated.Diverged(ctx, path)
+			if err != nil {
+				log.Printf("Failed to check replicas of %s: %v", path, err)
+				continue
+			}
+			for _, key := range diverged {
+				replicaID, err := uuid.Parse(key)
+				if err != nil {
+					continue
+				}
+				if err := j.replicationRepo.EnqueueReplication(ctx, storageConfig.ID, replicaID, models.ReplicationOpUpload, path); err != nil {
+					log.Printf("Failed to queue repair of %s on %s: %v", path, key, err)
+					continue
+				}
+				queued++
+			}
+		}
+		after = paths[len(paths)-1]
+	}
+
+	log.Printf("Reconciled replicated storage %s: %d artifacts checked, %d repairs queued", storageConfig.ID, checked, queued)
+}
+
+func (j *StorageReplicationJob) replicatedStorage(ctx context.Context, configID uuid.UUID) (*storage.ReplicatedStorage, error) {
+	backend, err := j.backends.Resolve(ctx, configID.String())
+	if err != nil {
+		return nil, err
+	}
+	replicated, ok := backend.(*storage.ReplicatedStorage)
+	if !ok {
+		return nil, fmt.Errorf("storage config %s is not a replicated configuration", configID)
+	}
+	return replicated, nil
+}
+
+func (j *StorageReplicationJob) beginRepair(configID uuid.UUID) bool {
+	j.activeRepairsMutex.Lock()
+	defer j.activeRepairsMutex.Unlock()
+	if j.activeRepairs[configID] {
+		return false
+	}
+	j.activeRepairs[configID] = true
+	return true
+}
+
+func (j *StorageReplicationJob) endRepair(configID uuid.UUID) {
+	j.activeRepairsMutex.Lock()
+	defer j.activeRepairsMutex.Unlock()
+	delete(j.activeRepairs, configID)
+}
@@ -0,0 +1,534 @@
+package jobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	storageScrubBatchSize = 100
+
+	// QuarantinePrefix is the storage path prefix quarantined orphans are moved under
+	QuarantinePrefix = "quarantine/"
+)
+
+// scrubbedPrefixes are the storage path prefixes artifacts are written under. Objects
+// elsewhere, including quarantined orphans, are never collected.
+var scrubbedPrefixes = []string{"modules/", "providers/", storage.BlobPathPrefix}
+
+var (
+	// ErrStorageScrubInProgress is returned when a storage scrub is already running
+	ErrStorageScrubInProgress = errors.New("storage scrub already in progress")
+	// ErrStorageOrphanNotFound is returned when purging an unknown orphan
+	ErrStorageOrphanNotFound = errors.New("storage orphan not found")
+	// ErrStorageOrphanReferenced is returned when purging an orphan an artifact has since claimed
+	ErrStorageOrphanReferenced = errors.New("object is referenced by an artifact")
+)
+
+// StorageScrubJob checks the integrity of stored artifacts and collects orphaned objects.
+// Each scrub confirms every artifact's object exists with the recorded size and checksum,
+// downloads and re-hashes a random sample, then lists the objects under the artifact prefixes
+// of each backend in use. Objects no artifact references are recorded as orphans and, once
+// they have been unreferenced and unmodified for the grace period, quarantined or deleted.
+type StorageScrubJob struct {
+	scrubRepo *repositories.StorageScrubRepository
+	blobRepo  *repositories.StorageBlobRepository
+	backends  *storage.Registry
+	cfg       config.StorageScrubConfig
+
+	running      bool
+	runningMutex sync.Mutex
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+}
+
+// scrubPass is the state of one scrub
+type scrubPass struct {
+	run     *models.StorageScrubRun
+	flagged map[uuid.UUID]bool // Artifacts with an issue already recorded
+}
+
+// NewStorageScrubJob creates a new storage scrub job
+func NewStorageScrubJob(scrubRepo *repositories.StorageScrubRepository, blobRepo *repositories.StorageBlobRepository, backends *storage.Registry, cfg config.StorageScrubConfig) *StorageScrubJob {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	return &StorageScrubJob{
+		scrubRepo: scrubRepo,
+		blobRepo:  blobRepo,
+		backends:  backends,
+		cfg:       cfg,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Start marks scrubs interrupted by a restart as failed and, when enabled, scrubs storage
+// at the configured interval
+func (j *StorageScrubJob) Start(ctx context.Context) {
+	if err := j.scrubRepo.FailInterruptedRuns(ctx); err != nil {
+		log.Printf("Failed to close interrupted storage scrubs: %v", err)
+	}
+	if !j.cfg.Enabled {
+		log.Println("Scheduled storage scrubs disabled")
+		return
+	}
+
+	log.Printf("Starting storage scrub job with interval of %s", j.cfg.Interval)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := j.Trigger(ctx); err != nil {
+					log.Printf("Scheduled storage scrub not started: %v", err)
+				}
+			case <-j.stopCh:
+				log.Println("Storage scrub job stopped")
+				return
+			case <-ctx.Done():
+				log.Println("Storage scrub job context cancelled")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops scheduled scrubs
+func (j *StorageScrubJob) Stop() {
+	close(j.stopCh)
+	j.wg.Wait()
+}
+
+// Trigger starts a scrub in the background and returns its run
+func (j *StorageScrubJob) Trigger(ctx context.Context) (*models.StorageScrubRun, error) {
+	j.runningMutex.Lock()
+	defer j.runningMutex.Unlock()
+	if j.running {
+		return nil, ErrStorageScrubInProgress
+	}
+
+	run, err := j.scrubRepo.CreateRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	j.running = true
+
+	go func() {
+		defer func() {
+			j.runningMutex.Lock()
+			j.running = false
+			j.runningMutex.Unlock()
+		}()
+		j.scrub(context.Background(), run)
+	}()
+
+	return run, nil
+}
+
+// Running reports whether a scrub is in progress
+func (j *StorageScrubJob) Running() bool {
+	j.runningMutex.Lock()
+	defer j.runningMutex.Unlock()
+	return j.running
+}
+
+// Config returns the scrubber configuration
+func (j *StorageScrubJob) Config() config.StorageScrubConfig {
+	return j.cfg
+}
+
+// Runs retrieves the most recent scrubs
+func (j *StorageScrubJob) Runs(ctx context.Context, limit int) ([]*models.StorageScrubRun, error) {
+	return j.scrubRepo.ListRuns(ctx, limit)
+}
+
+// Run retrieves a scrub and the issues it found
+func (j *StorageScrubJob) Run(ctx context.Context, id uuid.UUID) (*models.StorageScrubRun, []*models.StorageIntegrityIssue, error) {
+	run, err := j.scrubRepo.GetRun(ctx, id)
+	if err != nil || run == nil {
+		return nil, nil, err
+	}
+	issues, err := j.scrubRepo.ListIssues(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, issues, nil
+}
+
+// Orphans retrieves recorded orphans, optionally only those with the given status
+func (j *StorageScrubJob) Orphans(ctx context.Context, status string) ([]*models.StorageOrphan, error) {
+	return j.scrubRepo.ListOrphans(ctx, status)
+}
+
+// PurgeOrphan deletes an orphan's object, or its quarantined copy, and forgets the orphan
+// without waiting for the grace period
+func (j *StorageScrubJob) PurgeOrphan(ctx context.Context, id uuid.UUID) error {
+	orphan, err := j.scrubRepo.GetOrphan(ctx, id)
+	if err != nil {
+		return err
+	}
+	if orphan == nil {
+		return ErrStorageOrphanNotFound
+	}
+
+	if orphan.Status != models.StorageOrphanDeleted {
+		backend, err := j.backends.Resolve(ctx, orphan.StorageBackend)
+		if err != nil {
+			return fmt.Errorf("failed to open storage backend: %w", err)
+		}
+		path := orphan.StoragePath
+		if orphan.Status == models.StorageOrphanQuarantined && orphan.QuarantinePath != nil {
+			path = *orphan.QuarantinePath
+		} else if references, err := j.blobRepo.CountPathReferences(ctx, j.backends.Aliases(orphan.StorageBackend), path); err != nil {
+			return err
+		} else if references > 0 {
+			return ErrStorageOrphanReferenced
+		}
+		if err := j.deleteObject(ctx, backend, path); err != nil {
+			return err
+		}
+	}
+
+	return j.scrubRepo.DeleteOrphan(ctx, id)
+}
+
+func (j *StorageScrubJob) scrub(ctx context.Context, run *models.StorageScrubRun) {
+	log.Println("Starting storage scrub")
+	pass := &scrubPass{run: run, flagged: make(map[uuid.UUID]bool)}
+
+	err := j.checkArtifacts(ctx, pass)
+	if err == nil {
+		err = j.rehashSample(ctx, pass)
+	}
+	if err == nil {
+		err = j.collectOrphans(ctx, pass)
+	}
+
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	run.Status = models.StorageScrubCompleted
+	if err != nil {
+		message := err.Error()
+		run.Status = models.StorageScrubFailed
+		run.ErrorMessage = &message
+	}
+	if err := j.scrubRepo.UpdateRun(ctx, run); err != nil {
+		log.Printf("Failed to save storage scrub results: %v", err)
+	}
+
+	log.Printf("Storage scrub %s: %d artifacts checked, %d re-hashed, %d issues, %d objects scanned, %d orphans (%d quarantined, %d deleted)",
+		run.Status, run.ArtifactsChecked, run.ArtifactsRehashed, run.IssuesFound, run.ObjectsScanned,
+		run.OrphansFound, run.OrphansQuarantined, run.OrphansDeleted)
+}
+
+// checkArtifacts confirms every artifact's object exists with the recorded size and checksum
+func (j *StorageScrubJob) checkArtifacts(ctx context.Context, pass *scrubPass) error {
+	afterID := uuid.Nil
+	for {
+		artifacts, err := j.scrubRepo.ListArtifacts(ctx, afterID, storageScrubBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(artifacts) == 0 {
+			return nil
+		}
+
+		for _, artifact := range artifacts {
+			afterID = artifact.ArtifactID
+			j.checkArtifact(ctx, pass, artifact)
+			pass.run.ArtifactsChecked++
+		}
+
+		// Save progress so the admin API reflects a long scrub as it runs
+		if err := j.scrubRepo.UpdateRun(ctx, pass.run); err != nil {
+			log.Printf("Failed to save storage scrub progress: %v", err)
+		}
+	}
+}
+
+func (j *StorageScrubJob) checkArtifact(ctx context.Context, pass *scrubPass, artifact *models.StoredArtifact) {
+	backend, err := j.backends.Resolve(ctx, artifact.StorageBackend)
+	if err != nil {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueUnreadable, fmt.Sprintf("failed to open storage backend: %v", err))
+		return
+	}
+	// A cached copy must not hide an object lost from the backend
+	backend = storage.Uncached(backend)
+
+	exists, err := backend.Exists(ctx, artifact.StoragePath)
+	if err != nil {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueUnreadable, fmt.Sprintf("failed to check object: %v", err))
+		return
+	}
+	if !exists {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueMissing, "object not found")
+		return
+	}
+
+	metadata, err := backend.GetMetadata(ctx, artifact.StoragePath)
+	if err != nil {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueUnreadable, fmt.Sprintf("failed to get object metadata: %v", err))
+		return
+	}
+	if artifact.SizeBytes > 0 && metadata.Size != artifact.SizeBytes {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueSizeMismatch,
+			fmt.Sprintf("object is %d bytes, recorded %d", metadata.Size, artifact.SizeBytes))
+		return
+	}
+	if metadata.Checksum != "" && artifact.Checksum != "" && !strings.EqualFold(metadata.Checksum, artifact.Checksum) {
+		j.recordIssue(ctx, pass, artifact, models.StorageIssueChecksumMismatch,
+			fmt.Sprintf("object checksum is %s, recorded %s", metadata.Checksum, artifact.Checksum))
+	}
+}
+
+// rehashSample downloads a random sample of artifacts and compares their contents to the
+// recorded checksum, catching corruption the backend's own metadata does not reveal
+func (j *StorageScrubJob) rehashSample(ctx context.Context, pass *scrubPass) error {
+	if j.cfg.SampleSize <= 0 {
+		return nil
+	}
+
+	artifacts, err := j.scrubRepo.SampleArtifacts(ctx, j.cfg.SampleSize)
+	if err != nil {
+		return err
+	}
+
+	for _, artifact := range artifacts {
+		if pass.flagged[artifact.ArtifactID] || artifact.Checksum == "" {
+			continue
+		}
+
+		checksum, err := j.hashObject(ctx, artifact)
+		pass.run.ArtifactsRehashed++
+		if err != nil {
+			j.recordIssue(ctx, pass, artifact, models.StorageIssueUnreadable, err.Error())
+			continue
+		}
+		if !strings.EqualFold(checksum, artifact.Checksum) {
+			j.recordIssue(ctx, pass, artifact, models.StorageIssueChecksumMismatch,
+				fmt.Sprintf("contents hash to %s, recorded %s", checksum, artifact.Checksum))
+		}
+	}
+
+	return nil
+}
+
+func (j *StorageScrubJob) hashObject(ctx context.Context, artifact *models.StoredArtifact) (string, error) {
+	backend, err := j.backends.Resolve(ctx, artifact.StorageBackend)
+	if err != nil {
+		return "", fmt.Errorf("failed to open storage backend: %w", err)
+	}
+
+	reader, err := storage.Uncached(backend).Download(ctx, artifact.StoragePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to download object: %w", err)
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", fmt.Errorf("failed to read object: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func (j *StorageScrubJob) recordIssue(ctx context.Context, pass *scrubPass, artifact *models.StoredArtifact, kind models.StorageIntegrityIssueKind, details string) {
+	pass.flagged[artifact.ArtifactID] = true
+	pass.run.IssuesFound++
+
+	log.Printf("Storage scrub: %s %s at %s: %s", artifact.ArtifactType, artifact.ArtifactID, artifact.StoragePath, details)
+	issue := &models.StorageIntegrityIssue{
+		RunID:          pass.run.ID,
+		ArtifactType:   artifact.ArtifactType,
+		ArtifactID:     artifact.ArtifactID,
+		StorageBackend: artifact.StorageBackend,
+		StoragePath:    artifact.StoragePath,
+		Issue:          kind,
+		Details:        details,
+	}
+	if err := j.scrubRepo.CreateIssue(ctx, issue); err != nil {
+		log.Printf("Failed to record storage integrity issue: %v", err)
+	}
+}
+
+// collectOrphans lists the objects of every backend artifacts are stored on
+func (j *StorageScrubJob) collectOrphans(ctx context.Context, pass *scrubPass) error {
+	keys, err := j.scrubRepo.ListArtifactBackends(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Every name for the default backend lists the same objects, so it is scanned once
+	scanned := map[string]bool{}
+	var errs []error
+	for _, key := range append([]string{"default"}, keys...) {
+		if j.backends.IsDefault(key) {
+			key = "default"
+		}
+		if scanned[key] {
+			continue
+		}
+		scanned[key] = true
+
+		if err := j.collectBackendOrphans(ctx, pass, key); err != nil {
+			log.Printf("Storage scrub: failed to collect orphans on backend %s: %v", key, err)
+			errs = append(errs, fmt.Errorf("backend %s: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (j *StorageScrubJob) collectBackendOrphans(ctx context.Context, pass *scrubPass, key string) error {
+	backend, err := j.backends.Resolve(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to open storage backend: %w", err)
+	}
+	lister, ok := backend.(storage.Lister)
+	if !ok {
+		log.Printf("Storage scrub: backend %s cannot list objects, skipping orphan collection", key)
+		return nil
+	}
+
+	aliases := j.backends.Aliases(key)
+	paths, err := j.scrubRepo.ListReferencedPaths(ctx, aliases)
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		referenced[path] = true
+	}
+
+	scanStart := time.Now()
+	cutoff := scanStart.Add(-j.cfg.OrphanGracePeriod)
+	for _, prefix := range scrubbedPrefixes {
+		err := lister.List(ctx, prefix, func(object *storage.FileMetadata) error {
+			pass.run.ObjectsScanned++
+			if referenced[object.Path] || sbom.IsDocumentPath(object.Path) {
+				return nil
+			}
+
+			orphan, err := j.scrubRepo.RecordOrphan(ctx, key, object.Path, object.Size, scanStart)
+			if err != nil {
+				return err
+			}
+			pass.run.OrphansFound++
+
+			// Leave recent objects alone: an upload may not have recorded its artifact yet
+			if orphan.FirstSeenAt.After(cutoff) || object.LastModified.After(cutoff) {
+				return nil
+			}
+			j.actOnOrphan(ctx, pass, backend, aliases, orphan)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Pending orphans not seen this time have been removed or claimed by an artifact
+	return j.scrubRepo.ForgetStaleOrphans(ctx, key, scanStart)
+}
+
+// actOnOrphan quarantines or deletes an orphan past its grace period, per the configured action
+func (j *StorageScrubJob) actOnOrphan(ctx context.Context, pass *scrubPass, backend storage.Storage, aliases []string, orphan *models.StorageOrphan) {
+	action := j.cfg.OrphanAction
+	if action != "quarantine" && action != "delete" {
+		return
+	}
+
+	// An artifact may have claimed the object since the referenced paths were loaded
+	references, err := j.blobRepo.CountPathReferences(ctx, aliases, orphan.StoragePath)
+	if err != nil || references > 0 {
+		return
+	}
+
+	var quarantinePath *string
+	status := models.StorageOrphanDeleted
+	if action == "quarantine" {
+		path := QuarantinePrefix + orphan.StoragePath
+		if err := j.copyObject(ctx, backend, orphan.StoragePath, path, orphan.SizeBytes); err != nil {
+			log.Printf("Storage scrub: failed to quarantine %s: %v", orphan.StoragePath, err)
+			return
+		}
+		quarantinePath = &path
+		status = models.StorageOrphanQuarantined
+	}
+
+	if err := j.deleteObject(ctx, backend, orphan.StoragePath); err != nil {
+		log.Printf("Storage scrub: failed to remove orphan %s: %v", orphan.StoragePath, err)
+		return
+	}
+	if err := j.scrubRepo.MarkOrphan(ctx, orphan.ID, status, quarantinePath); err != nil {
+		log.Printf("Storage scrub: failed to record orphan %s: %v", orphan.StoragePath, err)
+	}
+
+	if status == models.StorageOrphanQuarantined {
+		pass.run.OrphansQuarantined++
+	} else {
+		pass.run.OrphansDeleted++
+	}
+}
+
+// copyObject copies an object within a backend, bypassing content addressing so the copy
+// keeps the path it is given
+func (j *StorageScrubJob) copyObject(ctx context.Context, backend storage.Storage, from, to string, size int64) error {
+	backend = rawBackend(backend)
+
+	reader, err := backend.Download(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer reader.Close()
+
+	if _, err := backend.Upload(ctx, to, reader, size); err != nil {
+		return fmt.Errorf("failed to upload: %w", err)
+	}
+	return nil
+}
+
+// deleteObject removes an object outright. Blobs are removed regardless of their reference
+// count, since no artifact references them, and their reference count is dropped with them.
+func (j *StorageScrubJob) deleteObject(ctx context.Context, backend storage.Storage, path string) error {
+	if err := rawBackend(backend).Delete(ctx, path); err != nil {
+		return err
+	}
+
+	if cas, ok := backend.(*storage.ContentAddressedStorage); ok {
+		if digest, ok := storage.BlobDigest(path); ok {
+			return j.blobRepo.DeleteBlob(ctx, cas.Key(), digest)
+		}
+	}
+	return nil
+}
+
+// rawBackend unwraps content-addressed storage, whose writes would otherwise be re-addressed
+func rawBackend(backend storage.Storage) storage.Storage {
+	if cas, ok := backend.(*storage.ContentAddressedStorage); ok {
+		return cas.Backend()
+	}
+	return backend
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/services"
+)
+
+// UploadSessionCleanupJob removes resumable upload sessions that have sat idle past their
+// expiry, along with their partially uploaded temp files
+type UploadSessionCleanupJob struct {
+	sessions *services.UploadSessionService
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewUploadSessionCleanupJob creates a new upload session cleanup job
+func NewUploadSessionCleanupJob(sessions *services.UploadSessionService) *UploadSessionCleanupJob {
+	return &UploadSessionCleanupJob{
+		sessions: sessions,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start removes expired upload sessions at the given interval
+func (j *UploadSessionCleanupJob) Start(ctx context.Context, intervalMinutes int) {
+	log.Printf("Starting upload session cleanup job with interval of %d minutes", intervalMinutes)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(intervalMinutes) * time.Minute)
+		defer ticker.Stop()
+
+		j.cleanup(ctx)
+
+		for {
+			select {
+			case <-ticker.C:
+				j.cleanup(ctx)
+			case <-j.stopCh:
+				log.Println("Upload session cleanup job stopped")
+				return
+			case <-ctx.Done():
+				log.Println("Upload session cleanup job context cancelled")
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the cleanup job
+func (j *UploadSessionCleanupJob) Stop() {
+	close(j.stopCh)
+	j.wg.Wait()
+}
+
+func (j *UploadSessionCleanupJob) cleanup(ctx context.Context) {
+	removed, err := j.sessions.CleanupExpired(ctx)
+	if err != nil {
+		log.Printf("Upload session cleanup failed: %v", err)
+	}
+	if removed > 0 {
+		log.Printf("Removed %d expired upload sessions", removed)
+	}
+}
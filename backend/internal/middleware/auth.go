@@ -246,3 +246,19 @@ func authenticateAPIKey(ctx context.Context, providedKey, keyPrefix string, apiK
 
 	return nil, nil
 }
+
+// Principal identifies the authenticated caller: "user:<id>" if there is a user, otherwise
+// "api_key:<id>", or "" for anonymous requests
+func Principal(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(string); ok && id != "" {
+			return "user:" + id
+		}
+	}
+	if apiKeyID, ok := c.Get("api_key_id"); ok {
+		if id, ok := apiKeyID.(string); ok && id != "" {
+			return "api_key:" + id
+		}
+	}
+	return ""
+}
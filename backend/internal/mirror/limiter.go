@@ -0,0 +1,43 @@
+package mirror
+
+import (
+	"context"
+	"sync"
+)
+
+// HostLimiter caps the requests in flight to each upstream host. One limiter is shared by all
+// clients so concurrent syncs of different mirrors don't add up to more than an upstream allows.
+type HostLimiter struct {
+	perHost int
+	hosts   map[string]chan struct{}
+	mu      sync.Mutex
+}
+
+// NewHostLimiter creates a limiter allowing perHost requests at once to each host
+func NewHostLimiter(perHost int) *HostLimiter {
+	if perHost < 1 {
+		perHost = 1
+	}
+	return &HostLimiter{
+		perHost: perHost,
+		hosts:   make(map[string]chan struct{}),
+	}
+}
+
+// Acquire waits for a free slot for host. The returned function releases it.
+func (l *HostLimiter) Acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, l.perHost)
+		l.hosts[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
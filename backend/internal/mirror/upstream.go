@@ -2,20 +2,36 @@ package mirror
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// maxBackoffRetries is how many times a request is retried when the upstream asks to back off
+const maxBackoffRetries = 5
+
+// defaultMaxRetryWait caps the wait honored from a Retry-After header when MaxRetryWait is not set
+const defaultMaxRetryWait = 5 * time.Minute
+
 // UpstreamRegistry represents a client for interacting with an upstream Terraform registry
 type UpstreamRegistry struct {
 	BaseURL        string
-	HTTPClient     *http.Client // For API requests (short timeout)
-	DownloadClient *http.Client // For file downloads (longer timeout)
+	HTTPClient     *http.Client  // For API requests (short timeout)
+	DownloadClient *http.Client  // For file downloads (longer timeout)
+	Limiter        *HostLimiter  // Optional cap on requests in flight per host, shared between clients
+	MaxRetryWait   time.Duration // Longest Retry-After honored before giving up on a request
+
+	discovery   *ServiceDiscoveryResponse
+	discoveryMu sync.Mutex
 }
 
 // NewUpstreamRegistry creates a new upstream registry client
@@ -68,6 +84,15 @@ type ProviderPackageResponse struct {
 	SigningKeys         SigningKeysInfo `json:"signing_keys"`
 }
 
+// ProviderMetadataResponse represents the provider details returned by {providers.v1}/{namespace}/{type}.
+// Registries that report a license return its SPDX identifier in License.
+type ProviderMetadataResponse struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Source    string `json:"source"`
+	License   string `json:"license"`
+}
+
 // SigningKeysInfo contains GPG key information for verifying provider signatures
 type SigningKeysInfo struct {
 	GPGPublicKeys []GPGPublicKey `json:"gpg_public_keys"`
@@ -82,8 +107,15 @@ type GPGPublicKey struct {
 	SourceURL      string `json:"source_url"`
 }
 
-// DiscoverServices performs service discovery to find the provider registry endpoints
+// DiscoverServices performs service discovery to find the provider registry endpoints.
+// The result is cached for the lifetime of the client.
 func (u *UpstreamRegistry) DiscoverServices(ctx context.Context) (*ServiceDiscoveryResponse, error) {
+	u.discoveryMu.Lock()
+	defer u.discoveryMu.Unlock()
+	if u.discovery != nil {
+		return u.discovery, nil
+	}
+
 	discoveryURL := fmt.Sprintf("%s/.well-known/terraform.json", u.BaseURL)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", discoveryURL, nil)
@@ -91,7 +123,7 @@ func (u *UpstreamRegistry) DiscoverServices(ctx context.Context) (*ServiceDiscov
 		return nil, fmt.Errorf("failed to create discovery request: %w", err)
 	}
 
-	resp, err := u.HTTPClient.Do(req)
+	resp, err := u.do(u.HTTPClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform discovery request: %w", err)
 	}
@@ -107,6 +139,7 @@ func (u *UpstreamRegistry) DiscoverServices(ctx context.Context) (*ServiceDiscov
 		return nil, fmt.Errorf("failed to decode discovery response: %w", err)
 	}
 
+	u.discovery = &discovery
 	return &discovery, nil
 }
 
@@ -133,7 +166,7 @@ func (u *UpstreamRegistry) ListProviderVersions(ctx context.Context, namespace,
 		return nil, fmt.Errorf("failed to create versions request: %w", err)
 	}
 
-	resp, err := u.HTTPClient.Do(req)
+	resp, err := u.do(u.HTTPClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch provider versions: %w", err)
 	}
@@ -188,7 +221,7 @@ func (u *UpstreamRegistry) GetProviderPackage(ctx context.Context, namespace, pr
 		return nil, fmt.Errorf("failed to create package request: %w", err)
 	}
 
-	resp, err := u.HTTPClient.Do(req)
+	resp, err := u.do(u.HTTPClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch provider package info: %w", err)
 	}
@@ -207,6 +240,47 @@ func (u *UpstreamRegistry) GetProviderPackage(ctx context.Context, namespace, pr
 	return &packageResp, nil
 }
 
+// GetProviderMetadata gets the provider-level details from upstream. Registries that do not
+// serve this endpoint yield empty metadata rather than an error.
+func (u *UpstreamRegistry) GetProviderMetadata(ctx context.Context, namespace, providerName string) (*ProviderMetadataResponse, error) {
+	// First, discover the providers endpoint
+	discovery, err := u.DiscoverServices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service discovery failed: %w", err)
+	}
+
+	// Format: {providers.v1}/{namespace}/{type}
+	providersPath := strings.TrimSuffix(discovery.ProvidersV1, "/")
+	metadataURL := fmt.Sprintf("%s%s/%s/%s", u.BaseURL, providersPath, namespace, providerName)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", metadataURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata request: %w", err)
+	}
+
+	resp, err := u.do(u.HTTPClient, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch provider metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &ProviderMetadataResponse{Namespace: namespace, Name: providerName}, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("metadata request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var metadata ProviderMetadataResponse
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata response: %w", err)
+	}
+
+	return &metadata, nil
+}
+
 // DownloadFile downloads a file from the given URL and returns the content
 // It uses a longer timeout and implements retry logic for transient failures
 func (u *UpstreamRegistry) DownloadFile(ctx context.Context, fileURL string) ([]byte, error) {
@@ -242,6 +316,95 @@ func (u *UpstreamRegistry) DownloadFile(ctx context.Context, fileURL string) ([]
 	return nil, fmt.Errorf("download failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// DownloadToFile downloads a file into file, returning its size and hex-encoded SHA256
+// checksum, so large provider binaries are never held in memory. Like DownloadFile it retries
+// transient failures; file is truncated before each attempt. If progress is not nil, it is called
+// with the bytes of the current attempt downloaded so far as they arrive.
+func (u *UpstreamRegistry) DownloadToFile(ctx context.Context, fileURL string, file *os.File, progress func(downloaded int64)) (int64, string, error) {
+	const maxRetries = 3
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		size, checksum, err := u.downloadToFileOnce(ctx, fileURL, file, progress)
+		if err == nil {
+			return size, checksum, nil
+		}
+
+		lastErr = err
+
+		// Check if context was cancelled - don't retry
+		if ctx.Err() != nil {
+			return 0, "", fmt.Errorf("download cancelled: %w", ctx.Err())
+		}
+
+		if attempt < maxRetries {
+			// Exponential backoff: 2s, 4s
+			backoff := time.Duration(1<<attempt) * time.Second
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return 0, "", fmt.Errorf("download cancelled during retry wait: %w", ctx.Err())
+			}
+		}
+	}
+
+	return 0, "", fmt.Errorf("download failed after %d attempts: %w", maxRetries, lastErr)
+}
+
+// downloadToFileOnce performs a single attempt of DownloadToFile
+func (u *UpstreamRegistry) downloadToFileOnce(ctx context.Context, fileURL string, file *os.File, progress func(downloaded int64)) (int64, string, error) {
+	if err := file.Truncate(0); err != nil {
+		return 0, "", fmt.Errorf("failed to truncate download file: %w", err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, "", fmt.Errorf("failed to rewind download file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create download request: %w", err)
+	}
+
+	resp, err := u.do(u.DownloadClient, req)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &progressReader{reader: resp.Body, progress: progress}
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hasher), body)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read download content: %w", err)
+	}
+
+	return size, hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// progressReader reports the bytes read through it so far
+type progressReader struct {
+	reader   io.Reader
+	read     int64
+	progress func(downloaded int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress(r.read)
+	}
+	return n, err
+}
+
 // downloadFileOnce performs a single download attempt
 func (u *UpstreamRegistry) downloadFileOnce(ctx context.Context, fileURL string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", fileURL, nil)
@@ -249,7 +412,7 @@ func (u *UpstreamRegistry) downloadFileOnce(ctx context.Context, fileURL string)
 		return nil, fmt.Errorf("failed to create download request: %w", err)
 	}
 
-	resp, err := u.DownloadClient.Do(req)
+	resp, err := u.do(u.DownloadClient, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
@@ -267,6 +430,96 @@ func (u *UpstreamRegistry) downloadFileOnce(ctx context.Context, fileURL string)
 	return data, nil
 }
 
+// do sends a request, waiting for a free slot for its host when a limiter is set. Requests
+// answered with 429 Too Many Requests, or 503 Service Unavailable with a Retry-After header,
+// are retried after the wait the upstream asks for, or with exponential backoff if it names none.
+// The host slot is held until the response body is closed.
+func (u *UpstreamRegistry) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	maxWait := u.MaxRetryWait
+	if maxWait <= 0 {
+		maxWait = defaultMaxRetryWait
+	}
+
+	for attempt := 0; ; attempt++ {
+		release := func() {}
+		if u.Limiter != nil {
+			var err error
+			if release, err = u.Limiter.Acquire(ctx, req.URL.Host); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		retryAfter := resp.Header.Get("Retry-After")
+		backoff := resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode == http.StatusServiceUnavailable && retryAfter != "")
+		if !backoff || attempt == maxBackoffRetries {
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		release()
+
+		wait := parseRetryAfter(retryAfter, time.Now())
+		if wait < 0 {
+			// Exponential backoff: 1s, 2s, 4s, ...
+			wait = time.Duration(1<<attempt) * time.Second
+		}
+		if wait > maxWait {
+			return nil, fmt.Errorf("%s asked to retry after %s, longer than the %s allowed", req.URL.Host, wait, maxWait)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// parseRetryAfter returns the wait a Retry-After header asks for, given in seconds or as an
+// HTTP date, or -1 if it names none
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return -1
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return -1
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+		return 0
+	}
+	return -1
+}
+
+// releasingBody releases a host slot when the response body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
 // ValidateRegistryURL validates that a registry URL is properly formatted
 func ValidateRegistryURL(registryURL string) error {
 	if registryURL == "" {
@@ -0,0 +1,209 @@
+package mirror
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// VersionFilter selects the upstream versions of a provider a mirror syncs. Filters are written as:
+//   - "~> 5.0, != 5.31.0" - a Terraform version constraint; as in Terraform, a pre-release only
+//     matches when named by an exact ("=" or bare) constraint
+//   - "3." or "3.x" - all versions starting with "3."
+//   - "3.74.0,3.73.0" - specific comma-separated versions
+//   - "latest:5" - the latest 5 releases
+//   - "latest:3 within ~> 5.0" - the latest 3 versions matching any of the above
+//   - "" - all versions
+type VersionFilter struct {
+	latest      int // Versions kept after matching, 0 for all
+	prefix      string
+	exact       map[string]bool
+	constraints version.Constraints
+}
+
+// ParseVersionFilter parses a mirror version filter, reporting filters that would not match as intended
+func ParseVersionFilter(filter string) (*VersionFilter, error) {
+	f := &VersionFilter{}
+	expr := strings.TrimSpace(filter)
+
+	if strings.HasPrefix(expr, "latest:") {
+		countStr, rest, _ := strings.Cut(strings.TrimPrefix(expr, "latest:"), " ")
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid latest:N filter %q: N must be a positive number", expr)
+		}
+		f.latest = count
+
+		rest = strings.TrimSpace(rest)
+		if rest != "" {
+			within, ok := strings.CutPrefix(rest, "within ")
+			if !ok || strings.TrimSpace(within) == "" {
+				return nil, fmt.Errorf("invalid version filter %q: expected \"latest:N within <constraint>\"", expr)
+			}
+			rest = strings.TrimSpace(within)
+		}
+		expr = rest
+	}
+
+	if expr == "" {
+		return f, nil
+	}
+
+	// Prefix matching (e.g., "3." or "3.x")
+	if strings.HasSuffix(expr, ".") || strings.HasSuffix(expr, ".x") {
+		prefix := strings.TrimSuffix(expr, "x")
+		if !isBareVersion(strings.TrimSuffix(prefix, ".")) {
+			return nil, fmt.Errorf("invalid version prefix %q", expr)
+		}
+		f.prefix = prefix
+		return f, nil
+	}
+
+	// Bare versions are a list of versions to sync rather than a constraint they must all meet
+	items := strings.Split(expr, ",")
+	bare := true
+	for _, item := range items {
+		if !isBareVersion(strings.TrimSpace(item)) {
+			bare = false
+			break
+		}
+	}
+	if bare {
+		f.exact = make(map[string]bool)
+		for _, item := range items {
+			f.exact[strings.TrimSpace(item)] = true
+		}
+		// A partial version such as "3" or "3.7" also matches the versions it prefixes
+		if len(items) == 1 && strings.Count(expr, ".") < 2 {
+			f.prefix = expr + "."
+		}
+		return f, nil
+	}
+
+	constraints, err := version.NewConstraint(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint %q: %w", expr, err)
+	}
+	f.constraints = constraints
+	return f, nil
+}
+
+// isBareVersion reports whether s is a version number without a constraint operator
+func isBareVersion(s string) bool {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return false
+	}
+	_, err := version.NewVersion(s)
+	return err == nil
+}
+
+// Apply returns the versions selected by the filter, keeping their upstream order
+func (f *VersionFilter) Apply(versions []ProviderVersion) []ProviderVersion {
+	if f == nil {
+		return versions
+	}
+
+	type candidate struct {
+		version ProviderVersion
+		parsed  *version.Version
+	}
+	var matched []candidate
+	for _, v := range versions {
+		parsed, _ := version.NewVersion(v.Version) // nil for versions that aren't valid
+		if !f.matches(v.Version, parsed) {
+			continue
+		}
+		matched = append(matched, candidate{version: v, parsed: parsed})
+	}
+
+	if f.latest > 0 {
+		// Without an expression to say otherwise, "latest" means the latest releases
+		releasesOnly := f.prefix == "" && f.exact == nil && f.constraints == nil
+		ranked := make([]candidate, 0, len(matched))
+		for _, c := range matched {
+			if c.parsed == nil || (releasesOnly && c.parsed.Prerelease() != "") {
+				continue
+			}
+			ranked = append(ranked, c)
+		}
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].parsed.GreaterThan(ranked[j].parsed)
+		})
+		if len(ranked) > f.latest {
+			ranked = ranked[:f.latest]
+		}
+		matched = ranked
+	}
+
+	var filtered []ProviderVersion
+	for _, c := range matched {
+		filtered = append(filtered, c.version)
+	}
+	return filtered
+}
+
+// matches reports whether an upstream version meets the filter's expression
+func (f *VersionFilter) matches(raw string, parsed *version.Version) bool {
+	switch {
+	case f.constraints != nil:
+		if parsed == nil {
+			return false
+		}
+		if parsed.Prerelease() != "" {
+			return checkPrerelease(f.constraints, parsed)
+		}
+		return f.constraints.Check(parsed)
+	case f.exact != nil:
+		return f.exact[raw] || (f.prefix != "" && strings.HasPrefix(raw, f.prefix))
+	case f.prefix != "":
+		return strings.HasPrefix(raw, f.prefix)
+	default:
+		return true
+	}
+}
+
+// constraintRegexp splits a single constraint into its operator and version
+var constraintRegexp = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>)?\s*(\S+)\s*$`)
+
+// checkPrerelease checks a pre-release against constraints the way Terraform does: it must be
+// named by an exact constraint, and the other constraints compare it by plain precedence
+func checkPrerelease(constraints version.Constraints, v *version.Version) bool {
+	named := false
+	for _, c := range constraints {
+		m := constraintRegexp.FindStringSubmatch(c.String())
+		if m == nil {
+			return false
+		}
+		target, err := version.NewVersion(m[2])
+		if err != nil {
+			return false
+		}
+
+		var ok bool
+		switch m[1] {
+		case "", "=":
+			ok = v.Equal(target)
+			named = named || ok
+		case "!=":
+			ok = !v.Equal(target)
+		case ">":
+			ok = v.GreaterThan(target)
+		case ">=":
+			ok = v.GreaterThanOrEqual(target)
+		case "<":
+			ok = v.LessThan(target)
+		case "<=":
+			ok = v.LessThanOrEqual(target)
+		default:
+			ok = c.Check(v)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return named
+}
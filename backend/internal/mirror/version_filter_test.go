@@ -0,0 +1,103 @@
+package mirror
+
+import (
+	"reflect"
+	"testing"
+)
+
+// upstreamVersions lists versions newest first, the way registries usually return them
+var upstreamVersions = []string{
+	"5.32.0-beta1", "5.31.0", "5.30.0", "5.1.0", "5.0.0", "4.67.0",
+	"3.74.0", "3.73.0", "3.7.1", "30.0.0", "nightly",
+}
+
+func providerVersions(versions []string) []ProviderVersion {
+	result := make([]ProviderVersion, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, ProviderVersion{Version: v})
+	}
+	return result
+}
+
+func versionStrings(versions []ProviderVersion) []string {
+	result := make([]string, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, v.Version)
+	}
+	return result
+}
+
+func TestVersionFilterApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   []string
+	}{
+		{"empty", "", upstreamVersions},
+		{"blank", "   ", upstreamVersions},
+		{"constraint", "~> 5.0, != 5.31.0", []string{"5.30.0", "5.1.0", "5.0.0"}},
+		{"constraint skips pre-releases", ">= 5.30.0", []string{"5.31.0", "5.30.0", "30.0.0"}},
+		{"exact constraint names a pre-release", "= 5.32.0-beta1", []string{"5.32.0-beta1"}},
+		{"pre-release with range", "5.32.0-beta1, < 6.0.0", []string{"5.32.0-beta1"}},
+		{"prefix", "3.", []string{"3.74.0", "3.73.0", "3.7.1"}},
+		{"prefix with x", "3.x", []string{"3.74.0", "3.73.0", "3.7.1"}},
+		{"minor prefix", "3.7.x", []string{"3.7.1"}},
+		{"version list", "3.74.0, 3.73.0", []string{"3.74.0", "3.73.0"}},
+		{"single version", "4.67.0", []string{"4.67.0"}},
+		{"partial version", "3.7", []string{"3.7.1"}},
+		{"no match", "9.9.9", nil},
+		{"latest releases", "latest:2", []string{"30.0.0", "5.31.0"}},
+		{"latest beyond available", "latest:20", []string{"30.0.0", "5.31.0", "5.30.0", "5.1.0", "5.0.0", "4.67.0", "3.74.0", "3.73.0", "3.7.1"}},
+		{"latest within constraint", "latest:2 within ~> 5.0", []string{"5.31.0", "5.30.0"}},
+		{"latest within prefix", "latest:2 within 3.x", []string{"3.74.0", "3.73.0"}},
+		{"latest within version list", "latest:1 within 3.73.0,3.74.0", []string{"3.74.0"}},
+		{"latest within exact pre-release", "latest:1 within = 5.32.0-beta1", []string{"5.32.0-beta1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseVersionFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("ParseVersionFilter(%q): %v", tt.filter, err)
+			}
+			got := versionStrings(filter.Apply(providerVersions(upstreamVersions)))
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply(%q) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionFilterApplyNil(t *testing.T) {
+	var filter *VersionFilter
+	got := versionStrings(filter.Apply(providerVersions(upstreamVersions)))
+	if !reflect.DeepEqual(got, upstreamVersions) {
+		t.Errorf("nil filter Apply = %v, want every version", got)
+	}
+}
+
+func TestParseVersionFilterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+	}{
+		{"latest zero", "latest:0"},
+		{"latest negative", "latest:-1"},
+		{"latest not a number", "latest:five"},
+		{"latest missing within", "latest:3 ~> 5.0"},
+		{"latest empty within", "latest:3 within"},
+		{"prefix not a version", "abc.x"},
+		{"prefix with v", "v3.x"},
+		{"bad constraint", ">>= 1.0"},
+		{"bad version in list", "1.0.0, nope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseVersionFilter(tt.filter); err == nil {
+				t.Errorf("ParseVersionFilter(%q) succeeded, want an error", tt.filter)
+			}
+		})
+	}
+}
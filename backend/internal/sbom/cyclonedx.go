@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cycloneDXBOM is the subset of the CycloneDX 1.5 JSON schema the registry emits
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Group      string              `json:"group,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Hashes     []cycloneDXHash     `json:"hashes,omitempty"`
+	Licenses   []cycloneDXLicense  `json:"licenses,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cycloneDXLicense struct {
+	License struct {
+		ID string `json:"id"`
+	} `json:"license"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// encodeCycloneDX renders the document as CycloneDX 1.5 JSON
+func encodeCycloneDX(d *Document) ([]byte, error) {
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid.New().String(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: d.Created.Format(time.RFC3339),
+			Tools: cycloneDXTools{Components: []cycloneDXComponent{
+				{Type: "application", Name: "terraform-registry"},
+			}},
+			Component: toCycloneDXComponent(d.Subject),
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+
+	subjectDeps := cycloneDXDependency{Ref: d.Subject.Ref, DependsOn: []string{}}
+	for _, dep := range d.Dependencies {
+		bom.Components = append(bom.Components, toCycloneDXComponent(dep))
+		subjectDeps.DependsOn = append(subjectDeps.DependsOn, dep.Ref)
+	}
+	for _, file := range d.Contents {
+		bom.Components = append(bom.Components, toCycloneDXComponent(file))
+	}
+	bom.Dependencies = append(bom.Dependencies, subjectDeps)
+
+	return marshalDocument(bom)
+}
+
+func toCycloneDXComponent(c Component) cycloneDXComponent {
+	component := cycloneDXComponent{
+		Type:    "library",
+		BOMRef:  c.Ref,
+		Group:   c.Namespace,
+		Name:    c.Name,
+		Version: c.Version,
+		PURL:    c.PURL,
+	}
+	if c.Kind == KindPlatform {
+		component.Type = "file"
+	}
+	if c.SHA256 != "" {
+		component.Hashes = []cycloneDXHash{{Alg: "SHA-256", Content: c.SHA256}}
+	}
+	if c.License != "" {
+		var license cycloneDXLicense
+		license.License.ID = c.License
+		component.Licenses = []cycloneDXLicense{license}
+	}
+
+	component.Properties = append(component.Properties, cycloneDXProperty{Name: "terraform:kind", Value: string(c.Kind)})
+	if c.Constraint != "" {
+		component.Properties = append(component.Properties, cycloneDXProperty{Name: "terraform:version_constraint", Value: c.Constraint})
+	}
+	for _, property := range c.Properties {
+		component.Properties = append(component.Properties, cycloneDXProperty{Name: property[0], Value: property[1]})
+	}
+
+	return component
+}
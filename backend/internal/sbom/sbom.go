@@ -0,0 +1,285 @@
+// Package sbom builds software bills of materials for module and provider versions
+// and encodes them as CycloneDX or SPDX JSON documents.
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// Format identifies an SBOM document format
+type Format string
+
+const (
+	FormatCycloneDX Format = "cyclonedx" // CycloneDX 1.5 JSON
+	FormatSPDX      Format = "spdx"      // SPDX 2.3 JSON
+)
+
+// Formats lists every supported format; SBOMs are generated and stored in all of them
+var Formats = []Format{FormatCycloneDX, FormatSPDX}
+
+// ParseFormat parses a format name, defaulting to CycloneDX when empty
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "cyclonedx", "cdx":
+		return FormatCycloneDX, nil
+	case "spdx":
+		return FormatSPDX, nil
+	default:
+		return "", fmt.Errorf("unsupported SBOM format %q (expected cyclonedx or spdx)", name)
+	}
+}
+
+// fileExtension returns the suffix used when storing a document in this format
+func (f Format) fileExtension() string {
+	if f == FormatSPDX {
+		return ".spdx.json"
+	}
+	return ".cdx.json"
+}
+
+// ContentType returns the media type for documents in this format
+func (f Format) ContentType() string {
+	if f == FormatSPDX {
+		return "application/spdx+json"
+	}
+	return "application/vnd.cyclonedx+json"
+}
+
+// ModulePath returns the storage path of a module version's SBOM. The path is derived from the
+// module's coordinates rather than its archive path, since content-addressed archives are shared.
+func ModulePath(namespace, name, system, version string, format Format) string {
+	return path.Join("modules", namespace, name, system, version+".sbom"+format.fileExtension())
+}
+
+// ProviderPath returns the storage path of a provider version's SBOM
+func ProviderPath(namespace, providerType, version string, format Format) string {
+	return path.Join("providers", namespace, providerType, version, "sbom"+format.fileExtension())
+}
+
+// IsDocumentPath reports whether a storage path is that of a stored SBOM document
+func IsDocumentPath(storagePath string) bool {
+	return strings.HasSuffix(storagePath, FormatSPDX.fileExtension()) || strings.HasSuffix(storagePath, FormatCycloneDX.fileExtension())
+}
+
+// ComponentKind classifies a component in the bill of materials
+type ComponentKind string
+
+const (
+	KindModule   ComponentKind = "module"   // A Terraform module
+	KindProvider ComponentKind = "provider" // A Terraform provider
+	KindPlatform ComponentKind = "platform" // A platform-specific provider binary
+)
+
+// Component is a format-neutral SBOM entry
+type Component struct {
+	Kind       ComponentKind
+	Ref        string // Unique reference within the document
+	Namespace  string
+	Name       string
+	Version    string // Exact version, when known
+	Constraint string // Version constraint for dependencies declared by range
+	PURL       string
+	License    string // SPDX identifier
+	SHA256     string
+	Properties [][2]string // Additional name/value pairs, in order
+}
+
+// Document is a format-neutral bill of materials for a single artifact version
+type Document struct {
+	Subject      Component
+	Dependencies []Component // Components the subject depends on
+	Contents     []Component // Files that make up the subject (provider platform binaries)
+	Created      time.Time
+}
+
+// ForModule builds the SBOM of a module version from its archive contents, listing its
+// registry module calls and its required providers with their version constraints
+func ForModule(registryHost string, module *models.Module, version *models.ModuleVersion, contents *validation.ModuleContents) *Document {
+	doc := &Document{
+		Subject: Component{
+			Kind:      KindModule,
+			Ref:       "module:" + module.Namespace + "/" + module.Name + "/" + module.System + "@" + version.Version,
+			Namespace: module.Namespace,
+			Name:      module.Name + "/" + module.System,
+			Version:   version.Version,
+			PURL:      modulePURL(registryHost, module.Namespace, module.Name, module.System, version.Version),
+			License:   stringValue(version.LicenseSPDX),
+			SHA256:    version.Checksum,
+		},
+		Created: time.Now().UTC(),
+	}
+
+	for _, call := range contents.RegistryModuleCalls() {
+		parts := strings.SplitN(call.Source, "/", 3)
+		component := Component{
+			Kind:       KindModule,
+			Ref:        "module-call:" + call.File + ":" + call.Name,
+			Namespace:  parts[0],
+			Name:       parts[1] + "/" + parts[2],
+			Constraint: call.Version,
+			PURL:       modulePURL(call.Hostname, parts[0], parts[1], parts[2], ""),
+			Properties: [][2]string{{"terraform:module_call", call.Name}, {"terraform:declared_in", call.File}},
+		}
+		if call.Hostname != "" {
+			component.Properties = append(component.Properties, [2]string{"terraform:registry", call.Hostname})
+		}
+		doc.Dependencies = append(doc.Dependencies, component)
+	}
+
+	for _, provider := range contents.RequiredProviders() {
+		hostname, namespace, name := splitProviderSource(provider.Source)
+		component := Component{
+			Kind:       KindProvider,
+			Ref:        "provider:" + provider.Source,
+			Namespace:  namespace,
+			Name:       name,
+			Constraint: strings.Join(provider.Constraints, ", "),
+			PURL:       providerPURL(hostname, namespace, name, ""),
+			Properties: [][2]string{{"terraform:local_name", provider.Name}},
+		}
+		if hostname != "" {
+			component.Properties = append(component.Properties, [2]string{"terraform:registry", hostname})
+		}
+		doc.Dependencies = append(doc.Dependencies, component)
+	}
+
+	return doc
+}
+
+// ForProvider builds the SBOM of a provider version, listing its platform binaries and their hashes
+func ForProvider(registryHost string, provider *models.Provider, version *models.ProviderVersion, platforms []*models.ProviderPlatform) *Document {
+	doc := &Document{
+		Subject: Component{
+			Kind:      KindProvider,
+			Ref:       "provider:" + provider.Namespace + "/" + provider.Type + "@" + version.Version,
+			Namespace: provider.Namespace,
+			Name:      provider.Type,
+			Version:   version.Version,
+			PURL:      providerPURL(registryHost, provider.Namespace, provider.Type, version.Version),
+			License:   stringValue(version.LicenseSPDX),
+			Properties: [][2]string{
+				{"terraform:protocols", strings.Join(version.Protocols, ",")},
+				{PlatformsDigestProperty, PlatformsDigest(platforms)},
+			},
+		},
+		Created: time.Now().UTC(),
+	}
+
+	for _, platform := range platforms {
+		doc.Contents = append(doc.Contents, Component{
+			Kind:    KindPlatform,
+			Ref:     "platform:" + platform.OS + "_" + platform.Arch,
+			Name:    platform.Filename,
+			Version: version.Version,
+			SHA256:  platform.Shasum,
+			Properties: [][2]string{
+				{"terraform:os", platform.OS},
+				{"terraform:arch", platform.Arch},
+				{"terraform:size_bytes", fmt.Sprintf("%d", platform.SizeBytes)},
+			},
+		})
+	}
+
+	return doc
+}
+
+// PlatformsDigestProperty names the provider SBOM property recording the platforms it was built from
+const PlatformsDigestProperty = "terraform:platforms_sha256"
+
+// PlatformsDigest fingerprints the platform binaries of a provider version, so a stored SBOM
+// can be recognised as stale once platforms are added, removed or rebuilt
+func PlatformsDigest(platforms []*models.ProviderPlatform) string {
+	entries := make([]string, 0, len(platforms))
+	for _, platform := range platforms {
+		entries = append(entries, platform.OS+"_"+platform.Arch+" "+platform.Shasum)
+	}
+	sort.Strings(entries)
+
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Encode renders the document in the requested format
+func (d *Document) Encode(format Format) ([]byte, error) {
+	switch format {
+	case FormatCycloneDX:
+		return encodeCycloneDX(d)
+	case FormatSPDX:
+		return encodeSPDX(d)
+	default:
+		return nil, fmt.Errorf("unsupported SBOM format %q", format)
+	}
+}
+
+// modulePURL builds a package URL for a registry module; version is omitted when empty
+func modulePURL(registryHost, namespace, name, system, version string) string {
+	return buildPURL(registryHost, []string{namespace, name, system}, version, "module")
+}
+
+// providerPURL builds a package URL for a registry provider; version is omitted when empty
+func providerPURL(registryHost, namespace, providerType, version string) string {
+	return buildPURL(registryHost, []string{namespace, providerType}, version, "provider")
+}
+
+func buildPURL(registryHost string, segments []string, version, kind string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(strings.ToLower(segment))
+	}
+	purl := "pkg:terraform/" + strings.Join(escaped, "/")
+	if version != "" {
+		purl += "@" + url.PathEscape(version)
+	}
+
+	qualifiers := url.Values{}
+	qualifiers.Set("type", kind)
+	if registryHost != "" {
+		qualifiers.Set("repository_url", registryHost)
+	}
+	return purl + "?" + qualifiers.Encode()
+}
+
+// splitProviderSource splits a provider source address "[hostname/]namespace/type"
+func splitProviderSource(source string) (hostname, namespace, name string) {
+	parts := strings.Split(source, "/")
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2]
+	case 2:
+		return "", parts[0], parts[1]
+	default:
+		return "", "", source
+	}
+}
+
+// marshalDocument encodes a document as indented JSON without HTML escaping,
+// so version constraints such as ">= 5.0" stay readable
+func marshalDocument(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
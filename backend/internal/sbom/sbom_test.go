@@ -0,0 +1,43 @@
+package sbom
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func TestPlatformsDigest(t *testing.T) {
+	linux := &models.ProviderPlatform{OS: "linux", Arch: "amd64", Shasum: "aa"}
+	darwin := &models.ProviderPlatform{OS: "darwin", Arch: "arm64", Shasum: "bb"}
+	rebuilt := &models.ProviderPlatform{OS: "linux", Arch: "amd64", Shasum: "cc"}
+
+	base := PlatformsDigest([]*models.ProviderPlatform{linux, darwin})
+	if got := PlatformsDigest([]*models.ProviderPlatform{darwin, linux}); got != base {
+		t.Errorf("digest depends on platform order")
+	}
+	if got := PlatformsDigest([]*models.ProviderPlatform{linux}); got == base {
+		t.Errorf("digest unchanged after removing a platform")
+	}
+	if got := PlatformsDigest([]*models.ProviderPlatform{rebuilt, darwin}); got == base {
+		t.Errorf("digest unchanged after a platform was rebuilt")
+	}
+}
+
+func TestForProviderRecordsPlatformsDigest(t *testing.T) {
+	provider := &models.Provider{Namespace: "acme", Type: "widget"}
+	version := &models.ProviderVersion{Version: "1.0.0", Protocols: []string{"6.0"}}
+	platforms := []*models.ProviderPlatform{{OS: "linux", Arch: "amd64", Filename: "widget_linux_amd64.zip", Shasum: "aa"}}
+
+	doc := ForProvider("registry.example.com", provider, version, platforms)
+	digest := PlatformsDigest(platforms)
+	for _, format := range Formats {
+		data, err := doc.Encode(format)
+		if err != nil {
+			t.Fatalf("Encode(%s): %v", format, err)
+		}
+		if !bytes.Contains(data, []byte(digest)) {
+			t.Errorf("%s document does not record the platforms digest", format)
+		}
+	}
+}
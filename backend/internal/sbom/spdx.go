@@ -0,0 +1,134 @@
+package sbom
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// spdxDocument is the subset of the SPDX 2.3 JSON schema the registry emits
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	Supplier         string            `json:"supplier,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxIDInvalidChars matches characters not permitted in SPDX element identifiers
+var spdxIDInvalidChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// encodeSPDX renders the document as SPDX 2.3 JSON
+func encodeSPDX(d *Document) ([]byte, error) {
+	subject := toSPDXPackage(d.Subject)
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Subject.Namespace + "/" + d.Subject.Name + "@" + d.Subject.Version,
+		DocumentNamespace: "https://spdx.org/spdxdocs/terraform-registry/" + uuid.New().String(),
+		CreationInfo: spdxCreationInfo{
+			Created:  d.Created.Format(time.RFC3339),
+			Creators: []string{"Tool: terraform-registry"},
+		},
+		Packages: []spdxPackage{subject},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: subject.SPDXID},
+		},
+	}
+
+	for _, dep := range d.Dependencies {
+		pkg := toSPDXPackage(dep)
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID: subject.SPDXID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+	for _, file := range d.Contents {
+		pkg := toSPDXPackage(file)
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID: subject.SPDXID, RelationshipType: "CONTAINS", RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	return marshalDocument(doc)
+}
+
+func toSPDXPackage(c Component) spdxPackage {
+	pkg := spdxPackage{
+		SPDXID:           "SPDXRef-" + strings.Trim(spdxIDInvalidChars.ReplaceAllString(c.Ref, "-"), "-"),
+		Name:             c.Name,
+		VersionInfo:      c.Version,
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+	}
+	if c.Namespace != "" {
+		pkg.Name = c.Namespace + "/" + c.Name
+		pkg.Supplier = "Organization: " + c.Namespace
+	}
+	if c.License != "" {
+		pkg.LicenseDeclared = c.License
+	}
+	if c.SHA256 != "" {
+		pkg.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: c.SHA256}}
+	}
+	if c.PURL != "" {
+		pkg.ExternalRefs = []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: c.PURL}}
+	}
+
+	var comments []string
+	if c.Constraint != "" {
+		comments = append(comments, "version constraint: "+c.Constraint)
+	}
+	for _, property := range c.Properties {
+		comments = append(comments, property[0]+"="+property[1])
+	}
+	pkg.Comment = strings.Join(comments, "; ")
+
+	return pkg
+}
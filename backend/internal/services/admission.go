@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+const (
+	// AdmissionReviewAPIVersion is the version of the payload sent to admission webhooks
+	AdmissionReviewAPIVersion = "registry.terraform.io/admission/v1"
+
+	// admissionDownloadURLTTL is how long the artifact download URL given to webhooks stays valid
+	admissionDownloadURLTTL = 15 * time.Minute
+
+	// maxAdmissionResponseSize caps how much of a webhook response is read (64KB)
+	maxAdmissionResponseSize = 64 * 1024
+)
+
+// AdmissionController consults external admission webhooks before a version is committed
+type AdmissionController struct {
+	webhookRepo *repositories.AdmissionWebhookRepository
+	backends    *storage.Registry
+	httpClient  *http.Client
+}
+
+// NewAdmissionController creates a new admission controller
+func NewAdmissionController(webhookRepo *repositories.AdmissionWebhookRepository, backends *storage.Registry) *AdmissionController {
+	return &AdmissionController{
+		webhookRepo: webhookRepo,
+		backends:    backends,
+		httpClient:  &http.Client{},
+	}
+}
+
+// AdmissionRequest describes the version under review. Module fields (Name, System) and
+// provider fields (Type, OS, Arch) are only set for the matching artifact type.
+type AdmissionRequest struct {
+	UID                  string              `json:"uid"`
+	ArtifactType         models.ArtifactType `json:"artifact_type"`
+	OrganizationID       string              `json:"organization_id"`
+	Namespace            string              `json:"namespace"`
+	Name                 string              `json:"name,omitempty"`
+	System               string              `json:"system,omitempty"`
+	Type                 string              `json:"type,omitempty"`
+	Version              string              `json:"version"`
+	OS                   string              `json:"os,omitempty"`
+	Arch                 string              `json:"arch,omitempty"`
+	Filename             string              `json:"filename,omitempty"`
+	Checksum             string              `json:"checksum"`
+	SizeBytes            int64               `json:"size_bytes"`
+	PublishedBy          string              `json:"published_by,omitempty"`
+	DownloadURL          string              `json:"download_url"`
+	DownloadURLExpiresAt time.Time           `json:"download_url_expires_at"`
+}
+
+// AdmissionReview is the envelope exchanged with admission webhooks.
+// The registry sends Request; the webhook replies with Response.
+type AdmissionReview struct {
+	APIVersion string             `json:"api_version"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionResponse is a webhook's verdict
+type AdmissionResponse struct {
+	UID     string `json:"uid"`
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message,omitempty"`
+}
+
+// AdmissionHookResult records the outcome of calling a single webhook
+type AdmissionHookResult struct {
+	Webhook    string `json:"webhook"`
+	Allowed    bool   `json:"allowed"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	FailedOpen bool   `json:"failed_open,omitempty"`
+}
+
+// AdmissionDecision is the combined verdict of every applicable webhook
+type AdmissionDecision struct {
+	Allowed bool                  `json:"allowed"`
+	Results []AdmissionHookResult `json:"results"`
+}
+
+// DenialMessage summarizes why the publish was denied
+func (d *AdmissionDecision) DenialMessage() string {
+	for _, result := range d.Results {
+		if result.Allowed {
+			continue
+		}
+		if result.Message != "" {
+			return fmt.Sprintf("denied by %s: %s", result.Webhook, result.Message)
+		}
+		if result.Error != "" {
+			return fmt.Sprintf("denied by %s: %s", result.Webhook, result.Error)
+		}
+		return fmt.Sprintf("denied by %s", result.Webhook)
+	}
+	return ""
+}
+
+// Review calls every active webhook for the organization and artifact type. The artifact must
+// already be in storage at storagePath so webhooks can download it. Every webhook is called and
+// the publish is allowed only if none of them deny it.
+func (a *AdmissionController) Review(ctx context.Context, req AdmissionRequest, storagePath string) (*AdmissionDecision, error) {
+	var orgID *uuid.UUID
+	if id, err := uuid.Parse(req.OrganizationID); err == nil {
+		orgID = &id
+	}
+
+	hooks, err := a.webhookRepo.ListActive(ctx, orgID, req.ArtifactType)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &AdmissionDecision{Allowed: true, Results: []AdmissionHookResult{}}
+	if len(hooks) == 0 {
+		return decision, nil
+	}
+
+	// The artifact has just been written to the default backend
+	downloadURL, err := a.backends.GetURL(ctx, "", storagePath, admissionDownloadURLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate download URL: %w", err)
+	}
+	req.DownloadURL = downloadURL
+	req.DownloadURLExpiresAt = time.Now().Add(admissionDownloadURLTTL).UTC()
+	if req.UID == "" {
+		req.UID = uuid.New().String()
+	}
+
+	for _, hook := range hooks {
+		result := a.callWebhook(ctx, hook, req)
+		if !result.Allowed {
+			decision.Allowed = false
+		}
+		decision.Results = append(decision.Results, result)
+	}
+
+	return decision, nil
+}
+
+// callWebhook sends the review to a single webhook and applies its failure policy
+func (a *AdmissionController) callWebhook(ctx context.Context, hook *models.AdmissionWebhook, req AdmissionRequest) AdmissionHookResult {
+	result := AdmissionHookResult{Webhook: hook.Name}
+
+	response, err := a.postReview(ctx, hook, req)
+	if err != nil {
+		result.Error = err.Error()
+		if hook.FailurePolicy == models.AdmissionFailOpen {
+			result.Allowed = true
+			result.FailedOpen = true
+		}
+		return result
+	}
+
+	result.Allowed = response.Allowed
+	result.Message = response.Message
+	return result
+}
+
+// postReview performs the HTTP exchange with a webhook, bounded by its timeout
+func (a *AdmissionController) postReview(ctx context.Context, hook *models.AdmissionWebhook, req AdmissionRequest) (*AdmissionResponse, error) {
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(AdmissionReview{
+		APIVersion: AdmissionReviewAPIVersion,
+		Kind:       "AdmissionReview",
+		Request:    &req,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode admission review: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "terraform-registry-admission")
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	var review AdmissionReview
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAdmissionResponseSize)).Decode(&review); err != nil {
+		return nil, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("webhook response missing 'response' field")
+	}
+	if review.Response.UID != "" && review.Response.UID != req.UID {
+		return nil, fmt.Errorf("webhook response UID %q does not match request", review.Response.UID)
+	}
+
+	return review.Response, nil
+}
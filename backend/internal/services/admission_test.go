@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// admissionHandler answers an admission review the test server received
+type admissionHandler func(w http.ResponseWriter, r *http.Request, review AdmissionReview)
+
+func respondAdmission(uid string, allowed bool, message string) admissionHandler {
+	return func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+		if uid == "request" {
+			uid = review.Request.UID
+		}
+		json.NewEncoder(w).Encode(AdmissionReview{
+			APIVersion: AdmissionReviewAPIVersion,
+			Kind:       "AdmissionReview",
+			Response:   &AdmissionResponse{UID: uid, Allowed: allowed, Message: message},
+		})
+	}
+}
+
+// newAdmissionServer serves handler, failing the test if the registry sends a malformed review
+func newAdmissionServer(t *testing.T, handler admissionHandler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("webhook called with %s %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		var review AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Errorf("invalid admission review: %v", err)
+			return
+		}
+		if review.APIVersion != AdmissionReviewAPIVersion || review.Request == nil || review.Request.UID == "" {
+			t.Errorf("admission review = %+v, want a %s request with a UID", review, AdmissionReviewAPIVersion)
+			return
+		}
+		handler(w, r, review)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAdmissionCallWebhook(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler admissionHandler
+		allowed bool   // Verdict of a webhook that answered
+		message string // Message of a webhook that answered
+		failure string // Error reported when the webhook fails, if it should
+	}{
+		{name: "allowed", handler: respondAdmission("request", true, ""), allowed: true},
+		{name: "denied", handler: respondAdmission("request", false, "license not approved"), message: "license not approved"},
+		{name: "response without uid", handler: respondAdmission("", true, ""), allowed: true},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+				<-r.Context().Done()
+			},
+			failure: "webhook request failed",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			failure: "webhook returned status 500",
+		},
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			failure: "webhook returned status 404",
+		},
+		{
+			name: "missing response",
+			handler: func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+				json.NewEncoder(w).Encode(AdmissionReview{APIVersion: AdmissionReviewAPIVersion, Kind: "AdmissionReview"})
+			},
+			failure: "missing 'response' field",
+		},
+		{
+			name: "invalid json",
+			handler: func(w http.ResponseWriter, r *http.Request, review AdmissionReview) {
+				w.Write([]byte("allowed"))
+			},
+			failure: "invalid webhook response",
+		},
+		{name: "uid mismatch", handler: respondAdmission("another-request", true, ""), failure: "does not match request"},
+	}
+
+	for _, tt := range tests {
+		server := newAdmissionServer(t, tt.handler)
+		for _, policy := range []models.AdmissionFailurePolicy{models.AdmissionFailClosed, models.AdmissionFailOpen} {
+			t.Run(tt.name+"/"+string(policy), func(t *testing.T) {
+				controller := &AdmissionController{httpClient: &http.Client{}}
+				hook := &models.AdmissionWebhook{Name: "policy-check", URL: server.URL, TimeoutSeconds: 1, FailurePolicy: policy}
+
+				result := controller.callWebhook(context.Background(), hook, AdmissionRequest{UID: "request-uid"})
+
+				if result.Webhook != hook.Name {
+					t.Errorf("result webhook = %q, want %q", result.Webhook, hook.Name)
+				}
+				if tt.failure == "" {
+					if result.Error != "" || result.FailedOpen {
+						t.Fatalf("result = %+v, want the webhook's verdict", result)
+					}
+					if result.Allowed != tt.allowed || result.Message != tt.message {
+						t.Errorf("result = %+v, want allowed %v with message %q", result, tt.allowed, tt.message)
+					}
+					return
+				}
+
+				if !strings.Contains(result.Error, tt.failure) {
+					t.Errorf("result error = %q, want it to contain %q", result.Error, tt.failure)
+				}
+				failOpen := policy == models.AdmissionFailOpen
+				if result.Allowed != failOpen || result.FailedOpen != failOpen {
+					t.Errorf("result = %+v, want allowed and failed open %v under %s", result, failOpen, policy)
+				}
+			})
+		}
+	}
+}
+
+func TestAdmissionDenialMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		decision AdmissionDecision
+		want     string
+	}{
+		{"allowed", AdmissionDecision{Allowed: true, Results: []AdmissionHookResult{{Webhook: "a", Allowed: true}}}, ""},
+		{"message", AdmissionDecision{Results: []AdmissionHookResult{{Webhook: "a", Allowed: true}, {Webhook: "b", Message: "unsigned"}}}, "denied by b: unsigned"},
+		{"error", AdmissionDecision{Results: []AdmissionHookResult{{Webhook: "a", Error: "webhook returned status 500"}}}, "denied by a: webhook returned status 500"},
+		{"bare denial", AdmissionDecision{Results: []AdmissionHookResult{{Webhook: "a"}}}, "denied by a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decision.DenialMessage(); got != tt.want {
+				t.Errorf("DenialMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
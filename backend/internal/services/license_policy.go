@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// LicensePolicyEvaluator checks detected licenses against organization license policies
+type LicensePolicyEvaluator struct {
+	policyRepo *repositories.LicensePolicyRepository
+}
+
+// NewLicensePolicyEvaluator creates a new license policy evaluator
+func NewLicensePolicyEvaluator(policyRepo *repositories.LicensePolicyRepository) *LicensePolicyEvaluator {
+	return &LicensePolicyEvaluator{policyRepo: policyRepo}
+}
+
+// LicenseDecision is the outcome of checking a license against every applicable policy
+type LicenseDecision struct {
+	License string `json:"license,omitempty"`
+	Allowed bool   `json:"allowed"`
+	Policy  string `json:"policy,omitempty"` // Name of the policy that denied the license
+	Message string `json:"message,omitempty"`
+}
+
+// Check evaluates the SPDX identifier against the active policies for the organization and
+// artifact type. An empty identifier means no license was detected. The first denying policy wins.
+func (e *LicensePolicyEvaluator) Check(ctx context.Context, organizationID string, artifactType models.ArtifactType, spdx string) (*LicenseDecision, error) {
+	var orgID *uuid.UUID
+	if id, err := uuid.Parse(organizationID); err == nil {
+		orgID = &id
+	}
+
+	policies, err := e.policyRepo.ListActive(ctx, orgID, artifactType)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := &LicenseDecision{License: spdx, Allowed: true}
+	for _, policy := range policies {
+		if policy.Permits(spdx) {
+			continue
+		}
+
+		decision.Allowed = false
+		decision.Policy = policy.Name
+		if spdx == "" {
+			decision.Message = fmt.Sprintf("no license detected; license policy %s requires a recognized license", policy.Name)
+		} else {
+			decision.Message = fmt.Sprintf("license %s is not permitted by license policy %s", spdx, policy.Name)
+		}
+		break
+	}
+
+	return decision, nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/attestation"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// SignatureVerifier verifies cosign signatures and SLSA provenance uploaded with module
+// versions against the organization's trusted signers
+type SignatureVerifier struct {
+	signerRepo *repositories.TrustedSignerRepository
+}
+
+// NewSignatureVerifier creates a new signature verifier
+func NewSignatureVerifier(signerRepo *repositories.TrustedSignerRepository) *SignatureVerifier {
+	return &SignatureVerifier{signerRepo: signerRepo}
+}
+
+// ModuleSignatures is the signing material uploaded alongside a module archive
+type ModuleSignatures struct {
+	Signature             []byte // Detached cosign blob signature over the archive
+	Certificate           []byte // PEM signing certificate chain for keyless signatures
+	Provenance            []byte // DSSE envelope (or .intoto.jsonl) holding a SLSA provenance statement
+	ProvenanceCertificate []byte // PEM signing certificate chain for keyless provenance
+}
+
+// SignedArchive identifies the archive whose signatures are verified by its SHA-256 digest.
+// The archive itself is only loaded for Ed25519 signatures, which sign the full message.
+type SignedArchive struct {
+	Digest []byte
+	Read   func() ([]byte, error)
+}
+
+// SignatureReport is the outcome of verifying a module version's signing material
+type SignatureReport struct {
+	SignatureStatus  models.AttestationStatus           `json:"signature_status"`
+	ProvenanceStatus models.AttestationStatus           `json:"provenance_status"`
+	SignedBy         string                             `json:"signed_by,omitempty"`
+	BuilderID        string                             `json:"builder_id,omitempty"`
+	Errors           []string                           `json:"errors,omitempty"`
+	Attestations     []*models.ModuleVersionAttestation `json:"-"`
+}
+
+// Verify checks the uploaded signing material against the active trusted signers for the
+// organization and namespace. Material that cannot be verified is recorded as unverified
+// rather than rejected; publish rules decide whether that blocks the publish.
+func (v *SignatureVerifier) Verify(ctx context.Context, organizationID, namespace string, archive SignedArchive, material ModuleSignatures) (*SignatureReport, error) {
+	report := &SignatureReport{
+		SignatureStatus:  models.AttestationStatusNone,
+		ProvenanceStatus: models.AttestationStatusNone,
+	}
+	if len(material.Signature) == 0 && len(material.Provenance) == 0 {
+		return report, nil
+	}
+
+	var orgID *uuid.UUID
+	if id, err := uuid.Parse(organizationID); err == nil {
+		orgID = &id
+	}
+
+	allSigners, err := v.signerRepo.ListActive(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var signers []*models.TrustedSigner
+	for _, signer := range allSigners {
+		if signer.Matches(namespace) {
+			signers = append(signers, signer)
+		}
+	}
+
+	if len(material.Signature) > 0 {
+		record := v.verifySignature(signers, archive, material)
+		report.Attestations = append(report.Attestations, record)
+		if record.Verified {
+			report.SignatureStatus = models.AttestationStatusVerified
+			report.SignedBy = derefString(record.SignerIdentity)
+		} else {
+			report.SignatureStatus = models.AttestationStatusUnverified
+			report.Errors = append(report.Errors, "signature: "+derefString(record.VerificationError))
+		}
+	}
+
+	if len(material.Provenance) > 0 {
+		record := v.verifyProvenance(signers, archive, material)
+		report.Attestations = append(report.Attestations, record)
+		report.BuilderID = derefString(record.BuilderID)
+		if record.Verified {
+			report.ProvenanceStatus = models.AttestationStatusVerified
+		} else {
+			report.ProvenanceStatus = models.AttestationStatusUnverified
+			report.Errors = append(report.Errors, "provenance: "+derefString(record.VerificationError))
+		}
+	}
+
+	return report, nil
+}
+
+// Record stores a report's attestations against the published module version
+func (v *SignatureVerifier) Record(ctx context.Context, moduleVersionID string, report *SignatureReport) error {
+	if report == nil || len(report.Attestations) == 0 {
+		return nil
+	}
+
+	versionID, err := uuid.Parse(moduleVersionID)
+	if err != nil {
+		return fmt.Errorf("invalid module version ID: %w", err)
+	}
+
+	for _, record := range report.Attestations {
+		record.ModuleVersionID = versionID
+	}
+
+	return v.signerRepo.CreateAttestations(ctx, report.Attestations)
+}
+
+// verifySignature checks a detached signature over the archive against each signer in turn
+func (v *SignatureVerifier) verifySignature(signers []*models.TrustedSigner, archive SignedArchive, material ModuleSignatures) *models.ModuleVersionAttestation {
+	signature := attestation.DecodeSignature(material.Signature)
+	record := &models.ModuleVersionAttestation{
+		AttestationType: models.AttestationTypeSignature,
+		Content:         base64.StdEncoding.EncodeToString(signature),
+		Certificate:     optionalString(material.Certificate),
+	}
+
+	var failures []string
+	for _, signer := range signers {
+		identity, err := verifyWithSigner(signer, archive.Digest, archive.Read, signature, material.Certificate)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", signer.Name, err))
+			continue
+		}
+		markVerified(record, signer, identity)
+		return record
+	}
+
+	record.VerificationError = verificationFailure(failures)
+	return record
+}
+
+// verifyProvenance checks that a DSSE envelope carries SLSA provenance for the archive and
+// is signed by a trusted signer whose builder pattern, if any, matches the recorded builder
+func (v *SignatureVerifier) verifyProvenance(signers []*models.TrustedSigner, archive SignedArchive, material ModuleSignatures) *models.ModuleVersionAttestation {
+	record := &models.ModuleVersionAttestation{
+		AttestationType: models.AttestationTypeProvenance,
+		Content:         string(material.Provenance),
+		Certificate:     optionalString(material.ProvenanceCertificate),
+	}
+	fail := func(message string) *models.ModuleVersionAttestation {
+		record.VerificationError = &message
+		return record
+	}
+
+	envelope, err := attestation.ParseEnvelope(material.Provenance)
+	if err != nil {
+		return fail(err.Error())
+	}
+	statement, err := envelope.Statement()
+	if err != nil {
+		return fail(err.Error())
+	}
+	record.PredicateType = optionalString([]byte(statement.PredicateType))
+	record.BuilderID = optionalString([]byte(statement.BuilderID()))
+
+	if !statement.IsSLSAProvenance() {
+		return fail(fmt.Sprintf("unsupported predicate type %q", statement.PredicateType))
+	}
+	if !statement.HasSubjectDigest(hex.EncodeToString(archive.Digest)) {
+		return fail("provenance subject does not match the archive digest")
+	}
+	if len(envelope.Signatures) == 0 {
+		return fail("envelope is not signed")
+	}
+	message, err := envelope.SignedMessage()
+	if err != nil {
+		return fail(err.Error())
+	}
+	messageDigest := sha256.Sum256(message)
+	readMessage := func() ([]byte, error) { return message, nil }
+
+	var failures []string
+	for _, signer := range signers {
+		if signer.BuilderIDPattern != nil && *signer.BuilderIDPattern != "" {
+			if ok, err := matchAnchored(*signer.BuilderIDPattern, statement.BuilderID()); err != nil || !ok {
+				failures = append(failures, fmt.Sprintf("%s: builder %q is not trusted", signer.Name, statement.BuilderID()))
+				continue
+			}
+		}
+		for _, envelopeSig := range envelope.Signatures {
+			signature, err := base64.StdEncoding.DecodeString(envelopeSig.Sig)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: invalid envelope signature encoding", signer.Name))
+				continue
+			}
+			identity, err := verifyWithSigner(signer, messageDigest[:], readMessage, signature, material.ProvenanceCertificate)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", signer.Name, err))
+				continue
+			}
+			markVerified(record, signer, identity)
+			return record
+		}
+	}
+
+	record.VerificationError = verificationFailure(failures)
+	return record
+}
+
+// verifyWithSigner verifies a signature over a message, given its digest, with a single trusted signer and
+// returns the identity that signed it
+func verifyWithSigner(signer *models.TrustedSigner, digest []byte, readMessage func() ([]byte, error), signature, certificate []byte) (string, error) {
+	switch signer.SignerType {
+	case models.TrustedSignerPublicKey:
+		key, err := attestation.ParsePublicKey(derefString(signer.PublicKey))
+		if err != nil {
+			return "", err
+		}
+		if err := attestation.VerifyDigestSignature(key, digest, readMessage, signature); err != nil {
+			return "", err
+		}
+		return signer.Name, nil
+
+	case models.TrustedSignerKeyless:
+		if len(certificate) == 0 {
+			return "", fmt.Errorf("a signing certificate is required")
+		}
+		leaf, intermediates, err := attestation.ParseCertificateChain(certificate)
+		if err != nil {
+			return "", err
+		}
+		identity, err := attestation.VerifyCertificate(leaf, intermediates, attestation.CertificatePolicy{
+			Roots:           derefString(signer.RootCertificates),
+			IdentityPattern: derefString(signer.CertificateIdentity),
+			Issuer:          derefString(signer.CertificateIssuer),
+		})
+		if err != nil {
+			return "", err
+		}
+		if err := attestation.VerifyDigestSignature(leaf.PublicKey, digest, readMessage, signature); err != nil {
+			return "", err
+		}
+		return identity, nil
+
+	default:
+		return "", fmt.Errorf("unknown signer type %q", signer.SignerType)
+	}
+}
+
+func markVerified(record *models.ModuleVersionAttestation, signer *models.TrustedSigner, identity string) {
+	signerID := signer.ID
+	record.Verified = true
+	record.SignerID = &signerID
+	record.SignerIdentity = &identity
+	record.VerificationError = nil
+}
+
+func verificationFailure(failures []string) *string {
+	message := "no trusted signer applies to this namespace"
+	if len(failures) > 0 {
+		message = strings.Join(failures, "; ")
+	}
+	return &message
+}
+
+// matchAnchored matches value against a regular expression that must cover the whole value
+func matchAnchored(pattern, value string) (bool, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(value), nil
+}
+
+func optionalString(data []byte) *string {
+	if len(data) == 0 {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
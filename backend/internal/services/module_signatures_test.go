@@ -0,0 +1,176 @@
+package services
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/attestation"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// publicKeySigner returns a trusted signer for the public half of key
+func publicKeySigner(t *testing.T, name string, key crypto.PublicKey) *models.TrustedSigner {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return &models.TrustedSigner{
+		ID:         uuid.New(),
+		Name:       name,
+		SignerType: models.TrustedSignerPublicKey,
+		PublicKey:  &pemKey,
+		IsActive:   true,
+	}
+}
+
+// testArchive returns a signed archive whose contents are only readable when allowRead is set
+func testArchive(t *testing.T, data []byte, allowRead bool) SignedArchive {
+	digest := sha256.Sum256(data)
+	return SignedArchive{
+		Digest: digest[:],
+		Read: func() ([]byte, error) {
+			if !allowRead {
+				t.Error("archive read although the signature is verified by digest")
+			}
+			return data, nil
+		},
+	}
+}
+
+func TestVerifySignatureWithTrustedKey(t *testing.T) {
+	archive := []byte("module archive contents")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(archive)
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSignature := ed25519.Sign(edKey, archive)
+
+	v := &SignatureVerifier{}
+	tests := []struct {
+		name      string
+		signers   []*models.TrustedSigner
+		signature []byte
+		readable  bool
+		verified  bool
+		signedBy  string
+	}{
+		{"ecdsa key", []*models.TrustedSigner{publicKeySigner(t, "release", ecKey.Public())}, ecSignature, false, true, "release"},
+		{"ed25519 key", []*models.TrustedSigner{publicKeySigner(t, "ed", edPub)}, edSignature, true, true, "ed"},
+		{"second signer matches", []*models.TrustedSigner{publicKeySigner(t, "other", otherKey.Public()), publicKeySigner(t, "release", ecKey.Public())}, ecSignature, false, true, "release"},
+		{"untrusted key", []*models.TrustedSigner{publicKeySigner(t, "other", otherKey.Public())}, ecSignature, false, false, ""},
+		{"no signers", nil, ecSignature, false, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := ModuleSignatures{Signature: []byte(base64.StdEncoding.EncodeToString(tt.signature))}
+			record := v.verifySignature(tt.signers, testArchive(t, archive, tt.readable), material)
+			if record.Verified != tt.verified {
+				t.Fatalf("Verified = %v, want %v (error %q)", record.Verified, tt.verified, derefString(record.VerificationError))
+			}
+			if got := derefString(record.SignerIdentity); got != tt.signedBy {
+				t.Errorf("SignerIdentity = %q, want %q", got, tt.signedBy)
+			}
+			if !tt.verified && record.VerificationError == nil {
+				t.Error("unverified signature has no verification error")
+			}
+		})
+	}
+}
+
+// provenanceEnvelope returns a DSSE envelope holding SLSA v1 provenance for archive, signed by key
+func provenanceEnvelope(t *testing.T, key *ecdsa.PrivateKey, archive []byte, builderID string) []byte {
+	t.Helper()
+	digest := sha256.Sum256(archive)
+	statement, err := json.Marshal(attestation.Statement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       []attestation.Subject{{Name: "module.tar.gz", Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])}}},
+		PredicateType: attestation.SLSAProvenanceV1,
+		Predicate:     json.RawMessage(`{"runDetails":{"builder":{"id":"` + builderID + `"}}}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageDigest := sha256.Sum256(attestation.PAE(attestation.InTotoPayloadType, statement))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, messageDigest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := json.Marshal(attestation.Envelope{
+		PayloadType: attestation.InTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures:  []attestation.EnvelopeSignature{{Sig: base64.StdEncoding.EncodeToString(signature)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestVerifyProvenanceWithTrustedKey(t *testing.T) {
+	archive := []byte("module archive contents")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const builder = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/builder.yml@refs/tags/v2.0.0"
+
+	trusted := publicKeySigner(t, "ci", key.Public())
+	pinned := publicKeySigner(t, "ci", key.Public())
+	pattern := `https://github\.com/slsa-framework/slsa-github-generator/.*`
+	pinned.BuilderIDPattern = &pattern
+	otherBuilder := publicKeySigner(t, "ci", key.Public())
+	otherPattern := `https://ci\.example\.com/.*`
+	otherBuilder.BuilderIDPattern = &otherPattern
+
+	v := &SignatureVerifier{}
+	tests := []struct {
+		name     string
+		signer   *models.TrustedSigner
+		archive  []byte
+		verified bool
+	}{
+		{"trusted key", trusted, archive, true},
+		{"builder pattern matches", pinned, archive, true},
+		{"builder pattern does not match", otherBuilder, archive, false},
+		{"provenance for another archive", trusted, []byte("another archive"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			material := ModuleSignatures{Provenance: provenanceEnvelope(t, key, archive, builder)}
+			record := v.verifyProvenance([]*models.TrustedSigner{tt.signer}, testArchive(t, tt.archive, false), material)
+			if record.Verified != tt.verified {
+				t.Fatalf("Verified = %v, want %v (error %q)", record.Verified, tt.verified, derefString(record.VerificationError))
+			}
+			if got := derefString(record.BuilderID); got != builder {
+				t.Errorf("BuilderID = %q, want %q", got, builder)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/attestation"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+const (
+	// ModuleManifestPayloadType is the DSSE payload type of registry-signed module manifests
+	ModuleManifestPayloadType = "application/vnd.terraform-registry.module-manifest+json"
+
+	moduleManifestSchema = "terraform-registry/module-manifest/v1"
+)
+
+// ModuleManifest is the statement the registry signs for each stored module archive
+type ModuleManifest struct {
+	Schema      string `json:"schema"`
+	Registry    string `json:"registry"`
+	Module      string `json:"module"` // namespace/name/system
+	Version     string `json:"version"`
+	Digest      string `json:"digest"` // sha256:<hex> of the archive
+	Size        int64  `json:"size"`
+	PublishedAt string `json:"published_at"`
+	Publisher   string `json:"publisher,omitempty"` // ID of the user who published the version
+}
+
+// SignedManifest is a module manifest wrapped in a DSSE envelope, alongside its decoded form
+type SignedManifest struct {
+	attestation.Envelope
+	Manifest ModuleManifest `json:"manifest"`
+}
+
+// PublicSigningKey is a registry public key in JWK form, extended with its PEM encoding and status
+type PublicSigningKey struct {
+	KeyType   string                  `json:"kty"`
+	Curve     string                  `json:"crv"`
+	X         string                  `json:"x"`
+	KeyID     string                  `json:"kid"`
+	Algorithm string                  `json:"alg"`
+	Use       string                  `json:"use"`
+	PEM       string                  `json:"pem"`
+	Status    models.SigningKeyStatus `json:"status"`
+	CreatedAt time.Time               `json:"created_at"`
+	RetiredAt *time.Time              `json:"retired_at,omitempty"`
+}
+
+// ModuleSigner signs module archive manifests with per-organization Ed25519 keys whose
+// private halves are stored encrypted with the token cipher
+type ModuleSigner struct {
+	keyRepo     *repositories.SigningKeyRepository
+	tokenCipher *crypto.TokenCipher
+	registryURL string
+}
+
+// NewModuleSigner creates a new module signer. baseURL is recorded in every manifest.
+func NewModuleSigner(keyRepo *repositories.SigningKeyRepository, tokenCipher *crypto.TokenCipher, baseURL string) *ModuleSigner {
+	return &ModuleSigner{
+		keyRepo:     keyRepo,
+		tokenCipher: tokenCipher,
+		registryURL: baseURL,
+	}
+}
+
+// SignModuleVersion builds, signs and stores the manifest of a module version
+func (s *ModuleSigner) SignModuleVersion(ctx context.Context, organizationID string, module *models.Module, version *models.ModuleVersion) (*SignedManifest, error) {
+	versionID, err := uuid.Parse(version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module version ID: %w", err)
+	}
+
+	key, privateKey, err := s.activeKey(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := ModuleManifest{
+		Schema:      moduleManifestSchema,
+		Registry:    s.registryURL,
+		Module:      module.Namespace + "/" + module.Name + "/" + module.System,
+		Version:     version.Version,
+		Digest:      "sha256:" + version.Checksum,
+		Size:        version.SizeBytes,
+		PublishedAt: version.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if version.PublishedBy != nil {
+		manifest.Publisher = *version.PublishedBy
+	}
+
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode module manifest: %w", err)
+	}
+	signature := ed25519.Sign(privateKey, attestation.PAE(ModuleManifestPayloadType, payload))
+
+	record := &models.ModuleVersionManifest{
+		ModuleVersionID: versionID,
+		KeyID:           key.KeyID,
+		Payload:         string(payload),
+		Signature:       base64.StdEncoding.EncodeToString(signature),
+	}
+	if err := s.keyRepo.SaveManifest(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return toSignedManifest(record)
+}
+
+// ModuleVersionManifest returns the signed manifest of a module version, signing one for
+// versions published before registry signing was enabled
+func (s *ModuleSigner) ModuleVersionManifest(ctx context.Context, organizationID string, module *models.Module, version *models.ModuleVersion) (*SignedManifest, error) {
+	versionID, err := uuid.Parse(version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module version ID: %w", err)
+	}
+
+	record, err := s.keyRepo.GetManifest(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return s.SignModuleVersion(ctx, organizationID, module, version)
+	}
+
+	return toSignedManifest(record)
+}
+
+// RotateKey generates a new active signing key for the organization and retires the
+// previous one. Retired keys stay in the key set so existing manifests remain verifiable.
+func (s *ModuleSigner) RotateKey(ctx context.Context, organizationID string) (*models.RegistrySigningKey, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organization ID: %w", err)
+	}
+
+	key, _, err := s.generateKey(orgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.keyRepo.CreateKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// PublicKeys returns the organization's public key set, including retired keys
+func (s *ModuleSigner) PublicKeys(ctx context.Context, organizationID string) ([]PublicSigningKey, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organization ID: %w", err)
+	}
+
+	keys, err := s.keyRepo.ListKeys(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKeys := make([]PublicSigningKey, 0, len(keys))
+	for _, key := range keys {
+		parsed, err := attestation.ParsePublicKey(key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %s: %w", key.KeyID, err)
+		}
+		edKey, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an Ed25519 key", key.KeyID)
+		}
+		publicKeys = append(publicKeys, PublicSigningKey{
+			KeyType:   "OKP",
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(edKey),
+			KeyID:     key.KeyID,
+			Algorithm: "EdDSA",
+			Use:       "sig",
+			PEM:       key.PublicKey,
+			Status:    key.Status,
+			CreatedAt: key.CreatedAt,
+			RetiredAt: key.RetiredAt,
+		})
+	}
+
+	return publicKeys, nil
+}
+
+// activeKey returns the organization's active signing key and its decrypted private key,
+// generating the key on first use
+func (s *ModuleSigner) activeKey(ctx context.Context, organizationID string) (*models.RegistrySigningKey, ed25519.PrivateKey, error) {
+	orgID, err := uuid.Parse(organizationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid organization ID: %w", err)
+	}
+
+	key, err := s.keyRepo.GetActiveKey(ctx, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil {
+		newKey, privateKey, err := s.generateKey(orgID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.keyRepo.CreateKey(ctx, newKey); err != nil {
+			return nil, nil, err
+		}
+		return newKey, privateKey, nil
+	}
+
+	privatePEM, err := s.tokenCipher.Open(key.PrivateKeyEncrypted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt signing key %s: %w", key.KeyID, err)
+	}
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, nil, fmt.Errorf("signing key %s is not PEM encoded", key.KeyID)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse signing key %s: %w", key.KeyID, err)
+	}
+	privateKey, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("signing key %s is not an Ed25519 key", key.KeyID)
+	}
+
+	return key, privateKey, nil
+}
+
+// generateKey creates a new active Ed25519 key with its private half sealed by the token cipher
+func (s *ModuleSigner) generateKey(orgID uuid.UUID) (*models.RegistrySigningKey, ed25519.PrivateKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	sealed, err := s.tokenCipher.Seal(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encrypt signing key: %w", err)
+	}
+
+	fingerprint := sha256.Sum256(publicDER)
+	key := &models.RegistrySigningKey{
+		ID:                  uuid.New(),
+		OrganizationID:      orgID,
+		KeyID:               hex.EncodeToString(fingerprint[:16]),
+		Algorithm:           "ed25519",
+		PublicKey:           string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		PrivateKeyEncrypted: sealed,
+		Status:              models.SigningKeyActive,
+		CreatedAt:           time.Now(),
+	}
+
+	return key, privateKey, nil
+}
+
+func toSignedManifest(record *models.ModuleVersionManifest) (*SignedManifest, error) {
+	var manifest ModuleManifest
+	if err := json.Unmarshal([]byte(record.Payload), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode module manifest: %w", err)
+	}
+
+	return &SignedManifest{
+		Envelope: attestation.Envelope{
+			PayloadType: ModuleManifestPayloadType,
+			Payload:     base64.StdEncoding.EncodeToString([]byte(record.Payload)),
+			Signatures:  []attestation.EnvelopeSignature{{KeyID: record.KeyID, Sig: record.Signature}},
+		},
+		Manifest: manifest,
+	}, nil
+}
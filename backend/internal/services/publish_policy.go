@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// PublishPolicyEvaluator evaluates organization publish rules against module archives
+type PublishPolicyEvaluator struct {
+	ruleRepo *repositories.PublishRuleRepository
+}
+
+// NewPublishPolicyEvaluator creates a new publish policy evaluator
+func NewPublishPolicyEvaluator(ruleRepo *repositories.PublishRuleRepository) *PublishPolicyEvaluator {
+	return &PublishPolicyEvaluator{ruleRepo: ruleRepo}
+}
+
+// PublishPolicyReport is the outcome of evaluating every applicable rule for a publish
+type PublishPolicyReport struct {
+	Results []*models.ModuleVersionPolicyResult `json:"results"`
+	Blocked bool                                `json:"blocked"`
+}
+
+// Failures returns the results of rules that did not pass
+func (r *PublishPolicyReport) Failures() []*models.ModuleVersionPolicyResult {
+	var failures []*models.ModuleVersionPolicyResult
+	for _, result := range r.Results {
+		if !result.Passed {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// PublishRequest describes the module version being published. Publishes that cannot carry
+// signing material, such as SCM tag pushes, leave Signatures nil; signature_required rules do
+// not apply to them, as they could never be satisfied. Those publishes are limited instead to
+// the repositories module administrators have linked.
+type PublishRequest struct {
+	OrganizationID string
+	Namespace      string
+	Version        string
+	Tag            string           // Source tag for SCM publishes; version_pattern rules match it instead of Version
+	Signatures     *SignatureReport // Verification of uploaded signing material; nil when none could be supplied
+}
+
+// Evaluate runs every active rule that applies to the request against the archive contents
+func (e *PublishPolicyEvaluator) Evaluate(ctx context.Context, req PublishRequest, contents *validation.ModuleContents) (*PublishPolicyReport, error) {
+	var orgID *uuid.UUID
+	if id, err := uuid.Parse(req.OrganizationID); err == nil {
+		orgID = &id
+	}
+
+	rules, err := e.ruleRepo.ListRules(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PublishPolicyReport{Results: []*models.ModuleVersionPolicyResult{}}
+	for _, rule := range rules {
+		if !rule.IsActive || !rule.Matches(req.Namespace) {
+			continue
+		}
+
+		violations, err := evaluatePublishRule(rule, req, contents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate rule %s: %w", rule.Name, err)
+		}
+
+		ruleID := rule.ID
+		result := &models.ModuleVersionPolicyResult{
+			RuleID:      &ruleID,
+			RuleName:    rule.Name,
+			RuleType:    rule.RuleType,
+			Enforcement: rule.Enforcement,
+			Passed:      len(violations) == 0,
+		}
+		if len(violations) > 0 {
+			message := strings.Join(violations, "; ")
+			result.Message = &message
+			if rule.Enforcement == models.RuleEnforcementBlock {
+				report.Blocked = true
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// Record stores a report's results against the published module version
+func (e *PublishPolicyEvaluator) Record(ctx context.Context, moduleVersionID string, report *PublishPolicyReport) error {
+	if report == nil || len(report.Results) == 0 {
+		return nil
+	}
+
+	versionID, err := uuid.Parse(moduleVersionID)
+	if err != nil {
+		return fmt.Errorf("invalid module version ID: %w", err)
+	}
+
+	for _, result := range report.Results {
+		result.ModuleVersionID = versionID
+	}
+
+	return e.ruleRepo.CreateVersionResults(ctx, report.Results)
+}
+
+// evaluatePublishRule dispatches a rule to its check and returns any violations
+func evaluatePublishRule(rule *models.ModulePublishRule, req PublishRequest, contents *validation.ModuleContents) ([]string, error) {
+	switch rule.RuleType {
+	case models.PublishRuleReadmeRequired:
+		return validation.CheckReadmePresent(contents), nil
+	case models.PublishRuleVariableDescriptions:
+		return validation.CheckVariableDescriptions(contents), nil
+	case models.PublishRuleVariableTypes:
+		return validation.CheckVariableTypes(contents), nil
+	case models.PublishRuleProviderVersionConstraints:
+		return validation.CheckProviderVersionConstraints(contents), nil
+	case models.PublishRuleLicenseRequired:
+		return validation.CheckLicensePresent(contents), nil
+	case models.PublishRuleNoProviderCredentials:
+		return validation.CheckNoProviderCredentials(contents), nil
+	case models.PublishRuleVersionPattern:
+		subject := req.Version
+		if req.Tag != "" {
+			subject = req.Tag
+		}
+		var pattern string
+		if rule.Parameter != nil {
+			pattern = *rule.Parameter
+		}
+		return validation.CheckVersionPattern(subject, pattern)
+	case models.PublishRuleSignatureRequired:
+		if req.Signatures == nil {
+			return nil, nil
+		}
+		requireProvenance := rule.Parameter != nil && strings.EqualFold(strings.TrimSpace(*rule.Parameter), "provenance")
+		return checkSignatureRequired(req.Signatures, requireProvenance), nil
+	default:
+		return nil, fmt.Errorf("unknown rule type: %s", rule.RuleType)
+	}
+}
+
+// checkSignatureRequired reports a violation unless the publish carries a verified signature
+// and, when required, verified provenance
+func checkSignatureRequired(report *SignatureReport, requireProvenance bool) []string {
+	signatureStatus, provenanceStatus := report.SignatureStatus, report.ProvenanceStatus
+
+	var violations []string
+	switch signatureStatus {
+	case models.AttestationStatusNone:
+		violations = append(violations, "module version is not signed")
+	case models.AttestationStatusUnverified:
+		violations = append(violations, "module signature could not be verified by a trusted signer")
+	}
+	if requireProvenance {
+		switch provenanceStatus {
+		case models.AttestationStatusNone:
+			violations = append(violations, "module version has no provenance attestation")
+		case models.AttestationStatusUnverified:
+			violations = append(violations, "module provenance could not be verified by a trusted signer")
+		}
+	}
+	return violations
+}
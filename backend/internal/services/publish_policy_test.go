@@ -0,0 +1,39 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+func TestSignatureRequiredRule(t *testing.T) {
+	provenance := "provenance"
+	signatureRule := &models.ModulePublishRule{Name: "signed", RuleType: models.PublishRuleSignatureRequired}
+	provenanceRule := &models.ModulePublishRule{Name: "provenance", RuleType: models.PublishRuleSignatureRequired, Parameter: &provenance}
+
+	signedOnly := &SignatureReport{SignatureStatus: models.AttestationStatusVerified, ProvenanceStatus: models.AttestationStatusNone}
+	tests := []struct {
+		name       string
+		rule       *models.ModulePublishRule
+		signatures *SignatureReport
+		violations int
+	}{
+		{"verified signature", signatureRule, signedOnly, 0},
+		{"unsigned upload", signatureRule, &SignatureReport{SignatureStatus: models.AttestationStatusNone, ProvenanceStatus: models.AttestationStatusNone}, 1},
+		{"unverified signature", signatureRule, &SignatureReport{SignatureStatus: models.AttestationStatusUnverified, ProvenanceStatus: models.AttestationStatusNone}, 1},
+		{"provenance missing", provenanceRule, signedOnly, 1},
+		{"signature and provenance verified", provenanceRule, &SignatureReport{SignatureStatus: models.AttestationStatusVerified, ProvenanceStatus: models.AttestationStatusVerified}, 0},
+		{"scm publish is exempt", provenanceRule, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := evaluatePublishRule(tt.rule, PublishRequest{Version: "1.0.0", Signatures: tt.signatures}, nil)
+			if err != nil {
+				t.Fatalf("evaluatePublishRule: %v", err)
+			}
+			if len(violations) != tt.violations {
+				t.Errorf("violations = %q, want %d", violations, tt.violations)
+			}
+		})
+	}
+}
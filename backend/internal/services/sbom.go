@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/sbom"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// SBOMService generates, stores and retrieves SBOMs for module and provider versions.
+// SBOMs are stored alongside the artifact and regenerated on demand when missing, or for
+// providers, when the version's platforms no longer match the stored document.
+type SBOMService struct {
+	providerRepo   *repositories.ProviderRepository
+	storageBackend storage.Storage
+	registryHost   string
+}
+
+// NewSBOMService creates a new SBOM service. baseURL is the registry's public URL,
+// whose host is recorded in package URLs.
+func NewSBOMService(providerRepo *repositories.ProviderRepository, storageBackend storage.Storage, baseURL string) *SBOMService {
+	var host string
+	if parsed, err := url.Parse(baseURL); err == nil {
+		host = parsed.Host
+	}
+	return &SBOMService{
+		providerRepo:   providerRepo,
+		storageBackend: storageBackend,
+		registryHost:   host,
+	}
+}
+
+// GenerateModuleSBOM builds and stores the SBOM of a module version in every format
+func (s *SBOMService) GenerateModuleSBOM(ctx context.Context, module *models.Module, version *models.ModuleVersion, contents *validation.ModuleContents) error {
+	doc := sbom.ForModule(s.registryHost, module, version, contents)
+	for _, format := range sbom.Formats {
+		if _, err := s.store(ctx, doc, format, sbom.ModulePath(module.Namespace, module.Name, module.System, version.Version, format)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModuleSBOM returns the stored SBOM of a module version, regenerating it from the
+// archive for versions published before SBOMs were produced
+func (s *SBOMService) ModuleSBOM(ctx context.Context, module *models.Module, version *models.ModuleVersion, format sbom.Format) ([]byte, error) {
+	storagePath := sbom.ModulePath(module.Namespace, module.Name, module.System, version.Version, format)
+	if data, ok, err := s.load(ctx, storagePath); err != nil || ok {
+		return data, err
+	}
+
+	archive, err := s.storageBackend.Download(ctx, version.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download module archive: %w", err)
+	}
+	defer archive.Close()
+
+	contents, err := validation.LoadModuleContents(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module archive: %w", err)
+	}
+
+	return s.store(ctx, sbom.ForModule(s.registryHost, module, version, contents), format, storagePath)
+}
+
+// GenerateProviderSBOM builds and stores the SBOM of a provider version in every format.
+// It is called whenever a platform is added so the stored SBOM lists every binary; documents
+// that still fall behind are caught by ProviderSBOM.
+func (s *SBOMService) GenerateProviderSBOM(ctx context.Context, provider *models.Provider, version *models.ProviderVersion) error {
+	platforms, err := s.providerRepo.ListPlatforms(ctx, version.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list provider platforms: %w", err)
+	}
+
+	doc := sbom.ForProvider(s.registryHost, provider, version, platforms)
+	for _, format := range sbom.Formats {
+		if _, err := s.store(ctx, doc, format, sbom.ProviderPath(provider.Namespace, provider.Type, version.Version, format)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProviderSBOM returns the stored SBOM of a provider version, regenerating it in every format
+// when missing or built from a different set of platforms
+func (s *SBOMService) ProviderSBOM(ctx context.Context, provider *models.Provider, version *models.ProviderVersion, format sbom.Format) ([]byte, error) {
+	platforms, err := s.providerRepo.ListPlatforms(ctx, version.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider platforms: %w", err)
+	}
+
+	storagePath := sbom.ProviderPath(provider.Namespace, provider.Type, version.Version, format)
+	data, ok, err := s.load(ctx, storagePath)
+	if err != nil {
+		return nil, err
+	}
+	if ok && bytes.Contains(data, []byte(sbom.PlatformsDigest(platforms))) {
+		return data, nil
+	}
+
+	doc := sbom.ForProvider(s.registryHost, provider, version, platforms)
+	for _, other := range sbom.Formats {
+		if other == format {
+			continue
+		}
+		if _, err := s.store(ctx, doc, other, sbom.ProviderPath(provider.Namespace, provider.Type, version.Version, other)); err != nil {
+			return nil, err
+		}
+	}
+	return s.store(ctx, doc, format, storagePath)
+}
+
+// load reads a stored SBOM, reporting whether it exists
+func (s *SBOMService) load(ctx context.Context, storagePath string) ([]byte, bool, error) {
+	exists, err := s.storageBackend.Exists(ctx, storagePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check for stored SBOM: %w", err)
+	}
+	if !exists {
+		return nil, false, nil
+	}
+
+	reader, err := s.storageBackend.Download(ctx, storagePath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to download SBOM: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read SBOM: %w", err)
+	}
+	return data, true, nil
+}
+
+// store encodes a document and writes it to storage
+func (s *SBOMService) store(ctx context.Context, doc *sbom.Document, format sbom.Format, storagePath string) ([]byte, error) {
+	data, err := doc.Encode(format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s SBOM: %w", format, err)
+	}
+	if _, err := s.storageBackend.Upload(ctx, storagePath, bytes.NewReader(data), int64(len(data))); err != nil {
+		return nil, fmt.Errorf("failed to store %s SBOM: %w", format, err)
+	}
+	return data, nil
+}
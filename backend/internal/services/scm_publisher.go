@@ -20,25 +20,40 @@ import (
 	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
 	"github.com/terraform-registry/terraform-registry/internal/scm"
 	"github.com/terraform-registry/terraform-registry/internal/storage"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
 )
 
 // SCMPublisher handles automated publishing from SCM repositories
 type SCMPublisher struct {
-	scmRepo        *repositories.SCMRepository
-	moduleRepo     *repositories.ModuleRepository
-	storageBackend storage.Storage
-	tokenCipher    *crypto.TokenCipher
-	tempDir        string
+	scmRepo         *repositories.SCMRepository
+	moduleRepo      *repositories.ModuleRepository
+	storageBackend  storage.Storage
+	storageKey      string // storage_backend recorded for versions written to storageBackend
+	tokenCipher     *crypto.TokenCipher
+	secretScanner   *SecretScanner
+	policyEvaluator *PublishPolicyEvaluator
+	admission       *AdmissionController
+	licenses        *LicensePolicyEvaluator
+	sboms           *SBOMService
+	signer          *ModuleSigner
+	tempDir         string
 }
 
 // NewSCMPublisher creates a new SCM publisher
-func NewSCMPublisher(scmRepo *repositories.SCMRepository, moduleRepo *repositories.ModuleRepository, storageBackend storage.Storage, tokenCipher *crypto.TokenCipher) *SCMPublisher {
+func NewSCMPublisher(scmRepo *repositories.SCMRepository, moduleRepo *repositories.ModuleRepository, storageBackend storage.Storage, storageKey string, tokenCipher *crypto.TokenCipher, secretScanner *SecretScanner, policyEvaluator *PublishPolicyEvaluator, admission *AdmissionController, licenses *LicensePolicyEvaluator, sboms *SBOMService, signer *ModuleSigner) *SCMPublisher {
 	return &SCMPublisher{
-		scmRepo:        scmRepo,
-		moduleRepo:     moduleRepo,
-		storageBackend: storageBackend,
-		tokenCipher:    tokenCipher,
-		tempDir:        os.TempDir(),
+		scmRepo:         scmRepo,
+		moduleRepo:      moduleRepo,
+		storageBackend:  storageBackend,
+		storageKey:      storageKey,
+		tokenCipher:     tokenCipher,
+		secretScanner:   secretScanner,
+		policyEvaluator: policyEvaluator,
+		admission:       admission,
+		licenses:        licenses,
+		sboms:           sboms,
+		signer:          signer,
+		tempDir:         os.TempDir(),
 	}
 }
 
@@ -84,14 +99,80 @@ func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, modu
 	}
 	defer file.Close()
 
-	// TODO: Add proper module lookup by ID
-	// For now use placeholder - GetByID method doesn't exist yet
-	// module, err := p.moduleRepo.GetByID(ctx, moduleSourceRepo.ModuleID)
-	module := &models.Module{
-		ID:        moduleSourceRepo.ModuleID.String(),
-		Namespace: "placeholder",
-		Name:      "placeholder",
-		System:    "placeholder",
+	// Look up the module being published
+	module, err := p.moduleRepo.GetModuleByID(ctx, moduleSourceRepo.ModuleID.String())
+	if err != nil || module == nil {
+		errMsg := fmt.Sprintf("failed to look up module %s: %v", moduleSourceRepo.ModuleID, err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+
+	// Scan packaged archive for secrets before publishing
+	secretFindings, err := p.secretScanner.Scan(file)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to scan archive: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+	if p.secretScanner.Blocks(secretFindings) {
+		errMsg := fmt.Sprintf("archive contains %d potential secret(s); first: %s in %s",
+			len(secretFindings), secretFindings[0].Description, secretFindings[0].File)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+
+	// Evaluate organization publish rules against the packaged archive
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		errMsg := fmt.Sprintf("failed to rewind archive: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+	contents, err := validation.LoadModuleContents(file)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to read archive: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+	policyReport, err := p.policyEvaluator.Evaluate(ctx, PublishRequest{
+		OrganizationID: module.OrganizationID,
+		Namespace:      module.Namespace,
+		Version:        version,
+		Tag:            hook.TagName,
+	}, contents)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to evaluate publish rules: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+	if policyReport.Blocked {
+		failures := policyReport.Failures()
+		errMsg := fmt.Sprintf("module does not satisfy %d publish rule(s); first: %s", len(failures), failures[0].RuleName)
+		if failures[0].Message != nil {
+			errMsg += ": " + *failures[0].Message
+		}
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+
+	// Detect the module license and check it against license policies
+	license := validation.DetectLicense(contents.LicenseText)
+	licenseDecision, err := p.licenses.Check(ctx, module.OrganizationID, models.ArtifactTypeModule, license)
+	if err != nil {
+		errMsg := fmt.Sprintf("failed to evaluate license policies: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+	if !licenseDecision.Allowed {
+		errMsg := "publish rejected: " + licenseDecision.Message
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+
+	// Upload to storage
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		errMsg := fmt.Sprintf("failed to rewind archive: %v", err)
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
 	}
 
 	storagePath := fmt.Sprintf("modules/%s/%s/%s/%s-%s.tar.gz",
@@ -105,12 +186,34 @@ func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, modu
 		return
 	}
 
-	if _, err := p.storageBackend.Upload(ctx, storagePath, file, fileInfo.Size()); err != nil {
+	uploadResult, err := p.storageBackend.Upload(ctx, storagePath, file, fileInfo.Size())
+	if err != nil {
 		errMsg := fmt.Sprintf("failed to upload to storage: %v", err)
 		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
 		return
 	}
 
+	// Consult admission webhooks before committing the version
+	admission, err := p.admission.Review(ctx, AdmissionRequest{
+		ArtifactType:   models.ArtifactTypeModule,
+		OrganizationID: module.OrganizationID,
+		Namespace:      module.Namespace,
+		Name:           module.Name,
+		System:         module.System,
+		Version:        version,
+		Checksum:       checksum,
+		SizeBytes:      uploadResult.Size,
+	}, uploadResult.Path)
+	if err != nil || !admission.Allowed {
+		p.storageBackend.Delete(ctx, uploadResult.Path)
+		errMsg := fmt.Sprintf("failed to run admission webhooks: %v", err)
+		if err == nil {
+			errMsg = "publish rejected: " + admission.DenialMessage()
+		}
+		p.scmRepo.UpdateWebhookLogState(ctx, logID, "failed", &errMsg, nil)
+		return
+	}
+
 	// Create module version record
 	// TODO: Store sourceTag and sourceCommit in extended metadata
 	// sourceTag := hook.TagName
@@ -121,11 +224,22 @@ func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, modu
 		ID:             versionID,
 		ModuleID:       moduleSourceRepo.ModuleID.String(),
 		Version:        version,
-		StoragePath:    storagePath,
-		StorageBackend: "default",
+		StoragePath:    uploadResult.Path,
+		StorageBackend: p.storageKey,
 		Checksum:       checksum,
 		CreatedAt:      time.Now(),
 	}
+	if license != "" {
+		moduleVersion.LicenseSPDX = &license
+	}
+
+	// Record the zip the archive is served as over OCI; it is computed on first request otherwise
+	if _, err := file.Seek(0, io.SeekStart); err == nil {
+		if digest, size, err := validation.ModuleZipDigest(file); err == nil {
+			moduleVersion.OCIZipDigest = &digest
+			moduleVersion.OCIZipSize = &size
+		}
+	}
 
 	if err := p.moduleRepo.CreateVersion(ctx, moduleVersion); err != nil {
 		errMsg := fmt.Sprintf("failed to create version: %v", err)
@@ -133,6 +247,20 @@ func (p *SCMPublisher) ProcessTagPush(ctx context.Context, logID uuid.UUID, modu
 		return
 	}
 
+	// Record secret findings and publish rule results for admin review
+	if err := p.secretScanner.Record(ctx, moduleVersion.ID, secretFindings); err != nil {
+		fmt.Printf("Warning: Failed to record secret findings: %v\n", err)
+	}
+	if err := p.policyEvaluator.Record(ctx, moduleVersion.ID, policyReport); err != nil {
+		fmt.Printf("Warning: Failed to record publish rule results: %v\n", err)
+	}
+	if err := p.sboms.GenerateModuleSBOM(ctx, module, moduleVersion, contents); err != nil {
+		fmt.Printf("Warning: Failed to generate SBOM: %v\n", err)
+	}
+	if _, err := p.signer.SignModuleVersion(ctx, module.OrganizationID, module, moduleVersion); err != nil {
+		fmt.Printf("Warning: Failed to sign module manifest: %v\n", err)
+	}
+
 	// Update webhook log to success
 	versionUUID, _ := uuid.Parse(versionID)
 	p.scmRepo.UpdateWebhookLogState(ctx, logID, "completed", nil, &versionUUID)
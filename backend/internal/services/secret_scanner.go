@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+	"github.com/terraform-registry/terraform-registry/internal/validation"
+)
+
+// SecretScanner scans module archives for secrets and records findings against module versions
+type SecretScanner struct {
+	cfg        config.SecretScanningConfig
+	moduleRepo *repositories.ModuleRepository
+}
+
+// NewSecretScanner creates a new secret scanner
+func NewSecretScanner(cfg config.SecretScanningConfig, moduleRepo *repositories.ModuleRepository) *SecretScanner {
+	return &SecretScanner{
+		cfg:        cfg,
+		moduleRepo: moduleRepo,
+	}
+}
+
+// Scan inspects a tar.gz module archive. It returns no findings when scanning is disabled.
+func (s *SecretScanner) Scan(archive io.Reader) ([]validation.SecretFinding, error) {
+	if !s.cfg.Enabled {
+		return nil, nil
+	}
+	return validation.ScanArchiveForSecrets(archive, validation.SecretScanOptions{
+		EntropyThreshold: s.cfg.EntropyThreshold,
+		MaxFileSize:      s.cfg.MaxFileSize,
+	})
+}
+
+// Blocks reports whether the findings should reject the publish
+func (s *SecretScanner) Blocks(findings []validation.SecretFinding) bool {
+	return len(findings) > 0 && s.cfg.Mode == "block"
+}
+
+// Record stores findings against a published module version for admin review
+func (s *SecretScanner) Record(ctx context.Context, versionID string, findings []validation.SecretFinding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	records := make([]*models.ModuleVersionSecretFinding, 0, len(findings))
+	for _, f := range findings {
+		record := &models.ModuleVersionSecretFinding{
+			ModuleVersionID: versionID,
+			Rule:            f.Rule,
+			FilePath:        f.File,
+			Description:     f.Description,
+		}
+		if f.Line > 0 {
+			line := f.Line
+			record.LineNumber = &line
+		}
+		if f.Match != "" {
+			match := f.Match
+			record.RedactedMatch = &match
+		}
+		records = append(records, record)
+	}
+
+	return s.moduleRepo.CreateSecretFindings(ctx, records)
+}
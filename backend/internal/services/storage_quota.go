@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+)
+
+// ErrStorageQuotaExceeded is returned when storing an artifact would take an organization over its quota
+var ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+
+// Sources of the quota applied to an organization
+const (
+	QuotaSourceOrganization = "organization"
+	QuotaSourceDefault      = "default"
+	QuotaSourceNone         = "none"
+)
+
+// StorageQuotaService enforces per-organization storage quotas. An organization's own quota
+// overrides the configured default; a quota of 0 is unlimited.
+type StorageQuotaService struct {
+	quotaRepo quotaStore
+	orgRepo   defaultOrganizationStore
+	cfg       config.StorageQuotaConfig
+
+	// reserved holds the bytes of uploads in flight by organization, which count against its
+	// quota until they are recorded. Checks hold mu so concurrent uploads can't all pass.
+	reserved map[string]int64
+	mu       sync.Mutex
+}
+
+// quotaStore reads organizations' quotas and the bytes they store
+type quotaStore interface {
+	GetQuota(ctx context.Context, organizationID string) (*models.StorageQuota, error)
+	GetUsedBytes(ctx context.Context, organizationID string) (int64, error)
+	GetUsageBreakdown(ctx context.Context, organizationID string) ([]*models.StorageUsage, error)
+}
+
+// defaultOrganizationStore looks up the organization owning artifacts recorded without one
+type defaultOrganizationStore interface {
+	GetDefaultOrganization(ctx context.Context) (*models.Organization, error)
+}
+
+// NewStorageQuotaService creates a new storage quota service
+func NewStorageQuotaService(quotaRepo *repositories.StorageQuotaRepository, orgRepo *repositories.OrganizationRepository, cfg config.StorageQuotaConfig) *StorageQuotaService {
+	return &StorageQuotaService{quotaRepo: quotaRepo, orgRepo: orgRepo, cfg: cfg, reserved: make(map[string]int64)}
+}
+
+// QuotaDecision is the outcome of checking an upload against an organization's quota
+type QuotaDecision struct {
+	Allowed        bool   `json:"allowed"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	LimitBytes     int64  `json:"limit_bytes"` // 0 is unlimited
+	UsedBytes      int64  `json:"used_bytes"`
+	RequestedBytes int64  `json:"requested_bytes"`
+	Message        string `json:"message,omitempty"`
+}
+
+// Err returns ErrStorageQuotaExceeded with the decision's message, or nil if the upload is allowed
+func (d *QuotaDecision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrStorageQuotaExceeded, d.Message)
+}
+
+// StorageUsageReport is an organization's storage usage against its quota
+type StorageUsageReport struct {
+	OrganizationID string                 `json:"organization_id"`
+	UsedBytes      int64                  `json:"used_bytes"`
+	LimitBytes     int64                  `json:"limit_bytes"` // 0 is unlimited
+	QuotaSource    string                 `json:"quota_source"`
+	Enforced       bool                   `json:"enforced"`
+	Breakdown      []*models.StorageUsage `json:"breakdown"`
+}
+
+// Enabled reports whether quotas are enforced
+func (s *StorageQuotaService) Enabled() bool {
+	return s.cfg.Enabled
+}
+
+// Check decides whether an organization may store requestedBytes more. An empty organization ID
+// means the default organization, which owns artifacts of global mirrors and single-tenant deployments.
+func (s *StorageQuotaService) Check(ctx context.Context, organizationID string, requestedBytes int64) (*QuotaDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.check(ctx, organizationID, requestedBytes)
+}
+
+// Reserve is Check for uploads that run concurrently: when allowed, requestedBytes count against
+// the organization's quota until release is called, so uploads checked together can't exceed it
+// together. Call release once the artifact is recorded, and so counted in the organization's
+// usage, or abandoned.
+func (s *StorageQuotaService) Reserve(ctx context.Context, organizationID string, requestedBytes int64) (*QuotaDecision, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	decision, err := s.check(ctx, organizationID, requestedBytes)
+	if err != nil || !decision.Allowed || decision.LimitBytes == 0 {
+		return decision, func() {}, err
+	}
+
+	orgID := decision.OrganizationID
+	s.reserved[orgID] += requestedBytes
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.reserved[orgID] -= requestedBytes; s.reserved[orgID] <= 0 {
+				delete(s.reserved, orgID)
+			}
+		})
+	}
+	return decision, release, nil
+}
+
+// check decides whether an organization may store requestedBytes more, counting the bytes
+// reserved by uploads in flight. The caller holds mu.
+func (s *StorageQuotaService) check(ctx context.Context, organizationID string, requestedBytes int64) (*QuotaDecision, error) {
+	decision := &QuotaDecision{Allowed: true, OrganizationID: organizationID, RequestedBytes: requestedBytes}
+	if !s.cfg.Enabled {
+		return decision, nil
+	}
+
+	orgID, err := s.resolveOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if orgID == "" {
+		return decision, nil
+	}
+	decision.OrganizationID = orgID
+
+	limit, _, err := s.limit(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		return decision, nil
+	}
+	decision.LimitBytes = limit
+
+	used, err := s.quotaRepo.GetUsedBytes(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	used += s.reserved[orgID]
+	decision.UsedBytes = used
+
+	if used+requestedBytes > limit {
+		decision.Allowed = false
+		decision.Message = fmt.Sprintf("storing %d bytes would exceed the organization's storage quota (%d of %d bytes used)",
+			requestedBytes, used, limit)
+	}
+
+	return decision, nil
+}
+
+// Usage reports an organization's storage usage, broken down by namespace, artifact type and backend
+func (s *StorageQuotaService) Usage(ctx context.Context, organizationID string) (*StorageUsageReport, error) {
+	limit, source, err := s.limit(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown, err := s.quotaRepo.GetUsageBreakdown(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if breakdown == nil {
+		breakdown = []*models.StorageUsage{}
+	}
+
+	report := &StorageUsageReport{
+		OrganizationID: organizationID,
+		LimitBytes:     limit,
+		QuotaSource:    source,
+		Enforced:       s.cfg.Enabled && limit > 0,
+		Breakdown:      breakdown,
+	}
+	for _, usage := range breakdown {
+		report.UsedBytes += usage.Bytes
+	}
+
+	return report, nil
+}
+
+// limit returns the quota applied to an organization and where it comes from
+func (s *StorageQuotaService) limit(ctx context.Context, organizationID string) (int64, string, error) {
+	quota, err := s.quotaRepo.GetQuota(ctx, organizationID)
+	if err != nil {
+		return 0, "", err
+	}
+	if quota != nil {
+		return quota.MaxBytes, QuotaSourceOrganization, nil
+	}
+	if s.cfg.DefaultMaxBytes > 0 {
+		return s.cfg.DefaultMaxBytes, QuotaSourceDefault, nil
+	}
+	return 0, QuotaSourceNone, nil
+}
+
+// resolveOrganization maps an empty organization ID to the default organization
+func (s *StorageQuotaService) resolveOrganization(ctx context.Context, organizationID string) (string, error) {
+	if organizationID != "" {
+		return organizationID, nil
+	}
+
+	org, err := s.orgRepo.GetDefaultOrganization(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get default organization: %w", err)
+	}
+	if org == nil {
+		return "", nil
+	}
+	return org.ID, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+const testQuotaOrg = "5b8f0c1e-3a41-4c8e-9d0f-2f6a7c1b9e42"
+
+// memoryQuotaStore is an in-memory quota store for tests
+type memoryQuotaStore struct {
+	quotas map[string]int64
+	used   map[string]int64
+	mu     sync.Mutex
+}
+
+func (m *memoryQuotaStore) GetQuota(ctx context.Context, organizationID string) (*models.StorageQuota, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	maxBytes, ok := m.quotas[organizationID]
+	if !ok {
+		return nil, nil
+	}
+	return &models.StorageQuota{OrganizationID: organizationID, MaxBytes: maxBytes}, nil
+}
+
+func (m *memoryQuotaStore) GetUsedBytes(ctx context.Context, organizationID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used[organizationID], nil
+}
+
+func (m *memoryQuotaStore) GetUsageBreakdown(ctx context.Context, organizationID string) ([]*models.StorageUsage, error) {
+	return nil, nil
+}
+
+// record stores bytes for an organization, as recording an artifact's row does
+func (m *memoryQuotaStore) record(organizationID string, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used[organizationID] += bytes
+}
+
+func newTestQuotaService(limit, used int64) (*StorageQuotaService, *memoryQuotaStore) {
+	store := &memoryQuotaStore{
+		quotas: map[string]int64{testQuotaOrg: limit},
+		used:   map[string]int64{testQuotaOrg: used},
+	}
+	service := &StorageQuotaService{
+		quotaRepo: store,
+		cfg:       config.StorageQuotaConfig{Enabled: true},
+		reserved:  make(map[string]int64),
+	}
+	return service, store
+}
+
+func reserveQuota(t *testing.T, s *StorageQuotaService, bytes int64) (bool, func()) {
+	t.Helper()
+	decision, release, err := s.Reserve(context.Background(), testQuotaOrg, bytes)
+	if err != nil {
+		t.Fatalf("Reserve(%d): %v", bytes, err)
+	}
+	if !decision.Allowed && !errors.Is(decision.Err(), ErrStorageQuotaExceeded) {
+		t.Errorf("denied decision error = %v, want %v", decision.Err(), ErrStorageQuotaExceeded)
+	}
+	return decision.Allowed, release
+}
+
+func TestStorageQuotaReservationsCountTogether(t *testing.T) {
+	s, store := newTestQuotaService(100, 40)
+
+	first, releaseFirst := reserveQuota(t, s, 50)
+	if !first {
+		t.Fatal("first reservation denied, want 90 of 100 bytes allowed")
+	}
+	if second, _ := reserveQuota(t, s, 20); second {
+		t.Fatal("second reservation allowed, want 40 used + 50 reserved + 20 denied by a 100 byte quota")
+	}
+
+	// Check sees reservations without making one
+	decision, err := s.Check(context.Background(), testQuotaOrg, 10)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !decision.Allowed || decision.UsedBytes != 90 {
+		t.Errorf("Check = allowed %v with %d bytes used, want allowed with 90", decision.Allowed, decision.UsedBytes)
+	}
+	if third, _ := reserveQuota(t, s, 10); !third {
+		t.Error("reservation filling the quota exactly denied")
+	}
+
+	// Recording the artifact moves its bytes from reserved to used; releasing twice is harmless
+	store.record(testQuotaOrg, 50)
+	releaseFirst()
+	releaseFirst()
+	if s.reserved[testQuotaOrg] != 10 {
+		t.Errorf("reserved = %d bytes after release, want the 10 still held", s.reserved[testQuotaOrg])
+	}
+	if again, _ := reserveQuota(t, s, 1); again {
+		t.Error("reservation allowed past a quota filled by recorded and reserved bytes")
+	}
+}
+
+func TestStorageQuotaAbandonedReservation(t *testing.T) {
+	s, _ := newTestQuotaService(100, 0)
+
+	allowed, release := reserveQuota(t, s, 80)
+	if !allowed {
+		t.Fatal("reservation denied")
+	}
+	if allowed, _ := reserveQuota(t, s, 80); allowed {
+		t.Fatal("second reservation allowed while the first is held")
+	}
+	release()
+	if allowed, _ := reserveQuota(t, s, 80); !allowed {
+		t.Error("reservation denied after an abandoned upload released its bytes")
+	}
+}
+
+func TestStorageQuotaConcurrentReservations(t *testing.T) {
+	s, _ := newTestQuotaService(100, 0)
+
+	var allowed int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decision, _, err := s.Reserve(context.Background(), testQuotaOrg, 30)
+			if err != nil {
+				t.Errorf("Reserve: %v", err)
+				return
+			}
+			if decision.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 3 {
+		t.Errorf("%d concurrent 30 byte reservations allowed under a 100 byte quota, want 3", allowed)
+	}
+}
+
+func TestStorageQuotaReserveWithoutLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		service *StorageQuotaService
+	}{
+		{"quotas disabled", &StorageQuotaService{cfg: config.StorageQuotaConfig{Enabled: false}, reserved: make(map[string]int64)}},
+		{"unlimited organization", func() *StorageQuotaService { s, _ := newTestQuotaService(0, 0); return s }()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				allowed, release := reserveQuota(t, tt.service, 1<<40)
+				if !allowed {
+					t.Fatal("reservation denied without a limit")
+				}
+				release()
+			}
+			if len(tt.service.reserved) != 0 {
+				t.Errorf("reserved = %v, want nothing held without a limit", tt.service.reserved)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+	"github.com/terraform-registry/terraform-registry/internal/db/repositories"
+
+	"github.com/google/uuid"
+)
+
+const uploadSessionCleanupBatchSize = 100
+
+var (
+	// ErrUploadSessionNotFound is returned for unknown sessions and sessions owned by someone else
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+	// ErrUploadSessionExpired is returned for sessions past their expiry that have not been removed yet
+	ErrUploadSessionExpired = errors.New("upload session has expired")
+	// ErrUploadSessionBusy is returned when another request is already appending to the session
+	ErrUploadSessionBusy = errors.New("upload session is being written by another request")
+	// ErrUploadOffsetMismatch is returned when a chunk does not start at the session's offset
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match the session offset")
+	// ErrUploadExceedsLength is returned when a chunk runs past the declared upload length
+	ErrUploadExceedsLength = errors.New("chunk exceeds the declared upload length")
+	// ErrUploadTooLarge is returned when a declared upload length exceeds the configured maximum
+	ErrUploadTooLarge = errors.New("upload length exceeds the maximum upload size")
+	// ErrUploadIncomplete is returned when publishing a session that has not received every byte
+	ErrUploadIncomplete = errors.New("upload session is not complete")
+	// ErrUploadArtifactMismatch is returned when publishing a session created for another artifact type
+	ErrUploadArtifactMismatch = errors.New("upload session was created for a different artifact type")
+)
+
+// UploadSessionService manages resumable uploads. Each session's bytes are written to a temp
+// file named after its ID, and its offset is only advanced once the bytes are on disk, so a
+// dropped connection loses at most the chunk in flight. Sessions live on the instance holding
+// their temp directory; deployments with several instances share it or route uploads stickily.
+type UploadSessionService struct {
+	repo    *repositories.UploadSessionRepository
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+
+	writing      map[uuid.UUID]bool
+	writingMutex sync.Mutex
+}
+
+// NewUploadSessionService creates a new upload session service, creating its temp directory
+func NewUploadSessionService(repo *repositories.UploadSessionRepository, cfg config.UploadSessionConfig) (*UploadSessionService, error) {
+	dir := cfg.TempDir
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "terraform-registry-uploads")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	ttl := cfg.SessionTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	return &UploadSessionService{
+		repo:    repo,
+		dir:     dir,
+		ttl:     ttl,
+		maxSize: cfg.MaxSize,
+		writing: make(map[uuid.UUID]bool),
+	}, nil
+}
+
+// MaxSize returns the largest upload length a session may declare, or 0 if unlimited
+func (s *UploadSessionService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Create starts a session for an upload of length bytes owned by owner
+func (s *UploadSessionService) Create(ctx context.Context, artifactType models.ArtifactType, filename string, length int64, metadata, owner string) (*models.UploadSession, error) {
+	if s.maxSize > 0 && length > s.maxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	// Only the base name of a client-supplied filename is kept
+	if filename != "" {
+		filename = filepath.Base(filename)
+	}
+	session := &models.UploadSession{
+		ArtifactType:   artifactType,
+		Filename:       filename,
+		UploadLength:   length,
+		UploadMetadata: metadata,
+		Owner:          owner,
+		ExpiresAt:      time.Now().Add(s.ttl),
+	}
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(s.path(session.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		s.repo.DeleteSession(ctx, session.ID)
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+
+	return session, nil
+}
+
+// Get retrieves a session owned by owner
+func (s *UploadSessionService) Get(ctx context.Context, id uuid.UUID, owner string) (*models.UploadSession, error) {
+	session, err := s.repo.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.Owner != owner {
+		return nil, ErrUploadSessionNotFound
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrUploadSessionExpired
+	}
+	return session, nil
+}
+
+// Append writes a chunk read from body at offset, which must be the session's current offset.
+// Whatever part of the chunk arrives is kept: when body fails part way, the session is advanced
+// past the bytes received and returned along with the read error, so the client can resume.
+func (s *UploadSessionService) Append(ctx context.Context, session *models.UploadSession, offset int64, body io.Reader) (*models.UploadSession, error) {
+	if offset != session.UploadOffset {
+		return nil, ErrUploadOffsetMismatch
+	}
+	if !s.beginWrite(session.ID) {
+		return nil, ErrUploadSessionBusy
+	}
+	defer s.endWrite(session.ID)
+
+	// The session may have advanced since the caller read it
+	session, err := s.repo.GetSession(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrUploadSessionNotFound
+	}
+	if offset != session.UploadOffset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	file, err := os.OpenFile(s.path(session.ID), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat upload file: %w", err)
+	}
+	if info.Size() < offset {
+		return nil, fmt.Errorf("upload file is missing data before offset %d", offset)
+	}
+	// Discard anything written past the recorded offset by an earlier interrupted request
+	if err := file.Truncate(offset); err != nil {
+		return nil, fmt.Errorf("failed to truncate upload file: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek upload file: %w", err)
+	}
+
+	remaining := session.UploadLength - offset
+	written, readErr := io.Copy(file, io.LimitReader(body, remaining))
+	if readErr == nil && written == remaining {
+		if n, _ := body.Read(make([]byte, 1)); n > 0 {
+			file.Truncate(offset)
+			return nil, ErrUploadExceedsLength
+		}
+	}
+	if written == 0 {
+		return session, readErr
+	}
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync upload file: %w", err)
+	}
+
+	updated := *session
+	updated.UploadOffset = offset + written
+	updated.ExpiresAt = time.Now().Add(s.ttl)
+	// Record the progress even when the client has gone away and cancelled ctx
+	advanced, err := s.repo.AdvanceOffset(context.WithoutCancel(ctx), session.ID, offset, updated.UploadOffset, updated.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !advanced {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	return &updated, readErr
+}
+
+// OpenCompleted opens the data of a completed session owned by owner for publishing as
+// artifactType. The caller closes the file and deletes the session once it is published.
+func (s *UploadSessionService) OpenCompleted(ctx context.Context, id uuid.UUID, owner string, artifactType models.ArtifactType) (*os.File, *models.UploadSession, error) {
+	session, err := s.Get(ctx, id, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.ArtifactType != artifactType {
+		return nil, nil, ErrUploadArtifactMismatch
+	}
+	if !session.Completed() {
+		return nil, nil, ErrUploadIncomplete
+	}
+
+	file, err := os.Open(s.path(session.ID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	return file, session, nil
+}
+
+// Delete removes a session and its temp file
+func (s *UploadSessionService) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteSession(ctx, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove upload file: %w", err)
+	}
+	return nil
+}
+
+// CleanupExpired removes expired sessions along with temp files that no longer belong to a
+// session, and returns how many sessions were removed
+func (s *UploadSessionService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		sessions, err := s.repo.ListExpiredSessions(ctx, time.Now(), uploadSessionCleanupBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		for _, session := range sessions {
+			if err := s.Delete(ctx, session.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(sessions) < uploadSessionCleanupBatchSize {
+			break
+		}
+	}
+
+	return removed, s.removeOrphanedFiles(ctx)
+}
+
+// removeOrphanedFiles deletes temp files older than the session TTL with no session row,
+// left behind when a session row was removed but its file could not be
+func (s *UploadSessionService) removeOrphanedFiles(ctx context.Context) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read upload directory: %w", err)
+	}
+
+	ids, err := s.repo.ListSessionIDs(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id.String()+".part"] = true
+	}
+
+	cutoff := time.Now().Add(-s.ttl)
+	for _, entry := range entries {
+		if entry.IsDir() || known[entry.Name()] || !strings.HasSuffix(entry.Name(), ".part") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove orphaned upload file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *UploadSessionService) path(id uuid.UUID) string {
+	return filepath.Join(s.dir, id.String()+".part")
+}
+
+func (s *UploadSessionService) beginWrite(id uuid.UUID) bool {
+	s.writingMutex.Lock()
+	defer s.writingMutex.Unlock()
+	if s.writing[id] {
+		return false
+	}
+	s.writing[id] = true
+	return true
+}
+
+func (s *UploadSessionService) endWrite(id uuid.UUID) {
+	s.writingMutex.Lock()
+	delete(s.writing, id)
+	s.writingMutex.Unlock()
+}
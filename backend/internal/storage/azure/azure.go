@@ -72,36 +72,59 @@ func New(cfg *config.AzureStorageConfig) (*AzureStorage, error) {
 	}, nil
 }
 
-// Upload stores a file in Azure Blob Storage
+// Upload stores a file in Azure Blob Storage. The file is streamed as staged blocks of
+// storage.MultipartPartSize, so large files are never held in memory.
 func (s *AzureStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*storage.UploadResult, error) {
-	// Read all content to calculate checksum and upload
-	// For large files, consider using block uploads with streaming hash
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read data: %w", err)
-	}
-
-	// Calculate SHA256 checksum
-	hasher := sha256.New()
-	hasher.Write(data)
-	checksum := hex.EncodeToString(hasher.Sum(nil))
+	knownChecksum := storage.KnownChecksum(reader)
 
 	// Get blob client for this path
 	blobClient := s.client.ServiceClient().NewContainerClient(s.containerName).NewBlockBlobClient(path)
 
-	// Upload the blob
-	_, err = blobClient.Upload(ctx, streaming.NopCloser(bytes.NewReader(data)), nil)
-	if err != nil {
+	options := &blockblob.UploadStreamOptions{
+		BlockSize:   storage.MultipartPartSize,
+		Concurrency: 2,
+	}
+	if knownChecksum != "" {
+		options.Metadata = map[string]*string{"sha256": &knownChecksum}
+	}
+
+	// Calculate the SHA256 checksum while uploading
+	hasher := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(reader, hasher)}
+
+	if _, err := blobClient.UploadStream(ctx, counter, options); err != nil {
 		return nil, fmt.Errorf("failed to upload to Azure Blob: %w", err)
 	}
 
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if knownChecksum != "" && knownChecksum != checksum {
+		blobClient.Delete(ctx, nil)
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", knownChecksum, checksum)
+	}
+	if knownChecksum == "" {
+		// Non-fatal: GetMetadata falls back to hashing the blob
+		blobClient.SetMetadata(ctx, map[string]*string{"sha256": &checksum}, nil)
+	}
+
 	return &storage.UploadResult{
 		Path:     path,
-		Size:     int64(len(data)),
+		Size:     counter.n,
 		Checksum: checksum,
 	}, nil
 }
 
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
 // Download retrieves a file from Azure Blob Storage
 func (s *AzureStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
 	// Get blob client for this path
@@ -116,6 +139,30 @@ func (s *AzureStorage) Download(ctx context.Context, path string) (io.ReadCloser
 	return resp.Body, nil
 }
 
+// DownloadRange retrieves part of a file from Azure Blob Storage
+func (s *AzureStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	// Get blob client for this path
+	blobClient := s.client.ServiceClient().NewContainerClient(s.containerName).NewBlobClient(path)
+
+	// A zero count reads to the end of the blob
+	count := length
+	if count < 0 {
+		count = 0
+	}
+	resp, err := blobClient.DownloadStream(ctx, &blob.DownloadStreamOptions{
+		Range: blob.HTTPRange{Offset: offset, Count: count},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download range from Azure Blob: %w", err)
+	}
+
+	return resp.Body, nil
+}
+
 // Delete removes a file from Azure Blob Storage
 func (s *AzureStorage) Delete(ctx context.Context, path string) error {
 	// Get blob client for this path
@@ -250,6 +297,39 @@ func (s *AzureStorage) GetMetadata(ctx context.Context, path string) (*storage.F
 	}, nil
 }
 
+// List calls fn for every blob whose name starts with prefix
+func (s *AzureStorage) List(ctx context.Context, prefix string, fn func(*storage.FileMetadata) error) error {
+	pager := s.client.NewListBlobsFlatPager(s.containerName, &azblob.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list blobs: %w", err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil {
+				continue
+			}
+			metadata := &storage.FileMetadata{Path: *item.Name}
+			if item.Properties != nil {
+				if item.Properties.ContentLength != nil {
+					metadata.Size = *item.Properties.ContentLength
+				}
+				if item.Properties.LastModified != nil {
+					metadata.LastModified = *item.Properties.LastModified
+				}
+			}
+			if err := fn(metadata); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // UploadWithMetadata stores a file and includes SHA256 checksum in blob metadata
 // This is a convenience method that stores the checksum for later retrieval
 func (s *AzureStorage) UploadWithMetadata(ctx context.Context, path string, reader io.Reader, size int64) (*storage.UploadResult, error) {
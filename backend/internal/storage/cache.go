@@ -0,0 +1,501 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errCacheBypass is returned when a file is too large to cache and must be read from the backend
+var errCacheBypass = errors.New("file too large to cache")
+
+// DiskCache is a size-bounded cache of stored files on local disk, evicting the least recently
+// used files first. It is shared by the CachingStorage decorators of every cached backend.
+// Each file is kept alongside a JSON sidecar holding its metadata, so the cache survives restarts.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // Cache key to element holding a *cacheEntry
+	lru     *list.List               // Most recently used at the front
+	size    int64
+	fills   map[string]*cacheFill
+}
+
+// cacheEntry is a cached file
+type cacheEntry struct {
+	Key       string       `json:"key"`
+	Namespace string       `json:"namespace"`
+	Metadata  FileMetadata `json:"metadata"`
+}
+
+// cacheFill is an in-progress fetch of a file into the cache, shared by concurrent misses
+type cacheFill struct {
+	done  chan struct{}
+	entry *cacheEntry
+	err   error
+}
+
+// cacheSource opens the contents of a missed file along with the metadata they must match
+type cacheSource func() (io.ReadCloser, *FileMetadata, error)
+
+// NewDiskCache opens a disk cache in dir holding at most maxBytes, indexing files left by a
+// previous run
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "terraform-registry-cache")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		fills:    make(map[string]*cacheFill),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Size returns the total size of cached files
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// lookup returns the metadata of a cached file, marking it recently used
+func (c *DiskCache) lookup(key string) (*FileMetadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	metadata := elem.Value.(*cacheEntry).Metadata
+	return &metadata, true
+}
+
+// open returns a cached file, filling the cache from source on a miss. Concurrent misses for
+// the same key wait for a single fill.
+func (c *DiskCache) open(key, namespace string, source cacheSource) (*os.File, *FileMetadata, error) {
+	// A file evicted between being fetched and opened is fetched again
+	for attempt := 0; attempt < 2; attempt++ {
+		entry, err := c.fetch(key, namespace, source)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := os.Open(c.dataPath(key))
+		if err == nil {
+			return file, &entry.Metadata, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("failed to open cached file: %w", err)
+		}
+	}
+	return nil, nil, errCacheBypass
+}
+
+func (c *DiskCache) fetch(key, namespace string, source cacheSource) (*cacheEntry, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*cacheEntry), nil
+	}
+	if fill, ok := c.fills[key]; ok {
+		c.mu.Unlock()
+		<-fill.done
+		return fill.entry, fill.err
+	}
+	fill := &cacheFill{done: make(chan struct{})}
+	c.fills[key] = fill
+	c.mu.Unlock()
+
+	fill.entry, fill.err = c.fill(key, namespace, source)
+
+	c.mu.Lock()
+	delete(c.fills, key)
+	if fill.err == nil {
+		c.add(fill.entry)
+	}
+	c.mu.Unlock()
+	close(fill.done)
+
+	return fill.entry, fill.err
+}
+
+// fill copies a file from its source into the cache, verifying its size and checksum
+func (c *DiskCache) fill(key, namespace string, source cacheSource) (*cacheEntry, error) {
+	reader, expected, err := source()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp(c.dir, "fill-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(reader, c.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if written > c.maxBytes {
+		return nil, errCacheBypass
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if expected.Size >= 0 && written != expected.Size {
+		return nil, fmt.Errorf("size mismatch filling cache for %s: expected %d bytes, read %d", expected.Path, expected.Size, written)
+	}
+	if expected.Checksum != "" && !strings.EqualFold(expected.Checksum, checksum) {
+		return nil, fmt.Errorf("checksum mismatch filling cache for %s: expected %s, got %s", expected.Path, expected.Checksum, checksum)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	entry := &cacheEntry{
+		Key:       key,
+		Namespace: namespace,
+		Metadata: FileMetadata{
+			Path:         expected.Path,
+			Size:         written,
+			Checksum:     checksum,
+			LastModified: expected.LastModified,
+		},
+	}
+	sidecar, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache metadata: %w", err)
+	}
+
+	dataPath := c.dataPath(key)
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(dataPath+".json", sidecar, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write cache metadata: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dataPath); err != nil {
+		os.Remove(dataPath + ".json")
+		return nil, fmt.Errorf("failed to store cache file: %w", err)
+	}
+
+	return entry, nil
+}
+
+// remove drops a file from the cache
+func (c *DiskCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.evict(elem)
+	}
+}
+
+// removeNamespace drops every file cached for a backend
+func (c *DiskCache) removeNamespace(namespace string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*cacheEntry).Namespace == namespace {
+			c.evict(elem)
+		}
+		elem = next
+	}
+}
+
+// add indexes a filled file and evicts the least recently used files beyond the size limit.
+// The caller must hold c.mu.
+func (c *DiskCache) add(entry *cacheEntry) {
+	if elem, ok := c.entries[entry.Key]; ok {
+		c.size -= elem.Value.(*cacheEntry).Metadata.Size
+		c.lru.Remove(elem)
+	}
+	c.entries[entry.Key] = c.lru.PushFront(entry)
+	c.size += entry.Metadata.Size
+
+	for c.size > c.maxBytes {
+		oldest := c.lru.Back()
+		if oldest == nil || oldest.Value.(*cacheEntry) == entry {
+			break
+		}
+		c.evict(oldest)
+	}
+}
+
+// evict removes a file from the index and the disk. The caller must hold c.mu.
+func (c *DiskCache) evict(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.Key)
+	c.size -= entry.Metadata.Size
+
+	dataPath := c.dataPath(entry.Key)
+	os.Remove(dataPath)
+	os.Remove(dataPath + ".json")
+}
+
+// load indexes files cached by a previous run, least recently written last, and removes
+// fills that never completed
+func (c *DiskCache) load() error {
+	type loaded struct {
+		entry   *cacheEntry
+		modTime time.Time
+	}
+	var found []loaded
+
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasSuffix(path, ".tmp") {
+			os.Remove(path)
+			return nil
+		}
+		if !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var entry cacheEntry
+		if json.Unmarshal(data, &entry) != nil || entry.Key == "" {
+			os.Remove(path)
+			return nil
+		}
+		info, err := os.Stat(c.dataPath(entry.Key))
+		if err != nil || info.Size() != entry.Metadata.Size {
+			os.Remove(path)
+			os.Remove(c.dataPath(entry.Key))
+			return nil
+		}
+		found = append(found, loaded{entry: &entry, modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index cache directory: %w", err)
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime.Before(found[j].modTime) })
+	c.mu.Lock()
+	for _, f := range found {
+		c.add(f.entry)
+	}
+	c.mu.Unlock()
+
+	if len(found) > 0 {
+		log.Printf("Storage cache: indexed %d cached files (%d bytes)", len(c.entries), c.size)
+	}
+	return nil
+}
+
+func (c *DiskCache) dataPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// CachingStorage implements the Storage interface by reading files through a disk cache in
+// front of a cloud backend. Files are fetched into the cache on first download, verified
+// against the checksum the backend recorded, and served from disk afterwards. With proxying
+// enabled, download URLs point at the registry's file endpoint instead of the backend, so
+// clients without access to the backend download through the registry. Under encryption the
+// cache sits beneath EncryptedStorage, so it only ever holds files as the backend stores them.
+type CachingStorage struct {
+	backend   Storage
+	cache     *DiskCache
+	namespace string
+	baseURL   string
+	proxy     bool
+	encrypted bool // Files may be stored encrypted, so blob paths don't name the digest of what is cached
+}
+
+// NewCachingStorage wraps backend with cache. namespace distinguishes the backend's files
+// from other backends sharing the cache; baseURL is the registry's URL for proxied downloads.
+func NewCachingStorage(backend Storage, cache *DiskCache, namespace, baseURL string, proxy bool) *CachingStorage {
+	return &CachingStorage{
+		backend:   backend,
+		cache:     cache,
+		namespace: namespace,
+		baseURL:   baseURL,
+		proxy:     proxy,
+	}
+}
+
+// SetEncrypted tells the cache the backend's files may be encrypted above it. Fills are then
+// verified against the checksum the backend recorded for the stored file, since the digest a
+// content-addressed blob is named after is that of its plaintext.
+func (s *CachingStorage) SetEncrypted(encrypted bool) {
+	s.encrypted = encrypted
+}
+
+// Upload stores a file in the backend, dropping any cached copy of the path
+func (s *CachingStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	s.cache.remove(s.key(path))
+	result, err := s.backend.Upload(ctx, path, reader, size)
+	s.cache.remove(s.key(path))
+	return result, err
+}
+
+// Download reads a file from the cache, fetching it from the backend on a miss. Files larger
+// than the cache are streamed from the backend directly.
+func (s *CachingStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	// The fill is shared with concurrent downloads, so it outlives this request's cancellation
+	fillCtx := context.WithoutCancel(ctx)
+	file, _, err := s.cache.open(s.key(path), s.namespace, func() (io.ReadCloser, *FileMetadata, error) {
+		return s.source(fillCtx, path)
+	})
+	if errors.Is(err, errCacheBypass) {
+		return s.backend.Download(ctx, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// DownloadRange reads part of a file from the cache, filling it from the backend on a miss
+func (s *CachingStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := s.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, ok := reader.(*os.File)
+	if !ok {
+		// Not cached; read the range from the backend instead of discarding up to offset
+		reader.Close()
+		return s.backend.DownloadRange(ctx, path, offset, length)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek cached file: %w", err)
+	}
+
+	return LimitReadCloser(file, length), nil
+}
+
+// Delete removes a file from the backend and the cache
+func (s *CachingStorage) Delete(ctx context.Context, path string) error {
+	s.cache.remove(s.key(path))
+	return s.backend.Delete(ctx, path)
+}
+
+// GetURL returns the registry's file endpoint when proxying downloads, otherwise the backend's URL
+func (s *CachingStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	if !s.proxy {
+		return s.backend.GetURL(ctx, path, ttl)
+	}
+	return fmt.Sprintf("%s/v1/files/%s", s.baseURL, path), nil
+}
+
+// Exists reports cached files as present without consulting the backend
+func (s *CachingStorage) Exists(ctx context.Context, path string) (bool, error) {
+	if _, ok := s.cache.lookup(s.key(path)); ok {
+		return true, nil
+	}
+	return s.backend.Exists(ctx, path)
+}
+
+// GetMetadata returns the metadata of a cached file, or asks the backend on a miss
+func (s *CachingStorage) GetMetadata(ctx context.Context, path string) (*FileMetadata, error) {
+	if metadata, ok := s.cache.lookup(s.key(path)); ok {
+		return metadata, nil
+	}
+	return s.backend.GetMetadata(ctx, path)
+}
+
+// List lists the files of the backend
+func (s *CachingStorage) List(ctx context.Context, prefix string, fn func(*FileMetadata) error) error {
+	lister, ok := s.backend.(Lister)
+	if !ok {
+		return fmt.Errorf("storage backend cannot list files")
+	}
+	return lister.List(ctx, prefix, fn)
+}
+
+// Backend returns the underlying backend
+func (s *CachingStorage) Backend() Storage {
+	return s.backend
+}
+
+// Purge drops every cached file of the backend, e.g. once its configuration has changed
+func (s *CachingStorage) Purge() {
+	s.cache.removeNamespace(s.namespace)
+}
+
+// source opens a file in the backend along with the size and checksum it must match.
+// Content-addressed blobs must hash to the digest they are named after, unless they may be encrypted.
+func (s *CachingStorage) source(ctx context.Context, path string) (io.ReadCloser, *FileMetadata, error) {
+	expected, err := s.backend.GetMetadata(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if digest, ok := BlobDigest(path); ok && !s.encrypted {
+		expected.Checksum = digest
+	}
+	if expected.Size > s.cache.maxBytes {
+		return nil, nil, errCacheBypass
+	}
+
+	reader, err := s.backend.Download(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, expected, nil
+}
+
+func (s *CachingStorage) key(path string) string {
+	sum := sha256.Sum256([]byte(s.namespace + "\x00" + path))
+	return hex.EncodeToString(sum[:])
+}
+
+// Uncached returns the backend beneath any caching and content addressing, for checks that
+// must see what the backend itself holds. Reads through content-addressed storage pass
+// straight to its backend, so unwrapping it does not change what is read. Encryption is kept
+// above the cache it wraps, so files are still checked against the size and checksum of their
+// plaintext.
+func Uncached(backend Storage) Storage {
+	switch s := backend.(type) {
+	case *CachingStorage:
+		return Uncached(s.backend)
+	case *ContentAddressedStorage:
+		return Uncached(s.backend)
+	case *EncryptedStorage:
+		if inner := Uncached(s.backend); inner != s.backend {
+			uncached := *s
+			uncached.backend = inner
+			return &uncached
+		}
+	}
+	return backend
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// BlobPathPrefix is the path under which content-addressed objects are stored
+const BlobPathPrefix = "blobs/sha256/"
+
+// BlobIndex counts the references to content-addressed objects
+type BlobIndex interface {
+	// AcquireBlob records a reference to a blob and returns its new reference count
+	AcquireBlob(ctx context.Context, backend, digest string, size int64) (int, error)
+
+	// ReleaseBlob drops a reference to a blob. Once nothing references it, deleteObject is
+	// called while the blob is locked against new references, and the blob is forgotten only
+	// after its object is gone.
+	ReleaseBlob(ctx context.Context, backend, digest string, deleteObject func() error) error
+}
+
+// BlobPath returns the storage path of the object with the given SHA256 digest
+func BlobPath(digest string) string {
+	return BlobPathPrefix + digest[:2] + "/" + digest
+}
+
+// BlobDigest returns the digest of a content-addressed object path, and whether the path is one
+func BlobDigest(path string) (string, bool) {
+	if !strings.HasPrefix(path, BlobPathPrefix) {
+		return "", false
+	}
+	digest := path[strings.LastIndex(path, "/")+1:]
+	if len(digest) != sha256.Size*2 || BlobPath(digest) != path {
+		return "", false
+	}
+	return digest, true
+}
+
+// ContentAddressedStorage implements the Storage interface by storing each distinct file once,
+// under the SHA256 digest of its contents. Uploads return the blob path, which callers record in
+// place of the path they asked for; identical uploads share one object. Deleting a blob path drops
+// a reference and removes the object only once nothing references it. Other paths, such as SBOMs
+// and manifests stored at fixed locations, are passed through to the underlying backend.
+type ContentAddressedStorage struct {
+	backend Storage
+	key     string
+	index   BlobIndex
+}
+
+// NewContentAddressedStorage wraps backend, whose blobs are recorded in index under key
+func NewContentAddressedStorage(backend Storage, key string, index BlobIndex) *ContentAddressedStorage {
+	return &ContentAddressedStorage{
+		backend: backend,
+		key:     key,
+		index:   index,
+	}
+}
+
+// Upload stores the file as a blob, unless an identical blob is already stored. Readers
+// annotated with WithChecksum and of known size are streamed straight to the backend; others are
+// spooled to a temp file and hashed first. The requested path is ignored; the returned result
+// holds the blob path.
+func (s *ContentAddressedStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	digest := KnownChecksum(reader)
+	if digest == "" || size < 0 {
+		spool, err := os.CreateTemp("", "cas-upload-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		hasher := sha256.New()
+		written, err := io.Copy(io.MultiWriter(spool, hasher), reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+		if size >= 0 && written != size {
+			return nil, fmt.Errorf("size mismatch: expected %d bytes, read %d", size, written)
+		}
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind upload: %w", err)
+		}
+		digest = hex.EncodeToString(hasher.Sum(nil))
+		reader = WithChecksum(spool, digest)
+		size = written
+	}
+	blobPath := BlobPath(digest)
+
+	refCount, err := s.index.AcquireBlob(ctx, s.key, digest, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// A referenced blob is normally already stored, but a concurrent first upload of the same
+	// contents may not have finished writing it yet
+	stored := false
+	if refCount > 1 {
+		stored, _ = s.backend.Exists(ctx, blobPath)
+	}
+	if !stored {
+		if _, err := s.backend.Upload(ctx, blobPath, reader, size); err != nil {
+			s.release(ctx, digest)
+			return nil, err
+		}
+	}
+
+	return &UploadResult{
+		Path:     blobPath,
+		Size:     size,
+		Checksum: digest,
+	}, nil
+}
+
+// Download retrieves a file from the underlying backend
+func (s *ContentAddressedStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.backend.Download(ctx, path)
+}
+
+// DownloadRange retrieves part of a file from the underlying backend
+func (s *ContentAddressedStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	return s.backend.DownloadRange(ctx, path, offset, length)
+}
+
+// Delete drops a reference to a blob, removing its object once it is no longer referenced.
+// Paths outside the blob layout are deleted directly.
+func (s *ContentAddressedStorage) Delete(ctx context.Context, path string) error {
+	digest, ok := BlobDigest(path)
+	if !ok {
+		return s.backend.Delete(ctx, path)
+	}
+
+	// An upload of the same contents waits for the object to be deleted, then writes it again
+	return s.index.ReleaseBlob(ctx, s.key, digest, func() error {
+		return s.backend.Delete(ctx, path)
+	})
+}
+
+// GetURL returns a download URL from the underlying backend
+func (s *ContentAddressedStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	return s.backend.GetURL(ctx, path, ttl)
+}
+
+// Exists checks the underlying backend for a file
+func (s *ContentAddressedStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.backend.Exists(ctx, path)
+}
+
+// GetMetadata retrieves file metadata from the underlying backend
+func (s *ContentAddressedStorage) GetMetadata(ctx context.Context, path string) (*FileMetadata, error) {
+	return s.backend.GetMetadata(ctx, path)
+}
+
+// List lists the files of the underlying backend
+func (s *ContentAddressedStorage) List(ctx context.Context, prefix string, fn func(*FileMetadata) error) error {
+	lister, ok := s.backend.(Lister)
+	if !ok {
+		return fmt.Errorf("storage backend cannot list files")
+	}
+	return lister.List(ctx, prefix, fn)
+}
+
+// Backend returns the underlying backend
+func (s *ContentAddressedStorage) Backend() Storage {
+	return s.backend
+}
+
+// Key returns the backend key blobs are recorded under
+func (s *ContentAddressedStorage) Key() string {
+	return s.key
+}
+
+// release drops the reference taken by a failed upload
+func (s *ContentAddressedStorage) release(ctx context.Context, digest string) {
+	if err := s.Delete(ctx, BlobPath(digest)); err != nil {
+		log.Printf("content-addressed storage: failed to release blob %s: %v", digest, err)
+	}
+}
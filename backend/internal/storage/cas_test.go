@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBlobIndex is a BlobIndex that, like the row lock of the database index, holds its
+// lock while a released blob's object is deleted
+type memoryBlobIndex struct {
+	refs map[string]int
+	mu   sync.Mutex
+}
+
+func newMemoryBlobIndex() *memoryBlobIndex {
+	return &memoryBlobIndex{refs: make(map[string]int)}
+}
+
+func (i *memoryBlobIndex) AcquireBlob(ctx context.Context, backend, digest string, size int64) (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.refs[digest]++
+	return i.refs[digest], nil
+}
+
+func (i *memoryBlobIndex) ReleaseBlob(ctx context.Context, backend, digest string, deleteObject func() error) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	count, ok := i.refs[digest]
+	if !ok || count == 0 {
+		return nil
+	}
+	i.refs[digest] = count - 1
+	if count > 1 {
+		return nil
+	}
+	if err := deleteObject(); err != nil {
+		return err
+	}
+	delete(i.refs, digest)
+	return nil
+}
+
+func digestOf(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestContentAddressedStorageReferenceCounting(t *testing.T) {
+	ctx := context.Background()
+	backend, index := newMemoryStorage(), newMemoryBlobIndex()
+	cas := NewContentAddressedStorage(backend, "local", index)
+
+	first, err := cas.Upload(ctx, "modules/a/1.0.0.tar.gz", strings.NewReader("archive"), -1)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	second, err := cas.Upload(ctx, "modules/b/1.0.0.tar.gz", WithChecksum(strings.NewReader("archive"), digestOf("archive")), 7)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if first.Path != BlobPath(digestOf("archive")) || second.Path != first.Path {
+		t.Fatalf("uploads stored at %s and %s, want %s", first.Path, second.Path, BlobPath(digestOf("archive")))
+	}
+	if len(backend.files) != 1 {
+		t.Fatalf("backend holds %d objects, want 1", len(backend.files))
+	}
+
+	if err := cas.Delete(ctx, first.Path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, _ := backend.Exists(ctx, first.Path); !exists {
+		t.Fatal("object deleted while still referenced")
+	}
+	if err := cas.Delete(ctx, second.Path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, _ := backend.Exists(ctx, first.Path); exists {
+		t.Fatal("object kept after its last reference was dropped")
+	}
+}
+
+func TestContentAddressedStorageFailedDeleteKeepsBlob(t *testing.T) {
+	ctx := context.Background()
+	backend, index := newMemoryStorage(), newMemoryBlobIndex()
+	cas := NewContentAddressedStorage(backend, "local", index)
+
+	result, err := cas.Upload(ctx, "x", strings.NewReader("archive"), -1)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	failing := errors.New("backend unavailable")
+	backend.failWith = failing
+	if err := cas.Delete(ctx, result.Path); !errors.Is(err, failing) {
+		t.Fatalf("Delete() = %v, want the backend error", err)
+	}
+	backend.failWith = nil
+
+	// The unreferenced blob is written again by the next upload of the same contents
+	delete(backend.files, result.Path)
+	if _, err := cas.Upload(ctx, "y", strings.NewReader("archive"), -1); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if exists, _ := backend.Exists(ctx, result.Path); !exists {
+		t.Fatal("object not rewritten after a failed delete")
+	}
+}
+
+// slowDeleteStorage delays deletes so a concurrent upload can race them
+type slowDeleteStorage struct {
+	*memoryStorage
+	deleting chan struct{}
+}
+
+func (s *slowDeleteStorage) Delete(ctx context.Context, path string) error {
+	close(s.deleting)
+	time.Sleep(50 * time.Millisecond)
+	return s.memoryStorage.Delete(ctx, path)
+}
+
+func TestContentAddressedStorageUploadDuringDelete(t *testing.T) {
+	ctx := context.Background()
+	backend := &slowDeleteStorage{memoryStorage: newMemoryStorage(), deleting: make(chan struct{})}
+	cas := NewContentAddressedStorage(backend, "local", newMemoryBlobIndex())
+
+	result, err := cas.Upload(ctx, "x", strings.NewReader("archive"), -1)
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := cas.Delete(ctx, result.Path); err != nil {
+			t.Errorf("Delete: %v", err)
+		}
+	}()
+
+	<-backend.deleting
+	if _, err := cas.Upload(ctx, "y", strings.NewReader("archive"), -1); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	wg.Wait()
+
+	reader, err := backend.Download(ctx, result.Path)
+	if err != nil {
+		t.Fatalf("object lost to the concurrent delete: %v", err)
+	}
+	defer reader.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(reader)
+	if buf.String() != "archive" {
+		t.Errorf("object = %q", buf.String())
+	}
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// NewFromStorageConfig creates a storage backend from a storage configuration saved through
+// the storage config API, decrypting its credentials with the token cipher
+func NewFromStorageConfig(sc *models.StorageConfig, tokenCipher *crypto.TokenCipher, serverBaseURL string) (Storage, error) {
+	cfg, err := ConfigFromStorageConfig(sc, tokenCipher, serverBaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return NewStorage(cfg)
+}
+
+// ConfigFromStorageConfig converts a saved storage configuration into the application
+// configuration shape the backend factories expect
+func ConfigFromStorageConfig(sc *models.StorageConfig, tokenCipher *crypto.TokenCipher, serverBaseURL string) (*config.Config, error) {
+	cfg := &config.Config{}
+	cfg.Server.BaseURL = serverBaseURL
+	cfg.Storage.DefaultBackend = sc.BackendType
+
+	decrypt := func(field string, value string, valid bool) (string, error) {
+		if !valid || value == "" {
+			return "", nil
+		}
+		plaintext, err := tokenCipher.Open(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+		}
+		return plaintext, nil
+	}
+
+	var err error
+	switch sc.BackendType {
+	case "local":
+		cfg.Storage.Local.BasePath = sc.LocalBasePath.String
+		cfg.Storage.Local.ServeDirectly = !sc.LocalServeDirectly.Valid || sc.LocalServeDirectly.Bool
+
+	case "azure":
+		cfg.Storage.Azure.AccountName = sc.AzureAccountName.String
+		cfg.Storage.Azure.ContainerName = sc.AzureContainerName.String
+		cfg.Storage.Azure.CDNURL = sc.AzureCDNURL.String
+		if cfg.Storage.Azure.AccountKey, err = decrypt("azure_account_key", sc.AzureAccountKeyEncrypted.String, sc.AzureAccountKeyEncrypted.Valid); err != nil {
+			return nil, err
+		}
+
+	case "s3":
+		cfg.Storage.S3.Endpoint = sc.S3Endpoint.String
+		cfg.Storage.S3.Region = sc.S3Region.String
+		cfg.Storage.S3.Bucket = sc.S3Bucket.String
+		cfg.Storage.S3.AuthMethod = sc.S3AuthMethod.String
+		cfg.Storage.S3.RoleARN = sc.S3RoleARN.String
+		cfg.Storage.S3.RoleSessionName = sc.S3RoleSessionName.String
+		cfg.Storage.S3.ExternalID = sc.S3ExternalID.String
+		cfg.Storage.S3.WebIdentityTokenFile = sc.S3WebIdentityTokenFile.String
+		if cfg.Storage.S3.AccessKeyID, err = decrypt("s3_access_key_id", sc.S3AccessKeyIDEncrypted.String, sc.S3AccessKeyIDEncrypted.Valid); err != nil {
+			return nil, err
+		}
+		if cfg.Storage.S3.SecretAccessKey, err = decrypt("s3_secret_access_key", sc.S3SecretAccessKeyEncrypted.String, sc.S3SecretAccessKeyEncrypted.Valid); err != nil {
+			return nil, err
+		}
+
+	case "gcs":
+		cfg.Storage.GCS.Bucket = sc.GCSBucket.String
+		cfg.Storage.GCS.ProjectID = sc.GCSProjectID.String
+		cfg.Storage.GCS.AuthMethod = sc.GCSAuthMethod.String
+		cfg.Storage.GCS.CredentialsFile = sc.GCSCredentialsFile.String
+		cfg.Storage.GCS.Endpoint = sc.GCSEndpoint.String
+		if cfg.Storage.GCS.CredentialsJSON, err = decrypt("gcs_credentials_json", sc.GCSCredentialsJSONEncrypted.String, sc.GCSCredentialsJSONEncrypted.Valid); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", sc.BackendType)
+	}
+
+	return cfg, nil
+}
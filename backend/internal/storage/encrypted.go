@@ -0,0 +1,564 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+const (
+	// encryptionChunkSize is the amount of plaintext sealed in each chunk of an encrypted file
+	encryptionChunkSize = 64 << 10
+
+	// encryptionTagSize is the size of the authentication tag added to each chunk
+	encryptionTagSize = 16
+
+	// encryptionNoncePrefixSize is the size of the random part of chunk nonces; the rest is the chunk index
+	encryptionNoncePrefixSize = 4
+
+	// rewrapBatchSize is how many data keys are re-wrapped per query during a rotation
+	rewrapBatchSize = 500
+)
+
+// encryptionHeader starts every encrypted file, identifying the format
+var encryptionHeader = []byte("TFRENC1\x00")
+
+// errEncryptedFileTruncated is returned when an encrypted file ends before its last chunk
+var errEncryptedFileTruncated = errors.New("encrypted file is truncated")
+
+// KeyWrapper wraps the data keys of encrypted files with a master key. It is implemented by
+// crypto.KeyRing for master keys kept in local files, and can be implemented on top of a KMS.
+type KeyWrapper interface {
+	// KeyID identifies the master key new data keys are wrapped with
+	KeyID() string
+
+	// Wrap encrypts a data key with the current master key
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+
+	// Unwrap decrypts a data key wrapped by the master key identified by keyID
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// DataKeyStore records the wrapped data keys of encrypted files
+type DataKeyStore interface {
+	// GetDataKey returns the data key of a file, or nil if the file is not encrypted
+	GetDataKey(ctx context.Context, backend, path string) (*models.StorageDataKey, error)
+
+	// PutDataKey records the data key of a file
+	PutDataKey(ctx context.Context, key *models.StorageDataKey) error
+
+	// DeleteDataKey removes the data key of a file
+	DeleteDataKey(ctx context.Context, backend, path string) error
+}
+
+// DataKeyRotationStore lists and updates data keys while rotating master keys
+type DataKeyRotationStore interface {
+	// ListDataKeysNotWrappedBy returns up to limit data keys wrapped by another master key
+	ListDataKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]*models.StorageDataKey, error)
+
+	// RewrapDataKey saves a re-wrapped data key if it is still wrapped by oldMasterKeyID
+	RewrapDataKey(ctx context.Context, key *models.StorageDataKey, oldMasterKeyID string) (bool, error)
+}
+
+// NewKeyWrapper creates the key wrapper of the configured master key provider
+func NewKeyWrapper(cfg config.StorageEncryptionConfig) (KeyWrapper, error) {
+	switch cfg.KeyProvider {
+	case "", "local":
+		ring, err := crypto.LoadKeyRing(cfg.KeyFile, cfg.RetiredKeyFiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load storage encryption keys: %w", err)
+		}
+		return ring, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage encryption key provider: %s", cfg.KeyProvider)
+	}
+}
+
+// RewrapDataKeys re-wraps every data key wrapped by a retired master key with the current one,
+// returning how many were re-wrapped. Files are not rewritten, so rotation is cheap; the retired
+// key may be discarded once it completes.
+func RewrapDataKeys(ctx context.Context, store DataKeyRotationStore, wrapper KeyWrapper) (int, error) {
+	rewrapped := 0
+	for {
+		keys, err := store.ListDataKeysNotWrappedBy(ctx, wrapper.KeyID(), rewrapBatchSize)
+		if err != nil {
+			return rewrapped, err
+		}
+		if len(keys) == 0 {
+			return rewrapped, nil
+		}
+
+		for _, key := range keys {
+			dataKey, err := wrapper.Unwrap(ctx, key.MasterKeyID, key.WrappedKey)
+			if err != nil {
+				return rewrapped, fmt.Errorf("failed to unwrap data key of %s in %s: %w", key.StoragePath, key.StorageBackend, err)
+			}
+			wrapped, err := wrapper.Wrap(ctx, dataKey)
+			if err != nil {
+				return rewrapped, fmt.Errorf("failed to wrap data key of %s in %s: %w", key.StoragePath, key.StorageBackend, err)
+			}
+
+			oldMasterKeyID := key.MasterKeyID
+			key.MasterKeyID = wrapper.KeyID()
+			key.WrappedKey = wrapped
+			updated, err := store.RewrapDataKey(ctx, key, oldMasterKeyID)
+			if err != nil {
+				return rewrapped, err
+			}
+			if updated {
+				rewrapped++
+			}
+		}
+	}
+}
+
+// EncryptedStorage implements the Storage interface by encrypting files before they reach a
+// backend. Each file is encrypted with its own random data key using AES-256-GCM in fixed-size
+// chunks, so files are encrypted and decrypted while streaming and ranges can be read without
+// decrypting the whole file. Data keys are kept wrapped by a master key in a DataKeyStore along
+// with the size and checksum of the plaintext. Files without a data key, such as those written
+// before encryption was enabled, are read as they are.
+type EncryptedStorage struct {
+	backend Storage
+	key     string
+	keys    DataKeyStore
+	wrapper KeyWrapper
+	baseURL string
+}
+
+// NewEncryptedStorage wraps backend, whose data keys are recorded in keys under key. Encrypted
+// files are downloaded through the registry's file endpoint at baseURL.
+func NewEncryptedStorage(backend Storage, key string, keys DataKeyStore, wrapper KeyWrapper, baseURL string) *EncryptedStorage {
+	return &EncryptedStorage{
+		backend: backend,
+		key:     key,
+		keys:    keys,
+		wrapper: wrapper,
+		baseURL: baseURL,
+	}
+}
+
+// Upload encrypts a file with a new data key while streaming it to the backend
+func (s *EncryptedStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	dataKey := make([]byte, 32)
+	noncePrefix := make([]byte, encryptionNoncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if _, err := io.ReadFull(rand.Reader, noncePrefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := s.wrapper.Wrap(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	encrypter := newEncryptingReader(reader, aead, noncePrefix)
+	encryptedSize := int64(-1)
+	if size >= 0 {
+		encryptedSize = encryptedFileSize(size)
+	}
+	result, err := s.backend.Upload(ctx, path, encrypter, encryptedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing can read the file without its data key, so it is removed if the key can't be kept
+	cleanupCtx := context.WithoutCancel(ctx)
+	checksum := hex.EncodeToString(encrypter.hasher.Sum(nil))
+	if known := KnownChecksum(reader); known != "" && !strings.EqualFold(known, checksum) {
+		s.backend.Delete(cleanupCtx, result.Path)
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", known, checksum)
+	}
+
+	record := &models.StorageDataKey{
+		StorageBackend:    s.key,
+		StoragePath:       result.Path,
+		MasterKeyID:       s.wrapper.KeyID(),
+		WrappedKey:        wrapped,
+		NoncePrefix:       noncePrefix,
+		PlaintextSize:     encrypter.size,
+		PlaintextChecksum: checksum,
+	}
+	if err := s.keys.PutDataKey(cleanupCtx, record); err != nil {
+		s.backend.Delete(cleanupCtx, result.Path)
+		return nil, err
+	}
+
+	return &UploadResult{
+		Path:     result.Path,
+		Size:     encrypter.size,
+		Checksum: checksum,
+	}, nil
+}
+
+// Download decrypts a file while streaming it from the backend
+func (s *EncryptedStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	record, aead, err := s.dataKey(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return s.backend.Download(ctx, path)
+	}
+
+	reader, err := s.backend.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(encryptionHeader))
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header, encryptionHeader) {
+		reader.Close()
+		return nil, fmt.Errorf("file %s is not in the encrypted format", path)
+	}
+
+	last := encryptedChunkCount(record.PlaintextSize) - 1
+	return newDecryptingReader(reader, aead, record.NoncePrefix, 0, last, last, 0), nil
+}
+
+// DownloadRange decrypts part of a file, reading only the chunks that hold it
+func (s *EncryptedStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	record, aead, err := s.dataKey(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return s.backend.DownloadRange(ctx, path, offset, length)
+	}
+
+	size := record.PlaintextSize
+	if offset >= size {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	if length < 0 || offset+length > size {
+		length = size - offset
+	}
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	const sealedChunkSize = encryptionChunkSize + encryptionTagSize
+	first := uint64(offset / encryptionChunkSize)
+	stop := uint64((offset + length - 1) / encryptionChunkSize)
+	last := encryptedChunkCount(size) - 1
+
+	encryptedOffset := int64(len(encryptionHeader)) + int64(first)*sealedChunkSize
+	encryptedLength := int64(stop-first+1) * sealedChunkSize
+	if stop == last {
+		encryptedLength = -1
+	}
+	reader, err := s.backend.DownloadRange(ctx, path, encryptedOffset, encryptedLength)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := int(offset - int64(first)*encryptionChunkSize)
+	return LimitReadCloser(newDecryptingReader(reader, aead, record.NoncePrefix, first, stop, last, skip), length), nil
+}
+
+// Delete removes a file and its data key
+func (s *EncryptedStorage) Delete(ctx context.Context, path string) error {
+	if err := s.backend.Delete(ctx, path); err != nil {
+		return err
+	}
+	return s.keys.DeleteDataKey(ctx, s.key, path)
+}
+
+// GetURL returns the backend's URL for unencrypted files. Encrypted files must be decrypted by
+// the registry, so their URL is the registry's file endpoint.
+func (s *EncryptedStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	record, err := s.keys.GetDataKey(ctx, s.key, path)
+	if err != nil {
+		return "", err
+	}
+	if record == nil {
+		return s.backend.GetURL(ctx, path, ttl)
+	}
+	return fmt.Sprintf("%s/v1/files/%s", s.baseURL, path), nil
+}
+
+// Exists checks if a file exists in the backend
+func (s *EncryptedStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.backend.Exists(ctx, path)
+}
+
+// GetMetadata returns the size and checksum of a file's plaintext
+func (s *EncryptedStorage) GetMetadata(ctx context.Context, path string) (*FileMetadata, error) {
+	metadata, err := s.backend.GetMetadata(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.keys.GetDataKey(ctx, s.key, path)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil {
+		metadata.Size = record.PlaintextSize
+		metadata.Checksum = record.PlaintextChecksum
+	}
+
+	return metadata, nil
+}
+
+// List lists the files of the backend. Sizes are those of the stored, encrypted files.
+func (s *EncryptedStorage) List(ctx context.Context, prefix string, fn func(*FileMetadata) error) error {
+	lister, ok := s.backend.(Lister)
+	if !ok {
+		return fmt.Errorf("storage backend cannot list files")
+	}
+	return lister.List(ctx, prefix, fn)
+}
+
+// Backend returns the underlying backend
+func (s *EncryptedStorage) Backend() Storage {
+	return s.backend
+}
+
+// dataKey returns a file's data key record and the cipher it unwraps to, or nil for unencrypted files
+func (s *EncryptedStorage) dataKey(ctx context.Context, path string) (*models.StorageDataKey, cipher.AEAD, error) {
+	record, err := s.keys.GetDataKey(ctx, s.key, path)
+	if err != nil || record == nil {
+		return nil, nil, err
+	}
+
+	dataKey, err := s.wrapper.Unwrap(ctx, record.MasterKeyID, record.WrappedKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unwrap data key of %s: %w", path, err)
+	}
+	aead, err := newChunkCipher(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return record, aead, nil
+}
+
+func newChunkCipher(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptedChunkCount returns the number of chunks a file of size bytes is sealed in. Empty
+// files are a single empty chunk, so truncation to nothing is still detected.
+func encryptedChunkCount(size int64) uint64 {
+	chunks := (size + encryptionChunkSize - 1) / encryptionChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return uint64(chunks)
+}
+
+// encryptedFileSize returns the stored size of a file of size bytes
+func encryptedFileSize(size int64) int64 {
+	return int64(len(encryptionHeader)) + size + int64(encryptedChunkCount(size))*encryptionTagSize
+}
+
+// chunkNonce returns the nonce of a chunk: the file's random prefix followed by the chunk index
+func chunkNonce(prefix []byte, index uint64) []byte {
+	nonce := make([]byte, encryptionNoncePrefixSize+8)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint64(nonce[encryptionNoncePrefixSize:], index)
+	return nonce
+}
+
+// chunkAAD authenticates whether a chunk is the last one, so files can't be truncated at a chunk boundary
+func chunkAAD(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptingReader seals the plaintext read from src into chunks, hashing the plaintext as it goes
+type encryptingReader struct {
+	src         io.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+	hasher      hash.Hash
+	size        int64
+
+	index   uint64
+	current []byte // The next chunk to seal
+	spare   []byte
+	srcDone bool // current is the last of the plaintext
+	out     []byte
+	started bool
+	done    bool
+}
+
+func newEncryptingReader(src io.Reader, aead cipher.AEAD, noncePrefix []byte) *encryptingReader {
+	return &encryptingReader{
+		src:         src,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		hasher:      sha256.New(),
+		current:     make([]byte, 0, encryptionChunkSize),
+		spare:       make([]byte, 0, encryptionChunkSize),
+	}
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.seal(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// seal produces the next piece of output: the header, then one sealed chunk at a time. A chunk
+// is only sealed once the following read shows whether it is the last.
+func (r *encryptingReader) seal() error {
+	if !r.started {
+		r.started = true
+		var err error
+		if r.current, r.srcDone, err = r.readChunk(r.current); err != nil {
+			return err
+		}
+		r.out = append(make([]byte, 0, len(encryptionHeader)), encryptionHeader...)
+		return nil
+	}
+
+	final := r.srcDone
+	var next []byte
+	if !final {
+		var nextDone bool
+		var err error
+		if next, nextDone, err = r.readChunk(r.spare); err != nil {
+			return err
+		}
+		final = nextDone && len(next) == 0
+		r.srcDone = nextDone
+	}
+
+	r.out = r.aead.Seal(r.out[:0], chunkNonce(r.noncePrefix, r.index), r.current, chunkAAD(final))
+	r.index++
+	if final {
+		r.done = true
+		return nil
+	}
+	r.current, r.spare = next, r.current
+	return nil
+}
+
+// readChunk reads up to a chunk of plaintext into buf, reporting whether src is exhausted
+func (r *encryptingReader) readChunk(buf []byte) ([]byte, bool, error) {
+	n, err := io.ReadFull(r.src, buf[:encryptionChunkSize])
+	buf = buf[:n]
+	r.hasher.Write(buf)
+	r.size += int64(n)
+	switch err {
+	case nil:
+		return buf, false, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return buf, true, nil
+	default:
+		return nil, false, err
+	}
+}
+
+// decryptingReader opens chunks first through stop of an encrypted file, of which last is the
+// final chunk, discarding skip bytes of plaintext at the start
+type decryptingReader struct {
+	src         io.ReadCloser
+	aead        cipher.AEAD
+	noncePrefix []byte
+	index       uint64
+	stop        uint64
+	last        uint64
+	skip        int
+
+	buf  []byte
+	out  []byte
+	done bool
+}
+
+func newDecryptingReader(src io.ReadCloser, aead cipher.AEAD, noncePrefix []byte, first, stop, last uint64, skip int) *decryptingReader {
+	return &decryptingReader{
+		src:         src,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		index:       first,
+		stop:        stop,
+		last:        last,
+		skip:        skip,
+		buf:         make([]byte, encryptionChunkSize+encryptionTagSize),
+	}
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// open reads and decrypts the next chunk
+func (r *decryptingReader) open() error {
+	n, err := io.ReadFull(r.src, r.buf)
+	final := r.index == r.last
+	switch {
+	case err == io.ErrUnexpectedEOF && final:
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return errEncryptedFileTruncated
+	case err != nil:
+		return err
+	}
+
+	plaintext, err := r.aead.Open(r.buf[:0], chunkNonce(r.noncePrefix, r.index), r.buf[:n], chunkAAD(final))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt chunk %d: %w", r.index, crypto.ErrCiphertextCorrupted)
+	}
+	if r.skip > 0 {
+		plaintext = plaintext[min(r.skip, len(plaintext)):]
+		r.skip = 0
+	}
+
+	r.out = plaintext
+	r.index++
+	r.done = r.index > r.stop
+	return nil
+}
+
+func (r *decryptingReader) Close() error {
+	return r.src.Close()
+}
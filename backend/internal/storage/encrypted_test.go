@@ -0,0 +1,447 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// memoryDataKeyStore is an in-memory DataKeyStore and DataKeyRotationStore for tests
+type memoryDataKeyStore struct {
+	keys map[string]*models.StorageDataKey
+	mu   sync.Mutex
+}
+
+func newMemoryDataKeyStore() *memoryDataKeyStore {
+	return &memoryDataKeyStore{keys: make(map[string]*models.StorageDataKey)}
+}
+
+func (s *memoryDataKeyStore) GetDataKey(ctx context.Context, backend, path string) (*models.StorageDataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[backend+"/"+path]
+	if !ok {
+		return nil, nil
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *memoryDataKeyStore) PutDataKey(ctx context.Context, key *models.StorageDataKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *key
+	s.keys[key.StorageBackend+"/"+key.StoragePath] = &copied
+	return nil
+}
+
+func (s *memoryDataKeyStore) DeleteDataKey(ctx context.Context, backend, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, backend+"/"+path)
+	return nil
+}
+
+func (s *memoryDataKeyStore) ListDataKeysNotWrappedBy(ctx context.Context, masterKeyID string, limit int) ([]*models.StorageDataKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []*models.StorageDataKey
+	for _, key := range s.keys {
+		if key.MasterKeyID != masterKeyID && len(keys) < limit {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+	return keys, nil
+}
+
+func (s *memoryDataKeyStore) RewrapDataKey(ctx context.Context, key *models.StorageDataKey, oldMasterKeyID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.keys[key.StorageBackend+"/"+key.StoragePath]
+	if !ok || current.MasterKeyID != oldMasterKeyID {
+		return false, nil
+	}
+	copied := *key
+	s.keys[key.StorageBackend+"/"+key.StoragePath] = &copied
+	return true, nil
+}
+
+func testMasterKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func newTestEncrypted(t *testing.T) (*EncryptedStorage, *memoryStorage, *memoryDataKeyStore) {
+	t.Helper()
+	ring, err := crypto.NewKeyRing(testMasterKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	backend := newMemoryStorage()
+	keys := newMemoryDataKeyStore()
+	return NewEncryptedStorage(backend, "local", keys, ring, "https://registry.example.com"), backend, keys
+}
+
+// testPlaintext returns n bytes that differ from chunk to chunk, so misplaced chunks are noticed
+func testPlaintext(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i*7 + i/encryptionChunkSize)
+	}
+	return data
+}
+
+func readAllAndClose(t *testing.T, reader io.ReadCloser) []byte {
+	t.Helper()
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return data
+}
+
+func TestEncryptedRoundTrip(t *testing.T) {
+	sizes := []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3*encryptionChunkSize + 123}
+	for _, size := range sizes {
+		for _, knownSize := range []bool{true, false} {
+			s, backend, keys := newTestEncrypted(t)
+			ctx := context.Background()
+			plaintext := testPlaintext(size)
+			digest := sha256.Sum256(plaintext)
+			checksum := hex.EncodeToString(digest[:])
+
+			uploadSize := int64(-1)
+			if knownSize {
+				uploadSize = int64(size)
+			}
+			result, err := s.Upload(ctx, "modules/a.tar.gz", bytes.NewReader(plaintext), uploadSize)
+			if err != nil {
+				t.Fatalf("size %d: Upload: %v", size, err)
+			}
+			if result.Size != int64(size) || result.Checksum != checksum {
+				t.Errorf("size %d: Upload result = %d %s, want plaintext %d %s", size, result.Size, result.Checksum, size, checksum)
+			}
+
+			stored := backend.files["modules/a.tar.gz"]
+			if int64(len(stored)) != encryptedFileSize(int64(size)) {
+				t.Errorf("size %d: stored %d bytes, want %d", size, len(stored), encryptedFileSize(int64(size)))
+			}
+			if !bytes.HasPrefix(stored, encryptionHeader) {
+				t.Errorf("size %d: stored file lacks the encryption header", size)
+			}
+			if size > 16 && bytes.Contains(stored, plaintext) {
+				t.Errorf("size %d: stored file contains the plaintext", size)
+			}
+
+			record, _ := keys.GetDataKey(ctx, "local", "modules/a.tar.gz")
+			if record == nil || record.PlaintextSize != int64(size) || record.PlaintextChecksum != checksum {
+				t.Fatalf("size %d: data key record = %+v", size, record)
+			}
+
+			reader, err := s.Download(ctx, "modules/a.tar.gz")
+			if err != nil {
+				t.Fatalf("size %d: Download: %v", size, err)
+			}
+			if got := readAllAndClose(t, reader); !bytes.Equal(got, plaintext) {
+				t.Errorf("size %d: Download returned %d bytes that differ from the plaintext", size, len(got))
+			}
+
+			metadata, err := s.GetMetadata(ctx, "modules/a.tar.gz")
+			if err != nil {
+				t.Fatalf("size %d: GetMetadata: %v", size, err)
+			}
+			if metadata.Size != int64(size) || metadata.Checksum != checksum {
+				t.Errorf("size %d: GetMetadata = %d %s, want plaintext %d %s", size, metadata.Size, metadata.Checksum, size, checksum)
+			}
+		}
+	}
+}
+
+func TestEncryptedDownloadRange(t *testing.T) {
+	s, _, _ := newTestEncrypted(t)
+	ctx := context.Background()
+	size := 3*encryptionChunkSize + 500
+	plaintext := testPlaintext(size)
+	if _, err := s.Upload(ctx, "p.zip", bytes.NewReader(plaintext), int64(size)); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           []byte
+	}{
+		{"start of first chunk", 0, 10, plaintext[:10]},
+		{"within a chunk", 100, 1000, plaintext[100:1100]},
+		{"ends at chunk boundary", encryptionChunkSize - 10, 10, plaintext[encryptionChunkSize-10 : encryptionChunkSize]},
+		{"starts at chunk boundary", encryptionChunkSize, 10, plaintext[encryptionChunkSize : encryptionChunkSize+10]},
+		{"spans chunks", encryptionChunkSize - 5, encryptionChunkSize + 10, plaintext[encryptionChunkSize-5 : 2*encryptionChunkSize+5]},
+		{"into last chunk", 2*encryptionChunkSize + 1, encryptionChunkSize + 10, plaintext[2*encryptionChunkSize+1 : 3*encryptionChunkSize+11]},
+		{"to end", 3 * encryptionChunkSize, -1, plaintext[3*encryptionChunkSize:]},
+		{"past end clamped", int64(size) - 5, 100, plaintext[size-5:]},
+		{"whole file", 0, int64(size), plaintext},
+		{"offset past end", int64(size), 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := s.DownloadRange(ctx, "p.zip", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("DownloadRange: %v", err)
+			}
+			if got := readAllAndClose(t, reader); !bytes.Equal(got, tt.want) {
+				t.Errorf("DownloadRange(%d, %d) returned %d bytes, want %d matching bytes", tt.offset, tt.length, len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestEncryptedTampering(t *testing.T) {
+	ctx := context.Background()
+	size := 2*encryptionChunkSize + 10
+	plaintext := testPlaintext(size)
+
+	tests := []struct {
+		name   string
+		tamper func([]byte) []byte
+	}{
+		{"flipped bit", func(data []byte) []byte {
+			data[len(encryptionHeader)+encryptionChunkSize/2] ^= 1
+			return data
+		}},
+		{"truncated after a chunk", func(data []byte) []byte {
+			return data[:len(encryptionHeader)+encryptionChunkSize+encryptionTagSize]
+		}},
+		{"swapped chunks", func(data []byte) []byte {
+			const sealed = encryptionChunkSize + encryptionTagSize
+			first := append([]byte(nil), data[len(encryptionHeader):len(encryptionHeader)+sealed]...)
+			copy(data[len(encryptionHeader):], data[len(encryptionHeader)+sealed:len(encryptionHeader)+2*sealed])
+			copy(data[len(encryptionHeader)+sealed:], first)
+			return data
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, backend, _ := newTestEncrypted(t)
+			if _, err := s.Upload(ctx, "p.zip", bytes.NewReader(plaintext), int64(size)); err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+			backend.files["p.zip"] = tt.tamper(backend.files["p.zip"])
+
+			reader, err := s.Download(ctx, "p.zip")
+			if err != nil {
+				return
+			}
+			defer reader.Close()
+			if _, err := io.ReadAll(reader); err == nil {
+				t.Error("reading a tampered file succeeded")
+			}
+		})
+	}
+}
+
+func TestEncryptedChecksumMismatch(t *testing.T) {
+	s, backend, keys := newTestEncrypted(t)
+	ctx := context.Background()
+
+	reader := WithChecksum(strings.NewReader("payload"), strings.Repeat("0", 64))
+	if _, err := s.Upload(ctx, "p.zip", reader, 7); err == nil {
+		t.Fatal("Upload with a wrong known checksum succeeded")
+	}
+	if _, ok := backend.files["p.zip"]; ok {
+		t.Error("file was left in the backend after a checksum mismatch")
+	}
+	if record, _ := keys.GetDataKey(ctx, "local", "p.zip"); record != nil {
+		t.Error("data key was recorded after a checksum mismatch")
+	}
+}
+
+func TestEncryptedUnencryptedFilesPassThrough(t *testing.T) {
+	s, backend, _ := newTestEncrypted(t)
+	ctx := context.Background()
+	backend.files["old.tar.gz"] = []byte("written before encryption")
+
+	reader, err := s.Download(ctx, "old.tar.gz")
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if got := readAllAndClose(t, reader); string(got) != "written before encryption" {
+		t.Errorf("Download = %q", got)
+	}
+
+	reader, err = s.DownloadRange(ctx, "old.tar.gz", 8, 6)
+	if err != nil {
+		t.Fatalf("DownloadRange: %v", err)
+	}
+	if got := readAllAndClose(t, reader); string(got) != "before" {
+		t.Errorf("DownloadRange = %q", got)
+	}
+}
+
+func TestEncryptedGetURLAndDelete(t *testing.T) {
+	s, backend, keys := newTestEncrypted(t)
+	ctx := context.Background()
+	if _, err := s.Upload(ctx, "p.zip", strings.NewReader("payload"), 7); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	url, err := s.GetURL(ctx, "p.zip", 0)
+	if err != nil {
+		t.Fatalf("GetURL: %v", err)
+	}
+	if url != "https://registry.example.com/v1/files/p.zip" {
+		t.Errorf("GetURL = %s, want the registry's file endpoint", url)
+	}
+
+	if err := s.Delete(ctx, "p.zip"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := backend.files["p.zip"]; ok {
+		t.Error("file remains in the backend after Delete")
+	}
+	if record, _ := keys.GetDataKey(ctx, "local", "p.zip"); record != nil {
+		t.Error("data key remains after Delete")
+	}
+}
+
+func TestRewrapDataKeys(t *testing.T) {
+	ctx := context.Background()
+	oldRing, err := crypto.NewKeyRing(testMasterKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	backend := newMemoryStorage()
+	keys := newMemoryDataKeyStore()
+	s := NewEncryptedStorage(backend, "local", keys, oldRing, "")
+	for _, path := range []string{"a.zip", "b.zip", "c.zip"} {
+		if _, err := s.Upload(ctx, path, strings.NewReader("contents of "+path), -1); err != nil {
+			t.Fatalf("Upload: %v", err)
+		}
+	}
+
+	newRing, err := crypto.NewKeyRing(testMasterKey(2), testMasterKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	rewrapped, err := RewrapDataKeys(ctx, keys, newRing)
+	if err != nil {
+		t.Fatalf("RewrapDataKeys: %v", err)
+	}
+	if rewrapped != 3 {
+		t.Errorf("RewrapDataKeys = %d, want 3", rewrapped)
+	}
+
+	// Files stay readable with only the new master key
+	currentOnly, err := crypto.NewKeyRing(testMasterKey(2))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	s = NewEncryptedStorage(backend, "local", keys, currentOnly, "")
+	for _, path := range []string{"a.zip", "b.zip", "c.zip"} {
+		reader, err := s.Download(ctx, path)
+		if err != nil {
+			t.Fatalf("Download %s: %v", path, err)
+		}
+		if got := readAllAndClose(t, reader); string(got) != "contents of "+path {
+			t.Errorf("Download %s = %q", path, got)
+		}
+	}
+
+	if again, err := RewrapDataKeys(ctx, keys, newRing); err != nil || again != 0 {
+		t.Errorf("second RewrapDataKeys = %d, %v, want 0", again, err)
+	}
+}
+
+func TestEncryptedCacheHoldsCiphertext(t *testing.T) {
+	ctx := context.Background()
+	ring, err := crypto.NewKeyRing(testMasterKey(1))
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	cacheDir := t.TempDir()
+	cache, err := NewDiskCache(cacheDir, 1<<20)
+	if err != nil {
+		t.Fatalf("NewDiskCache: %v", err)
+	}
+
+	// Stacked as the router does: content addressing, then encryption, then the cache
+	backend := newMemoryStorage()
+	cached := NewCachingStorage(backend, cache, "s3", "https://registry.example.com", true)
+	cached.SetEncrypted(true)
+	encrypted := NewEncryptedStorage(cached, "s3", newMemoryDataKeyStore(), ring, "https://registry.example.com")
+	cas := NewContentAddressedStorage(encrypted, "s3", newMemoryBlobIndex())
+
+	plaintext := testPlaintext(2*encryptionChunkSize + 77)
+	result, err := cas.Upload(ctx, "modules/a.tar.gz", bytes.NewReader(plaintext), int64(len(plaintext)))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	// The blob is named after its plaintext, but the cache is filled with and checks the ciphertext
+	for i := 0; i < 2; i++ {
+		if got := readAllAndClose(t, mustDownload(t, cas, result.Path)); !bytes.Equal(got, plaintext) {
+			t.Fatalf("download %d returned %d bytes that differ from the plaintext", i, len(got))
+		}
+	}
+	if cache.Size() != encryptedFileSize(int64(len(plaintext))) {
+		t.Fatalf("cache holds %d bytes, want the %d byte encrypted file", cache.Size(), encryptedFileSize(int64(len(plaintext))))
+	}
+
+	// Serve the next reads from the cache alone
+	backend.failWith = errors.New("backend unavailable")
+	reader, err := cas.DownloadRange(ctx, result.Path, encryptionChunkSize-10, 20)
+	if err != nil {
+		t.Fatalf("DownloadRange: %v", err)
+	}
+	if got := readAllAndClose(t, reader); !bytes.Equal(got, plaintext[encryptionChunkSize-10:encryptionChunkSize+10]) {
+		t.Errorf("cached range = %x, want %x", got, plaintext[encryptionChunkSize-10:encryptionChunkSize+10])
+	}
+	backend.failWith = nil
+
+	err = filepath.WalkDir(cacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, plaintext[:64]) {
+			t.Errorf("cache file %s holds plaintext", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk cache: %v", err)
+	}
+
+	// Checks that must see the backend skip the cache but still decrypt
+	uncached, ok := Uncached(cas).(*EncryptedStorage)
+	if !ok || uncached.backend != Storage(backend) {
+		t.Fatalf("Uncached = %T, want encryption directly over the backend", Uncached(cas))
+	}
+	if got := readAllAndClose(t, mustDownload(t, uncached, result.Path)); !bytes.Equal(got, plaintext) {
+		t.Error("uncached download differs from the plaintext")
+	}
+}
+
+func mustDownload(t *testing.T, s Storage, path string) io.ReadCloser {
+	t.Helper()
+	reader, err := s.Download(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Download %s: %v", path, err)
+	}
+	return reader
+}
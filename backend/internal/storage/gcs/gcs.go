@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 
 	appconfig "github.com/terraform-registry/terraform-registry/internal/config"
@@ -106,39 +107,57 @@ func (s *GCSStorage) Close() error {
 	return s.client.Close()
 }
 
-// Upload stores a file in GCS
+// Upload stores a file in GCS. Files are streamed as a resumable upload in chunks of
+// appstorage.MultipartPartSize, so large files are never held in memory.
 func (s *GCSStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*appstorage.UploadResult, error) {
-	// Read all content to calculate checksum
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read data: %w", err)
-	}
-
-	// Calculate SHA256 checksum
-	hasher := sha256.New()
-	hasher.Write(data)
-	checksum := hex.EncodeToString(hasher.Sum(nil))
+	knownChecksum := appstorage.KnownChecksum(reader)
 
 	// Get object handle
 	obj := s.client.Bucket(s.bucket).Object(path)
 
+	// Cancelling the context abandons the upload if it fails part way through
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Create writer and upload
-	writer := obj.NewWriter(ctx)
-	writer.Metadata = map[string]string{
-		"sha256": checksum,
+	writer := obj.NewWriter(uploadCtx)
+	writer.ChunkSize = appstorage.MultipartPartSize
+	if knownChecksum != "" {
+		writer.Metadata = map[string]string{
+			"sha256": knownChecksum,
+		}
 	}
 
-	if _, err := writer.Write(data); err != nil {
+	// Calculate SHA256 checksum while uploading
+	hasher := sha256.New()
+	written, err := io.Copy(writer, io.TeeReader(reader, hasher))
+	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to write to GCS: %w", err)
 	}
 
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if knownChecksum != "" && knownChecksum != checksum {
+		cancel()
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", knownChecksum, checksum)
+	}
+
 	if err := writer.Close(); err != nil {
 		return nil, fmt.Errorf("failed to close GCS writer: %w", err)
 	}
 
+	if knownChecksum == "" {
+		// Non-fatal: GetMetadata falls back to hashing the object
+		obj.Update(ctx, storage.ObjectAttrsToUpdate{
+			Metadata: map[string]string{
+				"sha256": checksum,
+			},
+		})
+	}
+
 	return &appstorage.UploadResult{
 		Path:     path,
-		Size:     int64(len(data)),
+		Size:     written,
 		Checksum: checksum,
 	}, nil
 }
@@ -155,6 +174,18 @@ func (s *GCSStorage) Download(ctx context.Context, path string) (io.ReadCloser,
 	return reader, nil
 }
 
+// DownloadRange retrieves part of a file from GCS
+func (s *GCSStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	obj := s.client.Bucket(s.bucket).Object(path)
+
+	reader, err := obj.NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read range from GCS: %w", err)
+	}
+
+	return reader, nil
+}
+
 // Delete removes a file from GCS
 func (s *GCSStorage) Delete(ctx context.Context, path string) error {
 	obj := s.client.Bucket(s.bucket).Object(path)
@@ -320,6 +351,27 @@ func (s *GCSStorage) ListObjects(ctx context.Context, prefix string, maxResults
 	return objects, nil
 }
 
+// List calls fn for every object whose name starts with prefix
+func (s *GCSStorage) List(ctx context.Context, prefix string, fn func(*appstorage.FileMetadata) error) error {
+	it := s.client.Bucket(s.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		if err := fn(&appstorage.FileMetadata{
+			Path:         attrs.Name,
+			Size:         attrs.Size,
+			LastModified: attrs.Updated,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
 // DeletePrefix deletes all objects with a given prefix
 func (s *GCSStorage) DeletePrefix(ctx context.Context, prefix string) error {
 	objects, err := s.ListObjects(ctx, prefix, 0)
@@ -6,8 +6,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/terraform-registry/terraform-registry/internal/config"
@@ -72,6 +75,10 @@ func (s *LocalStorage) Upload(ctx context.Context, path string, reader io.Reader
 	}
 
 	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if known := storage.KnownChecksum(reader); known != "" && known != checksum {
+		os.Remove(fullPath)
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", known, checksum)
+	}
 
 	return &storage.UploadResult{
 		Path:     path,
@@ -95,6 +102,22 @@ func (s *LocalStorage) Download(ctx context.Context, path string) (io.ReadCloser
 	return file, nil
 }
 
+// DownloadRange retrieves part of a file from the local filesystem
+func (s *LocalStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := s.Download(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := reader.(*os.File)
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	return storage.LimitReadCloser(file, length), nil
+}
+
 // Delete removes a file from the local filesystem
 func (s *LocalStorage) Delete(ctx context.Context, path string) error {
 	fullPath := filepath.Join(s.basePath, filepath.FromSlash(path))
@@ -190,3 +213,47 @@ func (s *LocalStorage) GetMetadata(ctx context.Context, path string) (*storage.F
 		LastModified: stat.ModTime(),
 	}, nil
 }
+
+// List calls fn for every file whose path starts with prefix
+func (s *LocalStorage) List(ctx context.Context, prefix string, fn func(*storage.FileMetadata) error) error {
+	root := filepath.Join(s.basePath, filepath.FromSlash(path.Dir(prefix)))
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	err := filepath.WalkDir(root, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.basePath, fullPath)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil // Removed while listing
+		}
+		return fn(&storage.FileMetadata{
+			Path:         name,
+			Size:         info.Size(),
+			LastModified: info.ModTime(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	return nil
+}
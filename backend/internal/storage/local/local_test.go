@@ -0,0 +1,55 @@
+package local
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func TestDownloadRange(t *testing.T) {
+	ctx := context.Background()
+	s, err := New(&config.LocalStorageConfig{BasePath: t.TempDir()}, "http://registry.example.com")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	const content = "0123456789abcdef"
+	if _, err := s.Upload(ctx, "providers/a/b.zip", strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           string
+	}{
+		{"whole file", 0, -1, content},
+		{"prefix", 0, 4, "0123"},
+		{"middle", 5, 3, "567"},
+		{"to the end", 10, -1, "abcdef"},
+		{"length past the end", 12, 100, "cdef"},
+		{"offset at the end", 16, -1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := s.DownloadRange(ctx, "providers/a/b.zip", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("DownloadRange: %v", err)
+			}
+			defer reader.Close()
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("DownloadRange(%d, %d) = %q, want %q", tt.offset, tt.length, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.DownloadRange(ctx, "providers/a/missing.zip", 0, 1); err == nil {
+		t.Error("DownloadRange succeeded for a missing file")
+	}
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// memoryStorage is an in-memory Storage for tests. When failWith is set every operation
+// returns it; noChecksums hides checksums from GetMetadata like backends that do not keep one.
+type memoryStorage struct {
+	files       map[string][]byte
+	failWith    error
+	noChecksums bool
+	mu          sync.Mutex
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{files: make(map[string][]byte)}
+}
+
+func (m *memoryStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	if m.failWith != nil {
+		return nil, m.failWith
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.files[path] = data
+	m.mu.Unlock()
+	digest := sha256.Sum256(data)
+	return &UploadResult{Path: path, Size: int64(len(data)), Checksum: hex.EncodeToString(digest[:])}, nil
+}
+
+func (m *memoryStorage) file(path string) ([]byte, error) {
+	if m.failWith != nil {
+		return nil, m.failWith
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+	return data, nil
+}
+
+func (m *memoryStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	data, err := m.file(path)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	data, err := m.file(path)
+	if err != nil {
+		return nil, err
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryStorage) Delete(ctx context.Context, path string) error {
+	if m.failWith != nil {
+		return m.failWith
+	}
+	m.mu.Lock()
+	delete(m.files, path)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	if _, err := m.file(path); err != nil {
+		return "", err
+	}
+	return "memory://" + path, nil
+}
+
+func (m *memoryStorage) Exists(ctx context.Context, path string) (bool, error) {
+	if m.failWith != nil {
+		return false, m.failWith
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.files[path]
+	return ok, nil
+}
+
+func (m *memoryStorage) GetMetadata(ctx context.Context, path string) (*FileMetadata, error) {
+	data, err := m.file(path)
+	if err != nil {
+		return nil, err
+	}
+	metadata := &FileMetadata{Path: path, Size: int64(len(data))}
+	if !m.noChecksums {
+		digest := sha256.Sum256(data)
+		metadata.Checksum = hex.EncodeToString(digest[:])
+	}
+	return metadata, nil
+}
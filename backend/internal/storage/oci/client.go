@@ -0,0 +1,263 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNotFound is returned for manifests and blobs the registry does not hold
+var errNotFound = errors.New("not found in OCI registry")
+
+// client speaks the OCI Distribution API to one registry. It answers basic and bearer token
+// authentication challenges, caching a token per repository until it expires.
+type client struct {
+	baseURL  string
+	username string
+	password string
+	http     *http.Client
+
+	tokens   map[string]bearerToken
+	tokensMu sync.Mutex
+}
+
+type bearerToken struct {
+	value   string
+	expires time.Time
+}
+
+func newClient(registry, username, password string, insecure bool) *client {
+	baseURL := registry
+	if !strings.Contains(registry, "://") {
+		scheme := "https"
+		if insecure {
+			scheme = "http"
+		}
+		baseURL = scheme + "://" + registry
+	}
+
+	return &client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		username: username,
+		password: password,
+		http:     &http.Client{},
+		tokens:   make(map[string]bearerToken),
+	}
+}
+
+// do sends a request for repository, authenticating when the registry challenges it. body
+// must be nil or replayable so the request can be retried after a challenge.
+func (c *client) do(ctx context.Context, repository, method, target string, body []byte, header http.Header) (*http.Response, error) {
+	if !strings.Contains(target, "://") {
+		target = c.baseURL + target
+	}
+
+	send := func(authorization string) (*http.Response, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if body != nil {
+			req.ContentLength = int64(len(body))
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return c.http.Do(req)
+	}
+
+	resp, err := send(c.cachedAuthorization(repository))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	drain(resp)
+	authorization, err := c.authorize(ctx, repository, challenge)
+	if err != nil {
+		return nil, err
+	}
+	return send(authorization)
+}
+
+// cachedAuthorization returns the Authorization header of a live token for repository
+func (c *client) cachedAuthorization(repository string) string {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+
+	token, ok := c.tokens[repository]
+	if !ok || time.Now().After(token.expires) {
+		return ""
+	}
+	return token.value
+}
+
+// authorize answers an authentication challenge, returning the Authorization header to retry with
+func (c *client) authorize(ctx context.Context, repository, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if c.username == "" {
+			return "", fmt.Errorf("OCI registry requires credentials")
+		}
+		req, _ := http.NewRequest(http.MethodGet, c.baseURL, nil)
+		req.SetBasicAuth(c.username, c.password)
+		authorization := req.Header.Get("Authorization")
+		c.storeToken(repository, authorization, 24*time.Hour)
+		return authorization, nil
+
+	case "bearer":
+		return c.fetchToken(ctx, repository, params)
+
+	default:
+		return "", fmt.Errorf("unsupported OCI registry authentication challenge: %q", challenge)
+	}
+}
+
+// fetchToken requests a bearer token covering pull, push and delete on repository
+func (c *client) fetchToken(ctx context.Context, repository string, params map[string]string) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("OCI registry token challenge has no realm")
+	}
+
+	query := url.Values{}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push,delete", repository))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid OCI registry token realm: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request OCI registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OCI registry token request failed with status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode OCI registry token: %w", err)
+	}
+	value := token.Token
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return "", fmt.Errorf("OCI registry token response has no token")
+	}
+
+	// Tokens live at least 60 seconds per the token spec; renew a little early
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = 60 * time.Second
+	}
+	authorization := "Bearer " + value
+	c.storeToken(repository, authorization, lifetime-10*time.Second)
+	return authorization, nil
+}
+
+func (c *client) storeToken(repository, authorization string, lifetime time.Duration) {
+	c.tokensMu.Lock()
+	defer c.tokensMu.Unlock()
+	c.tokens[repository] = bearerToken{value: authorization, expires: time.Now().Add(lifetime)}
+}
+
+// parseChallenge splits a WWW-Authenticate header into its scheme and parameters
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			params[strings.ToLower(key)] = value
+		}
+	}
+	return scheme, params
+}
+
+// checkStatus turns an unexpected response into an error, reading the registry's error message
+func checkStatus(resp *http.Response, action string, expected ...int) error {
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	defer drain(resp)
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body); err == nil && len(body.Errors) > 0 {
+		return &statusError{
+			status:  resp.StatusCode,
+			message: fmt.Sprintf("failed to %s: %s: %s (status %d)", action, body.Errors[0].Code, body.Errors[0].Message, resp.StatusCode),
+		}
+	}
+	return &statusError{status: resp.StatusCode, message: fmt.Sprintf("failed to %s: status %d", action, resp.StatusCode)}
+}
+
+// statusError is an unexpected registry response, exposing its status so callers can tell
+// server failures from rejected requests
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// HTTPStatusCode returns the status of the response
+func (e *statusError) HTTPStatusCode() int {
+	return e.status
+}
+
+// drain discards the rest of a response body so the connection can be reused
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,503 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+func init() {
+	// Register OCI registry storage backend
+	storage.Register("oci", func(cfg *config.Config) (storage.Storage, error) {
+		return New(&cfg.Storage.OCI, cfg.Server.BaseURL)
+	})
+}
+
+// Media types of the artifacts files are stored as
+const (
+	manifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	artifactType      = "application/vnd.terraform-registry.file.v1"
+	emptyMediaType    = "application/vnd.oci.empty.v1+json"
+)
+
+// Manifest annotations
+const (
+	annotationTitle   = "org.opencontainers.image.title"
+	annotationCreated = "org.opencontainers.image.created"
+	annotationPath    = "io.terraform-registry.path"
+)
+
+// emptyConfig is the config blob of artifacts that have no config
+var emptyConfig = []byte("{}")
+
+var (
+	tagPattern        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+	invalidNameChars  = regexp.MustCompile(`[^a-z0-9._-]+`)
+	nameSeparatorRuns = regexp.MustCompile(`[._-]{2,}`)
+	invalidTagChars   = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// maxSanitizedTagSize leaves room in a 128 character tag for "-" and the path hash
+const maxSanitizedTagSize = 128 - 1 - 12
+
+// OCIStorage implements the Storage interface on an OCI registry. Each file is pushed as a
+// single-layer artifact: the file's directory names the repository under the configured prefix
+// and its name the tag, so modules/hashicorp/consul/aws/1.0.0.tar.gz becomes
+// <prefix>/modules/hashicorp/consul/aws:1.0.0.tar.gz. Paths that aren't valid repository names
+// or tags are sanitized and tagged with a hash of the path to keep them apart.
+type OCIStorage struct {
+	client     *client
+	repository string
+	baseURL    string
+}
+
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        descriptor        `json:"config"`
+	Layers        []descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// New creates a new OCI registry storage backend
+func New(cfg *config.OCIStorageConfig, baseURL string) (*OCIStorage, error) {
+	if cfg.Registry == "" {
+		return nil, fmt.Errorf("oci registry is required")
+	}
+	repository := strings.Trim(cfg.Repository, "/")
+	if repository == "" {
+		return nil, fmt.Errorf("oci repository is required")
+	}
+
+	return &OCIStorage{
+		client:     newClient(cfg.Registry, cfg.Username, cfg.Password, cfg.Insecure),
+		repository: repository,
+		baseURL:    baseURL,
+	}, nil
+}
+
+// Upload pushes a file as an artifact, streaming its content to the registry in chunks of
+// storage.MultipartPartSize
+func (s *OCIStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*storage.UploadResult, error) {
+	knownChecksum := storage.KnownChecksum(reader)
+	repository, tag := s.reference(path)
+
+	layer, err := s.pushBlob(ctx, repository, reader, knownChecksum)
+	if err != nil {
+		return nil, err
+	}
+	layer.MediaType = layerMediaType(path)
+	layer.Annotations = map[string]string{annotationTitle: pathpkg.Base(path)}
+
+	if err := s.pushSmallBlob(ctx, repository, emptyConfig); err != nil {
+		return nil, err
+	}
+
+	m := manifest{
+		SchemaVersion: 2,
+		MediaType:     manifestMediaType,
+		ArtifactType:  artifactType,
+		Config:        descriptor{MediaType: emptyMediaType, Digest: digestOf(emptyConfig), Size: int64(len(emptyConfig))},
+		Layers:        []descriptor{*layer},
+		Annotations: map[string]string{
+			annotationPath:    path,
+			annotationCreated: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	body, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OCI manifest: %w", err)
+	}
+
+	resp, err := s.client.do(ctx, repository, http.MethodPut, manifestURL(repository, tag), body,
+		http.Header{"Content-Type": {manifestMediaType}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to push OCI manifest: %w", err)
+	}
+	if err := checkStatus(resp, "push OCI manifest", http.StatusCreated); err != nil {
+		return nil, err
+	}
+	drain(resp)
+
+	return &storage.UploadResult{
+		Path:     path,
+		Size:     layer.Size,
+		Checksum: strings.TrimPrefix(layer.Digest, "sha256:"),
+	}, nil
+}
+
+// pushBlob streams reader to the registry as a blob. A blob the repository already holds with
+// knownChecksum is not pushed again.
+func (s *OCIStorage) pushBlob(ctx context.Context, repository string, reader io.Reader, knownChecksum string) (*descriptor, error) {
+	if knownChecksum != "" {
+		digest := "sha256:" + knownChecksum
+		if size, err := s.blobSize(ctx, repository, digest); err == nil {
+			return &descriptor{Digest: digest, Size: size}, nil
+		}
+	}
+
+	location, err := s.startBlobUpload(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	cancel := func() {
+		if resp, err := s.client.do(context.Background(), repository, http.MethodDelete, location, nil, nil); err == nil {
+			drain(resp)
+		}
+	}
+
+	hasher := sha256.New()
+	chunk := make([]byte, storage.MultipartPartSize)
+	var offset int64
+	for {
+		n, readErr := io.ReadFull(reader, chunk)
+		if n > 0 {
+			hasher.Write(chunk[:n])
+			resp, err := s.client.do(ctx, repository, http.MethodPatch, location, chunk[:n], http.Header{
+				"Content-Type":  {"application/octet-stream"},
+				"Content-Range": {fmt.Sprintf("%d-%d", offset, offset+int64(n)-1)},
+			})
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("failed to upload OCI blob: %w", err)
+			}
+			if err := checkStatus(resp, "upload OCI blob", http.StatusAccepted); err != nil {
+				cancel()
+				return nil, err
+			}
+			if next := resp.Header.Get("Location"); next != "" {
+				location = next
+			}
+			drain(resp)
+			offset += int64(n)
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to read file: %w", readErr)
+		}
+	}
+
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if knownChecksum != "" && knownChecksum != checksum {
+		cancel()
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", knownChecksum, checksum)
+	}
+
+	digest := "sha256:" + checksum
+	if err := s.finishBlobUpload(ctx, repository, location, digest, nil); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &descriptor{Digest: digest, Size: offset}, nil
+}
+
+// pushSmallBlob pushes data as a blob in a single request, unless the repository already holds it
+func (s *OCIStorage) pushSmallBlob(ctx context.Context, repository string, data []byte) error {
+	digest := digestOf(data)
+	if _, err := s.blobSize(ctx, repository, digest); err == nil {
+		return nil
+	}
+
+	location, err := s.startBlobUpload(ctx, repository)
+	if err != nil {
+		return err
+	}
+	return s.finishBlobUpload(ctx, repository, location, digest, data)
+}
+
+// startBlobUpload opens an upload session, returning its location
+func (s *OCIStorage) startBlobUpload(ctx context.Context, repository string) (string, error) {
+	resp, err := s.client.do(ctx, repository, http.MethodPost, "/v2/"+repository+"/blobs/uploads/", nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to start OCI blob upload: %w", err)
+	}
+	if err := checkStatus(resp, "start OCI blob upload", http.StatusAccepted); err != nil {
+		return "", err
+	}
+	drain(resp)
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("OCI registry did not return a blob upload location")
+	}
+	return location, nil
+}
+
+// finishBlobUpload closes an upload session with the blob's digest and any remaining data
+func (s *OCIStorage) finishBlobUpload(ctx context.Context, repository, location, digest string, data []byte) error {
+	target, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("invalid OCI blob upload location: %w", err)
+	}
+	query := target.Query()
+	query.Set("digest", digest)
+	target.RawQuery = query.Encode()
+
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := s.client.do(ctx, repository, http.MethodPut, target.String(), data,
+		http.Header{"Content-Type": {"application/octet-stream"}})
+	if err != nil {
+		return fmt.Errorf("failed to complete OCI blob upload: %w", err)
+	}
+	if err := checkStatus(resp, "complete OCI blob upload", http.StatusCreated); err != nil {
+		return err
+	}
+	drain(resp)
+	return nil
+}
+
+// blobSize returns the size of a blob in repository, or errNotFound
+func (s *OCIStorage) blobSize(ctx context.Context, repository, digest string) (int64, error) {
+	resp, err := s.client.do(ctx, repository, http.MethodHead, "/v2/"+repository+"/blobs/"+digest, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkStatus(resp, "check OCI blob", http.StatusOK); err != nil {
+		return 0, err
+	}
+	drain(resp)
+	return resp.ContentLength, nil
+}
+
+// Download retrieves a file from the registry
+func (s *OCIStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	return s.DownloadRange(ctx, path, 0, -1)
+}
+
+// DownloadRange retrieves length bytes of a file starting at offset. A negative length reads to
+// the end of the file.
+func (s *OCIStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	repository, tag := s.reference(path)
+	m, _, err := s.getManifest(ctx, repository, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from OCI registry: %w", err)
+	}
+	if length == 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+
+	header := http.Header{}
+	if offset > 0 || length > 0 {
+		byteRange := fmt.Sprintf("bytes=%d-", offset)
+		if length > 0 {
+			byteRange += fmt.Sprintf("%d", offset+length-1)
+		}
+		header.Set("Range", byteRange)
+	}
+
+	resp, err := s.client.do(ctx, repository, http.MethodGet, "/v2/"+repository+"/blobs/"+m.Layers[0].Digest, nil, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from OCI registry: %w", err)
+	}
+	if err := checkStatus(resp, "download from OCI registry", http.StatusOK, http.StatusPartialContent); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusOK && offset > 0 {
+		// The registry ignored the range; skip to the offset ourselves
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to download from OCI registry: %w", err)
+		}
+	}
+	return storage.LimitReadCloser(resp.Body, length), nil
+}
+
+// Delete removes a file's artifact. The registry garbage collects blobs no longer referenced.
+func (s *OCIStorage) Delete(ctx context.Context, path string) error {
+	repository, tag := s.reference(path)
+	_, digest, err := s.getManifest(ctx, repository, tag)
+	if err == errNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete from OCI registry: %w", err)
+	}
+
+	resp, err := s.client.do(ctx, repository, http.MethodDelete, manifestURL(repository, digest), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete from OCI registry: %w", err)
+	}
+	if err := checkStatus(resp, "delete from OCI registry", http.StatusAccepted, http.StatusOK); err != nil && err != errNotFound {
+		return err
+	}
+	drain(resp)
+	return nil
+}
+
+// GetURL returns a URL serving the file through the registry's file endpoint, as the OCI
+// registry's blobs are not readable without its credentials
+func (s *OCIStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	exists, err := s.Exists(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("file not found: %s", path)
+	}
+
+	return fmt.Sprintf("%s/v1/files/%s", s.baseURL, path), nil
+}
+
+// Exists checks if a file's artifact exists in the registry
+func (s *OCIStorage) Exists(ctx context.Context, path string) (bool, error) {
+	repository, tag := s.reference(path)
+	resp, err := s.client.do(ctx, repository, http.MethodHead, manifestURL(repository, tag), nil,
+		http.Header{"Accept": {manifestMediaType}})
+	if err != nil {
+		return false, fmt.Errorf("failed to check OCI manifest: %w", err)
+	}
+	if err := checkStatus(resp, "check OCI manifest", http.StatusOK); err != nil {
+		if err == errNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	drain(resp)
+	return true, nil
+}
+
+// GetMetadata retrieves file metadata from the artifact's manifest
+func (s *OCIStorage) GetMetadata(ctx context.Context, path string) (*storage.FileMetadata, error) {
+	repository, tag := s.reference(path)
+	m, _, err := s.getManifest(ctx, repository, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OCI manifest: %w", err)
+	}
+
+	metadata := &storage.FileMetadata{
+		Path:     path,
+		Size:     m.Layers[0].Size,
+		Checksum: strings.TrimPrefix(m.Layers[0].Digest, "sha256:"),
+	}
+	if created, err := time.Parse(time.RFC3339, m.Annotations[annotationCreated]); err == nil {
+		metadata.LastModified = created
+	}
+	return metadata, nil
+}
+
+// getManifest fetches the manifest of a file's artifact and its digest
+func (s *OCIStorage) getManifest(ctx context.Context, repository, tag string) (*manifest, string, error) {
+	resp, err := s.client.do(ctx, repository, http.MethodGet, manifestURL(repository, tag), nil,
+		http.Header{"Accept": {manifestMediaType}})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkStatus(resp, "get OCI manifest", http.StatusOK); err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, "", fmt.Errorf("invalid OCI manifest: %w", err)
+	}
+	if len(m.Layers) != 1 || !strings.HasPrefix(m.Layers[0].Digest, "sha256:") {
+		return nil, "", fmt.Errorf("OCI manifest for %s:%s is not a stored file", repository, tag)
+	}
+
+	return &m, digestOf(body), nil
+}
+
+// reference maps a file path to the repository and tag of its artifact
+func (s *OCIStorage) reference(path string) (string, string) {
+	path = strings.Trim(path, "/")
+	dir, name := pathpkg.Split(path)
+
+	repository := s.repository
+	exact := true
+	for _, component := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if component == "" {
+			continue
+		}
+		sanitized := repositoryComponent(component)
+		exact = exact && sanitized == component
+		repository += "/" + sanitized
+	}
+
+	if exact && tagPattern.MatchString(name) {
+		return repository, name
+	}
+
+	// Tag sanitized paths with a hash of the original so they can't collide
+	tag := strings.TrimLeft(invalidTagChars.ReplaceAllString(name, "-"), ".-")
+	if len(tag) > maxSanitizedTagSize {
+		tag = tag[:maxSanitizedTagSize]
+	}
+	sum := sha256.Sum256([]byte(path))
+	hash := hex.EncodeToString(sum[:6])
+	if tag == "" {
+		return repository, hash
+	}
+	return repository, tag + "-" + hash
+}
+
+// repositoryComponent turns a path component into a valid repository name component
+func repositoryComponent(component string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(component), "-")
+	name = nameSeparatorRuns.ReplaceAllStringFunc(name, func(run string) string {
+		if run == "__" || strings.Trim(run, "-") == "" {
+			return run
+		}
+		return "-"
+	})
+	name = strings.Trim(name, "._-")
+	if name == "" {
+		return "x"
+	}
+	return name
+}
+
+// layerMediaType returns the media type of a file's layer from its extension
+func layerMediaType(path string) string {
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		return "application/zip"
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return "application/gzip"
+	case strings.HasSuffix(path, ".json"):
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func manifestURL(repository, reference string) string {
+	return "/v2/" + repository + "/manifests/" + reference
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,433 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/storage"
+)
+
+// testRegistry is an in-process OCI Distribution registry covering the endpoints the backend
+// uses. When token is set every /v2 request needs it, obtained through a bearer challenge.
+type testRegistry struct {
+	server *httptest.Server
+	token  string
+	// ignoreRange serves whole blobs to ranged requests, like registries without range support
+	ignoreRange bool
+	// rejectTokens refuses token requests, like a registry that no longer accepts the credentials
+	rejectTokens bool
+
+	blobs     map[string][]byte
+	manifests map[string][]byte // repository:reference -> manifest
+	uploads   map[string]*bytes.Buffer
+	nextID    int
+
+	patches        int
+	uploadsStarted int
+	uploadsDeleted int
+	tokenRequests  []*http.Request
+	mu             sync.Mutex
+}
+
+func newTestRegistry(t *testing.T, token string) *testRegistry {
+	t.Helper()
+	r := &testRegistry{
+		token:     token,
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+		uploads:   make(map[string]*bytes.Buffer),
+	}
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *testRegistry) storage(t *testing.T) *OCIStorage {
+	t.Helper()
+	s, err := New(&config.OCIStorageConfig{
+		Registry:   r.server.URL,
+		Repository: "terraform-registry",
+		Username:   "robot",
+		Password:   "secret",
+	}, "https://registry.example.com")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func (r *testRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		r.tokenRequests = append(r.tokenRequests, req)
+		if r.rejectTokens {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token": %q, "expires_in": 300}`, r.token)
+		return
+	}
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, r.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/blobs/uploads/"):
+		repository, id, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, repository, id)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		r.serveBlob(w, req, digest)
+	case strings.Contains(path, "/manifests/"):
+		repository, reference, _ := strings.Cut(path, "/manifests/")
+		r.serveManifest(w, req, repository, reference)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (r *testRegistry) serveUpload(w http.ResponseWriter, req *http.Request, repository, id string) {
+	if req.Method == http.MethodPost {
+		r.nextID++
+		r.uploadsStarted++
+		id = strconv.Itoa(r.nextID)
+		r.uploads[id] = &bytes.Buffer{}
+		w.Header().Set("Location", "/v2/"+repository+"/blobs/uploads/"+id)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	upload, ok := r.uploads[id]
+	if !ok {
+		writeRegistryError(w, http.StatusNotFound, "BLOB_UPLOAD_UNKNOWN")
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+
+	switch req.Method {
+	case http.MethodPatch:
+		r.patches++
+		if want := fmt.Sprintf("%d-%d", upload.Len(), upload.Len()+len(body)-1); req.Header.Get("Content-Range") != want {
+			writeRegistryError(w, http.StatusRequestedRangeNotSatisfiable, "BLOB_UPLOAD_INVALID")
+			return
+		}
+		upload.Write(body)
+		w.Header().Set("Location", "/v2/"+repository+"/blobs/uploads/"+id)
+		w.WriteHeader(http.StatusAccepted)
+
+	case http.MethodPut:
+		upload.Write(body)
+		digest := req.URL.Query().Get("digest")
+		if digestOf(upload.Bytes()) != digest {
+			writeRegistryError(w, http.StatusBadRequest, "DIGEST_INVALID")
+			return
+		}
+		r.blobs[digest] = upload.Bytes()
+		delete(r.uploads, id)
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodDelete:
+		r.uploadsDeleted++
+		delete(r.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (r *testRegistry) serveBlob(w http.ResponseWriter, req *http.Request, digest string) {
+	blob, ok := r.blobs[digest]
+	if !ok {
+		writeRegistryError(w, http.StatusNotFound, "BLOB_UNKNOWN")
+		return
+	}
+	if r.ignoreRange {
+		req.Header.Del("Range")
+	}
+	http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(blob))
+}
+
+func (r *testRegistry) serveManifest(w http.ResponseWriter, req *http.Request, repository, reference string) {
+	key := repository + ":" + reference
+	switch req.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(req.Body)
+		r.manifests[key] = body
+		r.manifests[repository+":"+digestOf(body)] = body
+		w.WriteHeader(http.StatusCreated)
+
+	case http.MethodGet, http.MethodHead:
+		body, ok := r.manifests[key]
+		if !ok {
+			writeRegistryError(w, http.StatusNotFound, "MANIFEST_UNKNOWN")
+			return
+		}
+		w.Header().Set("Content-Type", manifestMediaType)
+		w.Header().Set("Docker-Content-Digest", digestOf(body))
+		if req.Method == http.MethodGet {
+			w.Write(body)
+		}
+
+	case http.MethodDelete:
+		body, ok := r.manifests[key]
+		if !ok {
+			writeRegistryError(w, http.StatusNotFound, "MANIFEST_UNKNOWN")
+			return
+		}
+		for ref, candidate := range r.manifests {
+			if strings.HasPrefix(ref, repository+":") && bytes.Equal(candidate, body) {
+				delete(r.manifests, ref)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func writeRegistryError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"errors": [{"code": %q, "message": "test registry error"}]}`, code)
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadDownloadRoundTrip(t *testing.T) {
+	registry := newTestRegistry(t, "")
+	s := registry.storage(t)
+	ctx := context.Background()
+
+	// Larger than a part, so the blob is pushed in two PATCH chunks
+	data := make([]byte, storage.MultipartPartSize+1000)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	path := "providers/hashicorp/aws/5.0.0/terraform-provider-aws_5.0.0_linux_amd64.zip"
+
+	result, err := s.Upload(ctx, path, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if result.Size != int64(len(data)) || result.Checksum != checksumOf(data) {
+		t.Errorf("Upload result = %d %s, want %d %s", result.Size, result.Checksum, len(data), checksumOf(data))
+	}
+	if registry.patches != 2 {
+		t.Errorf("pushed %d PATCH chunks, want 2", registry.patches)
+	}
+	if _, ok := registry.manifests["terraform-registry/providers/hashicorp/aws/5.0.0:terraform-provider-aws_5.0.0_linux_amd64.zip"]; !ok {
+		t.Error("manifest was not tagged with the file name in the file's repository")
+	}
+
+	exists, err := s.Exists(ctx, path)
+	if err != nil || !exists {
+		t.Errorf("Exists = %v, %v, want true", exists, err)
+	}
+
+	metadata, err := s.GetMetadata(ctx, path)
+	if err != nil {
+		t.Fatalf("GetMetadata: %v", err)
+	}
+	if metadata.Size != int64(len(data)) || metadata.Checksum != checksumOf(data) || metadata.LastModified.IsZero() {
+		t.Errorf("GetMetadata = %+v", metadata)
+	}
+
+	reader, err := s.Download(ctx, path)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Download returned %d bytes (%v), want the uploaded %d", len(got), err, len(data))
+	}
+}
+
+func TestUploadSkipsExistingBlob(t *testing.T) {
+	registry := newTestRegistry(t, "")
+	s := registry.storage(t)
+	ctx := context.Background()
+	data := []byte("module archive")
+
+	if _, err := s.Upload(ctx, "modules/a/b/c/1.0.0.tar.gz", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	started := registry.uploadsStarted
+
+	// The same content with a known checksum is only re-tagged, not pushed again
+	reader := storage.WithChecksum(bytes.NewReader(data), checksumOf(data))
+	result, err := s.Upload(ctx, "modules/a/b/c/1.0.1.tar.gz", reader, int64(len(data)))
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if registry.uploadsStarted != started {
+		t.Errorf("started %d blob uploads for an existing blob, want none", registry.uploadsStarted-started)
+	}
+	if result.Size != int64(len(data)) || result.Checksum != checksumOf(data) {
+		t.Errorf("Upload result = %+v", result)
+	}
+	if exists, _ := s.Exists(ctx, "modules/a/b/c/1.0.1.tar.gz"); !exists {
+		t.Error("deduplicated file does not exist")
+	}
+}
+
+func TestUploadChecksumMismatch(t *testing.T) {
+	registry := newTestRegistry(t, "")
+	s := registry.storage(t)
+	ctx := context.Background()
+
+	reader := storage.WithChecksum(strings.NewReader("payload"), strings.Repeat("0", 64))
+	if _, err := s.Upload(ctx, "modules/a/b/c/1.0.0.tar.gz", reader, 7); err == nil {
+		t.Fatal("Upload with a wrong known checksum succeeded")
+	}
+	if registry.uploadsDeleted != 1 {
+		t.Errorf("cancelled %d upload sessions, want 1", registry.uploadsDeleted)
+	}
+	if exists, _ := s.Exists(ctx, "modules/a/b/c/1.0.0.tar.gz"); exists {
+		t.Error("file exists after a failed upload")
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           string
+	}{
+		{"prefix", 0, 5, "01234"},
+		{"middle", 10, 6, "abcdef"},
+		{"to end", 15, -1, "fghij"},
+		{"empty", 3, 0, ""},
+	}
+
+	for _, ignoreRange := range []bool{false, true} {
+		registry := newTestRegistry(t, "")
+		registry.ignoreRange = ignoreRange
+		s := registry.storage(t)
+		ctx := context.Background()
+		if _, err := s.Upload(ctx, "files/data.json", bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("Upload: %v", err)
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s ignoreRange=%v", tt.name, ignoreRange), func(t *testing.T) {
+				reader, err := s.DownloadRange(ctx, "files/data.json", tt.offset, tt.length)
+				if err != nil {
+					t.Fatalf("DownloadRange: %v", err)
+				}
+				defer reader.Close()
+				got, err := io.ReadAll(reader)
+				if err != nil || string(got) != tt.want {
+					t.Errorf("DownloadRange(%d, %d) = %q, %v, want %q", tt.offset, tt.length, got, err, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	registry := newTestRegistry(t, "")
+	s := registry.storage(t)
+	ctx := context.Background()
+	path := "modules/a/b/c/1.0.0.tar.gz"
+
+	if _, err := s.Upload(ctx, path, strings.NewReader("payload"), 7); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if err := s.Delete(ctx, path); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if exists, err := s.Exists(ctx, path); err != nil || exists {
+		t.Errorf("Exists after Delete = %v, %v, want false", exists, err)
+	}
+	if _, err := s.GetMetadata(ctx, path); err == nil {
+		t.Error("GetMetadata of a deleted file succeeded")
+	}
+	if err := s.Delete(ctx, path); err != nil {
+		t.Errorf("deleting a missing file: %v", err)
+	}
+}
+
+func TestBearerTokenChallenge(t *testing.T) {
+	registry := newTestRegistry(t, "registry-token")
+	s := registry.storage(t)
+	ctx := context.Background()
+	path := "modules/a/b/c/1.0.0.tar.gz"
+
+	if _, err := s.Upload(ctx, path, strings.NewReader("payload"), 7); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if exists, err := s.Exists(ctx, path); err != nil || !exists {
+		t.Fatalf("Exists = %v, %v, want true", exists, err)
+	}
+
+	if len(registry.tokenRequests) != 1 {
+		t.Fatalf("requested %d tokens, want 1 cached for the repository", len(registry.tokenRequests))
+	}
+	req := registry.tokenRequests[0]
+	if got := req.URL.Query().Get("service"); got != "test-registry" {
+		t.Errorf("token service = %q, want test-registry", got)
+	}
+	if got := req.URL.Query().Get("scope"); got != "repository:terraform-registry/modules/a/b/c:pull,push,delete" {
+		t.Errorf("token scope = %q", got)
+	}
+	if username, password, ok := req.BasicAuth(); !ok || username != "robot" || password != "secret" {
+		t.Errorf("token request credentials = %q %q %v", username, password, ok)
+	}
+
+	// A registry rejecting the credentials surfaces an error rather than looping
+	registry.mu.Lock()
+	registry.token = "rotated"
+	registry.rejectTokens = true
+	registry.mu.Unlock()
+	if _, err := s.Exists(ctx, path); err == nil {
+		t.Error("Exists succeeded without a valid token")
+	}
+}
+
+func TestReference(t *testing.T) {
+	s := &OCIStorage{repository: "prefix"}
+	tests := []struct {
+		path           string
+		wantRepository string
+		wantTag        string
+	}{
+		{"modules/hashicorp/consul/aws/1.0.0.tar.gz", "prefix/modules/hashicorp/consul/aws", "1.0.0.tar.gz"},
+		{"/leading/slash.zip", "prefix/leading", "slash.zip"},
+		{"Upper/Case/file.zip", "prefix/upper/case", "file.zip-"},
+		{"dir/.hidden", "prefix/dir", "hidden-"},
+	}
+	for _, tt := range tests {
+		repository, tag := s.reference(tt.path)
+		if repository != tt.wantRepository || !strings.HasPrefix(tag, tt.wantTag) {
+			t.Errorf("reference(%q) = %s:%s, want %s:%s...", tt.path, repository, tag, tt.wantRepository, tt.wantTag)
+		}
+		if !tagPattern.MatchString(tag) {
+			t.Errorf("reference(%q) tag %q is not a valid tag", tt.path, tag)
+		}
+	}
+
+	// Paths that sanitize alike still get distinct tags
+	_, a := s.reference("Dir/file.zip")
+	_, b := s.reference("dir!/file.zip")
+	if a == b {
+		t.Errorf("distinct paths share tag %s", a)
+	}
+}
@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/terraform-registry/terraform-registry/internal/config"
+	"github.com/terraform-registry/terraform-registry/internal/crypto"
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// BackendQueryParam names the query parameter that tells the file serving endpoint which
+// backend a locally served file lives in
+const BackendQueryParam = "backend"
+
+// defaultFileURLTTL is how long signed file URLs requested without a TTL remain valid
+const defaultFileURLTTL = 15 * time.Minute
+
+// ConfigSource looks up storage configurations saved through the storage config API
+type ConfigSource interface {
+	GetStorageConfig(ctx context.Context, id uuid.UUID) (*models.StorageConfig, error)
+}
+
+// ReplicationQueueStore persists the replica operations of replicated storage configurations
+type ReplicationQueueStore interface {
+	EnqueueReplication(ctx context.Context, configID, replicaID uuid.UUID, operation, path string) error
+}
+
+// Registry resolves the storage backend an artifact lives in from the storage_backend value
+// recorded with it. Values are either a backend type from the server configuration
+// ("local", "s3", ...) or the ID of a saved storage configuration.
+type Registry struct {
+	cfg            *config.Config
+	defaultBackend Storage
+	configs        ConfigSource
+	replication    ReplicationQueueStore
+	tokenCipher    *crypto.TokenCipher
+	cache          *DiskCache
+	signer         *URLSigner
+	dataKeys       DataKeyStore
+	keyWrapper     KeyWrapper
+
+	backends  map[string]Storage
+	types     map[string]string  // Backend type of each opened backend
+	decorated map[string]Storage // Opened backends with encryption and caching applied
+	cached    map[string]*CachingStorage
+	mu        sync.Mutex
+}
+
+// NewRegistry creates a backend registry. defaultBackend is the backend new artifacts are
+// written to and is used for rows recorded before storage_backend was meaningful.
+func NewRegistry(cfg *config.Config, defaultBackend Storage, configs ConfigSource, replication ReplicationQueueStore, tokenCipher *crypto.TokenCipher) *Registry {
+	return &Registry{
+		cfg:            cfg,
+		defaultBackend: defaultBackend,
+		configs:        configs,
+		replication:    replication,
+		tokenCipher:    tokenCipher,
+		backends:       make(map[string]Storage),
+		types:          make(map[string]string),
+		decorated:      make(map[string]Storage),
+		cached:         make(map[string]*CachingStorage),
+	}
+}
+
+// SetCache reads the cloud backends opened from now on through cache
+func (r *Registry) SetCache(cache *DiskCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = cache
+}
+
+// SetEncryption encrypts files written to the backends opened from now on, keeping their data
+// keys in dataKeys wrapped by wrapper
+func (r *Registry) SetEncryption(dataKeys DataKeyStore, wrapper KeyWrapper) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dataKeys = dataKeys
+	r.keyWrapper = wrapper
+}
+
+// SetURLSigner signs the URLs of files served by the registry's file endpoint with signer
+func (r *Registry) SetURLSigner(signer *URLSigner) {
+	r.signer = signer
+}
+
+// URLSigner returns the signer of file endpoint URLs, or nil if they are not signed
+func (r *Registry) URLSigner() *URLSigner {
+	return r.signer
+}
+
+// Default returns the backend new artifacts are written to
+func (r *Registry) Default() Storage {
+	return r.defaultBackend
+}
+
+// DefaultKey returns the backend type of the default backend, as recorded with its artifacts
+func (r *Registry) DefaultKey() string {
+	return r.cfg.Storage.DefaultBackend
+}
+
+// IsDefault reports whether key refers to the default backend
+func (r *Registry) IsDefault(key string) bool {
+	return key == "" || key == "default" || key == r.cfg.Storage.DefaultBackend
+}
+
+// Aliases returns every storage_backend value that refers to the backend identified by key.
+// Artifacts on the default backend may be recorded under its type, "default" or nothing.
+func (r *Registry) Aliases(key string) []string {
+	if !r.IsDefault(key) {
+		return []string{key}
+	}
+	return []string{"", "default", r.cfg.Storage.DefaultBackend}
+}
+
+// Resolve returns the backend identified by key, opening and caching it on first use
+func (r *Registry) Resolve(ctx context.Context, key string) (Storage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.IsDefault(key) {
+		return r.defaultBackend, nil
+	}
+	if decorated, ok := r.decorated[key]; ok {
+		return decorated, nil
+	}
+	backend, err := r.resolve(ctx, key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Local disks need no cache; replicated backends read from replicas that are cached when used directly
+	if backendType := r.types[key]; r.cache != nil && backendType != "local" && backendType != "replicated" {
+		cached := NewCachingStorage(backend, r.cache, key, r.cfg.Server.BaseURL, r.cfg.Storage.Cache.ProxyDownloads)
+		cached.SetEncrypted(r.keyWrapper != nil)
+		r.cached[key] = cached
+		backend = cached
+	}
+	// Files are encrypted before they reach the cache and the backend, so neither holds plaintext
+	if r.keyWrapper != nil {
+		backend = NewEncryptedStorage(backend, key, r.dataKeys, r.keyWrapper, r.cfg.Server.BaseURL)
+	}
+
+	r.decorated[key] = backend
+	return backend, nil
+}
+
+// resolve opens the backend identified by key. The caller must hold r.mu.
+func (r *Registry) resolve(ctx context.Context, key string, depth int) (Storage, error) {
+	if r.IsDefault(key) {
+		return r.defaultBackend, nil
+	}
+	if backend, ok := r.backends[key]; ok {
+		return backend, nil
+	}
+
+	var backend Storage
+	backendType := key
+	if configID, err := uuid.Parse(key); err == nil {
+		storageConfig, err := r.configs.GetStorageConfig(ctx, configID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get storage config: %w", err)
+		}
+		if storageConfig == nil {
+			return nil, fmt.Errorf("storage config %s not found", key)
+		}
+		backendType = storageConfig.BackendType
+		if storageConfig.BackendType == "replicated" {
+			if backend, err = r.replicated(ctx, storageConfig, depth); err != nil {
+				return nil, err
+			}
+		} else if backend, err = NewFromStorageConfig(storageConfig, r.tokenCipher, r.cfg.Server.BaseURL); err != nil {
+			return nil, err
+		}
+	} else {
+		// Another backend type from the server configuration, e.g. artifacts written
+		// before the default backend was changed
+		typeCfg := *r.cfg
+		typeCfg.Storage.DefaultBackend = key
+		if backend, err = NewStorage(&typeCfg); err != nil {
+			return nil, err
+		}
+	}
+
+	r.backends[key] = backend
+	r.types[key] = backendType
+	return backend, nil
+}
+
+// GetURL returns a download URL for an object in the backend identified by key. URLs served
+// through the file endpoint are tagged with the backend so the file is read from the right place,
+// and signed with an expiry when a URL signer is set.
+func (r *Registry) GetURL(ctx context.Context, key, path string, ttl time.Duration) (string, error) {
+	backend, err := r.Resolve(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	downloadURL, err := backend.GetURL(ctx, path, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	filesPrefix := r.cfg.Server.BaseURL + "/v1/files/"
+	if !strings.HasPrefix(downloadURL, filesPrefix) {
+		return downloadURL, nil
+	}
+
+	filePath, rawQuery, _ := strings.Cut(strings.TrimPrefix(downloadURL, filesPrefix), "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid download URL: %w", err)
+	}
+	if !r.IsDefault(key) {
+		query.Set(BackendQueryParam, key)
+	}
+	if r.signer != nil {
+		if ttl <= 0 {
+			ttl = defaultFileURLTTL
+		}
+		r.signer.Sign(filePath, query, time.Now().Add(ttl), PrincipalFromContext(ctx))
+	}
+	if len(query) == 0 {
+		return filesPrefix + filePath, nil
+	}
+
+	return filesPrefix + filePath + "?" + query.Encode(), nil
+}
+
+// Delete removes an object from the backend identified by key
+func (r *Registry) Delete(ctx context.Context, key, path string) error {
+	backend, err := r.Resolve(ctx, key)
+	if err != nil {
+		return err
+	}
+	return backend.Delete(ctx, path)
+}
+
+// Forget drops a cached backend so the next use picks up changes to its storage config.
+// Replicated backends using it as a replica are dropped too.
+func (r *Registry) Forget(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.backends, key)
+	delete(r.types, key)
+	delete(r.decorated, key)
+	if cached, ok := r.cached[key]; ok {
+		cached.Purge()
+		delete(r.cached, key)
+	}
+	for cachedKey, backend := range r.backends {
+		if replicated, ok := backend.(*ReplicatedStorage); ok && replicated.Uses(key) {
+			delete(r.backends, cachedKey)
+			delete(r.types, cachedKey)
+			delete(r.decorated, cachedKey)
+		}
+	}
+}
+
+// replicated opens a replicated storage configuration. Replicas must be plain backends.
+func (r *Registry) replicated(ctx context.Context, sc *models.StorageConfig, depth int) (Storage, error) {
+	if depth > 0 {
+		return nil, fmt.Errorf("replicated storage config %s cannot be used as a replica", sc.ID)
+	}
+	if !sc.ReplicationPrimaryID.Valid || len(sc.ReplicationSecondaryIDs) == 0 {
+		return nil, fmt.Errorf("replicated storage config %s needs a primary and at least one secondary", sc.ID)
+	}
+	if r.replication == nil {
+		return nil, fmt.Errorf("replicated storage is not available")
+	}
+
+	open := func(key string) (Replica, error) {
+		backend, err := r.resolve(ctx, key, depth+1)
+		if err != nil {
+			return Replica{}, fmt.Errorf("failed to open replica %s: %w", key, err)
+		}
+		return Replica{Key: key, Storage: backend}, nil
+	}
+
+	primary, err := open(sc.ReplicationPrimaryID.UUID.String())
+	if err != nil {
+		return nil, err
+	}
+	secondaries := make([]Replica, 0, len(sc.ReplicationSecondaryIDs))
+	for _, id := range sc.ReplicationSecondaryIDs {
+		secondary, err := open(id)
+		if err != nil {
+			return nil, err
+		}
+		secondaries = append(secondaries, secondary)
+	}
+
+	queue := &configReplicationQueue{store: r.replication, configID: sc.ID}
+	return NewReplicatedStorage(primary, secondaries, sc.ReplicationMode.String, queue), nil
+}
+
+// configReplicationQueue queues replica operations of one replicated storage configuration
+type configReplicationQueue struct {
+	store    ReplicationQueueStore
+	configID uuid.UUID
+}
+
+// Enqueue records an operation on the replica identified by replicaKey
+func (q *configReplicationQueue) Enqueue(ctx context.Context, replicaKey, operation, path string) error {
+	replicaID, err := uuid.Parse(replicaKey)
+	if err != nil {
+		return fmt.Errorf("invalid replica: %s", replicaKey)
+	}
+	return q.store.EnqueueReplication(ctx, q.configID, replicaID, operation, path)
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/config"
+)
+
+func TestRegistryAliases(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Storage.DefaultBackend = "s3"
+	r := NewRegistry(cfg, nil, nil, nil, nil)
+
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"s3", []string{"", "default", "s3"}},
+		{"default", []string{"", "default", "s3"}},
+		{"", []string{"", "default", "s3"}},
+		{"local", []string{"local"}},
+		{"6f1c1c5e-8a57-4a4b-9d43-1a3c9b7e2f10", []string{"6f1c1c5e-8a57-4a4b-9d43-1a3c9b7e2f10"}},
+	}
+	for _, tt := range tests {
+		if got := r.Aliases(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Aliases(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
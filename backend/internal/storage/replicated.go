@@ -0,0 +1,424 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"google.golang.org/api/googleapi"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// replicaHealthCooldown is how long a replica that failed an operation is tried last
+const replicaHealthCooldown = 30 * time.Second
+
+// ReplicationQueue records replica operations to be carried out in the background
+type ReplicationQueue interface {
+	Enqueue(ctx context.Context, replicaKey, operation, path string) error
+}
+
+// Replica is one member of a replicated storage, identified by its storage config ID
+type Replica struct {
+	Key     string
+	Storage Storage
+}
+
+// ReplicatedStorage implements the Storage interface over a primary backend and one or more
+// secondaries. Writes and deletes are fanned out to every replica, synchronously or through a
+// replication queue; reads are served by the primary and fall back to a healthy secondary.
+type ReplicatedStorage struct {
+	primary     Replica
+	secondaries []Replica
+	mode        string
+	queue       ReplicationQueue
+
+	unhealthyUntil map[string]time.Time
+	mu             sync.Mutex
+}
+
+// NewReplicatedStorage creates a replicated storage. queue is required in async mode and is
+// used in sync mode to record deletes that could not be applied to a secondary.
+func NewReplicatedStorage(primary Replica, secondaries []Replica, mode string, queue ReplicationQueue) *ReplicatedStorage {
+	if mode == "" {
+		mode = models.ReplicationModeSync
+	}
+	return &ReplicatedStorage{
+		primary:        primary,
+		secondaries:    secondaries,
+		mode:           mode,
+		queue:          queue,
+		unhealthyUntil: make(map[string]time.Time),
+	}
+}
+
+// Upload stores a file on the primary and replicates it to every secondary. In sync mode the
+// upload fails, and is removed everywhere, unless every secondary received an identical copy.
+func (s *ReplicatedStorage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*UploadResult, error) {
+	result, err := s.primary.Storage.Upload(ctx, path, reader, size)
+	if err != nil {
+		s.noteFailure(ctx, s.primary.Key, err)
+		return nil, err
+	}
+
+	if s.mode == models.ReplicationModeAsync {
+		for _, secondary := range s.secondaries {
+			if err := s.queue.Enqueue(ctx, secondary.Key, models.ReplicationOpUpload, path); err != nil {
+				log.Printf("Failed to queue replication of %s to %s: %v", path, secondary.Key, err)
+			}
+		}
+		return result, nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(s.secondaries))
+	for i, secondary := range s.secondaries {
+		wg.Add(1)
+		go func(i int, secondary Replica) {
+			defer wg.Done()
+			errs[i] = s.copy(ctx, s.primary, secondary, path, result.Checksum)
+		}(i, secondary)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		_ = s.primary.Storage.Delete(ctx, path)
+		for i, secondary := range s.secondaries {
+			if errs[i] == nil {
+				_ = secondary.Storage.Delete(ctx, path)
+			}
+		}
+		return nil, fmt.Errorf("failed to replicate %s: %w", path, err)
+	}
+
+	return result, nil
+}
+
+// Download retrieves a file from the first healthy replica that has it
+func (s *ReplicatedStorage) Download(ctx context.Context, path string) (io.ReadCloser, error) {
+	var lastErr error
+	for _, replica := range s.candidates() {
+		reader, err := replica.Storage.Download(ctx, path)
+		if err == nil {
+			return reader, nil
+		}
+		s.noteFailure(ctx, replica.Key, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// DownloadRange reads part of a file from the first healthy replica that serves it
+func (s *ReplicatedStorage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	var lastErr error
+	for _, replica := range s.candidates() {
+		reader, err := replica.Storage.DownloadRange(ctx, path, offset, length)
+		if err == nil {
+			return reader, nil
+		}
+		s.noteFailure(ctx, replica.Key, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Delete removes a file from every replica. In async mode secondaries are cleaned up by the
+// replication queue; in sync mode deletes that fail are queued for retry.
+func (s *ReplicatedStorage) Delete(ctx context.Context, path string) error {
+	var errs []error
+	if err := s.primary.Storage.Delete(ctx, path); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, secondary := range s.secondaries {
+		if s.mode == models.ReplicationModeSync {
+			if err := secondary.Storage.Delete(ctx, path); err == nil {
+				continue
+			}
+		}
+		if err := s.queue.Enqueue(ctx, secondary.Key, models.ReplicationOpDelete, path); err != nil {
+			errs = append(errs, fmt.Errorf("failed to queue delete on %s: %w", secondary.Key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetURL returns a download URL from the first healthy replica that has the file
+func (s *ReplicatedStorage) GetURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	lastErr := fmt.Errorf("file not found: %s", path)
+	for _, replica := range s.candidates() {
+		exists, err := replica.Storage.Exists(ctx, path)
+		if err != nil {
+			s.noteFailure(ctx, replica.Key, err)
+			lastErr = err
+			continue
+		}
+		if !exists {
+			continue
+		}
+		url, err := replica.Storage.GetURL(ctx, path, ttl)
+		if err == nil {
+			return url, nil
+		}
+		s.noteFailure(ctx, replica.Key, err)
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// Exists checks if any replica has the file
+func (s *ReplicatedStorage) Exists(ctx context.Context, path string) (bool, error) {
+	var lastErr error
+	for _, replica := range s.candidates() {
+		exists, err := replica.Storage.Exists(ctx, path)
+		if err != nil {
+			s.noteFailure(ctx, replica.Key, err)
+			lastErr = err
+			continue
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, lastErr
+}
+
+// GetMetadata retrieves file metadata from the first healthy replica that has the file
+func (s *ReplicatedStorage) GetMetadata(ctx context.Context, path string) (*FileMetadata, error) {
+	var lastErr error
+	for _, replica := range s.candidates() {
+		metadata, err := replica.Storage.GetMetadata(ctx, path)
+		if err == nil {
+			return metadata, nil
+		}
+		s.noteFailure(ctx, replica.Key, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// List lists the files of the primary replica, which holds every file the others should
+func (s *ReplicatedStorage) List(ctx context.Context, prefix string, fn func(*FileMetadata) error) error {
+	lister, ok := s.primary.Storage.(Lister)
+	if !ok {
+		return fmt.Errorf("storage backend %s cannot list files", s.primary.Key)
+	}
+	return lister.List(ctx, prefix, fn)
+}
+
+// Replicas returns the primary followed by the secondaries
+func (s *ReplicatedStorage) Replicas() []Replica {
+	return append([]Replica{s.primary}, s.secondaries...)
+}
+
+// Uses reports whether key identifies one of the replicas
+func (s *ReplicatedStorage) Uses(key string) bool {
+	for _, replica := range s.Replicas() {
+		if replica.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Diverged returns the keys of replicas whose copy of path is missing or differs in size or
+// SHA256 checksum from the copy on the primary, or from the other replicas when the primary
+// lost it. Copies whose metadata carries no checksum are hashed.
+func (s *ReplicatedStorage) Diverged(ctx context.Context, path string) ([]string, error) {
+	type copyInfo struct {
+		size     int64
+		checksum string
+	}
+	copies := make(map[string]copyInfo)
+	var reference *copyInfo
+	for _, replica := range s.Replicas() {
+		metadata, err := replica.Storage.GetMetadata(ctx, path)
+		if err != nil {
+			exists, existsErr := replica.Storage.Exists(ctx, path)
+			if existsErr != nil {
+				return nil, fmt.Errorf("failed to check %s on %s: %w", path, replica.Key, existsErr)
+			}
+			if exists {
+				return nil, fmt.Errorf("failed to get metadata of %s on %s: %w", path, replica.Key, err)
+			}
+			continue
+		}
+		checksum := metadata.Checksum
+		if checksum == "" {
+			if checksum, err = hashReplica(ctx, replica, path); err != nil {
+				return nil, err
+			}
+		}
+		info := copyInfo{size: metadata.Size, checksum: strings.ToLower(checksum)}
+		copies[replica.Key] = info
+		if reference == nil {
+			reference = &info
+		}
+	}
+	if reference == nil {
+		return nil, fmt.Errorf("no replica has %s", path)
+	}
+
+	var diverged []string
+	for _, replica := range s.Replicas() {
+		if info, ok := copies[replica.Key]; !ok || info != *reference {
+			diverged = append(diverged, replica.Key)
+		}
+	}
+	return diverged, nil
+}
+
+// hashReplica returns the SHA256 hex digest of a replica's copy of path
+func hashReplica(ctx context.Context, replica Replica, path string) (string, error) {
+	reader, err := replica.Storage.Download(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from %s: %w", path, replica.Key, err)
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", fmt.Errorf("failed to hash %s on %s: %w", path, replica.Key, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Repair copies path to the replica identified by key from the primary, or from a secondary
+// when the primary is the replica being repaired or does not have the file
+func (s *ReplicatedStorage) Repair(ctx context.Context, key, path string) error {
+	target, ok := s.replica(key)
+	if !ok {
+		return fmt.Errorf("unknown replica: %s", key)
+	}
+
+	var lastErr error
+	for _, source := range s.Replicas() {
+		if source.Key == key {
+			continue
+		}
+		exists, err := source.Storage.Exists(ctx, path)
+		if err != nil || !exists {
+			lastErr = err
+			continue
+		}
+		checksum := ""
+		if metadata, err := source.Storage.GetMetadata(ctx, path); err == nil {
+			checksum = metadata.Checksum
+		}
+		if err := s.copy(ctx, source, target, path, checksum); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no replica has %s", path)
+	}
+	return lastErr
+}
+
+// RemoveFrom deletes path from the replica identified by key
+func (s *ReplicatedStorage) RemoveFrom(ctx context.Context, key, path string) error {
+	replica, ok := s.replica(key)
+	if !ok {
+		return fmt.Errorf("unknown replica: %s", key)
+	}
+	return replica.Storage.Delete(ctx, path)
+}
+
+// copy streams path from one replica to another. When checksum is known the copy must match it.
+func (s *ReplicatedStorage) copy(ctx context.Context, from, to Replica, path, checksum string) error {
+	reader, err := from.Storage.Download(ctx, path)
+	if err != nil {
+		s.noteFailure(ctx, from.Key, err)
+		return fmt.Errorf("failed to read %s from %s: %w", path, from.Key, err)
+	}
+	defer reader.Close()
+
+	metadata, err := from.Storage.GetMetadata(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to get metadata of %s on %s: %w", path, from.Key, err)
+	}
+
+	result, err := to.Storage.Upload(ctx, path, reader, metadata.Size)
+	if err != nil {
+		s.noteFailure(ctx, to.Key, err)
+		return fmt.Errorf("failed to write %s to %s: %w", path, to.Key, err)
+	}
+	if checksum != "" && result.Checksum != "" && !strings.EqualFold(checksum, result.Checksum) {
+		_ = to.Storage.Delete(ctx, path)
+		return fmt.Errorf("checksum mismatch replicating %s to %s", path, to.Key)
+	}
+
+	return nil
+}
+
+// candidates orders the replicas for reads: healthy ones first, primary before secondaries
+func (s *ReplicatedStorage) candidates() []Replica {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var healthy, unhealthy []Replica
+	for _, replica := range s.Replicas() {
+		if until, ok := s.unhealthyUntil[replica.Key]; ok && now.Before(until) {
+			unhealthy = append(unhealthy, replica)
+		} else {
+			healthy = append(healthy, replica)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// noteFailure demotes a replica whose operation failed because the replica itself is
+// unreachable or failing. Files it does not hold and requests cancelled by the caller say
+// nothing about its health.
+func (s *ReplicatedStorage) noteFailure(ctx context.Context, key string, err error) {
+	if ctx.Err() != nil || !isReplicaFault(err) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unhealthyUntil[key] = time.Now().Add(replicaHealthCooldown)
+}
+
+// isReplicaFault reports whether err is a transport error or a server-side (5xx) failure
+func isReplicaFault(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var statusErr interface{ HTTPStatusCode() int } // S3 and OCI registry responses
+	if errors.As(err, &statusErr) {
+		return statusErr.HTTPStatusCode() >= http.StatusInternalServerError
+	}
+	var azureErr *azcore.ResponseError
+	if errors.As(err, &azureErr) {
+		return azureErr.StatusCode >= http.StatusInternalServerError
+	}
+	var gcsErr *googleapi.Error
+	if errors.As(err, &gcsErr) {
+		return gcsErr.Code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+func (s *ReplicatedStorage) replica(key string) (Replica, bool) {
+	for _, replica := range s.Replicas() {
+		if replica.Key == key {
+			return replica, true
+		}
+	}
+	return Replica{}, false
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/terraform-registry/terraform-registry/internal/db/models"
+)
+
+// httpStatusError mimics SDK errors that expose the status of a failed response
+type httpStatusError int
+
+func (e httpStatusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e httpStatusError) HTTPStatusCode() int { return int(e) }
+
+func newTestReplicated(primary, secondary *memoryStorage) *ReplicatedStorage {
+	return NewReplicatedStorage(
+		Replica{Key: "primary", Storage: primary},
+		[]Replica{{Key: "secondary", Storage: secondary}},
+		models.ReplicationModeSync, nil,
+	)
+}
+
+func TestReplicatedDemotesOnlyFaultyReplicas(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		demoted bool
+	}{
+		{"not found", fmt.Errorf("file not found: x"), false},
+		{"client error", fmt.Errorf("get object: %w", httpStatusError(403)), false},
+		{"server error", fmt.Errorf("get object: %w", httpStatusError(503)), true},
+		{"transport error", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary := newMemoryStorage(), newMemoryStorage()
+			secondary.files["a.zip"] = []byte("payload")
+			primary.failWith = tt.err
+			s := newTestReplicated(primary, secondary)
+
+			reader, err := s.Download(context.Background(), "a.zip")
+			if err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			data, _ := io.ReadAll(reader)
+			reader.Close()
+			if string(data) != "payload" {
+				t.Errorf("Download() = %q, want the secondary's copy", data)
+			}
+
+			demoted := s.candidates()[0].Key != "primary"
+			if demoted != tt.demoted {
+				t.Errorf("primary demoted = %v, want %v", demoted, tt.demoted)
+			}
+		})
+	}
+}
+
+func TestReplicatedCancelledRequestDoesNotDemote(t *testing.T) {
+	primary, secondary := newMemoryStorage(), newMemoryStorage()
+	primary.failWith = &net.OpError{Op: "read", Net: "tcp", Err: context.Canceled}
+	s := newTestReplicated(primary, secondary)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.GetMetadata(ctx, "a.zip"); err == nil {
+		t.Fatal("GetMetadata succeeded although no replica has the file")
+	}
+	if s.candidates()[0].Key != "primary" {
+		t.Error("primary demoted by a request the caller cancelled")
+	}
+}
+
+func TestReplicatedDiverged(t *testing.T) {
+	tests := []struct {
+		name        string
+		primary     map[string][]byte
+		secondary   map[string][]byte
+		noChecksums bool
+		want        []string
+	}{
+		{
+			name:      "identical copies",
+			primary:   map[string][]byte{"a.zip": []byte("payload")},
+			secondary: map[string][]byte{"a.zip": []byte("payload")},
+		},
+		{
+			name:      "missing on secondary",
+			primary:   map[string][]byte{"a.zip": []byte("payload")},
+			secondary: map[string][]byte{},
+			want:      []string{"secondary"},
+		},
+		{
+			name:      "missing on primary",
+			primary:   map[string][]byte{},
+			secondary: map[string][]byte{"a.zip": []byte("payload")},
+			want:      []string{"primary"},
+		},
+		{
+			name:      "same size, different content",
+			primary:   map[string][]byte{"a.zip": []byte("payload")},
+			secondary: map[string][]byte{"a.zip": []byte("paylaod")},
+			want:      []string{"secondary"},
+		},
+		{
+			name:        "same size, different content, no stored checksums",
+			primary:     map[string][]byte{"a.zip": []byte("payload")},
+			secondary:   map[string][]byte{"a.zip": []byte("paylaod")},
+			noChecksums: true,
+			want:        []string{"secondary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, secondary := newMemoryStorage(), newMemoryStorage()
+			primary.files, secondary.files = tt.primary, tt.secondary
+			secondary.noChecksums = tt.noChecksums
+			s := newTestReplicated(primary, secondary)
+
+			got, err := s.Diverged(context.Background(), "a.zip")
+			if err != nil {
+				t.Fatalf("Diverged: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diverged() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
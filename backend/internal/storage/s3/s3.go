@@ -199,19 +199,27 @@ func New(cfg *appconfig.S3StorageConfig) (*S3Storage, error) {
 	}, nil
 }
 
-// Upload stores a file in S3
+// Upload stores a file in S3. Objects up to storage.MultipartThreshold are sent in a single
+// request; larger objects are streamed as a multipart upload so they are never held in memory.
 func (s *S3Storage) Upload(ctx context.Context, path string, reader io.Reader, size int64) (*storage.UploadResult, error) {
-	// Read all content to calculate checksum
-	// For very large files, consider using multipart upload with streaming hash
-	data, err := io.ReadAll(reader)
+	knownChecksum := storage.KnownChecksum(reader)
+
+	// Read up to the threshold to decide between a single request and a multipart upload
+	data, err := io.ReadAll(io.LimitReader(reader, storage.MultipartThreshold+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data: %w", err)
 	}
+	if len(data) > storage.MultipartThreshold {
+		return s.uploadParts(ctx, path, io.MultiReader(bytes.NewReader(data), reader), storage.MultipartPartSize, knownChecksum)
+	}
 
 	// Calculate SHA256 checksum
 	hasher := sha256.New()
 	hasher.Write(data)
 	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if knownChecksum != "" && knownChecksum != checksum {
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", knownChecksum, checksum)
+	}
 
 	// Upload to S3
 	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
@@ -248,6 +256,28 @@ func (s *S3Storage) Download(ctx context.Context, path string) (io.ReadCloser, e
 	return result.Body, nil
 }
 
+// DownloadRange retrieves part of a file from S3 with a ranged GET
+func (s *S3Storage) DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		if length == 0 {
+			return io.NopCloser(bytes.NewReader(nil)), nil
+		}
+		byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	}
+
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download range from S3: %w", err)
+	}
+
+	return result.Body, nil
+}
+
 // Delete removes a file from S3
 func (s *S3Storage) Delete(ctx context.Context, path string) error {
 	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
@@ -416,6 +446,38 @@ func (s *S3Storage) ListObjects(ctx context.Context, prefix string, maxKeys int3
 	return keys, nil
 }
 
+// List calls fn for every object whose key starts with prefix
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(*storage.FileMetadata) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key == nil {
+				continue
+			}
+			metadata := &storage.FileMetadata{
+				Path: *obj.Key,
+				Size: aws.ToInt64(obj.Size),
+			}
+			if obj.LastModified != nil {
+				metadata.LastModified = *obj.LastModified
+			}
+			if err := fn(metadata); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // DeletePrefix deletes all objects with a given prefix
 func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
 	// List all objects with prefix
@@ -454,16 +516,36 @@ func (s *S3Storage) DeletePrefix(ctx context.Context, prefix string) error {
 // UploadMultipart uploads a large file using multipart upload
 // Recommended for files larger than 100MB
 func (s *S3Storage) UploadMultipart(ctx context.Context, path string, reader io.Reader, partSize int64) (*storage.UploadResult, error) {
-	// Create multipart upload
-	createResp, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+	return s.uploadParts(ctx, path, reader, partSize, storage.KnownChecksum(reader))
+}
+
+// uploadParts streams reader to S3 as a multipart upload, hashing it on the way. When the
+// checksum is known up front it is recorded on creation and verified once every part is sent;
+// otherwise it is recorded afterwards by copying the object onto itself.
+func (s *S3Storage) uploadParts(ctx context.Context, path string, reader io.Reader, partSize int64, knownChecksum string) (*storage.UploadResult, error) {
+	input := &s3.CreateMultipartUploadInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path),
-	})
+	}
+	if knownChecksum != "" {
+		input.Metadata = map[string]string{"sha256": knownChecksum}
+	}
+
+	// Create multipart upload
+	createResp, err := s.client.CreateMultipartUpload(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create multipart upload: %w", err)
 	}
 
 	uploadID := createResp.UploadId
+	abort := func() {
+		s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(path),
+			UploadId: uploadID,
+		})
+	}
+
 	hasher := sha256.New()
 	var completedParts []types.CompletedPart
 	partNumber := int32(1)
@@ -480,19 +562,16 @@ func (s *S3Storage) UploadMultipart(ctx context.Context, path string, reader io.
 
 			// Upload part
 			partResp, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
-				Bucket:     aws.String(s.bucket),
-				Key:        aws.String(path),
-				UploadId:   uploadID,
-				PartNumber: aws.Int32(partNumber),
-				Body:       bytes.NewReader(buf[:n]),
+				Bucket:        aws.String(s.bucket),
+				Key:           aws.String(path),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(partNumber),
+				Body:          bytes.NewReader(buf[:n]),
+				ContentLength: aws.Int64(int64(n)),
 			})
 			if err != nil {
 				// Abort the multipart upload on error
-				s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-					Bucket:   aws.String(s.bucket),
-					Key:      aws.String(path),
-					UploadId: uploadID,
-				})
+				abort()
 				return nil, fmt.Errorf("failed to upload part %d: %w", partNumber, err)
 			}
 
@@ -508,15 +587,17 @@ func (s *S3Storage) UploadMultipart(ctx context.Context, path string, reader io.
 		}
 		if readErr != nil {
 			// Abort on read error
-			s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-				Bucket:   aws.String(s.bucket),
-				Key:      aws.String(path),
-				UploadId: uploadID,
-			})
+			abort()
 			return nil, fmt.Errorf("failed to read data: %w", readErr)
 		}
 	}
 
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if knownChecksum != "" && knownChecksum != checksum {
+		abort()
+		return nil, fmt.Errorf("checksum mismatch: expected %s, got %s", knownChecksum, checksum)
+	}
+
 	// Complete multipart upload
 	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
 		Bucket:   aws.String(s.bucket),
@@ -530,21 +611,21 @@ func (s *S3Storage) UploadMultipart(ctx context.Context, path string, reader io.
 		return nil, fmt.Errorf("failed to complete multipart upload: %w", err)
 	}
 
-	checksum := hex.EncodeToString(hasher.Sum(nil))
-
-	// Update metadata with checksum (requires a copy operation)
-	_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
-		Bucket:            aws.String(s.bucket),
-		Key:               aws.String(path),
-		CopySource:        aws.String(fmt.Sprintf("%s/%s", s.bucket, path)),
-		MetadataDirective: types.MetadataDirectiveReplace,
-		Metadata: map[string]string{
-			"sha256": checksum,
-		},
-	})
-	if err != nil {
-		// Non-fatal: upload succeeded but metadata update failed
-		// Log this in production
+	if knownChecksum == "" {
+		// Update metadata with checksum (requires a copy operation)
+		_, err = s.client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:            aws.String(s.bucket),
+			Key:               aws.String(path),
+			CopySource:        aws.String(fmt.Sprintf("%s/%s", s.bucket, path)),
+			MetadataDirective: types.MetadataDirectiveReplace,
+			Metadata: map[string]string{
+				"sha256": checksum,
+			},
+		})
+		if err != nil {
+			// Non-fatal: upload succeeded but metadata update failed
+			// Log this in production
+		}
 	}
 
 	return &storage.UploadResult{
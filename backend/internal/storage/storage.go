@@ -15,6 +15,10 @@ type Storage interface {
 	// Download retrieves a file and returns a reader
 	Download(ctx context.Context, path string) (io.ReadCloser, error)
 
+	// DownloadRange retrieves length bytes of a file starting at offset
+	// A negative length reads to the end of the file
+	DownloadRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+
 	// Delete removes a file from storage
 	Delete(ctx context.Context, path string) error
 
@@ -30,6 +34,25 @@ type Storage interface {
 	GetMetadata(ctx context.Context, path string) (*FileMetadata, error)
 }
 
+// Lister is implemented by backends that can enumerate the files they hold
+type Lister interface {
+	// List calls fn for every file whose path starts with prefix, stopping at the first error.
+	// Listed metadata carries the path, size and modification time but not the checksum.
+	List(ctx context.Context, prefix string, fn func(*FileMetadata) error) error
+}
+
+// LimitReadCloser returns a ReadCloser reading at most n bytes from rc, or rc itself when n
+// is negative. Closing it closes rc.
+func LimitReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
+	if n < 0 {
+		return rc
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, n), rc}
+}
+
 // UploadResult contains information about an uploaded file
 type UploadResult struct {
 	// Path is the storage path where the file was stored
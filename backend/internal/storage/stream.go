@@ -0,0 +1,33 @@
+package storage
+
+import "io"
+
+const (
+	// MultipartThreshold is the size above which cloud backends upload in parts rather than
+	// in a single request. Smaller objects are buffered in memory; larger ones never are.
+	MultipartThreshold = 16 << 20 // 16MB
+
+	// MultipartPartSize is the size of each part of a multipart upload
+	MultipartPartSize = 16 << 20 // 16MB
+)
+
+// checksummedReader is a reader whose SHA256 checksum is already known
+type checksummedReader struct {
+	io.Reader
+	checksum string
+}
+
+// WithChecksum annotates reader with the hex SHA256 checksum of its contents, letting backends
+// record the checksum when the object is created instead of updating it after the upload.
+// Backends still hash what they store and reject uploads that do not match.
+func WithChecksum(reader io.Reader, checksum string) io.Reader {
+	return &checksummedReader{Reader: reader, checksum: checksum}
+}
+
+// KnownChecksum returns the checksum reader was annotated with by WithChecksum, or ""
+func KnownChecksum(reader io.Reader) string {
+	if r, ok := reader.(*checksummedReader); ok {
+		return r.checksum
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters of signed file URLs
+const (
+	ExpiresQueryParam   = "expires"
+	SignatureQueryParam = "signature"
+	BoundQueryParam     = "bound"
+)
+
+var (
+	// ErrURLUnsigned is returned for a file URL without a signature
+	ErrURLUnsigned = errors.New("file URL is not signed")
+
+	// ErrURLSignatureInvalid is returned for a file URL whose signature does not match
+	ErrURLSignatureInvalid = errors.New("file URL signature is invalid")
+
+	// ErrURLExpired is returned for a file URL past its expiry
+	ErrURLExpired = errors.New("file URL has expired")
+)
+
+// URLSigner signs and verifies the URLs of files served by the registry's file endpoint, which
+// has no authentication of its own. A signature covers the file path, the expiry and every other
+// query parameter, so neither can be altered. When principal binding is enabled, URLs issued to
+// an authenticated caller also cover the caller and are only accepted from them.
+type URLSigner struct {
+	key           []byte
+	bindPrincipal bool
+}
+
+// NewURLSigner creates a URL signer keyed by secret
+func NewURLSigner(secret string, bindPrincipal bool) *URLSigner {
+	// Derive a dedicated key so file URL signatures can't be confused with other uses of the secret
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("terraform-registry file URL signing"))
+	return &URLSigner{
+		key:           mac.Sum(nil),
+		bindPrincipal: bindPrincipal,
+	}
+}
+
+// Sign adds an expiry and signature to the query of a URL for filePath. principal is the caller
+// the URL is issued to, if any.
+func (s *URLSigner) Sign(filePath string, query url.Values, expires time.Time, principal string) {
+	query.Del(SignatureQueryParam)
+	query.Del(BoundQueryParam)
+	query.Set(ExpiresQueryParam, strconv.FormatInt(expires.Unix(), 10))
+	if s.bindPrincipal && principal != "" {
+		query.Set(BoundQueryParam, "1")
+	} else {
+		principal = ""
+	}
+	query.Set(SignatureQueryParam, s.signature(filePath, query, principal))
+}
+
+// Verify checks the signature and expiry of a request for filePath. principal is the caller
+// making the request, checked against URLs bound to the caller they were issued to.
+func (s *URLSigner) Verify(filePath string, query url.Values, principal string, now time.Time) error {
+	signature := query.Get(SignatureQueryParam)
+	if signature == "" {
+		return ErrURLUnsigned
+	}
+	expires, err := strconv.ParseInt(query.Get(ExpiresQueryParam), 10, 64)
+	if err != nil {
+		return ErrURLSignatureInvalid
+	}
+	if query.Get(BoundQueryParam) == "" {
+		principal = ""
+	}
+
+	expected := s.signature(filePath, query, principal)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrURLSignatureInvalid
+	}
+	if now.Unix() > expires {
+		return ErrURLExpired
+	}
+
+	return nil
+}
+
+// signature computes the MAC of a file path, its query without the signature, and a principal
+func (s *URLSigner) signature(filePath string, query url.Values, principal string) string {
+	signed := url.Values{}
+	for key, values := range query {
+		if key != SignatureQueryParam {
+			signed[key] = values
+		}
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(filePath))
+	mac.Write([]byte{0})
+	mac.Write([]byte(signed.Encode()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(principal))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal records the caller download URLs generated with ctx are issued to
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	if principal == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the caller recorded by WithPrincipal
+func PrincipalFromContext(ctx context.Context) string {
+	principal, _ := ctx.Value(principalContextKey{}).(string)
+	return principal
+}
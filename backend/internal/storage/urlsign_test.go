@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestURLSignerVerify(t *testing.T) {
+	const filePath = "modules/acme/vpc/aws/1.0.0.tar.gz"
+	issued := time.Unix(1_700_000_000, 0)
+	expires := issued.Add(15 * time.Minute)
+
+	tests := []struct {
+		name          string
+		bindPrincipal bool
+		issuedTo      string
+		// change alters the signed request before it is verified
+		change func(path *string, query url.Values, principal *string, now *time.Time)
+		want   error
+	}{
+		{name: "valid", change: func(*string, url.Values, *string, *time.Time) {}},
+		{name: "valid until expiry", change: func(_ *string, _ url.Values, _ *string, now *time.Time) { *now = expires }},
+		{name: "expired", change: func(_ *string, _ url.Values, _ *string, now *time.Time) { *now = expires.Add(time.Second) }, want: ErrURLExpired},
+		{name: "missing signature", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Del(SignatureQueryParam) }, want: ErrURLUnsigned},
+		{name: "tampered signature", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Set(SignatureQueryParam, "00") }, want: ErrURLSignatureInvalid},
+		{name: "tampered path", change: func(p *string, _ url.Values, _ *string, _ *time.Time) { *p = "modules/acme/vpc/aws/2.0.0.tar.gz" }, want: ErrURLSignatureInvalid},
+		{
+			name:   "extended expiry",
+			change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Set(ExpiresQueryParam, "9999999999") },
+			want:   ErrURLSignatureInvalid,
+		},
+		{name: "unparseable expiry", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Set(ExpiresQueryParam, "soon") }, want: ErrURLSignatureInvalid},
+		{name: "added query parameter", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Set("download", "1") }, want: ErrURLSignatureInvalid},
+		{name: "tampered backend", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Set(BackendQueryParam, "local") }, want: ErrURLSignatureInvalid},
+		{name: "removed backend", change: func(_ *string, q url.Values, _ *string, _ *time.Time) { q.Del(BackendQueryParam) }, want: ErrURLSignatureInvalid},
+		{
+			name: "bound to caller", bindPrincipal: true, issuedTo: "user:alice",
+			change: func(_ *string, _ url.Values, p *string, _ *time.Time) { *p = "user:alice" },
+		},
+		{
+			name: "bound to another caller", bindPrincipal: true, issuedTo: "user:alice",
+			change: func(_ *string, _ url.Values, p *string, _ *time.Time) { *p = "user:mallory" },
+			want:   ErrURLSignatureInvalid,
+		},
+		{
+			name: "bound url used anonymously", bindPrincipal: true, issuedTo: "user:alice",
+			change: func(_ *string, _ url.Values, p *string, _ *time.Time) { *p = "" },
+			want:   ErrURLSignatureInvalid,
+		},
+		{
+			name: "binding stripped", bindPrincipal: true, issuedTo: "user:alice",
+			change: func(_ *string, q url.Values, p *string, _ *time.Time) { q.Del(BoundQueryParam); *p = "" },
+			want:   ErrURLSignatureInvalid,
+		},
+		{
+			name: "anonymous url under binding", bindPrincipal: true,
+			change: func(_ *string, _ url.Values, p *string, _ *time.Time) { *p = "user:mallory" },
+		},
+		{
+			name: "binding disabled", issuedTo: "user:alice",
+			change: func(_ *string, _ url.Values, p *string, _ *time.Time) { *p = "user:mallory" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewURLSigner("test-secret", tt.bindPrincipal)
+			query := url.Values{BackendQueryParam: {"s3-archive"}}
+			signer.Sign(filePath, query, expires, tt.issuedTo)
+
+			path, principal, now := filePath, "", issued
+			tt.change(&path, query, &principal, &now)
+
+			if err := signer.Verify(path, query, principal, now); !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLSignerKeys(t *testing.T) {
+	const filePath = "providers/acme/dns/1.0.0/linux/amd64/terraform-provider-dns.zip"
+	expires := time.Now().Add(time.Minute)
+
+	query := url.Values{}
+	NewURLSigner("secret-one", false).Sign(filePath, query, expires, "")
+	if err := NewURLSigner("secret-two", false).Verify(filePath, query, "", time.Now()); !errors.Is(err, ErrURLSignatureInvalid) {
+		t.Errorf("Verify with another secret = %v, want %v", err, ErrURLSignatureInvalid)
+	}
+
+	// Signing again replaces the previous signature and binding rather than adding to them
+	signer := NewURLSigner("secret-one", true)
+	signer.Sign(filePath, query, expires, "user:alice")
+	signer.Sign(filePath, query, expires, "")
+	if query.Has(BoundQueryParam) || len(query[SignatureQueryParam]) != 1 {
+		t.Fatalf("re-signed query = %v, want a single unbound signature", query)
+	}
+	if err := signer.Verify(filePath, query, "user:bob", time.Now()); err != nil {
+		t.Errorf("Verify re-signed URL = %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package validation
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModuleCall is a module block that calls a module from a Terraform registry
+type ModuleCall struct {
+	Name     string // Label of the module block
+	Source   string // Registry address, e.g. "hashicorp/consul/aws" or "app.terraform.io/acme/vpc/aws"
+	Hostname string // Registry host, empty for the public registry
+	Version  string // Version constraint, if any
+	File     string // File that declares the call
+}
+
+// RequiredProvider is a provider listed in a required_providers block
+type RequiredProvider struct {
+	Name        string   // Local name, e.g. "aws"
+	Source      string   // Source address, e.g. "hashicorp/aws"
+	Constraints []string // Distinct version constraints across the module and its submodules
+}
+
+// registrySourcePattern matches [<HOSTNAME>/]<NAMESPACE>/<NAME>/<PROVIDER> module addresses
+var registrySourcePattern = regexp.MustCompile(`^(?:([a-zA-Z0-9][a-zA-Z0-9.-]*\.[a-zA-Z]{2,}(?::\d+)?)/)?([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9]+)$`)
+
+// vcsShorthandHosts are hosts Terraform treats as VCS shorthands rather than registries
+var vcsShorthandHosts = map[string]bool{"github.com": true, "bitbucket.org": true}
+
+// RegistryModuleCalls returns the module blocks that reference registry modules, in the
+// module root and its submodules. Examples are excluded since they are not dependencies.
+func (m *ModuleContents) RegistryModuleCalls() []ModuleCall {
+	var calls []ModuleCall
+	for _, name := range sortedKeys(m.TerraformFiles) {
+		if isExamplePath(name) {
+			continue
+		}
+		for _, block := range ParseTerraformConfig(m.TerraformFiles[name]) {
+			if block.Type != "module" || len(block.Labels) == 0 {
+				continue
+			}
+			source, ok := block.StringAttribute("source")
+			if !ok {
+				continue
+			}
+			hostname, address, ok := parseRegistrySource(source)
+			if !ok {
+				continue
+			}
+			version, _ := block.StringAttribute("version")
+			calls = append(calls, ModuleCall{
+				Name:     block.Labels[0],
+				Source:   address,
+				Hostname: hostname,
+				Version:  version,
+				File:     path.Clean(name),
+			})
+		}
+	}
+	return calls
+}
+
+// RequiredProviders returns the providers required by the module root and its submodules,
+// merging the version constraints declared for the same source
+func (m *ModuleContents) RequiredProviders() []RequiredProvider {
+	bySource := make(map[string]*RequiredProvider)
+	for _, name := range sortedKeys(m.TerraformFiles) {
+		if isExamplePath(name) {
+			continue
+		}
+		for _, block := range ParseTerraformConfig(m.TerraformFiles[name]) {
+			if block.Type != "terraform" {
+				continue
+			}
+			for _, rp := range block.BlocksOfType("required_providers") {
+				for _, localName := range sortedKeys(rp.Attributes) {
+					source, constraint := parseRequiredProvider(localName, rp.Attributes[localName])
+					provider, exists := bySource[source]
+					if !exists {
+						provider = &RequiredProvider{Name: localName, Source: source}
+						bySource[source] = provider
+					}
+					if constraint != "" && !containsString(provider.Constraints, constraint) {
+						provider.Constraints = append(provider.Constraints, constraint)
+					}
+				}
+			}
+		}
+	}
+
+	providers := make([]RequiredProvider, 0, len(bySource))
+	for _, provider := range bySource {
+		providers = append(providers, *provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Source < providers[j].Source })
+	return providers
+}
+
+// parseRegistrySource splits a registry module address into hostname and namespace/name/provider.
+// A "//subdir" suffix is dropped, since the dependency is on the whole module package.
+// Local paths, URLs and VCS shorthands are rejected.
+func parseRegistrySource(source string) (hostname, address string, ok bool) {
+	if strings.Contains(source, "::") || strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+		return "", "", false
+	}
+	if pkg, _, found := strings.Cut(source, "//"); found {
+		source = pkg
+	}
+	match := registrySourcePattern.FindStringSubmatch(source)
+	if match == nil || vcsShorthandHosts[strings.ToLower(match[1])] {
+		return "", "", false
+	}
+	return strings.ToLower(match[1]), match[2] + "/" + match[3] + "/" + match[4], true
+}
+
+// parseRequiredProvider reads a required_providers entry in either the object form
+// { source = "hashicorp/aws", version = "~> 5.0" } or the legacy version-string form
+func parseRequiredProvider(localName, expr string) (source, constraint string) {
+	source = "hashicorp/" + localName
+	if value, err := strconv.Unquote(expr); err == nil {
+		return source, strings.TrimSpace(value)
+	}
+
+	attrs := ParseObjectAttributes(expr)
+	if raw, ok := attrs["source"]; ok {
+		if value, err := strconv.Unquote(raw); err == nil && value != "" {
+			source = value
+		}
+	}
+	if raw, ok := attrs["version"]; ok {
+		if value, err := strconv.Unquote(raw); err == nil {
+			constraint = strings.TrimSpace(value)
+		}
+	}
+	return strings.ToLower(source), constraint
+}
+
+func isExamplePath(name string) bool {
+	return strings.HasPrefix(name, "examples/") || strings.Contains(name, "/examples/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
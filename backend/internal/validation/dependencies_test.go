@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRegistrySource(t *testing.T) {
+	tests := []struct {
+		source   string
+		hostname string
+		address  string
+		ok       bool
+	}{
+		{"hashicorp/consul/aws", "", "hashicorp/consul/aws", true},
+		{"app.terraform.io/Acme/vpc/aws", "app.terraform.io", "Acme/vpc/aws", true},
+		{"hashicorp/consul/aws//modules/consul-cluster", "", "hashicorp/consul/aws", true},
+		{"registry.example.com:8443/acme/net/aws//vpc", "registry.example.com:8443", "acme/net/aws", true},
+		{"./modules/vpc", "", "", false},
+		{"../shared", "", "", false},
+		{"github.com/acme/terraform-vpc", "", "", false},
+		{"github.com/acme/vpc/aws", "", "", false},
+		{"git::https://example.com/vpc.git", "", "", false},
+		{"https://example.com/vpc.zip", "", "", false},
+	}
+	for _, tt := range tests {
+		hostname, address, ok := parseRegistrySource(tt.source)
+		if hostname != tt.hostname || address != tt.address || ok != tt.ok {
+			t.Errorf("parseRegistrySource(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.source, hostname, address, ok, tt.hostname, tt.address, tt.ok)
+		}
+	}
+}
+
+func TestRegistryModuleCalls(t *testing.T) {
+	m := &ModuleContents{TerraformFiles: map[string]string{
+		"main.tf": `module "consul" {
+  source  = "hashicorp/consul/aws//modules/consul-cluster"
+  version = "~> 0.11"
+}
+
+module "local" {
+  source = "./modules/local"
+}
+`,
+		"examples/basic/main.tf": "module \"x\" {\n  source = \"acme/x/aws\"\n}\n",
+	}}
+	want := []ModuleCall{{
+		Name:    "consul",
+		Source:  "hashicorp/consul/aws",
+		Version: "~> 0.11",
+		File:    "main.tf",
+	}}
+	if got := m.RegistryModuleCalls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RegistryModuleCalls() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRequiredProviders(t *testing.T) {
+	m := &ModuleContents{TerraformFiles: map[string]string{
+		"versions.tf": `terraform {
+  required_providers {
+    aws    = { source = "hashicorp/aws", version = ">= 5.0" }
+    random = "~> 3.0"
+  }
+}
+`,
+		"modules/x/versions.tf": `terraform {
+  required_providers {
+    aws = {
+      source  = "HashiCorp/AWS"
+      version = "< 6.0"
+    }
+  }
+}
+`,
+	}}
+	want := []RequiredProvider{
+		{Name: "aws", Source: "hashicorp/aws", Constraints: []string{"< 6.0", ">= 5.0"}},
+		{Name: "random", Source: "hashicorp/random", Constraints: []string{"~> 3.0"}},
+	}
+	if got := m.RequiredProviders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RequiredProviders() = %+v, want %+v", got, want)
+	}
+}
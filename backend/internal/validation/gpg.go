@@ -3,6 +3,7 @@ package validation
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
@@ -190,21 +191,32 @@ func NormalizeGPGKey(key string) string {
 
 // ValidateProviderBinary performs basic validation on a provider binary file
 func ValidateProviderBinary(data []byte, maxSize int64) error {
-	if len(data) == 0 {
+	return ValidateProviderArchive(bytes.NewReader(data), int64(len(data)), maxSize)
+}
+
+// ValidateProviderArchive performs basic validation on a provider binary of the given size,
+// reading only its first bytes
+func ValidateProviderArchive(reader io.ReaderAt, size int64, maxSize int64) error {
+	if size == 0 {
 		return fmt.Errorf("provider binary cannot be empty")
 	}
 
-	if int64(len(data)) > maxSize {
-		return fmt.Errorf("provider binary too large: %d bytes (max %d bytes)", len(data), maxSize)
+	if size > maxSize {
+		return fmt.Errorf("provider binary too large: %d bytes (max %d bytes)", size, maxSize)
 	}
 
 	// Check for ZIP magic bytes (PK\x03\x04 or PK\x05\x06 for empty ZIP)
-	if len(data) < 4 {
+	if size < 4 {
 		return fmt.Errorf("provider binary too small to be a valid ZIP file")
 	}
 
-	if !bytes.HasPrefix(data, []byte{0x50, 0x4B, 0x03, 0x04}) && // PK\x03\x04
-		!bytes.HasPrefix(data, []byte{0x50, 0x4B, 0x05, 0x06}) { // PK\x05\x06 (empty)
+	magic := make([]byte, 4)
+	if _, err := reader.ReadAt(magic, 0); err != nil {
+		return fmt.Errorf("failed to read provider binary: %w", err)
+	}
+
+	if !bytes.HasPrefix(magic, []byte{0x50, 0x4B, 0x03, 0x04}) && // PK\x03\x04
+		!bytes.HasPrefix(magic, []byte{0x50, 0x4B, 0x05, 0x06}) { // PK\x05\x06 (empty)
 		return fmt.Errorf("provider binary is not a valid ZIP file")
 	}
 
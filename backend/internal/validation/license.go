@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// licenseFingerprint identifies a license by phrases that appear in its text.
+// A license matches when every phrase is present in the normalized text.
+type licenseFingerprint struct {
+	spdx    string
+	phrases []string
+}
+
+// licenseFingerprints are checked in order, so licenses whose text contains another
+// license's phrases (AGPL and LGPL contain GPL, BSD-3 contains BSD-2) come first
+var licenseFingerprints = []licenseFingerprint{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"BUSL-1.1", []string{"business source license", "1.1"}},
+	{"EPL-2.0", []string{"eclipse public license", "2.0"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name of"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms", "this list of conditions and the following disclaimer"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice shall be included"}},
+}
+
+// spdxIdentifierPattern matches an explicit SPDX-License-Identifier tag
+var spdxIdentifierPattern = regexp.MustCompile(`(?i)SPDX-License-Identifier:\s*([A-Za-z0-9.\-+]+)`)
+
+// whitespacePattern collapses runs of whitespace when normalizing license text
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// DetectLicense returns the SPDX identifier of a license text, or "" if it is not recognized
+func DetectLicense(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	if match := spdxIdentifierPattern.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+
+	normalized := strings.ToLower(whitespacePattern.ReplaceAllString(text, " "))
+	for _, fingerprint := range licenseFingerprints {
+		matched := true
+		for _, phrase := range fingerprint.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return fingerprint.spdx
+		}
+	}
+
+	return ""
+}
+
+// DetectLicenseInZip returns the SPDX identifier of the root license file in a provider
+// binary zip, or "" if the zip has no recognizable license file
+func DetectLicenseInZip(data []byte) string {
+	return DetectLicenseInZipReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// DetectLicenseInZipReader is DetectLicenseInZip for a zip of the given size read in place,
+// such as an upload spooled to disk
+func DetectLicenseInZipReader(reader io.ReaderAt, size int64) string {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return ""
+	}
+
+	for _, file := range zipReader.File {
+		if strings.Contains(file.Name, "/") || !isLicenseName(file.Name) {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return ""
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxContentFileSize))
+		rc.Close()
+		if err != nil {
+			return ""
+		}
+
+		return DetectLicense(string(content))
+	}
+
+	return ""
+}
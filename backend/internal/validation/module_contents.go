@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxContentFileSize is the largest file whose contents are loaded for inspection (1MB)
+const maxContentFileSize = 1024 * 1024
+
+// ModuleContents holds the parts of a module archive needed for publish-time checks
+type ModuleContents struct {
+	Files          []string          // All regular file paths in the archive
+	TerraformFiles map[string]string // .tf file contents keyed by path
+	Readme         string            // Root README content, if any
+	LicenseFile    string            // Path of the root LICENSE/COPYING file, if any
+	LicenseText    string            // Content of the root LICENSE/COPYING file
+}
+
+// licenseFileNames are the root-level files recognised as a module license
+var licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md", "LICENCE.txt", "COPYING", "COPYING.md", "COPYING.txt"}
+
+// LoadModuleContents reads a tar.gz module archive and collects the Terraform
+// configuration, README and license files
+func LoadModuleContents(reader io.Reader) (*ModuleContents, error) {
+	gzReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+
+	contents := &ModuleContents{
+		TerraformFiles: make(map[string]string),
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileName := strings.TrimPrefix(header.Name, "./")
+		contents.Files = append(contents.Files, fileName)
+
+		isRoot := !strings.Contains(fileName, "/")
+		isTerraform := strings.HasSuffix(fileName, ".tf")
+		isReadme := isRoot && isReadmeName(fileName) && contents.Readme == ""
+		isLicense := isRoot && isLicenseName(fileName) && contents.LicenseFile == ""
+
+		if !isTerraform && !isReadme && !isLicense {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tarReader, maxContentFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+
+		switch {
+		case isTerraform:
+			contents.TerraformFiles[fileName] = string(content)
+		case isReadme:
+			contents.Readme = string(content)
+		case isLicense:
+			contents.LicenseFile = fileName
+			contents.LicenseText = string(content)
+		}
+	}
+
+	return contents, nil
+}
+
+// RootTerraformFiles returns the .tf files in the module root, excluding nested modules and examples
+func (m *ModuleContents) RootTerraformFiles() map[string]string {
+	root := make(map[string]string)
+	for name, content := range m.TerraformFiles {
+		if path.Dir(name) == "." {
+			root[name] = content
+		}
+	}
+	return root
+}
+
+func isReadmeName(fileName string) bool {
+	for _, name := range []string{"README.md", "README", "README.txt"} {
+		if strings.EqualFold(fileName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLicenseName(fileName string) bool {
+	for _, name := range licenseFileNames {
+		if strings.EqualFold(fileName, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ModuleArchiveToZip rewrites a module's tar.gz archive as the zip OpenTofu installs modules
+// from over OCI. The output depends only on the archive, so its digest is stable: entries keep
+// the archive's order, names, modes and modification times, and are stored uncompressed.
+// Links and other special files are left out.
+func ModuleArchiveToZip(archive io.Reader, w io.Writer) error {
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return fmt.Errorf("invalid module archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	zw := zip.NewWriter(w)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid module archive: %w", err)
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if name == "" {
+				continue
+			}
+			if !strings.HasSuffix(name, "/") {
+				name += "/"
+			}
+		case tar.TypeReg:
+		default:
+			continue
+		}
+
+		entry := &zip.FileHeader{
+			Name:     name,
+			Method:   zip.Store,
+			Modified: header.ModTime.UTC(),
+		}
+		entry.SetMode(header.FileInfo().Mode())
+		fw, err := zw.CreateHeader(entry)
+		if err != nil {
+			return fmt.Errorf("failed to write module zip: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(fw, tr); err != nil {
+				return fmt.Errorf("failed to write module zip: %w", err)
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+// ModuleZipDigest returns the "sha256:"-prefixed digest and the size of the zip
+// ModuleArchiveToZip converts archive to, without keeping the zip
+func ModuleZipDigest(archive io.Reader) (string, int64, error) {
+	hasher := sha256.New()
+	counter := &countingWriter{w: hasher}
+	if err := ModuleArchiveToZip(archive, counter); err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), counter.n, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
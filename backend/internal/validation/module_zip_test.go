@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+)
+
+// tarEntry is an entry of an ordered test archive; content is ignored for non-regular files
+type tarEntry struct {
+	header  tar.Header
+	content string
+}
+
+var moduleZipTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func moduleEntries() []tarEntry {
+	return []tarEntry{
+		{header: tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755, ModTime: moduleZipTime}},
+		{header: tar.Header{Name: "./main.tf", Typeflag: tar.TypeReg, Mode: 0644, ModTime: moduleZipTime}, content: "resource \"null_resource\" \"x\" {}\n"},
+		{header: tar.Header{Name: "modules", Typeflag: tar.TypeDir, Mode: 0755, ModTime: moduleZipTime}},
+		{header: tar.Header{Name: "modules/child.tf", Typeflag: tar.TypeReg, Mode: 0600, ModTime: moduleZipTime}, content: "variable \"x\" {}\n"},
+		{header: tar.Header{Name: "scripts/run.sh", Typeflag: tar.TypeReg, Mode: 0755, ModTime: moduleZipTime}, content: "#!/bin/sh\n"},
+		{header: tar.Header{Name: "link.tf", Typeflag: tar.TypeSymlink, Linkname: "main.tf", ModTime: moduleZipTime}},
+	}
+}
+
+// buildOrderedTarGz packs entries in order, compressing with the given gzip level and header
+func buildOrderedTarGz(t *testing.T, entries []tarEntry, level int, gzipHeader gzip.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz.Header = gzipHeader
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := entry.header
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(entry.content))
+		}
+		if err := tw.WriteHeader(&header); err != nil {
+			t.Fatalf("write header %s: %v", header.Name, err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.content)); err != nil {
+				t.Fatalf("write %s: %v", header.Name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func moduleZipDigest(t *testing.T, archive []byte) string {
+	t.Helper()
+	digest, _, err := ModuleZipDigest(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("ModuleZipDigest: %v", err)
+	}
+	return digest
+}
+
+func TestModuleArchiveToZipContents(t *testing.T) {
+	archive := buildOrderedTarGz(t, moduleEntries(), gzip.DefaultCompression, gzip.Header{})
+
+	var out bytes.Buffer
+	if err := ModuleArchiveToZip(bytes.NewReader(archive), &out); err != nil {
+		t.Fatalf("ModuleArchiveToZip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip: %v", err)
+	}
+
+	want := []struct {
+		name    string
+		mode    string
+		content string
+	}{
+		{"main.tf", "-rw-r--r--", "resource \"null_resource\" \"x\" {}\n"},
+		{"modules/", "drwxr-xr-x", ""},
+		{"modules/child.tf", "-rw-------", "variable \"x\" {}\n"},
+		{"scripts/run.sh", "-rwxr-xr-x", "#!/bin/sh\n"},
+	}
+	if len(zr.File) != len(want) {
+		names := make([]string, 0, len(zr.File))
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		t.Fatalf("zip entries = %v, want %d entries without the root or links", names, len(want))
+	}
+	for i, w := range want {
+		f := zr.File[i]
+		if f.Name != w.name || f.Mode().String() != w.mode || f.Method != zip.Store || !f.Modified.Equal(moduleZipTime) {
+			t.Errorf("entry %d = %s %s method %d modified %s, want %s %s stored at %s",
+				i, f.Name, f.Mode(), f.Method, f.Modified, w.name, w.mode, moduleZipTime)
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(content) != w.content {
+			t.Errorf("%s content = %q, want %q", f.Name, content, w.content)
+		}
+	}
+}
+
+func TestModuleZipDigestStable(t *testing.T) {
+	base := buildOrderedTarGz(t, moduleEntries(), gzip.DefaultCompression, gzip.Header{})
+	digest := moduleZipDigest(t, base)
+
+	// The digest must not change between conversions, nor with how the tar stream was gzipped
+	if again := moduleZipDigest(t, base); again != digest {
+		t.Errorf("converting twice gave %s and %s", digest, again)
+	}
+	recompressed := buildOrderedTarGz(t, moduleEntries(), gzip.BestCompression, gzip.Header{
+		Name:    "module.tar",
+		ModTime: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		OS:      3,
+	})
+	if got := moduleZipDigest(t, recompressed); got != digest {
+		t.Errorf("recompressed archive digest = %s, want %s", got, digest)
+	}
+
+	// The digest must change whenever the extracted module would
+	changes := map[string]func([]tarEntry){
+		"content":  func(e []tarEntry) { e[1].content += "# changed\n" },
+		"name":     func(e []tarEntry) { e[1].header.Name = "./root.tf" },
+		"mode":     func(e []tarEntry) { e[1].header.Mode = 0755 },
+		"mod time": func(e []tarEntry) { e[1].header.ModTime = moduleZipTime.Add(time.Hour) },
+		"order":    func(e []tarEntry) { e[1], e[4] = e[4], e[1] },
+	}
+	for name, change := range changes {
+		entries := moduleEntries()
+		change(entries)
+		archive := buildOrderedTarGz(t, entries, gzip.DefaultCompression, gzip.Header{})
+		if got := moduleZipDigest(t, archive); got == digest {
+			t.Errorf("changing the %s kept digest %s", name, digest)
+		}
+	}
+}
+
+func TestModuleZipDigestMatchesConversion(t *testing.T) {
+	archive := buildOrderedTarGz(t, moduleEntries(), gzip.DefaultCompression, gzip.Header{})
+
+	var out bytes.Buffer
+	if err := ModuleArchiveToZip(bytes.NewReader(archive), &out); err != nil {
+		t.Fatalf("ModuleArchiveToZip: %v", err)
+	}
+	sum := sha256.Sum256(out.Bytes())
+
+	digest, size, err := ModuleZipDigest(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("ModuleZipDigest: %v", err)
+	}
+	if want := "sha256:" + hex.EncodeToString(sum[:]); digest != want {
+		t.Errorf("ModuleZipDigest = %s, want the digest of the served zip %s", digest, want)
+	}
+	if size != int64(out.Len()) {
+		t.Errorf("ModuleZipDigest size = %d, want %d", size, out.Len())
+	}
+}
+
+func TestModuleZipDigestInvalidArchive(t *testing.T) {
+	for name, archive := range map[string][]byte{
+		"not gzip":    []byte("plain text"),
+		"truncated":   buildOrderedTarGz(t, moduleEntries(), gzip.DefaultCompression, gzip.Header{})[:40],
+		"gzip no tar": gzipBytes(t, []byte("not a tar stream, just some bytes that are long enough to read")),
+	} {
+		if _, _, err := ModuleZipDigest(bytes.NewReader(archive)); err == nil {
+			t.Errorf("%s: ModuleZipDigest succeeded", name)
+		}
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
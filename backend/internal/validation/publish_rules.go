@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultVersionPattern is used by the version pattern check when no pattern is configured
+const DefaultVersionPattern = `^v?\d+\.\d+\.\d+$`
+
+// providerCredentialAttributes are provider arguments that carry credentials
+var providerCredentialAttributes = map[string]bool{
+	"access_key":                  true,
+	"secret_key":                  true,
+	"token":                       true,
+	"password":                    true,
+	"client_secret":               true,
+	"client_certificate_password": true,
+	"credentials":                 true,
+	"private_key":                 true,
+	"api_key":                     true,
+	"api_token":                   true,
+	"sas_token":                   true,
+}
+
+// CheckReadmePresent reports a violation when the module has no root README
+func CheckReadmePresent(m *ModuleContents) []string {
+	if strings.TrimSpace(m.Readme) == "" {
+		return []string{"module has no README in its root directory"}
+	}
+	return nil
+}
+
+// CheckLicensePresent reports a violation when the module has no root LICENSE or COPYING file
+func CheckLicensePresent(m *ModuleContents) []string {
+	if m.LicenseFile == "" {
+		return []string{"module has no LICENSE or COPYING file in its root directory"}
+	}
+	return nil
+}
+
+// CheckVariableDescriptions reports root module variables without a description
+func CheckVariableDescriptions(m *ModuleContents) []string {
+	return checkVariables(m, func(v TerraformBlock) bool {
+		desc, ok := v.StringAttribute("description")
+		return ok && strings.TrimSpace(desc) != ""
+	}, "has no description")
+}
+
+// CheckVariableTypes reports root module variables without a type constraint
+func CheckVariableTypes(m *ModuleContents) []string {
+	return checkVariables(m, func(v TerraformBlock) bool {
+		_, ok := v.Attributes["type"]
+		return ok
+	}, "has no type")
+}
+
+func checkVariables(m *ModuleContents, ok func(TerraformBlock) bool, problem string) []string {
+	var violations []string
+	for _, name := range sortedKeys(m.RootTerraformFiles()) {
+		for _, block := range ParseTerraformConfig(m.TerraformFiles[name]) {
+			if block.Type != "variable" || len(block.Labels) == 0 {
+				continue
+			}
+			if !ok(block) {
+				violations = append(violations, fmt.Sprintf("variable %q in %s:%d %s", block.Labels[0], name, block.Line, problem))
+			}
+		}
+	}
+	return violations
+}
+
+// CheckProviderVersionConstraints reports required providers without a version constraint,
+// and modules that declare resources without any required_providers block
+func CheckProviderVersionConstraints(m *ModuleContents) []string {
+	var violations []string
+	hasRequiredProviders := false
+	hasResources := false
+
+	for _, name := range sortedKeys(m.RootTerraformFiles()) {
+		for _, block := range ParseTerraformConfig(m.TerraformFiles[name]) {
+			switch block.Type {
+			case "resource", "data":
+				hasResources = true
+			case "terraform":
+				for _, rp := range block.BlocksOfType("required_providers") {
+					hasRequiredProviders = true
+					for _, provider := range sortedKeys(rp.Attributes) {
+						if !hasVersionConstraint(rp.Attributes[provider]) {
+							violations = append(violations, fmt.Sprintf("required provider %q in %s has no version constraint", provider, name))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if hasResources && !hasRequiredProviders {
+		violations = append(violations, "module declares resources but has no required_providers block")
+	}
+
+	return violations
+}
+
+// hasVersionConstraint reports whether a required_providers entry carries a version.
+// Entries are either a legacy version string or an object with a version attribute.
+func hasVersionConstraint(expr string) bool {
+	if strings.HasPrefix(expr, "\"") {
+		return strings.Trim(expr, "\" ") != ""
+	}
+	attrs := ParseObjectAttributes(expr)
+	version, ok := attrs["version"]
+	return ok && strings.Trim(version, "\" ") != ""
+}
+
+// CheckNoProviderCredentials reports provider blocks that set credential arguments
+func CheckNoProviderCredentials(m *ModuleContents) []string {
+	var violations []string
+	for _, name := range sortedKeys(m.TerraformFiles) {
+		// Examples are root modules and may legitimately configure providers
+		if isExamplePath(name) {
+			continue
+		}
+		for _, block := range ParseTerraformConfig(m.TerraformFiles[name]) {
+			if block.Type != "provider" || len(block.Labels) == 0 {
+				continue
+			}
+			for _, attr := range sortedKeys(block.Attributes) {
+				if providerCredentialAttributes[attr] {
+					violations = append(violations, fmt.Sprintf("provider %q in %s:%d sets credential argument %q", block.Labels[0], path.Clean(name), block.Line, attr))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// CheckVersionPattern reports a violation when the version (or source tag) does not match pattern
+func CheckVersionPattern(version, pattern string) ([]string, error) {
+	if pattern == "" {
+		pattern = DefaultVersionPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern: %w", err)
+	}
+	if !re.MatchString(version) {
+		return []string{fmt.Sprintf("%q does not match required pattern %s", version, pattern)}, nil
+	}
+	return nil, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckVariableDescriptions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "quoted description",
+			src:  "variable \"region\" {\n  description = \"AWS region\"\n  type = string\n}\n",
+		},
+		{
+			name: "heredoc description",
+			src:  "variable \"tags\" {\n  description = <<EOT\nTags applied to\nevery resource\nEOT\n  type = map(string)\n}\n",
+		},
+		{
+			name: "indented heredoc description",
+			src:  "variable \"tags\" {\n  description = <<-EOT\n    Tags applied to every resource\n  EOT\n}\n",
+		},
+		{
+			name: "missing description",
+			src:  "# comment\nvariable \"region\" {\n  type = string\n}\n",
+			want: []string{`variable "region" in variables.tf:2 has no description`},
+		},
+		{
+			name: "blank description",
+			src:  "variable \"region\" {\n  description = \"  \"\n}\n",
+			want: []string{`variable "region" in variables.tf:1 has no description`},
+		},
+		{
+			name: "interpolated description",
+			src:  "variable \"region\" {\n  description = \"Region for ${var.env}\"\n}\n",
+			want: []string{`variable "region" in variables.tf:1 has no description`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &ModuleContents{TerraformFiles: map[string]string{"variables.tf": tt.src}}
+			if got := CheckVariableDescriptions(m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckVariableDescriptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckVariableTypes(t *testing.T) {
+	m := &ModuleContents{TerraformFiles: map[string]string{
+		"variables.tf":           "variable \"typed\" {\n  type = list(string)\n}\n\nvariable \"untyped\" {}\n",
+		"modules/x/main.tf":      "variable \"nested\" {}\n",
+		"examples/basic/main.tf": "variable \"example\" {}\n",
+	}}
+	want := []string{`variable "untyped" in variables.tf:5 has no type`}
+	if got := CheckVariableTypes(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckVariableTypes() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckProviderVersionConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "object form with version",
+			src:  "terraform {\n  required_providers {\n    aws = {\n      source  = \"hashicorp/aws\"\n      version = \"~> 5.0\"\n    }\n  }\n}\nresource \"aws_s3_bucket\" \"b\" {}\n",
+		},
+		{
+			name: "legacy string form",
+			src:  "terraform {\n  required_providers {\n    aws = \"~> 5.0\"\n  }\n}\n",
+		},
+		{
+			name: "object form without version",
+			src:  "terraform {\n  required_providers {\n    aws = { source = \"hashicorp/aws\" }\n  }\n}\n",
+			want: []string{`required provider "aws" in main.tf has no version constraint`},
+		},
+		{
+			name: "resources without required_providers",
+			src:  "resource \"aws_s3_bucket\" \"b\" {\n  bucket = \"x\"\n}\n",
+			want: []string{"module declares resources but has no required_providers block"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &ModuleContents{TerraformFiles: map[string]string{"main.tf": tt.src}}
+			if got := CheckProviderVersionConstraints(m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckProviderVersionConstraints() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNoProviderCredentials(t *testing.T) {
+	m := &ModuleContents{TerraformFiles: map[string]string{
+		"main.tf":                "provider \"aws\" {\n  region     = \"eu-west-1\"\n  access_key = \"x\"\n}\n",
+		"examples/basic/main.tf": "provider \"aws\" {\n  secret_key = \"y\"\n}\n",
+	}}
+	want := []string{`provider "aws" in main.tf:1 sets credential argument "access_key"`}
+	if got := CheckNoProviderCredentials(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckNoProviderCredentials() = %q, want %q", got, want)
+	}
+}
+
+func TestCheckVersionPattern(t *testing.T) {
+	tests := []struct {
+		version    string
+		pattern    string
+		violations int
+		wantErr    bool
+	}{
+		{"1.2.3", "", 0, false},
+		{"v1.2.3", "", 0, false},
+		{"1.2.3-beta1", "", 1, false},
+		{"1.2", `^\d+\.\d+$`, 0, false},
+		{"1.2.3", "(", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := CheckVersionPattern(tt.version, tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CheckVersionPattern(%q, %q) error = %v", tt.version, tt.pattern, err)
+		}
+		if len(got) != tt.violations {
+			t.Errorf("CheckVersionPattern(%q, %q) = %q, want %d violations", tt.version, tt.pattern, got, tt.violations)
+		}
+	}
+}
+
+func TestParseObjectAttributes(t *testing.T) {
+	got := ParseObjectAttributes(`{ source = "hashicorp/aws", "version" = "~> 5.0" }`)
+	want := map[string]string{"source": `"hashicorp/aws"`, "version": `"~> 5.0"`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseObjectAttributes() = %q, want %q", got, want)
+	}
+	if got := ParseObjectAttributes(`"~> 5.0"`); got != nil {
+		t.Errorf("ParseObjectAttributes(string) = %q, want nil", got)
+	}
+}
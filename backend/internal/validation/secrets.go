@@ -0,0 +1,276 @@
+package validation
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultSecretScanEntropyThreshold is the Shannon entropy (bits per character)
+	// above which a variable default is reported as a likely secret
+	DefaultSecretScanEntropyThreshold = 4.5
+
+	// DefaultSecretScanMaxFileSize is the largest file that will be inspected (1MB)
+	DefaultSecretScanMaxFileSize = 1024 * 1024
+
+	// minEntropyStringLength is the shortest default value considered for entropy checks
+	minEntropyStringLength = 20
+
+	// entropyLengthFactor scales the threshold for short values that mix upper case, lower case
+	// and digits. A string of n characters has at most log2(n) bits/char, so a fixed threshold
+	// would only fire on long values.
+	entropyLengthFactor = 0.9
+)
+
+// SecretFinding describes a potential secret detected in a module archive
+type SecretFinding struct {
+	Rule        string `json:"rule"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	Description string `json:"description"`
+	Match       string `json:"match,omitempty"` // Redacted excerpt of the matched value
+}
+
+// SecretScanOptions controls the behaviour of ScanArchiveForSecrets
+type SecretScanOptions struct {
+	EntropyThreshold float64
+	MaxFileSize      int64
+}
+
+// secretPattern is a high-confidence content rule
+type secretPattern struct {
+	rule        string
+	description string
+	re          *regexp.Regexp
+}
+
+// secretPatterns are matched against every line of every text file in the archive.
+// Only patterns with a low false-positive rate belong here.
+var secretPatterns = []secretPattern{
+	{"aws-access-key-id", "AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-access-key", "AWS secret access key", regexp.MustCompile(`(?i)aws_?secret_?access_?key\s*[=:]\s*"?[A-Za-z0-9/+=]{40}\b`)},
+	{"github-token", "GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{"gitlab-token", "GitLab personal access token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`)},
+	{"slack-token", "Slack token", regexp.MustCompile(`\bxox[baprs]-[0-9A-Za-z\-]{10,}\b`)},
+	{"google-api-key", "Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"stripe-secret-key", "Stripe secret key", regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"azure-storage-key", "Azure storage account key", regexp.MustCompile(`AccountKey=[A-Za-z0-9+/]{86}==`)},
+	{"private-key", "Private key", regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+}
+
+// defaultAssignmentPattern matches a string default value in a Terraform variable block
+var defaultAssignmentPattern = regexp.MustCompile(`^\s*default\s*=\s*"([^"]*)"`)
+
+// ScanArchiveForSecrets inspects a tar.gz module archive for credentials, private keys,
+// Terraform state or variable files and high-entropy variable defaults.
+// It returns every finding; callers decide whether findings block the publish.
+func ScanArchiveForSecrets(reader io.Reader, opts SecretScanOptions) ([]SecretFinding, error) {
+	if opts.EntropyThreshold <= 0 {
+		opts.EntropyThreshold = DefaultSecretScanEntropyThreshold
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultSecretScanMaxFileSize
+	}
+
+	gzReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzReader.Close()
+
+	tarReader := tar.NewReader(gzReader)
+
+	var findings []SecretFinding
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileName := strings.TrimPrefix(header.Name, "./")
+
+		if finding := checkSensitiveFilename(fileName); finding != nil {
+			findings = append(findings, *finding)
+		}
+
+		if header.Size > opts.MaxFileSize {
+			continue
+		}
+
+		content, err := io.ReadAll(io.LimitReader(tarReader, opts.MaxFileSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+
+		if isBinaryContent(content) {
+			continue
+		}
+
+		findings = append(findings, scanFileContent(fileName, content, opts)...)
+	}
+
+	return findings, nil
+}
+
+// checkSensitiveFilename flags files that should never be published in a module
+func checkSensitiveFilename(fileName string) *SecretFinding {
+	base := strings.ToLower(path.Base(fileName))
+
+	switch {
+	case strings.HasSuffix(base, ".tfstate") || strings.HasSuffix(base, ".tfstate.backup"):
+		return &SecretFinding{
+			Rule:        "terraform-state-file",
+			File:        fileName,
+			Description: "Terraform state file may contain resource attributes and secrets",
+		}
+	case strings.HasSuffix(base, ".tfvars") || strings.HasSuffix(base, ".tfvars.json"):
+		return &SecretFinding{
+			Rule:        "terraform-variables-file",
+			File:        fileName,
+			Description: "Terraform variable values file may contain credentials",
+		}
+	}
+
+	return nil
+}
+
+// scanFileContent applies the content rules to a single text file
+func scanFileContent(fileName string, content []byte, opts SecretScanOptions) []SecretFinding {
+	var findings []SecretFinding
+	isTerraform := strings.HasSuffix(strings.ToLower(fileName), ".tf")
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), int(opts.MaxFileSize))
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+
+		for _, p := range secretPatterns {
+			if match := p.re.FindString(line); match != "" {
+				findings = append(findings, SecretFinding{
+					Rule:        p.rule,
+					File:        fileName,
+					Line:        lineNumber,
+					Description: p.description,
+					Match:       redactSecret(match),
+				})
+			}
+		}
+
+		if !isTerraform {
+			continue
+		}
+
+		if m := defaultAssignmentPattern.FindStringSubmatch(line); m != nil {
+			value := m[1]
+			if isHighEntropyValue(value, opts.EntropyThreshold) {
+				findings = append(findings, SecretFinding{
+					Rule:        "high-entropy-default",
+					File:        fileName,
+					Line:        lineNumber,
+					Description: fmt.Sprintf("Variable default has high entropy (%.2f bits/char)", shannonEntropy(value)),
+					Match:       redactSecret(value),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// isHighEntropyValue reports whether a default value looks like a random secret
+func isHighEntropyValue(value string, threshold float64) bool {
+	if len(value) < minEntropyStringLength {
+		return false
+	}
+	// Interpolations, sentences and URLs are not secrets on their own
+	if strings.Contains(value, "${") || strings.ContainsAny(value, " \t") || strings.Contains(value, "://") {
+		return false
+	}
+	// Generated secrets mix character classes; resource IDs such as "subnet-0a1b2c3d4e5f67890"
+	// are as random but hex, so they must meet the configured threshold in full
+	required := threshold
+	if mixesCharacterClasses(value) {
+		required = entropyThresholdFor(len(value), threshold)
+	}
+	return shannonEntropy(value) >= required
+}
+
+// mixesCharacterClasses reports whether s contains upper case letters, lower case letters and digits
+func mixesCharacterClasses(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// entropyThresholdFor returns the entropy a value of the given length must reach to be reported:
+// the configured threshold, lowered to a share of the maximum entropy a value that short can have
+func entropyThresholdFor(length int, threshold float64) float64 {
+	return math.Min(threshold, entropyLengthFactor*math.Log2(float64(length)))
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// redactSecret keeps a short prefix of the matched value so admins can locate it
+func redactSecret(value string) string {
+	const visible = 4
+	if len(value) <= visible {
+		return strings.Repeat("*", len(value))
+	}
+	return value[:visible] + strings.Repeat("*", 8)
+}
+
+// isBinaryContent uses the same heuristic as git: a NUL byte in the first 8KB
+func isBinaryContent(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0
+}
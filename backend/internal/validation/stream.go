@@ -0,0 +1,38 @@
+package validation
+
+import (
+	"io"
+	"sync"
+)
+
+// ReadConcurrently reads src once and hands every consumer its own copy of the stream, running
+// the consumers concurrently. A consumer that returns before reading to the end has the rest
+// of its copy discarded, so the others still see the whole stream. It returns each consumer's
+// error by position, and the error from reading src, if any.
+func ReadConcurrently(src io.Reader, consumers ...func(io.Reader) error) ([]error, error) {
+	errs := make([]error, len(consumers))
+	writers := make([]io.Writer, len(consumers))
+	pipes := make([]*io.PipeWriter, len(consumers))
+
+	var wg sync.WaitGroup
+	for i, consume := range consumers {
+		pr, pw := io.Pipe()
+		writers[i] = pw
+		pipes[i] = pw
+
+		wg.Add(1)
+		go func(i int, consume func(io.Reader) error, pr *io.PipeReader) {
+			defer wg.Done()
+			errs[i] = consume(pr)
+			io.Copy(io.Discard, pr)
+		}(i, consume, pr)
+	}
+
+	_, readErr := io.Copy(io.MultiWriter(writers...), src)
+	for _, pw := range pipes {
+		pw.CloseWithError(readErr)
+	}
+	wg.Wait()
+
+	return errs, readErr
+}
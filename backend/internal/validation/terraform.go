@@ -0,0 +1,126 @@
+package validation
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// TerraformBlock is a block parsed from Terraform configuration, e.g. variable "name" { ... }
+//
+// Configuration is parsed with the HCL native syntax parser. Expressions are kept as their source
+// text and are only evaluated when they need no variables or functions.
+type TerraformBlock struct {
+	Type       string
+	Labels     []string
+	Line       int
+	Attributes map[string]string // Raw attribute expressions keyed by name
+	Blocks     []TerraformBlock
+
+	expressions map[string]hclsyntax.Expression
+}
+
+// ParseTerraformConfig parses the top-level blocks of a Terraform configuration file.
+// Syntax errors are tolerated; the blocks that could be parsed are returned.
+func ParseTerraformConfig(src string) []TerraformBlock {
+	file, _ := hclsyntax.ParseConfig([]byte(src), "", hcl.InitialPos)
+	if file == nil {
+		return nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	return convertBlocks(body.Blocks, file.Bytes)
+}
+
+// StringAttribute returns the value of an attribute if it is a literal string, quoted or heredoc
+func (b TerraformBlock) StringAttribute(name string) (string, bool) {
+	expr, ok := b.expressions[name]
+	if !ok {
+		return "", false
+	}
+	return literalString(expr)
+}
+
+// BlocksOfType returns the nested blocks with the given type
+func (b TerraformBlock) BlocksOfType(blockType string) []TerraformBlock {
+	var matches []TerraformBlock
+	for _, nested := range b.Blocks {
+		if nested.Type == blockType {
+			matches = append(matches, nested)
+		}
+	}
+	return matches
+}
+
+// ParseObjectAttributes parses the attributes of an object expression such as
+// { source = "hashicorp/aws", version = "~> 5.0" }
+func ParseObjectAttributes(expr string) map[string]string {
+	src := []byte(strings.TrimSpace(expr))
+	parsed, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	object, ok := parsed.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil
+	}
+
+	attrs := make(map[string]string)
+	for _, item := range object.Items {
+		key := hcl.ExprAsKeyword(item.KeyExpr)
+		if key == "" {
+			// Quoted keys, e.g. { "version" = "~> 5.0" }
+			value, ok := literalString(item.KeyExpr)
+			if !ok {
+				continue
+			}
+			key = value
+		}
+		attrs[key] = sourceText(src, item.ValueExpr.Range())
+	}
+	return attrs
+}
+
+// convertBlocks converts parsed HCL blocks, keeping expression source text from src
+func convertBlocks(blocks hclsyntax.Blocks, src []byte) []TerraformBlock {
+	var converted []TerraformBlock
+	for _, block := range blocks {
+		tb := TerraformBlock{
+			Type:        block.Type,
+			Labels:      block.Labels,
+			Line:        block.TypeRange.Start.Line,
+			Attributes:  make(map[string]string),
+			expressions: make(map[string]hclsyntax.Expression),
+		}
+		if block.Body != nil {
+			for name, attr := range block.Body.Attributes {
+				tb.Attributes[name] = sourceText(src, attr.Expr.Range())
+				tb.expressions[name] = attr.Expr
+			}
+			tb.Blocks = convertBlocks(block.Body.Blocks, src)
+		}
+		converted = append(converted, tb)
+	}
+	return converted
+}
+
+// literalString evaluates an expression that needs no variables or functions to a string
+func literalString(expr hcl.Expression) (string, bool) {
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.String {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// sourceText returns the source of a range, trimmed of surrounding whitespace
+func sourceText(src []byte, rng hcl.Range) string {
+	if rng.Start.Byte < 0 || rng.End.Byte > len(src) || rng.Start.Byte > rng.End.Byte {
+		return ""
+	}
+	return strings.TrimSpace(string(src[rng.Start.Byte:rng.End.Byte]))
+}
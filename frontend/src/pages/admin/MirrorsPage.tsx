@@ -515,8 +515,8 @@ const MirrorsPage: React.FC = () => {
               fullWidth
               value={versionFilterInput}
               onChange={(e) => setVersionFilterInput(e.target.value)}
-              helperText="Filter versions to sync: '3.' (prefix), 'latest:5' (latest N), '>=3.0.0' (semver), or comma-separated list"
-              placeholder="e.g., 3. or latest:5 or >=3.0.0"
+              helperText="Filter versions to sync: a version constraint ('~> 5.0, != 5.31.0'), '3.' (prefix), 'latest:5' (latest N), 'latest:3 within ~> 5.0', or comma-separated list"
+              placeholder="e.g., ~> 5.0 or latest:5 or 3."
             />
 
             <TextField
@@ -653,6 +653,11 @@ class ApiClient {
     return response.data;
   }
 
+  async cancelMirrorSync(id: string) {
+    const response = await this.client.delete(`/api/v1/admin/mirrors/${id}/sync`);
+    return response.data;
+  }
+
   async getMirrorStatus(id: string) {
     const response = await this.client.get(`/api/v1/admin/mirrors/${id}/status`);
     return response.data;
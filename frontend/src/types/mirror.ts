@@ -8,12 +8,13 @@ export interface MirrorConfiguration {
   organization_id?: string;
   namespace_filter?: string; // JSON array string
   provider_filter?: string; // JSON array string
-  version_filter?: string; // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+  version_filter?: string; // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
   platform_filter?: string; // JSON array string of "os/arch" (e.g. ["linux/amd64", "windows/amd64"])
   enabled: boolean;
   sync_interval_hours: number;
+  sync_concurrency: number; // Provider versions downloaded at once
   last_sync_at?: string;
-  last_sync_status?: 'success' | 'failed' | 'in_progress';
+  last_sync_status?: 'success' | 'failed' | 'in_progress' | 'cancelled';
   last_sync_error?: string;
   created_at: string;
   updated_at: string;
@@ -46,10 +47,11 @@ export interface CreateMirrorConfigRequest {
   organization_id?: string;
   namespace_filter?: string[];
   provider_filter?: string[];
-  version_filter?: string; // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+  version_filter?: string; // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
   platform_filter?: string[]; // List of "os/arch" strings (e.g. ["linux/amd64", "windows/amd64"])
   enabled?: boolean;
   sync_interval_hours?: number;
+  sync_concurrency?: number;
 }
 
 export interface UpdateMirrorConfigRequest {
@@ -59,10 +61,11 @@ export interface UpdateMirrorConfigRequest {
   organization_id?: string;
   namespace_filter?: string[];
   provider_filter?: string[];
-  version_filter?: string; // Version filter: "3.", "latest:5", ">=3.0.0", or comma-separated
+  version_filter?: string; // Version filter: constraint ("~> 5.0, != 5.31.0"), "3.", "latest:5 within ~> 5.0", or comma-separated
   platform_filter?: string[]; // List of "os/arch" strings (e.g. ["linux/amd64", "windows/amd64"])
   enabled?: boolean;
   sync_interval_hours?: number;
+  sync_concurrency?: number;
 }
 
 export interface TriggerSyncRequest {